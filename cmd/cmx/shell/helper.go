@@ -73,8 +73,13 @@ func NewCloudProvider(opt *GlobalOptions) (ICloudProvider, error) {
 		return nil, errors.Wrapf(err, "GetProviderFactory")
 	}
 
+	endpoint := opt.CloudEnv
+	if len(opt.Url) > 0 {
+		endpoint = opt.Url
+	}
+
 	p, err := factory.GetProvider(cloudprovider.ProviderConfig{
-		URL:           opt.CloudEnv,
+		URL:           endpoint,
 		Account:       opt.AccessKey,
 		Secret:        opt.Secret,
 		ProxyFunc:     proxyFunc,
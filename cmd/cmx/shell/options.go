@@ -18,9 +18,10 @@ type GlobalOptions struct {
 	Debug      bool   `help:"Debug mode"`
 	SUBCOMMAND string `help:"Cloudmux client subcommand" subcommand:"true"`
 
-	Provider string `help:"Cloud provider" required:"true" choices:"Aliyun|Aws|Azure|Qcloud"`
+	Provider string `help:"Cloud provider" required:"true" choices:"Aliyun|Aws|Azure|Qcloud|Huawei|VMware|Proxmox|OpenStack"`
 
 	CloudEnv  string `help:"Cloud environment" default:"$CLOUDMUX_CLOUD_ENV" choices:"InternationalCloud|FinanceCloud|ChinaCloud|AzureGermanCloud|AzureChinaCloud|AzureUSGovernmentCloud|AzurePublicCloud" metavar:"CLOUDMUX_CLOUD_ENV"`
+	Url       string `help:"Endpoint url of private cloud" default:"$CLOUDMUX_URL" metavar:"CLOUDMUX_URL"`
 	AccessKey string `help:"Access key" default:"$CLOUDMUX_ACCESS_KEY" metavar:"CLOUDMUX_ACCESS_KEY"`
 	Secret    string `help:"Secret" default:"$CLOUDMUX_SECRET" metavar:"CLOUDMUX_SECRET"`
 	Region    string `help:"Default region" default:"$CLOUDMUX_REGION" metavar:"CLOUDMUX_REGION" short-token:"r"`
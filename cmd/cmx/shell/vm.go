@@ -0,0 +1,116 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"context"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud/migrate"
+)
+
+func init() {
+	cmd := NewCommand("vm")
+
+	type VmMigrateOptions struct {
+		ID      string `help:"Source vm id"`
+		WORKDIR string `help:"Directory to store exported images and migration state"`
+
+		StateFile string `help:"Migration state file, default <workdir>/<id>.json"`
+		Format    string `help:"Convert exported image to this format in advance" choices:"qcow2|vmdk|vhd|raw"`
+		ForceStop bool   `help:"Force stop source vm"`
+		ImageName string `help:"Name of the migrated image"`
+		Bucket    string `help:"Upload images to this destination bucket and import them from the bucket"`
+
+		DestProvider  string `help:"Destination cloud provider" required:"true" choices:"Aliyun|Aws|Azure|Qcloud|Huawei|VMware|Proxmox|OpenStack"`
+		DestCloudEnv  string `help:"Destination cloud environment"`
+		DestUrl       string `help:"Destination endpoint url of private cloud"`
+		DestAccessKey string `help:"Destination access key" required:"true"`
+		DestSecret    string `help:"Destination secret" required:"true"`
+		DestRegion    string `help:"Destination region" required:"true"`
+
+		DestZone         string `help:"Destination zone id"`
+		DestHost         string `help:"Destination host id"`
+		DestStoragecache string `help:"Destination storagecache id"`
+
+		Name         string   `help:"Name of destination vm, default is the source vm name"`
+		InstanceType string   `help:"Instance type of destination vm"`
+		Cpu          int      `help:"Cpu count, default is the same as source vm"`
+		MemoryMb     int      `help:"Memory size in MB, default is the same as source vm"`
+		SysDiskType  string   `help:"System disk storage type"`
+		DataDiskType string   `help:"Data disk storage type"`
+		Network      string   `help:"Destination network id"`
+		Secgroup     []string `help:"Destination security group ids"`
+		Password     string   `help:"Login password"`
+		PublicKey    string   `help:"Login public key"`
+	}
+
+	RegionR[VmMigrateOptions](cmd).RequireRegion().Run("migrate", "Migrate vm to another cloud, rerun with the same workdir to resume", func(cli cloudprovider.ICloudRegion, args *VmMigrateOptions) (any, error) {
+		dest, err := NewCloudProvider(&GlobalOptions{
+			Provider:  args.DestProvider,
+			CloudEnv:  args.DestCloudEnv,
+			Url:       args.DestUrl,
+			AccessKey: args.DestAccessKey,
+			Secret:    args.DestSecret,
+			Region:    args.DestRegion,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "destination provider")
+		}
+		region, err := dest.GetProvider().GetIRegionById(args.DestRegion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetIRegionById(%s)", args.DestRegion)
+		}
+		migrator, err := migrate.NewVmMigrator(cli, region, &migrate.SMigrateOptions{
+			SourceVmId: args.ID,
+			WorkDir:    args.WORKDIR,
+			StateFile:  args.StateFile,
+			Format:     args.Format,
+			ForceStop:  args.ForceStop,
+			ImageName:  args.ImageName,
+			Bucket:     args.Bucket,
+
+			TargetZoneId:         args.DestZone,
+			TargetHostId:         args.DestHost,
+			TargetStoragecacheId: args.DestStoragecache,
+
+			Name:         args.Name,
+			InstanceType: args.InstanceType,
+			Cpu:          args.Cpu,
+			MemoryMb:     args.MemoryMb,
+			SysDiskType:  args.SysDiskType,
+			DataDiskType: args.DataDiskType,
+			NetworkId:    args.Network,
+			SecgroupIds:  args.Secgroup,
+			Password:     args.Password,
+			PublicKey:    args.PublicKey,
+
+			Callback: func(stage string, percent float32) {
+				log.Infof("migrate %s %s: %.2f%%", args.ID, stage, percent)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = migrator.Run(context.Background())
+		if err != nil {
+			return nil, errors.Wrap(err, "rerun with the same options to resume")
+		}
+		return migrator.GetState(), nil
+	})
+}
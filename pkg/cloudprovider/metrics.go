@@ -53,6 +53,8 @@ const (
 	METRIC_RESOURCE_TYPE_K8S            TResourceType = "k8s"
 	METRIC_RESOURCE_TYPE_STORAGE        TResourceType = "storage"
 	METRIC_RESOURCE_TYPE_WIRE           TResourceType = "wire"
+	METRIC_RESOURCE_TYPE_EIP            TResourceType = "eip"
+	METRIC_RESOURCE_TYPE_CLUSTER        TResourceType = "cluster"
 	METRIC_RESOURCE_TYPE_CLOUD_ACCOUNT  TResourceType = "cloudaccount_balance"
 	METRIC_RESOURCE_TYPE_MODELARTS_POOL TResourceType = "modelarts"
 )
@@ -162,6 +164,12 @@ const (
 	// 宿主机磁盘写速率
 	// 支持平台: esxi
 	HOST_METRIC_TYPE_DISK_IO_WRITE_BPS TMetricType = "diskio.write_bps"
+	// 宿主机磁盘读IOPS
+	// 支持平台: bingocloud
+	HOST_METRIC_TYPE_DISK_IO_READ_IOPS TMetricType = "diskio.read_iops"
+	// 宿主机磁盘写IOPS
+	// 支持平台: bingocloud
+	HOST_METRIC_TYPE_DISK_IO_WRITE_IOPS TMetricType = "diskio.write_iops"
 	// 宿主机网络入速率
 	// 支持平台: esxi
 	HOST_METRIC_TYPE_NET_BPS_RX TMetricType = "net.bps_recv"
@@ -272,6 +280,39 @@ const (
 	WIRE_METRIC_TYPE_MEM_USAGE            TMetricType = "wire_mem.usage_percent"
 	WIRE_METRIC_TYPE_NET_RT               TMetricType = "wire_net.rt"               // 响应时间ms
 	WIRE_METRIC_TYPE_NET_UNREACHABLE_RATE TMetricType = "wire_net.unreachable_rate" // 不可达率
+
+	// 存储使用率
+	// 支持平台: bingocloud
+	STORAGE_METRIC_TYPE_USAGE TMetricType = "storage.used_percent"
+	// 存储读速率
+	// 支持平台: bingocloud
+	STORAGE_METRIC_TYPE_READ_BPS TMetricType = "storage_io.read_bps"
+	// 存储写速率
+	// 支持平台: bingocloud
+	STORAGE_METRIC_TYPE_WRITE_BPS TMetricType = "storage_io.write_bps"
+	// 存储读IOPS
+	// 支持平台: bingocloud
+	STORAGE_METRIC_TYPE_READ_IOPS TMetricType = "storage_io.read_iops"
+	// 存储写IOPS
+	// 支持平台: bingocloud
+	STORAGE_METRIC_TYPE_WRITE_IOPS TMetricType = "storage_io.write_iops"
+
+	// EIP 入流量
+	// 支持平台: bingocloud
+	EIP_METRIC_TYPE_NET_BPS_RX TMetricType = "eip_netio.bps_recv"
+	// EIP 出流量
+	// 支持平台: bingocloud
+	EIP_METRIC_TYPE_NET_BPS_TX TMetricType = "eip_netio.bps_sent"
+
+	// 集群CPU使用率
+	// 支持平台: bingocloud
+	CLUSTER_METRIC_TYPE_CPU_USAGE TMetricType = "cluster_cpu.usage_active"
+	// 集群内存使用率
+	// 支持平台: bingocloud
+	CLUSTER_METRIC_TYPE_MEM_USAGE TMetricType = "cluster_mem.used_percent"
+	// 集群存储使用率
+	// 支持平台: bingocloud
+	CLUSTER_METRIC_TYPE_DISK_USAGE TMetricType = "cluster_disk.used_percent"
 )
 
 var (
@@ -302,6 +343,8 @@ var (
 		HOST_METRIC_TYPE_MEM_USAGE,
 		HOST_METRIC_TYPE_DISK_IO_READ_BPS,
 		HOST_METRIC_TYPE_DISK_IO_WRITE_BPS,
+		HOST_METRIC_TYPE_DISK_IO_READ_IOPS,
+		HOST_METRIC_TYPE_DISK_IO_WRITE_IOPS,
 		HOST_METRIC_TYPE_NET_BPS_RX,
 		HOST_METRIC_TYPE_NET_BPS_TX,
 	}
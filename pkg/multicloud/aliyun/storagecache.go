@@ -135,10 +135,12 @@ func (self *SStoragecache) UploadImage(ctx context.Context, image *cloudprovider
 }
 
 func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
-	reader, sizeByte, err := image.GetReader(image.ImageId, string(qemuimgfmt.QCOW2))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.QCOW2, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeByte, callback := reader.Size, reader.Callback
 
 	bucketName := strings.ToLower(fmt.Sprintf("imgcache-%s-%s", self.region.GetId(), image.ImageId))
 	exist, err := self.region.IBucketExist(bucketName)
@@ -170,6 +172,15 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 
 	defer bucket.DeleteObject(context.Background(), image.ImageId) // remove object
 
+	return self.importImage(bucketName, image.ImageId, image, callback)
+}
+
+// ImportImageFromBucket 从oss存储桶中的镜像文件导入镜像, 不删除镜像文件
+func (self *SStoragecache) ImportImageFromBucket(ctx context.Context, bucketName, key string, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
+	return self.importImage(bucketName, key, image, callback)
+}
+
+func (self *SStoragecache) importImage(bucketName, key string, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
 	imageBaseName := image.ImageId
 	if imageBaseName[0] >= '0' && imageBaseName[0] <= '9' {
 		imageBaseName = fmt.Sprintf("img%s", image.ImageId)
@@ -179,7 +190,7 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 
 	// check image name, avoid name conflict
 	for {
-		_, err = self.region.GetImageByName(imageName)
+		_, err := self.region.GetImageByName(imageName)
 		if err != nil {
 			if errors.Cause(err) == cloudprovider.ErrNotFound {
 				break
@@ -194,15 +205,15 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 	log.Debugf("Import image %s", imageName)
 
 	// ensure privileges
-	err = self.region.GetClient().EnableImageImport()
+	err := self.region.GetClient().EnableImageImport()
 	if err != nil {
 		return "", errors.Wrapf(err, "EnableImageImport")
 	}
 
-	task, err := self.region.ImportImage(imageName, image.OsArch, image.OsType, image.OsDistribution, bucketName, image.ImageId)
+	task, err := self.region.ImportImage(imageName, image.OsArch, image.OsType, image.OsDistribution, bucketName, key)
 
 	if err != nil {
-		return "", errors.Wrapf(err, "ImportImage %s %s", image.ImageId, bucketName)
+		return "", errors.Wrapf(err, "ImportImage %s %s", key, bucketName)
 	}
 
 	// timeout: 1hour = 3600 seconds
@@ -143,10 +143,12 @@ func (self *SStoragecache) UploadImage(ctx context.Context, image *cloudprovider
 }
 
 func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(progress float32)) (string, error) {
-	reader, sizeByte, err := image.GetReader(image.ImageId, string(qemuimgfmt.QCOW2))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.QCOW2, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeByte, callback := reader.Size, reader.Callback
 
 	bucketName := strings.ToLower(fmt.Sprintf("imgcache-%s-%s", self.region.GetId(), image.ImageId))
 	exist, err := self.region.IBucketExist(bucketName)
@@ -150,10 +150,12 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 
 	defer self.region.DeleteIBucket(bucketName)
 
-	reader, sizeBytes, err := image.GetReader(image.ImageId, string(qemuimgfmt.VMDK))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.VMDK, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeBytes, callback := reader.Size, reader.Callback
 
 	bucket, err := self.region.GetIBucketByName(bucketName)
 	if err != nil {
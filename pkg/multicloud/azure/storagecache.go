@@ -139,27 +139,33 @@ func (self *SStoragecache) checkStorageAccount() (*SStorageAccount, error) {
 }
 
 func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, tmpPath string, callback func(progress float32)) (string, error) {
-	reader, sizeBytes, err := image.GetReader(image.ImageId, string(qemuimgfmt.VHD))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.VHD, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeBytes, callback := reader.Size, reader.Callback
 
 	imageNameOnBlob := image.ImageName
 	if !strings.HasSuffix(imageNameOnBlob, ".vhd") {
 		imageNameOnBlob = fmt.Sprintf("%s.vhd", imageNameOnBlob)
 	}
 	tmpFile := fmt.Sprintf("%s/%s", tmpPath, imageNameOnBlob)
+	os.Remove(tmpFile)
 	defer os.Remove(tmpFile)
-	f, err := os.Create(tmpFile)
-	if err != nil {
-		return "", errors.Wrap(err, "os.Create(tmpFile)")
-	}
-	defer f.Close()
-
-	// 下载占33%
-	r := multicloud.NewProgress(sizeBytes, 33, reader, callback)
-	if _, err := io.Copy(f, r); err != nil {
-		return "", errors.Wrap(err, "io.Copy(f, reader)")
+	// 转换后的镜像已是本地稀疏文件, 以blob名称硬链接, 避免再次写入整个固定大小的vhd
+	if len(reader.Path) == 0 || os.Link(reader.Path, tmpFile) != nil {
+		f, err := os.Create(tmpFile)
+		if err != nil {
+			return "", errors.Wrap(err, "os.Create(tmpFile)")
+		}
+		// 下载占33%
+		r := multicloud.NewProgress(sizeBytes, 33, reader, callback)
+		_, err = multicloud.WriteSparse(f, r)
+		f.Close()
+		if err != nil {
+			return "", errors.Wrap(err, "WriteSparse")
+		}
 	}
 
 	storageaccount, err := self.checkStorageAccount()
@@ -121,16 +121,19 @@ func (self *SBingoCloudClient) getDefaultClient(timeout time.Duration) *http.Cli
 
 func (self *SBingoCloudClient) sign(query string) string {
 	uri, _ := url.Parse(self.endpoint)
+	return self.signRequest(httputils.POST, uri.Host, uri.Path, query)
+}
+
+func (self *SBingoCloudClient) signRequest(method httputils.THttpMethod, host, path, query string) string {
 	items := strings.Split(query, "&")
 	sort.Slice(items, func(i, j int) bool {
 		x0, y0 := strings.Split(items[i], "=")[0], strings.Split(items[j], "=")[0]
 		return x0 < y0
 	})
-	path := "/"
-	if len(uri.Path) > 0 {
-		path = uri.Path
+	if len(path) == 0 {
+		path = "/"
 	}
-	stringToSign := fmt.Sprintf("POST\n%s\n%s\n", uri.Host, path) + strings.Join(items, "&")
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n", method, host, path) + strings.Join(items, "&")
 	hmac := hmac.New(sha256.New, []byte(self.secretKey))
 	hmac.Write([]byte(stringToSign))
 	return base64.StdEncoding.EncodeToString(hmac.Sum(nil))
@@ -281,6 +284,34 @@ func (self *SBingoCloudClient) invoke(action string, params map[string]string) (
 	return obj, nil
 }
 
+func (self *SBingoCloudClient) storageRequest(ctx context.Context, method httputils.THttpMethod, storageId, fileName string, header http.Header, body io.Reader) (*http.Response, error) {
+	uri, err := url.Parse(self.endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "url.Parse(%s)", self.endpoint)
+	}
+	path := fmt.Sprintf("/storage/%s/%s", storageId, fileName)
+	params := url.Values{}
+	sh, _ := time.LoadLocation("Asia/Shanghai")
+	params.Set("Timestamp", time.Now().In(sh).Format("2006-01-02T15:04:05.000Z"))
+	params.Set("AWSAccessKeyId", self.accessKey)
+	params.Set("SignatureVersion", "2")
+	params.Set("SignatureMethod", "HmacSHA256")
+	query := params.Encode()
+	query += "&" + url.Values{"Signature": []string{self.signRequest(method, uri.Host, path, query)}}.Encode()
+	storageUrl := fmt.Sprintf("%s://%s%s?%s", uri.Scheme, uri.Host, path, query)
+	client := self.getDefaultClient(0)
+	resp, err := httputils.Request(client, ctx, method, storageUrl, header, body, self.debug)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", method, path)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, errors.Errorf("%s %s: %s %s", method, path, resp.Status, string(msg))
+	}
+	return resp, nil
+}
+
 func (self *SBingoCloudClient) GetSubAccounts() ([]cloudprovider.SSubAccount, error) {
 	var tags []struct {
 		ResourceId string `json:"resourceId"`
@@ -196,7 +196,11 @@ func (self *SDisk) GetStatus() string {
 	}
 }
 
-func (self *SRegion) GetDisks(id string, maxResult int, nextToken string) ([]SDisk, string, error) {
+func (self *SDisk) SetTags(tags map[string]string, replace bool) error {
+	return self.storage.cluster.region.SetResourceTags(self.VolumeId, tags, replace)
+}
+
+func (self *SRegion) GetDisks(id string, tags map[string]string, maxResult int, nextToken string) ([]SDisk, string, error) {
 	params := map[string]string{}
 	idx := 1
 	if len(id) > 0 {
@@ -204,6 +208,7 @@ func (self *SRegion) GetDisks(id string, maxResult int, nextToken string) ([]SDi
 		params[fmt.Sprintf("Filter.%d.Value.1", idx)] = id
 		idx++
 	}
+	setTagFilter(params, idx, tags)
 
 	if len(nextToken) > 0 {
 		params["NextToken"] = nextToken
@@ -226,14 +231,14 @@ func (self *SRegion) GetDisks(id string, maxResult int, nextToken string) ([]SDi
 }
 
 func (self *SStorage) GetIDisks() ([]cloudprovider.ICloudDisk, error) {
-	part, nextToken, err := self.cluster.region.GetDisks("", MAX_RESULT, "")
+	part, nextToken, err := self.cluster.region.GetDisks("", nil, MAX_RESULT, "")
 	if err != nil {
 		return nil, err
 	}
 	var disks []SDisk
 	disks = append(disks, part...)
 	for len(nextToken) > 0 {
-		part, nextToken, err = self.cluster.region.GetDisks("", MAX_RESULT, nextToken)
+		part, nextToken, err = self.cluster.region.GetDisks("", nil, MAX_RESULT, nextToken)
 		if err != nil {
 			return nil, err
 		}
@@ -262,7 +267,7 @@ func (self *SStorage) GetIDiskById(id string) (cloudprovider.ICloudDisk, error)
 }
 
 func (self *SRegion) GetDisk(id string) (*SDisk, error) {
-	disks, _, err := self.GetDisks(id, 1, "")
+	disks, _, err := self.GetDisks(id, nil, 1, "")
 	if err != nil {
 		return nil, err
 	}
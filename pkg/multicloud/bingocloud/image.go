@@ -16,8 +16,10 @@ package bingocloud
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/imagetools"
 	"yunion.io/x/pkg/util/rbacscope"
@@ -83,7 +85,11 @@ func (self *SImage) GetName() string {
 }
 
 func (self *SImage) Delete(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	return self.cache.region.DeregisterImage(self.ImageId)
+}
+
+func (self *SImage) SetTags(tags map[string]string, replace bool) error {
+	return self.cache.region.SetResourceTags(self.ImageId, tags, replace)
 }
 
 func (self *SImage) GetIStoragecache() cloudprovider.ICloudStoragecache {
@@ -184,16 +190,107 @@ func (self *SImage) GetStatus() string {
 	switch self.ImageState {
 	case "available":
 		return api.CACHED_IMAGE_STATUS_ACTIVE
+	case "pending":
+		return api.CACHED_IMAGE_STATUS_SAVING
+	case "failed":
+		return api.CACHED_IMAGE_STATUS_CACHE_FAILED
 	default:
 		return self.ImageState
 	}
 }
 
-func (self *SRegion) GetImages(id, nextToken string) ([]SImage, string, error) {
+func (self *SImage) Refresh() error {
+	image, err := self.cache.region.GetImageById(self.ImageId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, image)
+}
+
+func (self *SRegion) RegisterImage(opts *cloudprovider.SImageCreateOption, storageId, location string) (string, error) {
+	params := map[string]string{
+		"Name":          opts.ImageName,
+		"ImageLocation": location,
+		"StorageId":     storageId,
+	}
+	if len(opts.Description) > 0 {
+		params["Description"] = opts.Description
+	}
+	if len(opts.OsArch) > 0 {
+		params["Architecture"] = opts.OsArch
+	}
+	if strings.ToLower(opts.OsType) == "windows" {
+		params["Platform"] = "windows"
+	}
+	resp, err := self.invoke("RegisterImage", params)
+	if err != nil {
+		return "", errors.Wrapf(err, "RegisterImage")
+	}
+	imageId, err := resp.GetString("imageId")
+	if err != nil {
+		return "", errors.Wrapf(err, "resp.GetString(imageId)")
+	}
+	return imageId, nil
+}
+
+func (self *SRegion) CreateImageFromSnapshot(snapshotId, name, osType, desc string) (string, error) {
+	params := map[string]string{
+		"Name":                                         name,
+		"RootDeviceName":                               "/dev/sda1",
+		"BlockDeviceMapping.1.DeviceName":              "/dev/sda1",
+		"BlockDeviceMapping.1.Ebs.SnapshotId":          snapshotId,
+		"BlockDeviceMapping.1.Ebs.DeleteOnTermination": "true",
+	}
+	if len(desc) > 0 {
+		params["Description"] = desc
+	}
+	if strings.ToLower(osType) == "windows" {
+		params["Platform"] = "windows"
+	}
+	resp, err := self.invoke("RegisterImage", params)
+	if err != nil {
+		return "", errors.Wrapf(err, "RegisterImage")
+	}
+	imageId, err := resp.GetString("imageId")
+	if err != nil {
+		return "", errors.Wrapf(err, "resp.GetString(imageId)")
+	}
+	return imageId, nil
+}
+
+func (self *SRegion) SaveImage(instanceId string, opts *cloudprovider.SaveImageOptions) (string, error) {
+	params := map[string]string{
+		"InstanceId": instanceId,
+		"Name":       opts.Name,
+	}
+	if len(opts.Notes) > 0 {
+		params["Description"] = opts.Notes
+	}
+	resp, err := self.invoke("CreateImage", params)
+	if err != nil {
+		return "", errors.Wrapf(err, "CreateImage")
+	}
+	imageId, err := resp.GetString("imageId")
+	if err != nil {
+		return "", errors.Wrapf(err, "resp.GetString(imageId)")
+	}
+	return imageId, nil
+}
+
+func (self *SRegion) DeregisterImage(id string) error {
+	params := map[string]string{
+		"ImageId": id,
+	}
+	_, err := self.invoke("DeregisterImage", params)
+	return err
+}
+
+func (self *SRegion) GetImages(id string, tags map[string]string, nextToken string) ([]SImage, string, error) {
 	params := map[string]string{}
 	if len(id) > 0 {
 		params["ImageId.1"] = id
 	}
+	setTagFilter(params, 1, tags)
 	if len(nextToken) > 0 {
 		params["NextToken"] = nextToken
 	}
@@ -210,7 +307,7 @@ func (self *SRegion) GetImages(id, nextToken string) ([]SImage, string, error) {
 }
 
 func (self *SRegion) GetImageById(id string) (*SImage, error) {
-	imgs, _, err := self.GetImages(id, "")
+	imgs, _, err := self.GetImages(id, nil, "")
 	if err != nil {
 		return nil, err
 	}
@@ -220,24 +317,52 @@ func (self *SRegion) GetImageById(id string) (*SImage, error) {
 	return &imgs[0], nil
 }
 
-func (self *SStoragecache) GetICloudImages() ([]cloudprovider.ICloudImage, error) {
-	part, nextToken, err := self.region.GetImages("", "")
+func (self *SStoragecache) getImages() ([]SImage, error) {
+	part, nextToken, err := self.region.GetImages("", nil, "")
 	if err != nil {
 		return nil, err
 	}
 	var images []SImage
 	images = append(images, part...)
 	for len(nextToken) > 0 {
-		part, nextToken, err = self.region.GetImages("", nextToken)
+		part, nextToken, err = self.region.GetImages("", nil, nextToken)
 		if err != nil {
 			return nil, err
 		}
 		images = append(images, part...)
 	}
-	var ret []cloudprovider.ICloudImage
+	var ret []SImage
 	for i := range images {
 		if images[i].StorageId == self.storageId {
 			images[i].cache = self
+			ret = append(ret, images[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SStoragecache) GetICloudImages() ([]cloudprovider.ICloudImage, error) {
+	images, err := self.getImages()
+	if err != nil {
+		return nil, err
+	}
+	var ret []cloudprovider.ICloudImage
+	for i := range images {
+		if images[i].IsPublic {
+			ret = append(ret, &images[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SStoragecache) GetICustomizedCloudImages() ([]cloudprovider.ICloudImage, error) {
+	images, err := self.getImages()
+	if err != nil {
+		return nil, err
+	}
+	var ret []cloudprovider.ICloudImage
+	for i := range images {
+		if !images[i].IsPublic {
 			ret = append(ret, &images[i])
 		}
 	}
@@ -245,7 +370,7 @@ func (self *SStoragecache) GetICloudImages() ([]cloudprovider.ICloudImage, error
 }
 
 func (self *SStoragecache) GetIImageById(id string) (cloudprovider.ICloudImage, error) {
-	images, _, err := self.region.GetImages(id, "")
+	images, _, err := self.region.GetImages(id, nil, "")
 	if err != nil {
 		return nil, err
 	}
@@ -325,7 +325,7 @@ func (self *SInstance) GetProjectId() string {
 }
 
 func (self *SInstance) Refresh() error {
-	newInstances, _, err := self.node.cluster.region.GetInstances(self.InstancesSet.InstanceId, self.node.NodeId, MAX_RESULT, "")
+	newInstances, _, err := self.node.cluster.region.GetInstances(self.InstancesSet.InstanceId, self.node.NodeId, nil, MAX_RESULT, "")
 	if err != nil {
 		return err
 	}
@@ -394,6 +394,20 @@ func (self *SInstance) RebuildRoot(ctx context.Context, config *cloudprovider.SM
 	return "", errors.Wrap(cloudprovider.ErrUnknown, "RebuildRoot")
 }
 
+func (self *SInstance) SetTags(tags map[string]string, replace bool) error {
+	return self.node.cluster.region.SetResourceTags(self.InstancesSet.InstanceId, tags, replace)
+}
+
+func (self *SInstance) SaveImage(opts *cloudprovider.SaveImageOptions) (cloudprovider.ICloudImage, error) {
+	region := self.node.cluster.region
+	imageId, err := region.SaveImage(self.InstancesSet.InstanceId, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "SaveImage")
+	}
+	cache := &SStoragecache{region: region, storageId: self.InstancesSet.StorageId}
+	return cache.waitImageReady(imageId)
+}
+
 func (self *SInstance) StartVM(ctx context.Context) error {
 	params := map[string]string{}
 	params["InstanceId.1"] = self.InstancesSet.InstanceId
@@ -474,7 +488,7 @@ func (self *SInstance) ResetToInstanceSnapshot(ctx context.Context, idStr string
 }
 
 func (self *SRegion) GetIVMById(id string) (cloudprovider.ICloudVM, error) {
-	vms, _, err := self.GetInstances(id, "", 1, "")
+	vms, _, err := self.GetInstances(id, "", nil, 1, "")
 	if err != nil {
 		return nil, err
 	}
@@ -486,7 +500,7 @@ func (self *SRegion) GetIVMById(id string) (cloudprovider.ICloudVM, error) {
 	return nil, cloudprovider.ErrNotFound
 }
 
-func (self *SRegion) GetInstances(id, nodeId string, maxResult int, nextToken string) ([]SInstance, string, error) {
+func (self *SRegion) GetInstances(id, nodeId string, tags map[string]string, maxResult int, nextToken string) ([]SInstance, string, error) {
 	params := map[string]string{}
 	if maxResult > 0 {
 		params["MaxRecords"] = fmt.Sprintf("%d", maxResult)
@@ -507,6 +521,7 @@ func (self *SRegion) GetInstances(id, nodeId string, maxResult int, nextToken st
 		params[fmt.Sprintf("Filter.%d.Value.1", idx)] = id
 		idx++
 	}
+	setTagFilter(params, idx, tags)
 
 	resp, err := self.invoke("DescribeInstances", params)
 	if err != nil {
@@ -14,9 +14,43 @@
 
 package bingocloud
 
-import "yunion.io/x/cloudmux/pkg/cloudprovider"
+import (
+	"fmt"
+
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+)
+
+type SPrivateIpAddress struct {
+	nic *SInstanceNic
+
+	Association      string `json:"association"`
+	Primary          string `json:"primary"`
+	PrivateDNSName   string `json:"privateDnsName"`
+	PrivateIPAddress string `json:"privateIpAddress"`
+}
+
+func (self *SPrivateIpAddress) GetGlobalId() string {
+	return self.PrivateIPAddress
+}
+
+func (self *SPrivateIpAddress) GetINetworkId() string {
+	return self.nic.SubnetId
+}
+
+func (self *SPrivateIpAddress) GetIP() string {
+	return self.PrivateIPAddress
+}
+
+func (self *SPrivateIpAddress) IsPrimary() bool {
+	return self.Primary == "true" || self.PrivateIPAddress == self.nic.PrivateIPAddress
+}
 
 type SInstanceNic struct {
+	region *SRegion
+
 	Association string `json:"association"`
 	Attachment  struct {
 		AttachTime          string `json:"attachTime"`
@@ -27,27 +61,22 @@ type SInstanceNic struct {
 		InstanceOwnerId     string `json:"instanceOwnerId"`
 		Status              string `json:"status"`
 	} `json:"attachment"`
-	AvailabilityZone      string `json:"availabilityZone"`
-	Description           string `json:"description"`
-	FirstPacketLimit      string `json:"firstPacketLimit"`
-	MACAddress            string `json:"macAddress"`
-	Model                 string `json:"model"`
-	NetworkInterfaceId    string `json:"networkInterfaceId"`
-	NoMatchPort           string `json:"noMatchPort"`
-	OwnerId               string `json:"ownerId"`
-	PrivateDNSName        string `json:"privateDnsName"`
-	PrivateIPAddress      string `json:"privateIpAddress"`
-	PrivateIPAddressesSet []struct {
-		Association      string `json:"association"`
-		Primary          string `json:"primary"`
-		PrivateDNSName   string `json:"privateDnsName"`
-		PrivateIPAddress string `json:"privateIpAddress"`
-	} `json:"privateIpAddressesSet"`
-	RequesterManaged string `json:"requesterManaged"`
-	SourceDestCheck  string `json:"sourceDestCheck"`
-	Status           string `json:"status"`
-	SubnetId         string `json:"subnetId"`
-	VpcId            string `json:"vpcId"`
+	AvailabilityZone      string              `json:"availabilityZone"`
+	Description           string              `json:"description"`
+	FirstPacketLimit      string              `json:"firstPacketLimit"`
+	MACAddress            string              `json:"macAddress"`
+	Model                 string              `json:"model"`
+	NetworkInterfaceId    string              `json:"networkInterfaceId"`
+	NoMatchPort           string              `json:"noMatchPort"`
+	OwnerId               string              `json:"ownerId"`
+	PrivateDNSName        string              `json:"privateDnsName"`
+	PrivateIPAddress      string              `json:"privateIpAddress"`
+	PrivateIPAddressesSet []SPrivateIpAddress `json:"privateIpAddressesSet"`
+	RequesterManaged      string              `json:"requesterManaged"`
+	SourceDestCheck       string              `json:"sourceDestCheck"`
+	Status                string              `json:"status"`
+	SubnetId              string              `json:"subnetId"`
+	VpcId                 string              `json:"vpcId"`
 }
 
 func (self *SInstanceNic) GetId() string {
@@ -85,19 +114,83 @@ func (self *SInstanceNic) GetSubAddress() ([]string, error) {
 }
 
 func (self *SInstanceNic) AssignNAddress(count int) ([]string, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	exists, _ := self.GetSubAddress()
+	err := self.region.AssignPrivateIpAddresses(self.NetworkInterfaceId, nil, count)
+	if err != nil {
+		return nil, err
+	}
+	nic, err := self.region.GetNetworkInterface(self.NetworkInterfaceId)
+	if err != nil {
+		return nil, err
+	}
+	ipAddrs, _ := nic.GetSubAddress()
+	var ret []string
+	for _, ip := range ipAddrs {
+		if !utils.IsInStringArray(ip, exists) {
+			ret = append(ret, ip)
+		}
+	}
+	if len(ret) != count {
+		return nil, errors.Errorf("AssignNAddress want %d addresses, got %d", count, len(ret))
+	}
+	return ret, nil
 }
 
 func (self *SInstanceNic) AssignAddress(ipAddrs []string) error {
-	return cloudprovider.ErrNotImplemented
+	return self.region.AssignPrivateIpAddresses(self.NetworkInterfaceId, ipAddrs, 0)
 }
 
 func (self *SInstanceNic) UnassignAddress(ipAddrs []string) error {
-	return cloudprovider.ErrNotImplemented
+	return self.region.UnassignPrivateIpAddresses(self.NetworkInterfaceId, ipAddrs)
+}
+
+func (self *SRegion) AssignPrivateIpAddresses(nicId string, ipAddrs []string, count int) error {
+	params := map[string]string{
+		"NetworkInterfaceId": nicId,
+	}
+	for i, ip := range ipAddrs {
+		params[fmt.Sprintf("PrivateIpAddress.%d", i+1)] = ip
+	}
+	if count > 0 {
+		params["SecondaryPrivateIpAddressCount"] = fmt.Sprintf("%d", count)
+	}
+	_, err := self.invoke("AssignPrivateIpAddresses", params)
+	return err
+}
+
+func (self *SRegion) UnassignPrivateIpAddresses(nicId string, ipAddrs []string) error {
+	params := map[string]string{
+		"NetworkInterfaceId": nicId,
+	}
+	for i, ip := range ipAddrs {
+		params[fmt.Sprintf("PrivateIpAddress.%d", i+1)] = ip
+	}
+	_, err := self.invoke("UnassignPrivateIpAddresses", params)
+	return err
 }
 
 func (self *SRegion) GetInstanceNics(insId string) ([]SInstanceNic, error) {
+	return self.GetNetworkInterfaces("", insId)
+}
+
+func (self *SRegion) GetNetworkInterface(id string) (*SInstanceNic, error) {
+	nics, err := self.GetNetworkInterfaces(id, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range nics {
+		if nics[i].NetworkInterfaceId == id {
+			return &nics[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, id)
+}
+
+func (self *SRegion) GetNetworkInterfaces(id, insId string) ([]SInstanceNic, error) {
 	params := map[string]string{}
+	if len(id) > 0 {
+		params["NetworkInterfaceId.1"] = id
+	}
 	if len(insId) > 0 {
 		params["InstanceId"] = insId
 	}
@@ -112,5 +205,8 @@ func (self *SRegion) GetInstanceNics(insId string) ([]SInstanceNic, error) {
 	}{}
 	_ = resp.Unmarshal(&ret)
 
+	for i := range ret.NetworkInterfaceSet {
+		ret.NetworkInterfaceSet[i].region = self
+	}
 	return ret.NetworkInterfaceSet, nil
 }
@@ -18,12 +18,23 @@ import (
 	"fmt"
 	"time"
 
-	"yunion.io/x/log"
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/utils"
 
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 )
 
+const (
+	STATISTIC_AVERAGE      = "Average"
+	STATISTIC_MAXIMUM      = "Maximum"
+	STATISTIC_MINIMUM      = "Minimum"
+	STATISTIC_SUM          = "Sum"
+	STATISTIC_SAMPLE_COUNT = "SampleCount"
+
+	DEFAULT_METRIC_PERIOD = 60
+)
+
 type MetricOutput struct {
 	Datapoints Datapoints
 	ObjName    string
@@ -40,34 +51,103 @@ type DatapointMember struct {
 	Unit        string
 }
 
-func (self DatapointMember) GetValue() float64 {
-	return self.Average + self.Maximum + self.Minimum + self.Sum
+func (self DatapointMember) GetValue(statistic string) float64 {
+	switch statistic {
+	case STATISTIC_MAXIMUM:
+		return self.Maximum
+	case STATISTIC_MINIMUM:
+		return self.Minimum
+	case STATISTIC_SUM:
+		return self.Sum
+	case STATISTIC_SAMPLE_COUNT:
+		return self.SampleCount
+	default:
+		return self.Average
+	}
 }
 
 type Datapoints struct {
 	Member []DatapointMember
 }
 
-func (self *SBingoCloudClient) DescribeMetricList(ns, metricNm, dimensionName, dimensionValue string, since time.Time, until time.Time) (*MetricOutput, error) {
+type sMetricSpec struct {
+	Name      string
+	Statistic string
+	// Sum 为周期内的累计值, 需换算为每秒速率
+	PerSecond bool
+	// 字节换算为比特
+	Bits bool
+}
+
+func (spec sMetricSpec) value(point DatapointMember, period int) float64 {
+	value := point.GetValue(spec.Statistic)
+	if spec.PerSecond && period > 0 {
+		value = value / float64(period)
+	}
+	if spec.Bits {
+		value = value * 8
+	}
+	return value
+}
+
+// DescribeMetricList 批量查询多个资源的监控数据, 返回结果按ObjName区分资源
+func (self *SBingoCloudClient) DescribeMetricList(ns, metricNm, statistic, dimensionName string, dimensionValues []string, period int, since time.Time, until time.Time) ([]MetricOutput, error) {
 	params := map[string]string{}
 	params["Namespace"] = ns
 	params["MetricName"] = metricNm
-	params["Dimensions.member.1.Name"] = dimensionName
-	params["Dimensions.member.1.Value"] = dimensionValue
+	for i, value := range dimensionValues {
+		params[fmt.Sprintf("Dimensions.member.%d.Name", i+1)] = dimensionName
+		params[fmt.Sprintf("Dimensions.member.%d.Value", i+1)] = value
+	}
 	params["StartTime"] = since.Format(time.RFC3339)
 	params["EndTime"] = until.Format(time.RFC3339)
-	params["Statistics.member.1"] = "Average"
-	params["Period"] = "60"
+	params["Statistics.member.1"] = statistic
+	params["Period"] = fmt.Sprintf("%d", period)
 	resp, err := self.invoke("GetMetricStatistics", params)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetMetricStatistics err")
 	}
-	ret := &MetricOutput{}
-	return ret, resp.Unmarshal(ret, "GetMetricStatisticsResult")
+	result, err := resp.Get("GetMetricStatisticsResult")
+	if err != nil {
+		return nil, errors.Wrapf(err, "resp.Get(GetMetricStatisticsResult)")
+	}
+	ret := []MetricOutput{}
+	if _, ok := result.(*jsonutils.JSONArray); ok {
+		return ret, result.Unmarshal(&ret)
+	}
+	output := MetricOutput{}
+	err = result.Unmarshal(&output)
+	if err != nil {
+		return nil, err
+	}
+	if len(output.ObjName) == 0 && len(dimensionValues) == 1 {
+		output.ObjName = dimensionValues[0]
+	}
+	return append(ret, output), nil
+}
+
+func getMetricPeriod(opts *cloudprovider.MetricListOptions) int {
+	if opts.Interval <= 0 {
+		return DEFAULT_METRIC_PERIOD
+	}
+	if opts.Interval%DEFAULT_METRIC_PERIOD != 0 {
+		return (opts.Interval/DEFAULT_METRIC_PERIOD + 1) * DEFAULT_METRIC_PERIOD
+	}
+	return opts.Interval
+}
+
+func getMetricResourceIds(opts *cloudprovider.MetricListOptions) []string {
+	ret := []string{}
+	for _, id := range append([]string{opts.ResourceId}, opts.ResourceIds...) {
+		if len(id) > 0 && !utils.IsInStringArray(id, ret) {
+			ret = append(ret, id)
+		}
+	}
+	return ret
 }
 
 func (self *SBingoCloudClient) GetMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
-	if len(opts.ResourceId) == 0 {
+	if len(getMetricResourceIds(opts)) == 0 {
 		return nil, fmt.Errorf("missing resourceId")
 	}
 	switch opts.ResourceType {
@@ -75,70 +155,102 @@ func (self *SBingoCloudClient) GetMetrics(opts *cloudprovider.MetricListOptions)
 		return self.GetEcsMetrics(opts)
 	case cloudprovider.METRIC_RESOURCE_TYPE_HOST:
 		return self.GetHostMetrics(opts)
+	case cloudprovider.METRIC_RESOURCE_TYPE_STORAGE:
+		return self.GetStorageMetrics(opts)
+	case cloudprovider.METRIC_RESOURCE_TYPE_EIP:
+		return self.GetEipMetrics(opts)
+	case cloudprovider.METRIC_RESOURCE_TYPE_CLUSTER:
+		return self.GetClusterMetrics(opts)
 	default:
 		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "%s", opts.ResourceType)
 	}
 }
 
-func (self *SBingoCloudClient) GetEcsMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
+func (self *SBingoCloudClient) getMetrics(ns, dimensionName string, metrics map[cloudprovider.TMetricType]sMetricSpec, opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
+	ids := getMetricResourceIds(opts)
+	period := getMetricPeriod(opts)
 	var ret []cloudprovider.MetricValues
-	for metricType, metricName := range map[cloudprovider.TMetricType]string{
-		cloudprovider.VM_METRIC_TYPE_CPU_USAGE:          "CPUUtilization",
-		cloudprovider.VM_METRIC_TYPE_MEM_USAGE:          "MemoryUsage",
-		cloudprovider.VM_METRIC_TYPE_NET_BPS_RX:         "NetworkIn",
-		cloudprovider.VM_METRIC_TYPE_NET_BPS_TX:         "NetworkOut",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_READ_BPS:   "DiskReadBytes",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_WRITE_BPS:  "DiskWriteBytes",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_READ_IOPS:  "DiskReadOps",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_WRITE_IOPS: "DiskWriteOps",
-	} {
-		data, err := self.DescribeMetricList("AWS/EC2", metricName, "InstanceId", opts.ResourceId, opts.StartTime, opts.EndTime)
-		if err != nil {
-			log.Errorf("DescribeMetricList error: %v", err)
+	for metricType, spec := range metrics {
+		if len(opts.MetricType) > 0 && opts.MetricType != metricType {
 			continue
 		}
-		metric := cloudprovider.MetricValues{}
-		metric.Id = opts.ResourceId
-		metric.MetricType = metricType
-		for _, value := range data.Datapoints.Member {
-			metricValue := cloudprovider.MetricValue{}
-			metricValue.Timestamp = value.Timestamp
-			metricValue.Value = value.GetValue()
-			metric.Values = append(metric.Values, metricValue)
+		outputs, err := self.DescribeMetricList(ns, spec.Name, spec.Statistic, dimensionName, ids, period, opts.StartTime, opts.EndTime)
+		if err != nil {
+			return nil, errors.Wrapf(err, "DescribeMetricList %s %s", ns, spec.Name)
+		}
+		for _, output := range outputs {
+			if !utils.IsInStringArray(output.ObjName, ids) {
+				continue
+			}
+			metric := cloudprovider.MetricValues{}
+			metric.Id = output.ObjName
+			metric.MetricType = metricType
+			outputPeriod := period
+			if output.Period > 0 {
+				outputPeriod = int(output.Period)
+			}
+			for _, value := range output.Datapoints.Member {
+				if len(metric.Unit) == 0 {
+					metric.Unit = value.Unit
+				}
+				metricValue := cloudprovider.MetricValue{}
+				metricValue.Timestamp = value.Timestamp
+				metricValue.Value = spec.value(value, outputPeriod)
+				metric.Values = append(metric.Values, metricValue)
+			}
+			ret = append(ret, metric)
 		}
-		ret = append(ret, metric)
 	}
 	return ret, nil
 }
 
+func (self *SBingoCloudClient) GetEcsMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
+	return self.getMetrics("AWS/EC2", "InstanceId", map[cloudprovider.TMetricType]sMetricSpec{
+		cloudprovider.VM_METRIC_TYPE_CPU_USAGE:          {Name: "CPUUtilization", Statistic: STATISTIC_AVERAGE},
+		cloudprovider.VM_METRIC_TYPE_MEM_USAGE:          {Name: "MemoryUsage", Statistic: STATISTIC_AVERAGE},
+		cloudprovider.VM_METRIC_TYPE_NET_BPS_RX:         {Name: "NetworkIn", Statistic: STATISTIC_SUM, PerSecond: true, Bits: true},
+		cloudprovider.VM_METRIC_TYPE_NET_BPS_TX:         {Name: "NetworkOut", Statistic: STATISTIC_SUM, PerSecond: true, Bits: true},
+		cloudprovider.VM_METRIC_TYPE_DISK_IO_READ_BPS:   {Name: "DiskReadBytes", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.VM_METRIC_TYPE_DISK_IO_WRITE_BPS:  {Name: "DiskWriteBytes", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.VM_METRIC_TYPE_DISK_IO_READ_IOPS:  {Name: "DiskReadOps", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.VM_METRIC_TYPE_DISK_IO_WRITE_IOPS: {Name: "DiskWriteOps", Statistic: STATISTIC_SUM, PerSecond: true},
+	}, opts)
+}
+
 func (self *SBingoCloudClient) GetHostMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
-	var ret []cloudprovider.MetricValues
-	for metricType, metricName := range map[cloudprovider.TMetricType]string{
-		cloudprovider.VM_METRIC_TYPE_CPU_USAGE:          "CPUUtilization",
-		cloudprovider.VM_METRIC_TYPE_MEM_USAGE:          "MemoryUsage",
-		cloudprovider.VM_METRIC_TYPE_NET_BPS_RX:         "NetworkIn",
-		cloudprovider.VM_METRIC_TYPE_NET_BPS_TX:         "NetworkOut",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_READ_BPS:   "DiskReadBytes",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_WRITE_BPS:  "DiskWriteBytes",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_READ_IOPS:  "DiskReadOps",
-		cloudprovider.VM_METRIC_TYPE_DISK_IO_WRITE_IOPS: "DiskWriteOps",
-	} {
-		data, err := self.DescribeMetricList("AWS/HOST", metricName, "HostId", opts.ResourceId, opts.StartTime, opts.EndTime)
-		if err != nil {
-			log.Errorf("DescribeMetricList error: %v", err)
-			continue
-		}
-		metric := cloudprovider.MetricValues{}
-		metric.Id = opts.ResourceId
-		metric.MetricType = metricType
-		for _, value := range data.Datapoints.Member {
-			metricValue := cloudprovider.MetricValue{}
-			metricValue.Timestamp = value.Timestamp
-			metricValue.Value = value.GetValue()
-			metric.Values = append(metric.Values, metricValue)
-		}
-		ret = append(ret, metric)
-	}
-	return ret, nil
+	return self.getMetrics("AWS/HOST", "HostId", map[cloudprovider.TMetricType]sMetricSpec{
+		cloudprovider.HOST_METRIC_TYPE_CPU_USAGE:          {Name: "CPUUtilization", Statistic: STATISTIC_AVERAGE},
+		cloudprovider.HOST_METRIC_TYPE_MEM_USAGE:          {Name: "MemoryUsage", Statistic: STATISTIC_AVERAGE},
+		cloudprovider.HOST_METRIC_TYPE_NET_BPS_RX:         {Name: "NetworkIn", Statistic: STATISTIC_SUM, PerSecond: true, Bits: true},
+		cloudprovider.HOST_METRIC_TYPE_NET_BPS_TX:         {Name: "NetworkOut", Statistic: STATISTIC_SUM, PerSecond: true, Bits: true},
+		cloudprovider.HOST_METRIC_TYPE_DISK_IO_READ_BPS:   {Name: "DiskReadBytes", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.HOST_METRIC_TYPE_DISK_IO_WRITE_BPS:  {Name: "DiskWriteBytes", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.HOST_METRIC_TYPE_DISK_IO_READ_IOPS:  {Name: "DiskReadOps", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.HOST_METRIC_TYPE_DISK_IO_WRITE_IOPS: {Name: "DiskWriteOps", Statistic: STATISTIC_SUM, PerSecond: true},
+	}, opts)
+}
+
+func (self *SBingoCloudClient) GetStorageMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
+	return self.getMetrics("AWS/STORAGE", "StorageId", map[cloudprovider.TMetricType]sMetricSpec{
+		cloudprovider.STORAGE_METRIC_TYPE_USAGE:      {Name: "StorageUsage", Statistic: STATISTIC_AVERAGE},
+		cloudprovider.STORAGE_METRIC_TYPE_READ_BPS:   {Name: "ReadBytes", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.STORAGE_METRIC_TYPE_WRITE_BPS:  {Name: "WriteBytes", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.STORAGE_METRIC_TYPE_READ_IOPS:  {Name: "ReadOps", Statistic: STATISTIC_SUM, PerSecond: true},
+		cloudprovider.STORAGE_METRIC_TYPE_WRITE_IOPS: {Name: "WriteOps", Statistic: STATISTIC_SUM, PerSecond: true},
+	}, opts)
+}
+
+func (self *SBingoCloudClient) GetEipMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
+	return self.getMetrics("AWS/EIP", "PublicIp", map[cloudprovider.TMetricType]sMetricSpec{
+		cloudprovider.EIP_METRIC_TYPE_NET_BPS_RX: {Name: "NetworkIn", Statistic: STATISTIC_SUM, PerSecond: true, Bits: true},
+		cloudprovider.EIP_METRIC_TYPE_NET_BPS_TX: {Name: "NetworkOut", Statistic: STATISTIC_SUM, PerSecond: true, Bits: true},
+	}, opts)
+}
 
+func (self *SBingoCloudClient) GetClusterMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
+	return self.getMetrics("AWS/CLUSTER", "ClusterId", map[cloudprovider.TMetricType]sMetricSpec{
+		cloudprovider.CLUSTER_METRIC_TYPE_CPU_USAGE:  {Name: "CPUUtilization", Statistic: STATISTIC_AVERAGE},
+		cloudprovider.CLUSTER_METRIC_TYPE_MEM_USAGE:  {Name: "MemoryUsage", Statistic: STATISTIC_AVERAGE},
+		cloudprovider.CLUSTER_METRIC_TYPE_DISK_USAGE: {Name: "StorageUsage", Statistic: STATISTIC_AVERAGE},
+	}, opts)
 }
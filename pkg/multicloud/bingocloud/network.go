@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strings"
 
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/netutils"
 	"yunion.io/x/pkg/util/rbacscope"
@@ -88,7 +89,7 @@ func (self *SNetwork) GetName() string {
 }
 
 func (self *SNetwork) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return self.wire.vpc.region.DeleteNetwork(self.SubnetId)
 }
 
 func (self *SNetwork) GetAllocTimeoutSeconds() int {
@@ -144,8 +145,26 @@ func (self *SNetwork) GetStatus() string {
 	return strings.ToLower(self.State)
 }
 
+func (self *SNetwork) Refresh() error {
+	networks, err := self.wire.vpc.region.GetNetworks(self.SubnetId, "", "")
+	if err != nil {
+		return err
+	}
+	for i := range networks {
+		if networks[i].SubnetId == self.SubnetId {
+			return jsonutils.Update(self, networks[i])
+		}
+	}
+	return errors.Wrapf(cloudprovider.ErrNotFound, self.SubnetId)
+}
+
 func (self *SWire) CreateINetwork(opts *cloudprovider.SNetworkCreateOptions) (cloudprovider.ICloudNetwork, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	network, err := self.vpc.region.CreateNetwork(self.cluster.ClusterId, self.vpc.VpcId, opts)
+	if err != nil {
+		return nil, err
+	}
+	network.wire = self
+	return network, nil
 }
 
 func (self *SWire) GetINetworks() ([]cloudprovider.ICloudNetwork, error) {
@@ -174,6 +193,52 @@ func (self *SWire) GetINetworkById(id string) (cloudprovider.ICloudNetwork, erro
 	return nil, errors.Wrapf(cloudprovider.ErrNotFound, id)
 }
 
+func (self *SRegion) CreateNetwork(clusterId, vpcId string, opts *cloudprovider.SNetworkCreateOptions) (*SNetwork, error) {
+	params := map[string]string{
+		"VpcId":     vpcId,
+		"CidrBlock": opts.Cidr,
+	}
+	if len(clusterId) > 0 {
+		params["AvailabilityZone"] = clusterId
+	}
+	if len(opts.Name) > 0 {
+		params["SubnetName"] = opts.Name
+	}
+	if len(opts.Desc) > 0 {
+		params["Description"] = opts.Desc
+	}
+	resp, err := self.invoke("CreateSubnet", params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateSubnet")
+	}
+	network := &SNetwork{}
+	err = resp.Unmarshal(network, "subnet")
+	if err != nil {
+		return nil, errors.Wrapf(err, "resp.Unmarshal")
+	}
+	if len(network.SubnetId) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "subnetId after CreateSubnet")
+	}
+	networks, err := self.GetNetworks(network.SubnetId, "", "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range networks {
+		if networks[i].SubnetId == network.SubnetId {
+			return &networks[i], nil
+		}
+	}
+	return network, nil
+}
+
+func (self *SRegion) DeleteNetwork(id string) error {
+	params := map[string]string{
+		"SubnetId": id,
+	}
+	_, err := self.invoke("DeleteSubnet", params)
+	return err
+}
+
 func (self *SRegion) GetNetworks(id, clusterId, vpcId string) ([]SNetwork, error) {
 	params := map[string]string{}
 	if len(id) > 0 {
@@ -0,0 +1,169 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bingocloud
+
+import (
+	"fmt"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SNetworkInterface struct {
+	multicloud.SNetworkInterfaceBase
+	BingoTags
+
+	SInstanceNic
+}
+
+func (self *SNetworkInterface) GetGlobalId() string {
+	return self.NetworkInterfaceId
+}
+
+func (self *SNetworkInterface) GetName() string {
+	if len(self.Description) > 0 {
+		return self.Description
+	}
+	return self.NetworkInterfaceId
+}
+
+func (self *SNetworkInterface) GetMacAddress() string {
+	return self.MACAddress
+}
+
+func (self *SNetworkInterface) GetAssociateType() string {
+	if len(self.Attachment.InstanceId) > 0 {
+		return api.NETWORK_INTERFACE_ASSOCIATE_TYPE_SERVER
+	}
+	return ""
+}
+
+func (self *SNetworkInterface) GetAssociateId() string {
+	return self.Attachment.InstanceId
+}
+
+func (self *SNetworkInterface) GetStatus() string {
+	switch self.Status {
+	case "available", "in-use":
+		return api.NETWORK_INTERFACE_STATUS_AVAILABLE
+	case "attaching":
+		return api.NETWORK_INTERFACE_STATUS_ATTACHING
+	case "detaching":
+		return api.NETWORK_INTERFACE_STATUS_DETACHING
+	}
+	return self.Status
+}
+
+func (self *SNetworkInterface) Refresh() error {
+	nic, err := self.region.GetNetworkInterface(self.NetworkInterfaceId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(&self.SInstanceNic, nic)
+}
+
+func (self *SNetworkInterface) GetICloudInterfaceAddresses() ([]cloudprovider.ICloudInterfaceAddress, error) {
+	var ret []cloudprovider.ICloudInterfaceAddress
+	for i := range self.PrivateIPAddressesSet {
+		self.PrivateIPAddressesSet[i].nic = &self.SInstanceNic
+		ret = append(ret, &self.PrivateIPAddressesSet[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetINetworkInterfaces() ([]cloudprovider.ICloudNetworkInterface, error) {
+	nics, err := self.GetNetworkInterfaces("", "")
+	if err != nil {
+		return nil, err
+	}
+	var ret []cloudprovider.ICloudNetworkInterface
+	for i := range nics {
+		// 已挂载到虚拟机的网卡会随虚拟机同步
+		if len(nics[i].Attachment.InstanceId) > 0 {
+			continue
+		}
+		ret = append(ret, &SNetworkInterface{SInstanceNic: nics[i]})
+	}
+	return ret, nil
+}
+
+func (self *SRegion) CreateNetworkInterface(subnetId, ipAddr, desc string, secgroupIds []string) (*SInstanceNic, error) {
+	params := map[string]string{
+		"SubnetId": subnetId,
+	}
+	if len(ipAddr) > 0 {
+		params["PrivateIpAddress"] = ipAddr
+	}
+	if len(desc) > 0 {
+		params["Description"] = desc
+	}
+	for i, id := range secgroupIds {
+		params[fmt.Sprintf("SecurityGroupId.%d", i+1)] = id
+	}
+	resp, err := self.invoke("CreateNetworkInterface", params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateNetworkInterface")
+	}
+	nic := &SInstanceNic{}
+	err = resp.Unmarshal(nic, "networkInterface")
+	if err != nil {
+		return nil, errors.Wrapf(err, "resp.Unmarshal")
+	}
+	return self.GetNetworkInterface(nic.NetworkInterfaceId)
+}
+
+func (self *SRegion) DeleteNetworkInterface(id string) error {
+	params := map[string]string{
+		"NetworkInterfaceId": id,
+	}
+	_, err := self.invoke("DeleteNetworkInterface", params)
+	return err
+}
+
+func (self *SRegion) AttachNetworkInterface(id, instanceId string, deviceIndex int) (string, error) {
+	params := map[string]string{
+		"NetworkInterfaceId": id,
+		"InstanceId":         instanceId,
+		"DeviceIndex":        fmt.Sprintf("%d", deviceIndex),
+	}
+	resp, err := self.invoke("AttachNetworkInterface", params)
+	if err != nil {
+		return "", errors.Wrapf(err, "AttachNetworkInterface")
+	}
+	attachmentId, _ := resp.GetString("attachmentId")
+	return attachmentId, nil
+}
+
+func (self *SRegion) DetachNetworkInterface(id string, force bool) error {
+	nic, err := self.GetNetworkInterface(id)
+	if err != nil {
+		return err
+	}
+	if len(nic.Attachment.AttachmentId) == 0 {
+		return nil
+	}
+	params := map[string]string{
+		"AttachmentId": nic.Attachment.AttachmentId,
+	}
+	if force {
+		params["Force"] = "true"
+	}
+	_, err = self.invoke("DetachNetworkInterface", params)
+	return err
+}
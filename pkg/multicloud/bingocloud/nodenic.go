@@ -0,0 +1,94 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bingocloud
+
+import (
+	"strings"
+
+	"yunion.io/x/pkg/tristate"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+)
+
+type SNodeNic struct {
+	node  *SNode
+	index int8
+
+	NodeId     string `json:"nodeId"`
+	DeviceName string `json:"deviceName"`
+	Driver     string `json:"driver"`
+	MacAddress string `json:"macAddress"`
+	IpAddress  string `json:"ipAddress"`
+	Mtu        int32  `json:"mtu"`
+	LinkStatus string `json:"linkStatus"`
+	Bridge     string `json:"bridge"`
+}
+
+func (self *SNodeNic) GetDevice() string {
+	return self.DeviceName
+}
+
+func (self *SNodeNic) GetDriver() string {
+	return self.Driver
+}
+
+func (self *SNodeNic) GetMac() string {
+	return strings.ToLower(self.MacAddress)
+}
+
+func (self *SNodeNic) GetIndex() int8 {
+	return self.index
+}
+
+func (self *SNodeNic) IsLinkUp() tristate.TriState {
+	switch strings.ToLower(self.LinkStatus) {
+	case "up", "connected":
+		return tristate.True
+	case "down", "disconnected":
+		return tristate.False
+	}
+	return tristate.None
+}
+
+func (self *SNodeNic) GetIpAddr() string {
+	return self.IpAddress
+}
+
+func (self *SNodeNic) GetMtu() int32 {
+	return self.Mtu
+}
+
+func (self *SNodeNic) GetNicType() string {
+	if len(self.IpAddress) > 0 && self.IpAddress == self.node.GetAccessIp() {
+		return api.NIC_TYPE_ADMIN
+	}
+	return ""
+}
+
+func (self *SNodeNic) GetBridge() string {
+	return self.Bridge
+}
+
+func (self *SRegion) GetNodeNics(nodeId string) ([]SNodeNic, error) {
+	params := map[string]string{
+		"NodeId": nodeId,
+	}
+	resp, err := self.invoke("DescribeNodeNetworkInterfaces", params)
+	if err != nil {
+		return nil, err
+	}
+	var ret []SNodeNic
+	return ret, resp.Unmarshal(&ret, "nodeNetworkInterfaceSet")
+}
@@ -31,7 +31,6 @@ type SRegion struct {
 	multicloud.SRegionLbBase
 	multicloud.SRegionOssBase
 	multicloud.SRegionSecurityGroupBase
-	multicloud.SRegionVpcBase
 	multicloud.SRegionZoneBase
 
 	client *SBingoCloudClient
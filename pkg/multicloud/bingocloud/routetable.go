@@ -0,0 +1,283 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bingocloud
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SRouteTableAssociation struct {
+	RouteTableAssociationId string `json:"routeTableAssociationId"`
+	RouteTableId            string `json:"routeTableId"`
+	SubnetId                string `json:"subnetId"`
+	GatewayId               string `json:"gatewayId"`
+	Main                    string `json:"main"`
+}
+
+type SRouteTable struct {
+	multicloud.SResourceBase
+	BingoTags
+
+	vpc *SVpc
+
+	RouteTableId   string                   `json:"routeTableId"`
+	RouteTableName string                   `json:"routeTableName"`
+	VpcId          string                   `json:"vpcId"`
+	OwnerId        string                   `json:"ownerId"`
+	Description    string                   `json:"description"`
+	RouteSet       []SRoute                 `json:"routeSet"`
+	AssociationSet []SRouteTableAssociation `json:"associationSet"`
+}
+
+func (self *SRouteTable) GetId() string {
+	return self.RouteTableId
+}
+
+func (self *SRouteTable) GetGlobalId() string {
+	return self.RouteTableId
+}
+
+func (self *SRouteTable) GetName() string {
+	if len(self.RouteTableName) > 0 {
+		return self.RouteTableName
+	}
+	return self.RouteTableId
+}
+
+func (self *SRouteTable) GetStatus() string {
+	return api.ROUTE_TABLE_AVAILABLE
+}
+
+func (self *SRouteTable) Refresh() error {
+	tables, err := self.vpc.region.GetRouteTables(self.RouteTableId, self.VpcId)
+	if err != nil {
+		return err
+	}
+	for i := range tables {
+		if tables[i].RouteTableId == self.RouteTableId {
+			return jsonutils.Update(self, tables[i])
+		}
+	}
+	return errors.Wrapf(cloudprovider.ErrNotFound, self.RouteTableId)
+}
+
+func (self *SRouteTable) GetDescription() string {
+	return self.Description
+}
+
+func (self *SRouteTable) GetRegionId() string {
+	return self.vpc.region.GetId()
+}
+
+func (self *SRouteTable) GetVpcId() string {
+	return self.VpcId
+}
+
+func (self *SRouteTable) GetType() cloudprovider.RouteTableType {
+	for i := range self.AssociationSet {
+		if self.AssociationSet[i].Main == "true" {
+			return cloudprovider.RouteTableTypeSystem
+		}
+	}
+	return cloudprovider.RouteTableTypeCustom
+}
+
+func (self *SRouteTable) GetAssociations() []cloudprovider.RouteTableAssociation {
+	ret := []cloudprovider.RouteTableAssociation{}
+	for _, assoc := range self.AssociationSet {
+		if len(assoc.SubnetId) > 0 {
+			ret = append(ret, cloudprovider.RouteTableAssociation{
+				AssociationId:        assoc.RouteTableAssociationId,
+				AssociationType:      cloudprovider.RouteTableAssociaToSubnet,
+				AssociatedResourceId: assoc.SubnetId,
+			})
+		}
+		if len(assoc.GatewayId) > 0 {
+			ret = append(ret, cloudprovider.RouteTableAssociation{
+				AssociationId:        assoc.RouteTableAssociationId,
+				AssociationType:      cloudprovider.RouteTableAssociaToRouter,
+				AssociatedResourceId: assoc.GatewayId,
+			})
+		}
+	}
+	return ret
+}
+
+func (self *SRouteTable) GetIRoutes() ([]cloudprovider.ICloudRoute, error) {
+	var ret []cloudprovider.ICloudRoute
+	for i := range self.RouteSet {
+		self.RouteSet[i].routetable = self
+		ret = append(ret, &self.RouteSet[i])
+	}
+	return ret, nil
+}
+
+func (self *SRouteTable) CreateRoute(route cloudprovider.RouteSet) error {
+	return self.vpc.region.CreateRoute(self.RouteTableId, route.Destination, route.NextHopType, route.NextHop)
+}
+
+func (self *SRouteTable) UpdateRoute(route cloudprovider.RouteSet) error {
+	return self.vpc.region.ReplaceRoute(self.RouteTableId, route.Destination, route.NextHopType, route.NextHop)
+}
+
+func (self *SRouteTable) RemoveRoute(route cloudprovider.RouteSet) error {
+	return self.vpc.region.DeleteRoute(self.RouteTableId, route.Destination)
+}
+
+type SRoute struct {
+	multicloud.SResourceBase
+	BingoTags
+
+	routetable *SRouteTable
+
+	DestinationCidrBlock string `json:"destinationCidrBlock"`
+	GatewayId            string `json:"gatewayId"`
+	InstanceId           string `json:"instanceId"`
+	NetworkInterfaceId   string `json:"networkInterfaceId"`
+	NatGatewayId         string `json:"natGatewayId"`
+	Origin               string `json:"origin"`
+	State                string `json:"state"`
+}
+
+func (self *SRoute) GetId() string {
+	return fmt.Sprintf("%s:%s", self.DestinationCidrBlock, self.GetNextHop())
+}
+
+func (self *SRoute) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SRoute) GetName() string {
+	return ""
+}
+
+func (self *SRoute) GetStatus() string {
+	switch self.State {
+	case "active", "":
+		return api.ROUTE_ENTRY_STATUS_AVAILIABLE
+	default:
+		return api.ROUTE_ENTRY_STATUS_UNKNOWN
+	}
+}
+
+func (self *SRoute) GetType() string {
+	if self.Origin == "CreateRoute" {
+		return api.ROUTE_ENTRY_TYPE_CUSTOM
+	}
+	return api.ROUTE_ENTRY_TYPE_SYSTEM
+}
+
+func (self *SRoute) GetCidr() string {
+	return self.DestinationCidrBlock
+}
+
+func (self *SRoute) GetNextHopType() string {
+	switch {
+	case len(self.InstanceId) > 0:
+		return api.NEXT_HOP_TYPE_INSTANCE
+	case len(self.NetworkInterfaceId) > 0:
+		return api.NEXT_HOP_TYPE_NETWORK
+	case len(self.NatGatewayId) > 0:
+		return api.NEXT_HOP_TYPE_NAT
+	case strings.HasPrefix(self.GatewayId, "igw-"):
+		return api.NEXT_HOP_TYPE_INTERNET
+	}
+	return ""
+}
+
+func (self *SRoute) GetNextHop() string {
+	for _, hop := range []string{self.InstanceId, self.NetworkInterfaceId, self.NatGatewayId, self.GatewayId} {
+		if len(hop) > 0 {
+			return hop
+		}
+	}
+	return ""
+}
+
+func (self *SRegion) GetRouteTables(id, vpcId string) ([]SRouteTable, error) {
+	params := map[string]string{}
+	if len(id) > 0 {
+		params["RouteTableId.1"] = id
+	}
+	if len(vpcId) > 0 {
+		params["Filter.1.Name"] = "vpc-id"
+		params["Filter.1.Value.1"] = vpcId
+	}
+	resp, err := self.invoke("DescribeRouteTables", params)
+	if err != nil {
+		return nil, err
+	}
+	var ret []SRouteTable
+	return ret, resp.Unmarshal(&ret, "routeTableSet")
+}
+
+func routeTargetParams(params map[string]string, nextHopType, nextHop string) error {
+	switch nextHopType {
+	case api.NEXT_HOP_TYPE_INSTANCE:
+		params["InstanceId"] = nextHop
+	case api.NEXT_HOP_TYPE_NETWORK:
+		params["NetworkInterfaceId"] = nextHop
+	case api.NEXT_HOP_TYPE_NAT:
+		params["NatGatewayId"] = nextHop
+	case api.NEXT_HOP_TYPE_INTERNET, "":
+		params["GatewayId"] = nextHop
+	default:
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "next hop type %s", nextHopType)
+	}
+	return nil
+}
+
+func (self *SRegion) CreateRoute(routeTableId, cidr, nextHopType, nextHop string) error {
+	params := map[string]string{
+		"RouteTableId":         routeTableId,
+		"DestinationCidrBlock": cidr,
+	}
+	err := routeTargetParams(params, nextHopType, nextHop)
+	if err != nil {
+		return err
+	}
+	_, err = self.invoke("CreateRoute", params)
+	return err
+}
+
+func (self *SRegion) ReplaceRoute(routeTableId, cidr, nextHopType, nextHop string) error {
+	params := map[string]string{
+		"RouteTableId":         routeTableId,
+		"DestinationCidrBlock": cidr,
+	}
+	err := routeTargetParams(params, nextHopType, nextHop)
+	if err != nil {
+		return err
+	}
+	_, err = self.invoke("ReplaceRoute", params)
+	return err
+}
+
+func (self *SRegion) DeleteRoute(routeTableId, cidr string) error {
+	params := map[string]string{
+		"RouteTableId":         routeTableId,
+		"DestinationCidrBlock": cidr,
+	}
+	_, err := self.invoke("DeleteRoute", params)
+	return err
+}
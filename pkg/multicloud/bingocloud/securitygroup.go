@@ -16,6 +16,7 @@ package bingocloud
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"yunion.io/x/log"
@@ -153,6 +154,10 @@ func (self *SSecurityGroup) SyncRules(common, inAdds, outAdds, inDels, outDels [
 	return nil
 }
 
+func (self *SSecurityGroup) SetTags(tags map[string]string, replace bool) error {
+	return self.region.SetResourceTags(self.GroupId, tags, replace)
+}
+
 func (self *SSecurityGroup) Delete() error {
 	return self.region.deleteSecurityGroup(self.GroupId)
 }
@@ -198,18 +203,22 @@ func (self *SRegion) addSecurityGroupRules(secGrpId string, rule cloudprovider.S
 	return nil
 }
 
-func (self *SRegion) GetSecurityGroups(id, name, nextToken string) ([]SSecurityGroup, string, error) {
+func (self *SRegion) GetSecurityGroups(id, name string, tags map[string]string, nextToken string) ([]SSecurityGroup, string, error) {
 	params := map[string]string{}
-	params["Filter.1.Name"] = "owner-id"
-	params["Filter.1.Value.1"] = self.getAccountUser()
+	idx := 1
+	params[fmt.Sprintf("Filter.%d.Name", idx)] = "owner-id"
+	params[fmt.Sprintf("Filter.%d.Value.1", idx)] = self.getAccountUser()
+	idx++
 
 	if len(id) > 0 {
 		params["GroupId.1"] = id
 	}
 	if len(name) > 0 {
-		params["Filter.2.Name"] = "group-name"
-		params["Filter.2.Value.1"] = name
+		params[fmt.Sprintf("Filter.%d.Name", idx)] = "group-name"
+		params[fmt.Sprintf("Filter.%d.Value.1", idx)] = name
+		idx++
 	}
+	setTagFilter(params, idx, tags)
 	if len(nextToken) > 0 {
 		params["NextToken"] = nextToken
 	}
@@ -227,7 +236,7 @@ func (self *SRegion) GetSecurityGroups(id, name, nextToken string) ([]SSecurityG
 }
 
 func (self *SRegion) GetISecurityGroupById(id string) (cloudprovider.ICloudSecurityGroup, error) {
-	groups, _, err := self.GetSecurityGroups(id, "", "")
+	groups, _, err := self.GetSecurityGroups(id, "", nil, "")
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +250,7 @@ func (self *SRegion) GetISecurityGroupById(id string) (cloudprovider.ICloudSecur
 }
 
 func (self *SRegion) GetISecurityGroupByName(opts *cloudprovider.SecurityGroupFilterOptions) (cloudprovider.ICloudSecurityGroup, error) {
-	groups, _, err := self.GetSecurityGroups("", opts.Name, "")
+	groups, _, err := self.GetSecurityGroups("", opts.Name, nil, "")
 	if err != nil {
 		return nil, err
 	}
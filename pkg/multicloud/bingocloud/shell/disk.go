@@ -25,9 +25,10 @@ func init() {
 		Id        string
 		MaxResult int
 		NextToken string
+		Tag       []string `help:"filter by tag, key=value or key"`
 	}
 	shellutils.R(&DiskListOptions{}, "disk-list", "list disks", func(cli *bingocloud.SRegion, args *DiskListOptions) error {
-		vms, _, err := cli.GetDisks(args.Id, args.MaxResult, args.NextToken)
+		vms, _, err := cli.GetDisks(args.Id, parseTags(args.Tag), args.MaxResult, args.NextToken)
 		if err != nil {
 			return err
 		}
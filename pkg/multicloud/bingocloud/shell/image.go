@@ -24,13 +24,40 @@ func init() {
 	type ImageListOptions struct {
 		Id        string
 		NextToken string
+		Tag       []string `help:"filter by tag, key=value or key"`
 	}
 	shellutils.R(&ImageListOptions{}, "image-list", "list images", func(cli *bingocloud.SRegion, args *ImageListOptions) error {
-		images, _, err := cli.GetImages(args.Id, args.NextToken)
+		images, _, err := cli.GetImages(args.Id, parseTags(args.Tag), args.NextToken)
 		if err != nil {
 			return err
 		}
 		printList(images, 0, 0, 0, []string{})
 		return nil
 	})
+
+	type ImageIdOptions struct {
+		ID string
+	}
+	shellutils.R(&ImageIdOptions{}, "image-delete", "delete image", func(cli *bingocloud.SRegion, args *ImageIdOptions) error {
+		return cli.DeregisterImage(args.ID)
+	})
+
+	type ImageCreateOptions struct {
+		SNAPSHOT_ID string
+		NAME        string
+		OsType      string
+		Desc        string
+	}
+	shellutils.R(&ImageCreateOptions{}, "image-create", "create image from snapshot", func(cli *bingocloud.SRegion, args *ImageCreateOptions) error {
+		imageId, err := cli.CreateImageFromSnapshot(args.SNAPSHOT_ID, args.NAME, args.OsType, args.Desc)
+		if err != nil {
+			return err
+		}
+		image, err := cli.GetImageById(imageId)
+		if err != nil {
+			return err
+		}
+		printObject(image)
+		return nil
+	})
 }
@@ -26,9 +26,10 @@ func init() {
 		NodeId    string
 		MaxResult int
 		NextToken string
+		Tag       []string `help:"filter by tag, key=value or key"`
 	}
 	shellutils.R(&InstanceListOptions{}, "instance-list", "list instances", func(cli *bingocloud.SRegion, args *InstanceListOptions) error {
-		vms, _, err := cli.GetInstances(args.Id, args.NodeId, args.MaxResult, args.NextToken)
+		vms, _, err := cli.GetInstances(args.Id, args.NodeId, parseTags(args.Tag), args.MaxResult, args.NextToken)
 		if err != nil {
 			return err
 		}
@@ -49,4 +50,56 @@ func init() {
 		return nil
 	})
 
+	type NetworkInterfaceCreateOptions struct {
+		SUBNET_ID   string
+		Ip          string
+		Desc        string
+		SecgroupIds []string
+	}
+	shellutils.R(&NetworkInterfaceCreateOptions{}, "network-interface-create", "create network interface", func(cli *bingocloud.SRegion, args *NetworkInterfaceCreateOptions) error {
+		nic, err := cli.CreateNetworkInterface(args.SUBNET_ID, args.Ip, args.Desc, args.SecgroupIds)
+		if err != nil {
+			return err
+		}
+		printObject(nic)
+		return nil
+	})
+
+	type NetworkInterfaceIdOptions struct {
+		ID string
+	}
+	shellutils.R(&NetworkInterfaceIdOptions{}, "network-interface-delete", "delete network interface", func(cli *bingocloud.SRegion, args *NetworkInterfaceIdOptions) error {
+		return cli.DeleteNetworkInterface(args.ID)
+	})
+
+	type NetworkInterfaceAttachOptions struct {
+		ID          string
+		INSTANCE_ID string
+		DeviceIndex int
+	}
+	shellutils.R(&NetworkInterfaceAttachOptions{}, "network-interface-attach", "attach network interface to instance", func(cli *bingocloud.SRegion, args *NetworkInterfaceAttachOptions) error {
+		_, err := cli.AttachNetworkInterface(args.ID, args.INSTANCE_ID, args.DeviceIndex)
+		return err
+	})
+
+	type NetworkInterfaceDetachOptions struct {
+		ID    string
+		Force bool
+	}
+	shellutils.R(&NetworkInterfaceDetachOptions{}, "network-interface-detach", "detach network interface from instance", func(cli *bingocloud.SRegion, args *NetworkInterfaceDetachOptions) error {
+		return cli.DetachNetworkInterface(args.ID, args.Force)
+	})
+
+	type NetworkInterfaceAddressOptions struct {
+		ID    string
+		Ip    []string
+		Count int
+	}
+	shellutils.R(&NetworkInterfaceAddressOptions{}, "network-interface-assign-address", "assign secondary private ip addresses", func(cli *bingocloud.SRegion, args *NetworkInterfaceAddressOptions) error {
+		return cli.AssignPrivateIpAddresses(args.ID, args.Ip, args.Count)
+	})
+
+	shellutils.R(&NetworkInterfaceAddressOptions{}, "network-interface-unassign-address", "unassign secondary private ip addresses", func(cli *bingocloud.SRegion, args *NetworkInterfaceAddressOptions) error {
+		return cli.UnassignPrivateIpAddresses(args.ID, args.Ip)
+	})
 }
@@ -17,6 +17,7 @@ package shell
 import (
 	"yunion.io/x/pkg/util/shellutils"
 
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud/bingocloud"
 )
 
@@ -34,4 +35,31 @@ func init() {
 		printList(networks, 0, 0, 0, nil)
 		return nil
 	})
+
+	type NetworkCreateOptions struct {
+		VPC_ID string
+		CIDR   string
+		Name   string
+		Desc   string
+		ZoneId string
+	}
+	shellutils.R(&NetworkCreateOptions{}, "network-create", "Create network", func(cli *bingocloud.SRegion, args *NetworkCreateOptions) error {
+		network, err := cli.CreateNetwork(args.ZoneId, args.VPC_ID, &cloudprovider.SNetworkCreateOptions{
+			Name: args.Name,
+			Cidr: args.CIDR,
+			Desc: args.Desc,
+		})
+		if err != nil {
+			return err
+		}
+		printObject(network)
+		return nil
+	})
+
+	type NetworkIdOptions struct {
+		ID string
+	}
+	shellutils.R(&NetworkIdOptions{}, "network-delete", "Delete network", func(cli *bingocloud.SRegion, args *NetworkIdOptions) error {
+		return cli.DeleteNetwork(args.ID)
+	})
 }
@@ -33,4 +33,16 @@ func init() {
 		printList(nodes, 0, 0, 0, nil)
 		return nil
 	})
+
+	type NodeIdOptions struct {
+		ID string
+	}
+	shellutils.R(&NodeIdOptions{}, "node-nic-list", "List node physical nics", func(cli *bingocloud.SRegion, args *NodeIdOptions) error {
+		nics, err := cli.GetNodeNics(args.ID)
+		if err != nil {
+			return err
+		}
+		printList(nics, 0, 0, 0, nil)
+		return nil
+	})
 }
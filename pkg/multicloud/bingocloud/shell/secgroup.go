@@ -25,9 +25,10 @@ func init() {
 		Id        string
 		Name      string
 		NextToken string
+		Tag       []string `help:"filter by tag, key=value or key"`
 	}
 	shellutils.R(&SecurityGroupListOptions{}, "security-group-list", "List security-groups", func(cli *bingocloud.SRegion, args *SecurityGroupListOptions) error {
-		groups, _, err := cli.GetSecurityGroups(args.Id, args.Name, args.NextToken)
+		groups, _, err := cli.GetSecurityGroups(args.Id, args.Name, parseTags(args.Tag), args.NextToken)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,59 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"strings"
+
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/multicloud/bingocloud"
+)
+
+func parseTags(tags []string) map[string]string {
+	ret := map[string]string{}
+	for _, tag := range tags {
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) == 2 {
+			ret[kv[0]] = kv[1]
+		} else {
+			ret[kv[0]] = ""
+		}
+	}
+	return ret
+}
+
+func init() {
+	type TagListOptions struct {
+		RESOURCE_ID string
+	}
+	shellutils.R(&TagListOptions{}, "tag-list", "list resource tags", func(cli *bingocloud.SRegion, args *TagListOptions) error {
+		tags, err := cli.GetResourceTags(args.RESOURCE_ID)
+		if err != nil {
+			return err
+		}
+		printObject(tags)
+		return nil
+	})
+
+	type TagSetOptions struct {
+		RESOURCE_ID string
+		Tag         []string `help:"tag in key=value format"`
+		Replace     bool
+	}
+	shellutils.R(&TagSetOptions{}, "tag-set", "set resource tags", func(cli *bingocloud.SRegion, args *TagSetOptions) error {
+		return cli.SetResourceTags(args.RESOURCE_ID, parseTags(args.Tag), args.Replace)
+	})
+}
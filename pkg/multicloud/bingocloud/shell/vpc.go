@@ -17,19 +17,73 @@ package shell
 import (
 	"yunion.io/x/pkg/util/shellutils"
 
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud/bingocloud"
 )
 
 func init() {
 	type VpcListOptions struct {
-		Id string
+		Id  string
+		Tag []string `help:"filter by tag, key=value or key"`
 	}
 	shellutils.R(&VpcListOptions{}, "vpc-list", "List vpcs", func(cli *bingocloud.SRegion, args *VpcListOptions) error {
-		vpcs, err := cli.GetVpcs(args.Id)
+		vpcs, err := cli.GetVpcs(args.Id, parseTags(args.Tag))
 		if err != nil {
 			return err
 		}
 		printList(vpcs, 0, 0, 0, nil)
 		return nil
 	})
+
+	type VpcCreateOptions struct {
+		NAME string
+		CIDR string
+		Desc string
+	}
+	shellutils.R(&VpcCreateOptions{}, "vpc-create", "Create vpc", func(cli *bingocloud.SRegion, args *VpcCreateOptions) error {
+		vpc, err := cli.CreateVpc(&cloudprovider.VpcCreateOptions{
+			NAME: args.NAME,
+			CIDR: args.CIDR,
+			Desc: args.Desc,
+		})
+		if err != nil {
+			return err
+		}
+		printObject(vpc)
+		return nil
+	})
+
+	type VpcIdOptions struct {
+		ID string
+	}
+	shellutils.R(&VpcIdOptions{}, "vpc-delete", "Delete vpc", func(cli *bingocloud.SRegion, args *VpcIdOptions) error {
+		return cli.DeleteVpc(args.ID)
+	})
+
+	type RouteTableListOptions struct {
+		Id    string
+		VpcId string
+	}
+	shellutils.R(&RouteTableListOptions{}, "route-table-list", "List route tables", func(cli *bingocloud.SRegion, args *RouteTableListOptions) error {
+		tables, err := cli.GetRouteTables(args.Id, args.VpcId)
+		if err != nil {
+			return err
+		}
+		printList(tables, 0, 0, 0, nil)
+		return nil
+	})
+
+	type RouteOptions struct {
+		ROUTE_TABLE_ID string
+		CIDR           string
+		NextHopType    string
+		NextHop        string
+	}
+	shellutils.R(&RouteOptions{}, "route-create", "Create route", func(cli *bingocloud.SRegion, args *RouteOptions) error {
+		return cli.CreateRoute(args.ROUTE_TABLE_ID, args.CIDR, args.NextHopType, args.NextHop)
+	})
+
+	shellutils.R(&RouteOptions{}, "route-delete", "Delete route", func(cli *bingocloud.SRegion, args *RouteOptions) error {
+		return cli.DeleteRoute(args.ROUTE_TABLE_ID, args.CIDR)
+	})
 }
@@ -10,7 +10,9 @@ import (
 )
 
 type SSnapshot struct {
-	region       *SRegion
+	BingoTags
+	region *SRegion
+
 	SnapshotId   string
 	SnapshotName string
 	BackupId     string
@@ -68,11 +70,11 @@ func (self SSnapshot) GetSysTags() map[string]string {
 }
 
 func (self SSnapshot) GetTags() (map[string]string, error) {
-	return nil, nil
+	return self.BingoTags.GetTags()
 }
 
 func (self SSnapshot) SetTags(tags map[string]string, replace bool) error {
-	return nil
+	return self.region.SetResourceTags(self.SnapshotId, tags, replace)
 }
 
 func (self SSnapshot) GetProjectId() string {
@@ -17,9 +17,17 @@ package bingocloud
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
 
 	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/httputils"
+	"yunion.io/x/pkg/util/qemuimgfmt"
 
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
 )
@@ -45,10 +53,6 @@ func (self *SStoragecache) GetName() string {
 	return self.storageName
 }
 
-func (self *SStoragecache) GetICustomizedCloudImages() ([]cloudprovider.ICloudImage, error) {
-	return nil, cloudprovider.ErrNotImplemented
-}
-
 func (self *SStoragecache) GetPath() string {
 	return ""
 }
@@ -57,14 +61,100 @@ func (self *SStoragecache) GetStatus() string {
 	return "available"
 }
 
+func (self *SStoragecache) waitImageReady(imageId string) (*SImage, error) {
+	image := &SImage{cache: self, ImageId: imageId}
+	err := cloudprovider.Wait(time.Second*10, time.Minute*30, func() (bool, error) {
+		err := image.Refresh()
+		if err != nil {
+			if errors.Cause(err) == cloudprovider.ErrNotFound {
+				return false, nil
+			}
+			return false, err
+		}
+		switch image.GetStatus() {
+		case api.CACHED_IMAGE_STATUS_ACTIVE:
+			return true, nil
+		case api.CACHED_IMAGE_STATUS_CACHE_FAILED:
+			return false, errors.Errorf("image %s failed: %s", imageId, image.StateReason)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "wait image %s ready", imageId)
+	}
+	return image, nil
+}
+
 func (self *SStoragecache) CreateIImage(snapshotId, imageName, osType, imageDesc string) (cloudprovider.ICloudImage, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	imageId, err := self.region.CreateImageFromSnapshot(snapshotId, imageName, osType, imageDesc)
+	if err != nil {
+		return nil, err
+	}
+	return self.waitImageReady(imageId)
 }
 
 func (self *SStoragecache) DownloadImage(imageId string, extId string, path string) (jsonutils.JSONObject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return self.ExportImage(context.Background(), extId, &multicloud.SImageDownloadOptions{
+		Path: path,
+		Callback: func(progress float32) {
+			log.Debugf("download image %s(%s) progress: %.2f%%", imageId, extId, progress)
+		},
+	})
+}
+
+func (self *SStoragecache) ExportImage(ctx context.Context, imageId string, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	image, err := self.region.GetImageById(imageId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetImageById(%s)", imageId)
+	}
+	fileName := filepath.Base(image.ImagePath)
+	if len(image.ImagePath) == 0 {
+		fileName = image.ImageId
+	}
+	resp, err := self.region.client.storageRequest(ctx, httputils.GET, self.storageId, fileName, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	size := resp.ContentLength
+	if size <= 0 {
+		size = image.ImageSize
+	}
+	stream := &multicloud.SImageStream{
+		Body:   resp.Body,
+		Size:   size,
+		Format: image.GetImageFormat(),
+	}
+	return multicloud.SaveImage(ctx, stream, opts)
 }
 
 func (self *SStoragecache) UploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.QCOW2, callback)
+	if err != nil {
+		return "", errors.Wrapf(err, "GetImageReader")
+	}
+	defer reader.Close()
+	size, callback := reader.Size, reader.Callback
+	fileName := fmt.Sprintf("%s.qcow2", image.ImageId)
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Length", fmt.Sprintf("%d", size))
+	body := multicloud.NewProgress(size, 90, reader, callback)
+	resp, err := self.region.client.storageRequest(ctx, httputils.PUT, self.storageId, fileName, header, body)
+	if err != nil {
+		return "", errors.Wrapf(err, "upload %s", fileName)
+	}
+	resp.Body.Close()
+
+	imageId, err := self.region.RegisterImage(image, self.storageId, fileName)
+	if err != nil {
+		return "", err
+	}
+	_, err = self.waitImageReady(imageId)
+	if err != nil {
+		return "", err
+	}
+	if callback != nil {
+		callback(100)
+	}
+	return imageId, nil
 }
@@ -15,6 +15,9 @@
 package bingocloud
 
 import (
+	"fmt"
+	"sort"
+
 	"yunion.io/x/pkg/errors"
 
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -42,3 +45,105 @@ func (self *BingoTags) GetSysTags() map[string]string {
 func (self *BingoTags) SetTags(tags map[string]string, replace bool) error {
 	return errors.Wrap(cloudprovider.ErrNotImplemented, "SetTags")
 }
+
+func setTagFilter(params map[string]string, idx int, tags map[string]string) int {
+	keys := []string{}
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if len(tags[k]) > 0 {
+			params[fmt.Sprintf("Filter.%d.Name", idx)] = fmt.Sprintf("tag:%s", k)
+			params[fmt.Sprintf("Filter.%d.Value.1", idx)] = tags[k]
+		} else {
+			params[fmt.Sprintf("Filter.%d.Name", idx)] = "tag-key"
+			params[fmt.Sprintf("Filter.%d.Value.1", idx)] = k
+		}
+		idx++
+	}
+	return idx
+}
+
+func (self *SRegion) GetResourceTags(resId string) (map[string]string, error) {
+	resp, err := self.client.describeTags(map[string]string{"resource-id": resId})
+	if err != nil {
+		return nil, errors.Wrapf(err, "DescribeTags")
+	}
+	ret := struct {
+		TagSet []struct {
+			ResourceId string
+			Key        string
+			Value      string
+		}
+	}{}
+	_ = resp.Unmarshal(&ret)
+	tags := map[string]string{}
+	for _, tag := range ret.TagSet {
+		if tag.ResourceId == resId {
+			tags[tag.Key] = tag.Value
+		}
+	}
+	return tags, nil
+}
+
+func (self *SRegion) CreateTags(resId string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	params := map[string]string{
+		"ResourceId.1": resId,
+	}
+	i := 1
+	for k, v := range tags {
+		params[fmt.Sprintf("Tag.%d.Key", i)] = k
+		params[fmt.Sprintf("Tag.%d.Value", i)] = v
+		i++
+	}
+	_, err := self.invoke("CreateTags", params)
+	return err
+}
+
+func (self *SRegion) DeleteTags(resId string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	params := map[string]string{
+		"ResourceId.1": resId,
+	}
+	for i, k := range keys {
+		params[fmt.Sprintf("Tag.%d.Key", i+1)] = k
+	}
+	_, err := self.invoke("DeleteTags", params)
+	return err
+}
+
+func (self *SRegion) SetResourceTags(resId string, tags map[string]string, replace bool) error {
+	oldTags, err := self.GetResourceTags(resId)
+	if err != nil {
+		return err
+	}
+	addTags := map[string]string{}
+	for k, v := range tags {
+		if old, ok := oldTags[k]; !ok || old != v {
+			addTags[k] = v
+		}
+	}
+	delKeys := []string{}
+	if replace {
+		for k := range oldTags {
+			if _, ok := tags[k]; !ok {
+				delKeys = append(delKeys, k)
+			}
+		}
+	}
+	err = self.DeleteTags(resId, delKeys)
+	if err != nil {
+		return errors.Wrapf(err, "DeleteTags %s", delKeys)
+	}
+	err = self.CreateTags(resId, addTags)
+	if err != nil {
+		return errors.Wrapf(err, "CreateTags")
+	}
+	return nil
+}
@@ -15,6 +15,7 @@
 package bingocloud
 
 import (
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
@@ -24,7 +25,7 @@ import (
 
 type SVpc struct {
 	multicloud.SVpc
-	multicloud.STagBase
+	BingoTags
 
 	region *SRegion
 
@@ -60,8 +61,12 @@ func (self *SVpc) GetName() string {
 	return self.VpcName
 }
 
+func (self *SVpc) SetTags(tags map[string]string, replace bool) error {
+	return self.region.SetResourceTags(self.VpcId, tags, replace)
+}
+
 func (self *SVpc) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return self.region.DeleteVpc(self.VpcId)
 }
 
 func (self *SVpc) GetCidrBlock() string {
@@ -69,22 +74,41 @@ func (self *SVpc) GetCidrBlock() string {
 }
 
 func (self *SVpc) GetIRouteTableById(id string) (cloudprovider.ICloudRouteTable, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	tables, err := self.region.GetRouteTables(id, self.VpcId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		if tables[i].GetGlobalId() == id {
+			tables[i].vpc = self
+			return &tables[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, id)
 }
 
 func (self *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	tables, err := self.region.GetRouteTables("", self.VpcId)
+	if err != nil {
+		return nil, err
+	}
+	var ret []cloudprovider.ICloudRouteTable
+	for i := range tables {
+		tables[i].vpc = self
+		ret = append(ret, &tables[i])
+	}
+	return ret, nil
 }
 
 func (self *SVpc) GetISecurityGroups() ([]cloudprovider.ICloudSecurityGroup, error) {
-	part, nextToken, err := self.region.GetSecurityGroups("", "", "")
+	part, nextToken, err := self.region.GetSecurityGroups("", "", nil, "")
 	if err != nil {
 		return nil, err
 	}
 	var groups []SSecurityGroup
 	groups = append(groups, part...)
 	for len(nextToken) > 0 {
-		part, nextToken, err = self.region.GetSecurityGroups("", "", nextToken)
+		part, nextToken, err = self.region.GetSecurityGroups("", "", nil, nextToken)
 		if err != nil {
 			return nil, err
 		}
@@ -110,16 +134,32 @@ func (self *SVpc) GetStatus() string {
 	switch self.State {
 	case "available":
 		return api.VPC_STATUS_AVAILABLE
+	case "pending":
+		return api.VPC_STATUS_PENDING
 	default:
 		return self.State
 	}
 }
 
-func (self *SRegion) GetVpcs(id string) ([]SVpc, error) {
+func (self *SVpc) Refresh() error {
+	vpcs, err := self.region.GetVpcs(self.VpcId, nil)
+	if err != nil {
+		return err
+	}
+	for i := range vpcs {
+		if vpcs[i].VpcId == self.VpcId {
+			return jsonutils.Update(self, vpcs[i])
+		}
+	}
+	return errors.Wrapf(cloudprovider.ErrNotFound, self.VpcId)
+}
+
+func (self *SRegion) GetVpcs(id string, tags map[string]string) ([]SVpc, error) {
 	params := map[string]string{}
 	if len(id) > 0 {
 		params["VpcId"] = id
 	}
+	setTagFilter(params, 1, tags)
 
 	resp, err := self.invoke("DescribeVpcs", params)
 	if err != nil {
@@ -130,8 +170,71 @@ func (self *SRegion) GetVpcs(id string) ([]SVpc, error) {
 	return vpcs, resp.Unmarshal(&vpcs, "vpcSet")
 }
 
+func (self *SRegion) GetVpc(id string) (*SVpc, error) {
+	vpcs, err := self.GetVpcs(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vpcs {
+		if vpcs[i].VpcId == id {
+			vpcs[i].region = self
+			return &vpcs[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, id)
+}
+
+func (self *SRegion) CreateVpc(opts *cloudprovider.VpcCreateOptions) (*SVpc, error) {
+	params := map[string]string{
+		"CidrBlock": opts.CIDR,
+	}
+	if len(opts.NAME) > 0 {
+		params["VpcName"] = opts.NAME
+	}
+	if len(opts.Desc) > 0 {
+		params["Description"] = opts.Desc
+	}
+	resp, err := self.invoke("CreateVpc", params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateVpc")
+	}
+	vpc := &SVpc{region: self}
+	err = resp.Unmarshal(vpc, "vpc")
+	if err != nil {
+		return nil, errors.Wrapf(err, "resp.Unmarshal")
+	}
+	if len(vpc.VpcId) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "vpcId after CreateVpc")
+	}
+	return self.GetVpc(vpc.VpcId)
+}
+
+func (self *SRegion) DeleteVpc(id string) error {
+	params := map[string]string{
+		"VpcId": id,
+	}
+	_, err := self.invoke("DeleteVpc", params)
+	return err
+}
+
+func (self *SRegion) CreateIVpc(opts *cloudprovider.VpcCreateOptions) (cloudprovider.ICloudVpc, error) {
+	vpc, err := self.CreateVpc(opts)
+	if err != nil {
+		return nil, err
+	}
+	return vpc, nil
+}
+
+func (self *SRegion) GetIVpcById(id string) (cloudprovider.ICloudVpc, error) {
+	vpc, err := self.GetVpc(id)
+	if err != nil {
+		return nil, err
+	}
+	return vpc, nil
+}
+
 func (self *SRegion) GetIVpcs() ([]cloudprovider.ICloudVpc, error) {
-	vpcs, err := self.GetVpcs("")
+	vpcs, err := self.GetVpcs("", nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "GetVpcs")
 	}
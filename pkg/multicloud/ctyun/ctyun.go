@@ -86,6 +86,9 @@ type SCtyunClient struct {
 
 	httpClient *http.Client
 	iregions   []cloudprovider.ICloudRegion
+
+	oosClient *SOosClient
+	iBuckets  []cloudprovider.ICloudBucket
 }
 
 func NewSCtyunClient(cfg *CtyunClientConfig) (*SCtyunClient, error) {
@@ -345,10 +348,6 @@ func (self *SCtyunClient) GetIRegionById(id string) (cloudprovider.ICloudRegion,
 	return nil, cloudprovider.ErrNotFound
 }
 
-func (self *SCtyunClient) GetIProjects() ([]cloudprovider.ICloudProject, error) {
-	return nil, cloudprovider.ErrNotImplemented
-}
-
 func (self *SCtyunClient) GetAccessEnv() string {
 	return api.CLOUD_ACCESS_ENV_CTYUN_CHINA
 }
@@ -384,12 +383,12 @@ func (self *SCtyunClient) GetCloudRegionExternalIdPrefix() string {
 
 func (self *SCtyunClient) GetCapabilities() []string {
 	caps := []string{
-		// cloudprovider.CLOUD_CAPABILITY_PROJECT,
+		cloudprovider.CLOUD_CAPABILITY_PROJECT,
 		cloudprovider.CLOUD_CAPABILITY_COMPUTE,
 		cloudprovider.CLOUD_CAPABILITY_NETWORK,
 		cloudprovider.CLOUD_CAPABILITY_EIP,
-		// cloudprovider.CLOUD_CAPABILITY_LOADBALANCER,
-		// cloudprovider.CLOUD_CAPABILITY_OBJECTSTORE,
+		cloudprovider.CLOUD_CAPABILITY_LOADBALANCER,
+		cloudprovider.CLOUD_CAPABILITY_OBJECTSTORE,
 		// cloudprovider.CLOUD_CAPABILITY_RDS,
 		// cloudprovider.CLOUD_CAPABILITY_CACHE,
 		// cloudprovider.CLOUD_CAPABILITY_EVENT,
@@ -60,6 +60,7 @@ type SDisk struct {
 	WorkOrderResourceID string       `json:"workOrderResourceId"`
 	ExpireTime          int64        `json:"expireTime"`
 	IsFreeze            int64        `json:"isFreeze"`
+	EnterpriseProjectID string       `json:"enterprise_project_id"`
 }
 
 type Attachment struct {
@@ -194,7 +195,7 @@ func (self *SDisk) GetSysTags() map[string]string {
 }
 
 func (self *SDisk) GetProjectId() string {
-	return ""
+	return self.EnterpriseProjectID
 }
 
 func (self *SDisk) GetIStorage() (cloudprovider.ICloudStorage, error) {
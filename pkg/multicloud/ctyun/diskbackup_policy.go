@@ -15,6 +15,7 @@
 package ctyun
 
 import (
+	"fmt"
 	"strconv"
 
 	"yunion.io/x/jsonutils"
@@ -98,7 +99,12 @@ func (self *SDiskBacupPolicy) GetRetentionDays() int {
 }
 
 func (self *SDiskBacupPolicy) GetRepeatWeekdays() ([]int, error) {
-	return []int{self.ScheduledPolicy.Frequency}, nil
+	// 每天备份
+	if self.ScheduledPolicy.Frequency <= 1 {
+		return []int{1, 2, 3, 4, 5, 6, 7}, nil
+	}
+	// 按天间隔执行的策略无法用星期表示
+	return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "backup every %d days", self.ScheduledPolicy.Frequency)
 }
 
 func (self *SDiskBacupPolicy) GetTimePoints() ([]int, error) {
@@ -145,28 +151,37 @@ func (self *SRegion) GetDiskBackupPolicy(policyId string) (*SDiskBacupPolicy, er
 	return nil, errors.Wrap(cloudprovider.ErrNotFound, "SRegion.GetDiskBackupPolicy")
 }
 
-func (self *SRegion) CreateDiskBackupPolicy(name, startTime, frequency, rententionNum, firstBackup, status string) error {
-	policyParams := jsonutils.NewDict()
-	policyParams.Set("policyName", jsonutils.NewString(name))
+func newScheduledPolicyParams(startTime, frequency, rententionNum, firstBackup, status string) *jsonutils.JSONDict {
 	scheduleParams := jsonutils.NewDict()
 	scheduleParams.Set("startTime", jsonutils.NewString(startTime))
 	scheduleParams.Set("frequency", jsonutils.NewString(frequency))
 	scheduleParams.Set("rententionNum", jsonutils.NewString(rententionNum))
 	scheduleParams.Set("firstBackup", jsonutils.NewString(firstBackup))
 	scheduleParams.Set("status", jsonutils.NewString(status))
-	policyParams.Set("scheduledPolicy", scheduleParams)
+	return scheduleParams
+}
+
+func (self *SRegion) CreateDiskBackupPolicy(name, startTime, frequency, rententionNum, firstBackup, status string) (string, error) {
+	policyParams := jsonutils.NewDict()
+	policyParams.Set("policyName", jsonutils.NewString(name))
+	policyParams.Set("scheduledPolicy", newScheduledPolicyParams(startTime, frequency, rententionNum, firstBackup, status))
 
 	params := map[string]jsonutils.JSONObject{
 		"regionId": jsonutils.NewString(self.GetId()),
 		"jsonStr":  policyParams,
 	}
 
-	_, err := self.client.DoPost("/apiproxy/v3/ondemand/createDiskBackupPolicy", params)
+	resp, err := self.client.DoPost("/apiproxy/v3/ondemand/createDiskBackupPolicy", params)
 	if err != nil {
-		return errors.Wrap(err, "SRegion.CreateDiskBackupPolicy.DoPost")
+		return "", errors.Wrap(err, "SRegion.CreateDiskBackupPolicy.DoPost")
 	}
 
-	return nil
+	policyId, err := resp.GetString("returnObj", "backup_policy_id")
+	if err != nil {
+		return "", errors.Wrap(err, "SRegion.CreateDiskBackupPolicy.GetString")
+	}
+
+	return policyId, nil
 }
 
 func (self *SRegion) BindingDiskBackupPolicy(policyId, resourceId, resourceType string) error {
@@ -211,3 +226,104 @@ func (self *SRegion) UnBindDiskBackupPolicy(policyId, resourceId string) error {
 
 	return nil
 }
+
+func (self *SRegion) UpdateDiskBackupPolicy(policyId, name, startTime, frequency, rententionNum, firstBackup, status string) error {
+	policyParams := jsonutils.NewDict()
+	if len(name) > 0 {
+		policyParams.Set("policyName", jsonutils.NewString(name))
+	}
+	policyParams.Set("scheduledPolicy", newScheduledPolicyParams(startTime, frequency, rententionNum, firstBackup, status))
+
+	params := map[string]jsonutils.JSONObject{
+		"regionId": jsonutils.NewString(self.GetId()),
+		"policyId": jsonutils.NewString(policyId),
+		"jsonStr":  policyParams,
+	}
+
+	_, err := self.client.DoPost("/apiproxy/v3/ondemand/updateDiskBackupPolicy", params)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.UpdateDiskBackupPolicy.DoPost")
+	}
+
+	return nil
+}
+
+func (self *SRegion) DeleteDiskBackupPolicy(policyId string) error {
+	params := map[string]jsonutils.JSONObject{
+		"regionId": jsonutils.NewString(self.GetId()),
+		"policyId": jsonutils.NewString(policyId),
+	}
+
+	_, err := self.client.DoPost("/apiproxy/v3/ondemand/deleteDiskBackupPolicy", params)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.DeleteDiskBackupPolicy.DoPost")
+	}
+
+	return nil
+}
+
+// 云硬盘备份策略按天间隔执行(1-14天), 不支持指定星期, 保留份数范围为 2-99999
+func getDiskBackupPolicySchedule(input *cloudprovider.SnapshotPolicyInput) (startTime, frequency, rententionNum string, err error) {
+	if len(input.TimePoints) == 0 {
+		return "", "", "", errors.Wrap(cloudprovider.ErrInputParameter, "missing time points")
+	}
+	if len(input.TimePoints) > 1 {
+		return "", "", "", errors.Wrapf(cloudprovider.ErrNotSupported, "multiple time points %v", input.TimePoints)
+	}
+	startTime = fmt.Sprintf("%02d:00", input.TimePoints[0])
+
+	if len(input.RepeatWeekdays) > 0 && len(input.RepeatWeekdays) < 7 {
+		return "", "", "", errors.Wrapf(cloudprovider.ErrNotSupported, "repeat weekdays %v", input.RepeatWeekdays)
+	}
+	frequency = "1"
+
+	retention := input.RetentionDays
+	if retention < 0 {
+		// 永久保留
+		retention = 99999
+	} else if retention < 2 {
+		retention = 2
+	}
+	rententionNum = strconv.Itoa(retention)
+	return startTime, frequency, rententionNum, nil
+}
+
+func (self *SRegion) CreateSnapshotPolicy(input *cloudprovider.SnapshotPolicyInput) (string, error) {
+	startTime, frequency, rententionNum, err := getDiskBackupPolicySchedule(input)
+	if err != nil {
+		return "", err
+	}
+	policyId, err := self.CreateDiskBackupPolicy(input.PolicyName, startTime, frequency, rententionNum, "N", "ON")
+	if err != nil {
+		return "", errors.Wrap(err, "CreateDiskBackupPolicy")
+	}
+	return policyId, nil
+}
+
+func (self *SRegion) UpdateSnapshotPolicy(input *cloudprovider.SnapshotPolicyInput, snapshotPolicyId string) error {
+	policy, err := self.GetDiskBackupPolicy(snapshotPolicyId)
+	if err != nil {
+		return errors.Wrap(err, "GetDiskBackupPolicy")
+	}
+	startTime, frequency, rententionNum, err := getDiskBackupPolicySchedule(input)
+	if err != nil {
+		return err
+	}
+	firstBackup := policy.ScheduledPolicy.RemainFirstBackupOfCurMonth
+	if len(firstBackup) == 0 {
+		firstBackup = "N"
+	}
+	return self.UpdateDiskBackupPolicy(snapshotPolicyId, input.PolicyName, startTime, frequency, rententionNum, firstBackup, policy.ScheduledPolicy.Status)
+}
+
+func (self *SRegion) DeleteSnapshotPolicy(policyId string) error {
+	return self.DeleteDiskBackupPolicy(policyId)
+}
+
+func (self *SRegion) ApplySnapshotPolicyToDisks(snapshotPolicyId string, diskId string) error {
+	return self.BindingDiskBackupPolicy(snapshotPolicyId, diskId, "volume")
+}
+
+func (self *SRegion) CancelSnapshotPolicyToDisks(snapshotPolicyId string, diskId string) error {
+	return self.UnBindDiskBackupPolicy(snapshotPolicyId, diskId)
+}
@@ -115,7 +115,7 @@ func (self *SEip) IsEmulated() bool {
 }
 
 func (self *SEip) GetProjectId() string {
-	return ""
+	return self.EnterpriseProjectID
 }
 
 func (self *SEip) GetIpAddr() string {
@@ -59,6 +59,7 @@ type SInstance struct {
 	OSEXTAZAvailabilityZone          string               `json:"OS-EXT-AZ:availability_zone"`
 	OSExtendedVolumesVolumesAttached []Volume             `json:"os-extended-volumes:volumes_attached"`
 	MasterOrderId                    string               `json:"masterOrderId"`
+	EnterpriseProjectID              string               `json:"enterprise_project_id"`
 }
 
 type InstanceDetails struct {
@@ -187,7 +188,7 @@ func (self *SInstance) IsEmulated() bool {
 }
 
 func (self *SInstance) GetProjectId() string {
-	return ""
+	return self.EnterpriseProjectID
 }
 
 func (self *SInstance) GetIHost() cloudprovider.ICloudHost {
@@ -0,0 +1,456 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctyun
+
+import (
+	"context"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SElbResourceRef struct {
+	ID string `json:"id"`
+}
+
+type SLoadbalancer struct {
+	multicloud.SLoadbalancerBase
+	CtyunTags
+	region *SRegion
+
+	ID                  string            `json:"id"`
+	Name                string            `json:"name"`
+	Description         string            `json:"description"`
+	ProvisioningStatus  string            `json:"provisioning_status"`
+	OperatingStatus     string            `json:"operating_status"`
+	AdminStateUp        bool              `json:"admin_state_up"`
+	VipAddress          string            `json:"vip_address"`
+	VipPortID           string            `json:"vip_port_id"`
+	VipSubnetID         string            `json:"vip_subnet_id"`
+	VpcID               string            `json:"vpc_id"`
+	Listeners           []SElbResourceRef `json:"listeners"`
+	Pools               []SElbResourceRef `json:"pools"`
+	CreatedAt           time.Time         `json:"created_at"`
+	MasterOrderID       string            `json:"masterOrderId"`
+	EnterpriseProjectID string            `json:"enterprise_project_id"`
+}
+
+func (self *SLoadbalancer) GetId() string {
+	return self.ID
+}
+
+func (self *SLoadbalancer) GetName() string {
+	return self.Name
+}
+
+func (self *SLoadbalancer) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SLoadbalancer) GetStatus() string {
+	switch self.ProvisioningStatus {
+	case "ACTIVE":
+		return api.LB_STATUS_ENABLED
+	case "PENDING_CREATE":
+		return api.LB_CREATING
+	case "PENDING_DELETE":
+		return api.LB_STATUS_DELETING
+	default:
+		return api.LB_STATUS_UNKNOWN
+	}
+}
+
+func (self *SLoadbalancer) Refresh() error {
+	lb, err := self.region.GetLoadbalancer(self.ID)
+	if err != nil {
+		return errors.Wrap(err, "SLoadbalancer.Refresh.GetLoadbalancer")
+	}
+	self.Listeners = nil
+	self.Pools = nil
+	return jsonutils.Update(self, lb)
+}
+
+func (self *SLoadbalancer) GetProjectId() string {
+	return self.EnterpriseProjectID
+}
+
+func (self *SLoadbalancer) GetCreatedAt() time.Time {
+	return self.CreatedAt
+}
+
+func (self *SLoadbalancer) GetAddress() string {
+	return self.VipAddress
+}
+
+func (self *SLoadbalancer) GetAddressType() string {
+	return api.LB_ADDR_TYPE_INTRANET
+}
+
+func (self *SLoadbalancer) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+// vip_subnet_id 为neutron子网ID, 需要转换为resVlanId
+func (self *SLoadbalancer) getNetwork() (*SNetwork, error) {
+	networks, err := self.region.GetNetwroks(self.VpcID)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetNetwroks")
+	}
+	for i := range networks {
+		if networks[i].NeutronSubnetID == self.VipSubnetID {
+			return &networks[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "neutron subnet %s", self.VipSubnetID)
+}
+
+func (self *SLoadbalancer) GetNetworkIds() []string {
+	network, err := self.getNetwork()
+	if err != nil {
+		log.Errorf("SLoadbalancer.GetNetworkIds %s", err)
+		return []string{}
+	}
+	return []string{network.GetId()}
+}
+
+func (self *SLoadbalancer) GetVpcId() string {
+	if len(self.VpcID) > 0 {
+		return self.VpcID
+	}
+	network, err := self.getNetwork()
+	if err != nil {
+		return ""
+	}
+	return network.VpcID
+}
+
+func (self *SLoadbalancer) GetZoneId() string {
+	return ""
+}
+
+func (self *SLoadbalancer) GetZone1Id() string {
+	return ""
+}
+
+func (self *SLoadbalancer) GetLoadbalancerSpec() string {
+	return ""
+}
+
+func (self *SLoadbalancer) GetChargeType() string {
+	return api.LB_CHARGE_TYPE_BY_TRAFFIC
+}
+
+func (self *SLoadbalancer) GetEgressMbps() int {
+	return 0
+}
+
+func (self *SLoadbalancer) GetIEIP() (cloudprovider.ICloudEIP, error) {
+	if len(self.VipPortID) == 0 {
+		return nil, nil
+	}
+	eips, err := self.region.GetEips()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetEips")
+	}
+	for i := range eips {
+		if eips[i].PortID == self.VipPortID {
+			return &eips[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (self *SLoadbalancer) Delete(ctx context.Context) error {
+	return self.region.DeleteLoadbalancer(self.ID)
+}
+
+func (self *SLoadbalancer) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancer) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	listeners, err := self.region.GetLoadbalancerListeners(self.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudLoadbalancerListener{}
+	for i := range listeners {
+		listeners[i].lb = self
+		ret = append(ret, &listeners[i])
+	}
+	return ret, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	listener, err := self.region.GetLoadbalancerListener(listenerId)
+	if err != nil {
+		return nil, err
+	}
+	listener.lb = self
+	return listener, nil
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerListener(ctx context.Context, opts *cloudprovider.SLoadbalancerListenerCreateOptions) (cloudprovider.ICloudLoadbalancerListener, error) {
+	listener, err := self.region.CreateLoadbalancerListener(self.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	listener.lb = self
+	return listener, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	groups, err := self.region.GetLoadbalancerBackendGroups(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudLoadbalancerBackendGroup{}
+	for i := range groups {
+		groups[i].lb = self
+		ret = append(ret, &groups[i])
+	}
+	return ret, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	group, err := self.region.GetLoadbalancerBackendGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+	group.lb = self
+	return group, nil
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerBackendGroup(opts *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	group, err := self.region.CreateLoadbalancerBackendGroup(self.ID, "", opts)
+	if err != nil {
+		return nil, err
+	}
+	group.lb = self
+	return group, nil
+}
+
+func (self *SRegion) elbList(apiName string, params map[string]string, retVal interface{}) error {
+	params["regionId"] = self.GetId()
+	resp, err := self.client.DoGet(apiName, params)
+	if err != nil {
+		return errors.Wrapf(err, "DoGet %s", apiName)
+	}
+	err = resp.Unmarshal(retVal, "returnObj")
+	if err != nil {
+		return errors.Wrapf(err, "Unmarshal %s", apiName)
+	}
+	return nil
+}
+
+func (self *SRegion) elbPost(apiName string, params map[string]string, jsonStr jsonutils.JSONObject) (jsonutils.JSONObject, error) {
+	body := map[string]jsonutils.JSONObject{
+		"regionId": jsonutils.NewString(self.GetId()),
+	}
+	for k, v := range params {
+		body[k] = jsonutils.NewString(v)
+	}
+	if jsonStr != nil {
+		body["jsonStr"] = jsonStr
+	}
+	resp, err := self.client.DoPost(apiName, body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "DoPost %s", apiName)
+	}
+	return resp, nil
+}
+
+func (self *SRegion) GetLoadbalancers(lbId string) ([]SLoadbalancer, error) {
+	params := map[string]string{}
+	if len(lbId) > 0 {
+		params["loadBalancerId"] = lbId
+	}
+	lbs := make([]SLoadbalancer, 0)
+	err := self.elbList("/apiproxy/v3/elb/queryLoadBalancers", params, &lbs)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.GetLoadbalancers")
+	}
+	for i := range lbs {
+		lbs[i].region = self
+	}
+	return lbs, nil
+}
+
+func (self *SRegion) GetLoadbalancer(lbId string) (*SLoadbalancer, error) {
+	lbs, err := self.GetLoadbalancers(lbId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lbs {
+		if lbs[i].ID == lbId {
+			return &lbs[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "SRegion.GetLoadbalancer %s", lbId)
+}
+
+func (self *SRegion) GetILoadBalancers() ([]cloudprovider.ICloudLoadbalancer, error) {
+	lbs, err := self.GetLoadbalancers("")
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudLoadbalancer{}
+	for i := range lbs {
+		ret = append(ret, &lbs[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetILoadBalancerById(id string) (cloudprovider.ICloudLoadbalancer, error) {
+	return self.GetLoadbalancer(id)
+}
+
+func (self *SRegion) CreateILoadBalancer(opts *cloudprovider.SLoadbalancerCreateOptions) (cloudprovider.ICloudLoadbalancer, error) {
+	if len(opts.NetworkIds) == 0 {
+		return nil, errors.Wrap(cloudprovider.ErrInputParameter, "missing network")
+	}
+	network, err := self.GetNetwork(opts.NetworkIds[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "GetNetwork")
+	}
+	lbParams := jsonutils.NewDict()
+	lbParams.Set("name", jsonutils.NewString(opts.Name))
+	lbParams.Set("description", jsonutils.NewString(opts.Desc))
+	lbParams.Set("vpcId", jsonutils.NewString(opts.VpcId))
+	lbParams.Set("subnetId", jsonutils.NewString(network.NeutronSubnetID))
+	if len(opts.Address) > 0 {
+		lbParams.Set("vipAddress", jsonutils.NewString(opts.Address))
+	}
+	resp, err := self.elbPost("/apiproxy/v3/ondemand/createLoadBalancer", nil, lbParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateILoadBalancer")
+	}
+	lbId, err := resp.GetString("returnObj", "id")
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateILoadBalancer.GetId")
+	}
+	lb, err := self.GetLoadbalancer(lbId)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.EipId) > 0 {
+		err = self.AssociateEip(opts.EipId, lb.VipPortID)
+		if err != nil {
+			return nil, errors.Wrap(err, "AssociateEip")
+		}
+	}
+	return lb, nil
+}
+
+func (self *SRegion) DeleteLoadbalancer(lbId string) error {
+	_, err := self.elbPost("/apiproxy/v3/ondemand/deleteLoadBalancer", map[string]string{"loadBalancerId": lbId}, nil)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.DeleteLoadbalancer")
+	}
+	return nil
+}
+
+func (self *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) GetILoadBalancerAclById(aclId string) (cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) CreateILoadBalancerAcl(acl *cloudprovider.SLoadbalancerAccessControlList) (cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) getInstanceByIP(ipAddr string) (*SInstance, error) {
+	vms, err := self.GetVMs()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetVMs")
+	}
+	for i := range vms {
+		for _, addrs := range vms[i].Addresses {
+			for _, addr := range addrs {
+				if addr.Addr == ipAddr {
+					return &vms[i], nil
+				}
+			}
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "instance with ip %s", ipAddr)
+}
+
+func getElbProtocol(listenerType string) string {
+	switch listenerType {
+	case api.LB_LISTENER_TYPE_HTTP:
+		return "HTTP"
+	case api.LB_LISTENER_TYPE_HTTPS:
+		return "TERMINATED_HTTPS"
+	case api.LB_LISTENER_TYPE_UDP:
+		return "UDP"
+	default:
+		return "TCP"
+	}
+}
+
+func getElbAlgorithm(scheduler string) string {
+	switch scheduler {
+	case api.LB_SCHEDULER_WLC:
+		return "LEAST_CONNECTIONS"
+	case api.LB_SCHEDULER_SCH:
+		return "SOURCE_IP"
+	default:
+		return "ROUND_ROBIN"
+	}
+}
+
+func getElbSessionPersistence(opts *cloudprovider.ListenerStickySessionOptions) jsonutils.JSONObject {
+	if opts.StickySession != api.LB_BOOL_ON {
+		return nil
+	}
+	session := jsonutils.NewDict()
+	switch opts.StickySessionType {
+	case api.LB_STICKY_SESSION_TYPE_SERVER:
+		session.Set("type", jsonutils.NewString("APP_COOKIE"))
+		session.Set("cookieName", jsonutils.NewString(opts.StickySessionCookie))
+	default:
+		session.Set("type", jsonutils.NewString("HTTP_COOKIE"))
+	}
+	if opts.StickySessionCookieTimeout > 0 {
+		// 单位为分钟
+		session.Set("persistenceTimeout", jsonutils.NewInt(int64(opts.StickySessionCookieTimeout/60)))
+	}
+	return session
+}
+
+func getElbHealthMonitorType(healthCheckType string) string {
+	switch healthCheckType {
+	case api.LB_HEALTH_CHECK_HTTP, api.LB_HEALTH_CHECK_HTTPS:
+		return "HTTP"
+	case api.LB_HEALTH_CHECK_UDP:
+		return "UDP_CONNECT"
+	default:
+		return "TCP"
+	}
+}
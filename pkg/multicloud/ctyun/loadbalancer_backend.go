@@ -0,0 +1,153 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctyun
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SElbBackend struct {
+	multicloud.SResourceBase
+	CtyunTags
+	group *SElbBackendGroup
+
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Address         string `json:"address"`
+	ProtocolPort    int    `json:"protocol_port"`
+	Weight          int    `json:"weight"`
+	SubnetID        string `json:"subnet_id"`
+	AdminStateUp    bool   `json:"admin_state_up"`
+	OperatingStatus string `json:"operating_status"`
+}
+
+func (self *SElbBackend) GetId() string {
+	return self.ID
+}
+
+func (self *SElbBackend) GetName() string {
+	return self.Name
+}
+
+func (self *SElbBackend) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbBackend) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbBackend) Refresh() error {
+	backends, err := self.group.region.GetLoadbalancerBackends(self.group.ID)
+	if err != nil {
+		return err
+	}
+	for i := range backends {
+		if backends[i].ID == self.ID {
+			return jsonutils.Update(self, backends[i])
+		}
+	}
+	return errors.Wrapf(cloudprovider.ErrNotFound, self.ID)
+}
+
+func (self *SElbBackend) GetWeight() int {
+	return self.Weight
+}
+
+func (self *SElbBackend) GetPort() int {
+	return self.ProtocolPort
+}
+
+func (self *SElbBackend) GetBackendType() string {
+	return api.LB_BACKEND_GUEST
+}
+
+func (self *SElbBackend) GetBackendRole() string {
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+// 后端服务器仅返回IP地址, 通过IP反查云主机
+func (self *SElbBackend) GetBackendId() string {
+	vm, err := self.group.region.getInstanceByIP(self.Address)
+	if err != nil {
+		log.Errorf("SElbBackend.GetBackendId %s", err)
+		return ""
+	}
+	return vm.GetGlobalId()
+}
+
+func (self *SElbBackend) GetIpAddress() string {
+	return ""
+}
+
+func (self *SElbBackend) SyncConf(ctx context.Context, port, weight int) error {
+	if port > 0 && port != self.ProtocolPort {
+		log.Warningf("SElbBackend.SyncConf unsupport modify port")
+	}
+	params := jsonutils.NewDict()
+	params.Set("weight", jsonutils.NewInt(int64(weight)))
+	return self.group.region.UpdateLoadbalancerBackend(self.group.ID, self.ID, params)
+}
+
+func (self *SRegion) GetLoadbalancerBackends(poolId string) ([]SElbBackend, error) {
+	backends := make([]SElbBackend, 0)
+	err := self.elbList("/apiproxy/v3/elb/queryMembers", map[string]string{"poolId": poolId}, &backends)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.GetLoadbalancerBackends")
+	}
+	return backends, nil
+}
+
+func (self *SRegion) CreateLoadbalancerBackend(poolId, subnetId, ipAddr string, port, weight int) (*SElbBackend, error) {
+	params := jsonutils.NewDict()
+	params.Set("subnetId", jsonutils.NewString(subnetId))
+	params.Set("address", jsonutils.NewString(ipAddr))
+	params.Set("protocolPort", jsonutils.NewInt(int64(port)))
+	params.Set("weight", jsonutils.NewInt(int64(weight)))
+	resp, err := self.elbPost("/apiproxy/v3/elb/createMember", map[string]string{"poolId": poolId}, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateLoadbalancerBackend")
+	}
+	backend := &SElbBackend{}
+	err = resp.Unmarshal(backend, "returnObj")
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateLoadbalancerBackend.Unmarshal")
+	}
+	return backend, nil
+}
+
+func (self *SRegion) UpdateLoadbalancerBackend(poolId, memberId string, params jsonutils.JSONObject) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/updateMember", map[string]string{"poolId": poolId, "memberId": memberId}, params)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.UpdateLoadbalancerBackend")
+	}
+	return nil
+}
+
+func (self *SRegion) DeleteLoadbalancerBackend(poolId, memberId string) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/deleteMember", map[string]string{"poolId": poolId, "memberId": memberId}, nil)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.DeleteLoadbalancerBackend")
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctyun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SElbSessionPersistence struct {
+	// SOURCE_IP, HTTP_COOKIE, APP_COOKIE
+	Type               string `json:"type"`
+	CookieName         string `json:"cookie_name"`
+	PersistenceTimeout int    `json:"persistence_timeout"`
+}
+
+type SElbBackendGroup struct {
+	multicloud.SResourceBase
+	CtyunTags
+	region *SRegion
+	lb     *SLoadbalancer
+
+	healthCheck *SElbHealthCheck
+
+	ID                 string                 `json:"id"`
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description"`
+	Protocol           string                 `json:"protocol"`
+	LbAlgorithm        string                 `json:"lb_algorithm"`
+	AdminStateUp       bool                   `json:"admin_state_up"`
+	HealthmonitorID    string                 `json:"healthmonitor_id"`
+	SessionPersistence SElbSessionPersistence `json:"session_persistence"`
+	Members            []SElbResourceRef      `json:"members"`
+	Listeners          []SElbResourceRef      `json:"listeners"`
+	Loadbalancers      []SElbResourceRef      `json:"loadbalancers"`
+}
+
+func (self *SElbBackendGroup) GetId() string {
+	return self.ID
+}
+
+func (self *SElbBackendGroup) GetName() string {
+	return self.Name
+}
+
+func (self *SElbBackendGroup) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbBackendGroup) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbBackendGroup) Refresh() error {
+	group, err := self.region.GetLoadbalancerBackendGroup(self.ID)
+	if err != nil {
+		return errors.Wrap(err, "SElbBackendGroup.Refresh.GetLoadbalancerBackendGroup")
+	}
+	self.healthCheck = nil
+	self.Members = nil
+	self.Listeners = nil
+	self.Loadbalancers = nil
+	return jsonutils.Update(self, group)
+}
+
+func (self *SElbBackendGroup) IsDefault() bool {
+	return false
+}
+
+func (self *SElbBackendGroup) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_NORMAL
+}
+
+func (self *SElbBackendGroup) GetScheduler() string {
+	switch self.LbAlgorithm {
+	case "LEAST_CONNECTIONS":
+		return api.LB_SCHEDULER_WLC
+	case "SOURCE_IP":
+		return api.LB_SCHEDULER_SCH
+	default:
+		return api.LB_SCHEDULER_WRR
+	}
+}
+
+func (self *SElbBackendGroup) getHealthCheck() *SElbHealthCheck {
+	if self.healthCheck != nil || len(self.HealthmonitorID) == 0 {
+		return self.healthCheck
+	}
+	hc, err := self.region.GetLoadbalancerHealthCheck(self.HealthmonitorID)
+	if err != nil {
+		log.Errorf("SElbBackendGroup.getHealthCheck %s", err)
+		return nil
+	}
+	self.healthCheck = hc
+	return self.healthCheck
+}
+
+func (self *SElbBackendGroup) setHealthCheck(opts *cloudprovider.ListenerHealthCheckOptions) error {
+	if opts.HealthCheck != api.LB_BOOL_ON {
+		if len(self.HealthmonitorID) == 0 {
+			return nil
+		}
+		return self.region.DeleteLoadbalancerHealthCheck(self.HealthmonitorID)
+	}
+	params := jsonutils.NewDict()
+	params.Set("type", jsonutils.NewString(getElbHealthMonitorType(opts.HealthCheckType)))
+	params.Set("delay", jsonutils.NewInt(int64(opts.HealthCheckInterval)))
+	params.Set("timeout", jsonutils.NewInt(int64(opts.HealthCheckTimeout)))
+	params.Set("maxRetries", jsonutils.NewInt(int64(opts.HealthCheckRise)))
+	if opts.HealthCheckType == api.LB_HEALTH_CHECK_HTTP || opts.HealthCheckType == api.LB_HEALTH_CHECK_HTTPS {
+		if len(opts.HealthCheckDomain) > 0 {
+			params.Set("domainName", jsonutils.NewString(opts.HealthCheckDomain))
+		}
+		if len(opts.HealthCheckURI) > 0 {
+			params.Set("urlPath", jsonutils.NewString(opts.HealthCheckURI))
+		}
+		if len(opts.HealthCheckHttpCode) > 0 {
+			params.Set("expectedCodes", jsonutils.NewString(getElbExpectedCodes(opts.HealthCheckHttpCode)))
+		}
+	}
+	if len(self.HealthmonitorID) > 0 {
+		return self.region.UpdateLoadbalancerHealthCheck(self.HealthmonitorID, params)
+	}
+	params.Set("poolId", jsonutils.NewString(self.ID))
+	_, err := self.region.CreateLoadbalancerHealthCheck(params)
+	return err
+}
+
+func (self *SElbBackendGroup) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	backends, err := self.region.GetLoadbalancerBackends(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudLoadbalancerBackend{}
+	for i := range backends {
+		backends[i].group = self
+		ret = append(ret, &backends[i])
+	}
+	return ret, nil
+}
+
+func (self *SElbBackendGroup) GetILoadbalancerBackendById(backendId string) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	backends, err := self.region.GetLoadbalancerBackends(self.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range backends {
+		if backends[i].ID == backendId {
+			backends[i].group = self
+			return &backends[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, backendId)
+}
+
+func (self *SElbBackendGroup) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	nics, err := self.region.GetNics(serverId)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetNics")
+	}
+	if len(nics) == 0 || len(nics[0].FixedIPS) == 0 {
+		return nil, fmt.Errorf("AddBackendServer %s no network interface found", serverId)
+	}
+	fixedIp := nics[0].FixedIPS[0]
+	backend, err := self.region.CreateLoadbalancerBackend(self.ID, fixedIp.SubnetID, fixedIp.IPAddress, port, weight)
+	if err != nil {
+		return nil, err
+	}
+	backend.group = self
+	return backend, nil
+}
+
+func (self *SElbBackendGroup) RemoveBackendServer(serverId string, weight int, port int) error {
+	backends, err := self.region.GetLoadbalancerBackends(self.ID)
+	if err != nil {
+		return err
+	}
+	for i := range backends {
+		if backends[i].ProtocolPort == port && backends[i].GetBackendId() == serverId {
+			return self.region.DeleteLoadbalancerBackend(self.ID, backends[i].ID)
+		}
+	}
+	return nil
+}
+
+func (self *SElbBackendGroup) Delete(ctx context.Context) error {
+	if len(self.HealthmonitorID) > 0 {
+		err := self.region.DeleteLoadbalancerHealthCheck(self.HealthmonitorID)
+		if err != nil && errors.Cause(err) != cloudprovider.ErrNotFound {
+			return errors.Wrap(err, "DeleteLoadbalancerHealthCheck")
+		}
+	}
+	return self.region.DeleteLoadbalancerBackendGroup(self.ID)
+}
+
+func (self *SElbBackendGroup) Sync(ctx context.Context, group *cloudprovider.SLoadbalancerBackendGroup) error {
+	if group == nil || len(group.Scheduler) == 0 {
+		return nil
+	}
+	params := jsonutils.NewDict()
+	params.Set("name", jsonutils.NewString(group.Name))
+	params.Set("lbAlgorithm", jsonutils.NewString(getElbAlgorithm(group.Scheduler)))
+	return self.region.UpdateLoadbalancerBackendGroup(self.ID, params)
+}
+
+type SElbHealthCheck struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Delay         int    `json:"delay"`
+	Timeout       int    `json:"timeout"`
+	MaxRetries    int    `json:"max_retries"`
+	DomainName    string `json:"domain_name"`
+	UrlPath       string `json:"url_path"`
+	ExpectedCodes string `json:"expected_codes"`
+	AdminStateUp  bool   `json:"admin_state_up"`
+}
+
+func (self *SElbHealthCheck) GetHealthCheckType() string {
+	switch self.Type {
+	case "HTTP":
+		return api.LB_HEALTH_CHECK_HTTP
+	case "UDP_CONNECT":
+		return api.LB_HEALTH_CHECK_UDP
+	default:
+		return api.LB_HEALTH_CHECK_TCP
+	}
+}
+
+// http_2xx,http_3xx => 200-399
+func getElbExpectedCodes(httpCode string) string {
+	codes := []string{}
+	for _, code := range strings.Split(httpCode, ",") {
+		code = strings.TrimPrefix(strings.TrimSpace(code), "http_")
+		if len(code) == 3 && strings.HasSuffix(code, "xx") {
+			codes = append(codes, fmt.Sprintf("%c00-%c99", code[0], code[0]))
+		}
+	}
+	if len(codes) == 0 {
+		return "200"
+	}
+	return strings.Join(codes, ",")
+}
+
+func (self *SRegion) GetLoadbalancerBackendGroups(lbId string) ([]SElbBackendGroup, error) {
+	params := map[string]string{}
+	if len(lbId) > 0 {
+		params["loadBalancerId"] = lbId
+	}
+	groups := make([]SElbBackendGroup, 0)
+	err := self.elbList("/apiproxy/v3/elb/queryPools", params, &groups)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.GetLoadbalancerBackendGroups")
+	}
+	for i := range groups {
+		groups[i].region = self
+	}
+	return groups, nil
+}
+
+func (self *SRegion) GetLoadbalancerBackendGroup(poolId string) (*SElbBackendGroup, error) {
+	groups := make([]SElbBackendGroup, 0)
+	err := self.elbList("/apiproxy/v3/elb/queryPools", map[string]string{"poolId": poolId}, &groups)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.GetLoadbalancerBackendGroup")
+	}
+	for i := range groups {
+		if groups[i].ID == poolId {
+			groups[i].region = self
+			return &groups[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "SRegion.GetLoadbalancerBackendGroup %s", poolId)
+}
+
+func (self *SRegion) CreateLoadbalancerBackendGroup(lbId, listenerId string, opts *cloudprovider.SLoadbalancerBackendGroup) (*SElbBackendGroup, error) {
+	params := jsonutils.NewDict()
+	params.Set("name", jsonutils.NewString(opts.Name))
+	if len(listenerId) > 0 {
+		params.Set("listenerId", jsonutils.NewString(listenerId))
+	} else {
+		params.Set("loadBalancerId", jsonutils.NewString(lbId))
+	}
+	protocol := "TCP"
+	switch opts.Protocol {
+	case api.LB_LISTENER_TYPE_HTTP, api.LB_LISTENER_TYPE_HTTPS:
+		protocol = "HTTP"
+	case api.LB_LISTENER_TYPE_UDP:
+		protocol = "UDP"
+	}
+	params.Set("protocol", jsonutils.NewString(protocol))
+	params.Set("lbAlgorithm", jsonutils.NewString(getElbAlgorithm(opts.Scheduler)))
+	resp, err := self.elbPost("/apiproxy/v3/elb/createPool", nil, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateLoadbalancerBackendGroup")
+	}
+	poolId, err := resp.GetString("returnObj", "id")
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateLoadbalancerBackendGroup.GetId")
+	}
+	group, err := self.GetLoadbalancerBackendGroup(poolId)
+	if err != nil {
+		return nil, err
+	}
+	for _, backend := range opts.Backends {
+		_, err = group.AddBackendServer(backend.ExternalID, backend.Weight, backend.Port)
+		if err != nil {
+			return nil, errors.Wrapf(err, "AddBackendServer %s", backend.ExternalID)
+		}
+	}
+	return group, nil
+}
+
+func (self *SRegion) UpdateLoadbalancerBackendGroup(poolId string, params jsonutils.JSONObject) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/updatePool", map[string]string{"poolId": poolId}, params)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.UpdateLoadbalancerBackendGroup")
+	}
+	return nil
+}
+
+func (self *SRegion) DeleteLoadbalancerBackendGroup(poolId string) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/deletePool", map[string]string{"poolId": poolId}, nil)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.DeleteLoadbalancerBackendGroup")
+	}
+	return nil
+}
+
+func (self *SRegion) GetLoadbalancerHealthCheck(healthmonitorId string) (*SElbHealthCheck, error) {
+	hcs := make([]SElbHealthCheck, 0)
+	err := self.elbList("/apiproxy/v3/elb/queryHealthMonitors", map[string]string{"healthMonitorId": healthmonitorId}, &hcs)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.GetLoadbalancerHealthCheck")
+	}
+	for i := range hcs {
+		if hcs[i].ID == healthmonitorId {
+			return &hcs[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "SRegion.GetLoadbalancerHealthCheck %s", healthmonitorId)
+}
+
+func (self *SRegion) CreateLoadbalancerHealthCheck(params jsonutils.JSONObject) (string, error) {
+	resp, err := self.elbPost("/apiproxy/v3/elb/createHealthMonitor", nil, params)
+	if err != nil {
+		return "", errors.Wrap(err, "SRegion.CreateLoadbalancerHealthCheck")
+	}
+	return resp.GetString("returnObj", "id")
+}
+
+func (self *SRegion) UpdateLoadbalancerHealthCheck(healthmonitorId string, params jsonutils.JSONObject) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/updateHealthMonitor", map[string]string{"healthMonitorId": healthmonitorId}, params)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.UpdateLoadbalancerHealthCheck")
+	}
+	return nil
+}
+
+func (self *SRegion) DeleteLoadbalancerHealthCheck(healthmonitorId string) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/deleteHealthMonitor", map[string]string{"healthMonitorId": healthmonitorId}, nil)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.DeleteLoadbalancerHealthCheck")
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctyun
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SElbCert struct {
+	multicloud.SResourceBase
+	CtyunTags
+	region *SRegion
+
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	Domain      string    `json:"domain"`
+	Certificate string    `json:"certificate"`
+	PrivateKey  string    `json:"private_key"`
+	CreateTime  time.Time `json:"create_time"`
+	ExpireTime  time.Time `json:"expire_time"`
+}
+
+func (self *SElbCert) GetId() string {
+	return self.ID
+}
+
+func (self *SElbCert) GetName() string {
+	return self.Name
+}
+
+func (self *SElbCert) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbCert) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SElbCert) GetCreatedAt() time.Time {
+	return self.CreateTime
+}
+
+func (self *SElbCert) GetProjectId() string {
+	return ""
+}
+
+func (self *SElbCert) Refresh() error {
+	cert, err := self.region.GetLoadbalancerCertificate(self.ID)
+	if err != nil {
+		return errors.Wrap(err, "SElbCert.Refresh.GetLoadbalancerCertificate")
+	}
+	return jsonutils.Update(self, cert)
+}
+
+func (self *SElbCert) Sync(name, privateKey, publickKey string) error {
+	params := jsonutils.NewDict()
+	params.Set("name", jsonutils.NewString(name))
+	params.Set("privateKey", jsonutils.NewString(privateKey))
+	params.Set("certificate", jsonutils.NewString(publickKey))
+	_, err := self.region.elbPost("/apiproxy/v3/elb/updateCertificate", map[string]string{"certificateId": self.ID}, params)
+	if err != nil {
+		return errors.Wrap(err, "SElbCert.Sync")
+	}
+	return nil
+}
+
+func (self *SElbCert) Delete() error {
+	return self.region.DeleteLoadbalancerCertificate(self.ID)
+}
+
+func (self *SElbCert) GetCommonName() string {
+	return strings.Split(self.Domain, ",")[0]
+}
+
+func (self *SElbCert) GetSubjectAlternativeNames() string {
+	return self.Domain
+}
+
+func (self *SElbCert) GetFingerprint() string {
+	_fp := sha1.Sum([]byte(self.Certificate))
+	fp := fmt.Sprintf("sha1:% x", _fp)
+	return strings.Replace(fp, " ", ":", -1)
+}
+
+func (self *SElbCert) GetExpireTime() time.Time {
+	return self.ExpireTime
+}
+
+func (self *SElbCert) GetPublickKey() string {
+	return self.Certificate
+}
+
+func (self *SElbCert) GetPrivateKey() string {
+	return self.PrivateKey
+}
+
+func (self *SRegion) GetLoadbalancerCertificates() ([]SElbCert, error) {
+	certs := make([]SElbCert, 0)
+	err := self.elbList("/apiproxy/v3/elb/queryCertificates", map[string]string{}, &certs)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.GetLoadbalancerCertificates")
+	}
+	for i := range certs {
+		certs[i].region = self
+	}
+	return certs, nil
+}
+
+func (self *SRegion) GetLoadbalancerCertificate(certId string) (*SElbCert, error) {
+	certs, err := self.GetLoadbalancerCertificates()
+	if err != nil {
+		return nil, err
+	}
+	for i := range certs {
+		if certs[i].ID == certId {
+			return &certs[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "SRegion.GetLoadbalancerCertificate %s", certId)
+}
+
+func (self *SRegion) GetILoadBalancerCertificates() ([]cloudprovider.ICloudLoadbalancerCertificate, error) {
+	certs, err := self.GetLoadbalancerCertificates()
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudLoadbalancerCertificate{}
+	for i := range certs {
+		ret = append(ret, &certs[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetILoadBalancerCertificateById(certId string) (cloudprovider.ICloudLoadbalancerCertificate, error) {
+	return self.GetLoadbalancerCertificate(certId)
+}
+
+func (self *SRegion) CreateILoadBalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (cloudprovider.ICloudLoadbalancerCertificate, error) {
+	params := jsonutils.NewDict()
+	params.Set("name", jsonutils.NewString(cert.Name))
+	params.Set("type", jsonutils.NewString("server"))
+	params.Set("certificate", jsonutils.NewString(cert.Certificate))
+	params.Set("privateKey", jsonutils.NewString(cert.PrivateKey))
+	resp, err := self.elbPost("/apiproxy/v3/elb/createCertificate", nil, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateILoadBalancerCertificate")
+	}
+	certId, err := resp.GetString("returnObj", "id")
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateILoadBalancerCertificate.GetId")
+	}
+	return self.GetLoadbalancerCertificate(certId)
+}
+
+func (self *SRegion) DeleteLoadbalancerCertificate(certId string) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/deleteCertificate", map[string]string{"certificateId": certId}, nil)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.DeleteLoadbalancerCertificate")
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctyun
+
+import (
+	"context"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SElbListener struct {
+	multicloud.SResourceBase
+	multicloud.SLoadbalancerRedirectBase
+	CtyunTags
+	region *SRegion
+	lb     *SLoadbalancer
+
+	// 健康检查及会话保持配置在后端服务器组上
+	backendGroup *SElbBackendGroup
+
+	ID                     string            `json:"id"`
+	Name                   string            `json:"name"`
+	Description            string            `json:"description"`
+	Protocol               string            `json:"protocol"`
+	ProtocolPort           int               `json:"protocol_port"`
+	DefaultPoolID          string            `json:"default_pool_id"`
+	ConnectionLimit        int               `json:"connection_limit"`
+	AdminStateUp           bool              `json:"admin_state_up"`
+	DefaultTLSContainerRef string            `json:"default_tls_container_ref"`
+	Http2Enable            bool              `json:"http2_enable"`
+	Loadbalancers          []SElbResourceRef `json:"loadbalancers"`
+}
+
+func (self *SElbListener) GetId() string {
+	return self.ID
+}
+
+func (self *SElbListener) GetName() string {
+	return self.Name
+}
+
+func (self *SElbListener) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SElbListener) GetStatus() string {
+	if self.AdminStateUp {
+		return api.LB_STATUS_ENABLED
+	}
+	return api.LB_STATUS_DISABLED
+}
+
+func (self *SElbListener) Refresh() error {
+	listener, err := self.region.GetLoadbalancerListener(self.ID)
+	if err != nil {
+		return errors.Wrap(err, "SElbListener.Refresh.GetLoadbalancerListener")
+	}
+	self.backendGroup = nil
+	self.Loadbalancers = nil
+	return jsonutils.Update(self, listener)
+}
+
+func (self *SElbListener) getBackendGroup() *SElbBackendGroup {
+	if self.backendGroup != nil || len(self.DefaultPoolID) == 0 {
+		return self.backendGroup
+	}
+	group, err := self.region.GetLoadbalancerBackendGroup(self.DefaultPoolID)
+	if err != nil {
+		log.Errorf("SElbListener.getBackendGroup %s", err)
+		return nil
+	}
+	self.backendGroup = group
+	return self.backendGroup
+}
+
+func (self *SElbListener) getHealthCheck() *SElbHealthCheck {
+	group := self.getBackendGroup()
+	if group == nil {
+		return nil
+	}
+	return group.getHealthCheck()
+}
+
+func (self *SElbListener) GetListenerType() string {
+	switch strings.ToUpper(self.Protocol) {
+	case "HTTP":
+		return api.LB_LISTENER_TYPE_HTTP
+	case "TERMINATED_HTTPS", "HTTPS":
+		return api.LB_LISTENER_TYPE_HTTPS
+	case "UDP":
+		return api.LB_LISTENER_TYPE_UDP
+	default:
+		return api.LB_LISTENER_TYPE_TCP
+	}
+}
+
+func (self *SElbListener) GetListenerPort() int {
+	return self.ProtocolPort
+}
+
+func (self *SElbListener) GetScheduler() string {
+	group := self.getBackendGroup()
+	if group == nil {
+		return ""
+	}
+	return group.GetScheduler()
+}
+
+func (self *SElbListener) GetAclStatus() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetAclType() string {
+	return ""
+}
+
+func (self *SElbListener) GetAclId() string {
+	return ""
+}
+
+func (self *SElbListener) GetEgressMbps() int {
+	return 0
+}
+
+func (self *SElbListener) GetBackendGroupId() string {
+	return self.DefaultPoolID
+}
+
+func (self *SElbListener) GetBackendServerPort() int {
+	return 0
+}
+
+func (self *SElbListener) GetClientIdleTimeout() int {
+	return 0
+}
+
+func (self *SElbListener) GetBackendConnectTimeout() int {
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheck() string {
+	if self.getHealthCheck() != nil {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetHealthCheckType() string {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.GetHealthCheckType()
+	}
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckTimeout() int {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.Timeout
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckInterval() int {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.Delay
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckRise() int {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.MaxRetries
+	}
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckFail() int {
+	return 0
+}
+
+func (self *SElbListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckDomain() string {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.DomainName
+	}
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckURI() string {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.UrlPath
+	}
+	return ""
+}
+
+func (self *SElbListener) GetHealthCheckCode() string {
+	if hc := self.getHealthCheck(); hc != nil {
+		return hc.ExpectedCodes
+	}
+	return ""
+}
+
+func (self *SElbListener) GetStickySession() string {
+	group := self.getBackendGroup()
+	if group != nil && len(group.SessionPersistence.Type) > 0 {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SElbListener) GetStickySessionType() string {
+	group := self.getBackendGroup()
+	if group == nil {
+		return ""
+	}
+	switch group.SessionPersistence.Type {
+	case "HTTP_COOKIE":
+		return api.LB_STICKY_SESSION_TYPE_INSERT
+	case "APP_COOKIE":
+		return api.LB_STICKY_SESSION_TYPE_SERVER
+	}
+	return ""
+}
+
+func (self *SElbListener) GetStickySessionCookie() string {
+	group := self.getBackendGroup()
+	if group == nil {
+		return ""
+	}
+	return group.SessionPersistence.CookieName
+}
+
+func (self *SElbListener) GetStickySessionCookieTimeout() int {
+	group := self.getBackendGroup()
+	if group == nil {
+		return 0
+	}
+	return group.SessionPersistence.PersistenceTimeout * 60
+}
+
+func (self *SElbListener) XForwardedForEnabled() bool {
+	return false
+}
+
+func (self *SElbListener) GzipEnabled() bool {
+	return false
+}
+
+func (self *SElbListener) GetCertificateId() string {
+	return self.DefaultTLSContainerRef
+}
+
+func (self *SElbListener) GetTLSCipherPolicy() string {
+	return ""
+}
+
+func (self *SElbListener) HTTP2Enabled() bool {
+	return self.Http2Enable
+}
+
+func (self *SElbListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return []cloudprovider.ICloudLoadbalancerListenerRule{}, nil
+}
+
+func (self *SElbListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, ruleId)
+}
+
+func (self *SElbListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SElbListener) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElbListener) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElbListener) ChangeScheduler(ctx context.Context, opts *cloudprovider.ChangeListenerSchedulerOptions) error {
+	if len(self.DefaultPoolID) == 0 {
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "listener %s without backend group", self.ID)
+	}
+	params := jsonutils.NewDict()
+	params.Set("lbAlgorithm", jsonutils.NewString(getElbAlgorithm(opts.Scheduler)))
+	session := getElbSessionPersistence(&opts.ListenerStickySessionOptions)
+	if session != nil {
+		params.Set("sessionPersistence", session)
+	} else {
+		params.Set("sessionPersistence", jsonutils.JSONNull)
+	}
+	return self.region.UpdateLoadbalancerBackendGroup(self.DefaultPoolID, params)
+}
+
+func (self *SElbListener) SetHealthCheck(ctx context.Context, opts *cloudprovider.ListenerHealthCheckOptions) error {
+	group := self.getBackendGroup()
+	if group == nil {
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "listener %s without backend group", self.ID)
+	}
+	return group.setHealthCheck(opts)
+}
+
+func (self *SElbListener) ChangeCertificate(ctx context.Context, opts *cloudprovider.ListenerCertificateOptions) error {
+	params := jsonutils.NewDict()
+	params.Set("defaultTlsContainerRef", jsonutils.NewString(opts.CertificateId))
+	return self.region.UpdateLoadbalancerListener(self.ID, params)
+}
+
+func (self *SElbListener) SetAcl(ctx context.Context, opts *cloudprovider.ListenerAclOptions) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElbListener) Delete(ctx context.Context) error {
+	return self.region.DeleteLoadbalancerListener(self.ID)
+}
+
+func (self *SRegion) GetLoadbalancerListeners(lbId, listenerId string) ([]SElbListener, error) {
+	params := map[string]string{}
+	if len(lbId) > 0 {
+		params["loadBalancerId"] = lbId
+	}
+	if len(listenerId) > 0 {
+		params["listenerId"] = listenerId
+	}
+	listeners := make([]SElbListener, 0)
+	err := self.elbList("/apiproxy/v3/elb/queryListeners", params, &listeners)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.GetLoadbalancerListeners")
+	}
+	for i := range listeners {
+		listeners[i].region = self
+	}
+	return listeners, nil
+}
+
+func (self *SRegion) GetLoadbalancerListener(listenerId string) (*SElbListener, error) {
+	listeners, err := self.GetLoadbalancerListeners("", listenerId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range listeners {
+		if listeners[i].ID == listenerId {
+			return &listeners[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "SRegion.GetLoadbalancerListener %s", listenerId)
+}
+
+func (self *SRegion) CreateLoadbalancerListener(lbId string, opts *cloudprovider.SLoadbalancerListenerCreateOptions) (*SElbListener, error) {
+	params := jsonutils.NewDict()
+	params.Set("loadBalancerId", jsonutils.NewString(lbId))
+	params.Set("name", jsonutils.NewString(opts.Name))
+	params.Set("description", jsonutils.NewString(opts.Description))
+	params.Set("protocol", jsonutils.NewString(getElbProtocol(opts.ListenerType)))
+	params.Set("protocolPort", jsonutils.NewInt(int64(opts.ListenerPort)))
+	if len(opts.BackendGroupId) > 0 {
+		params.Set("defaultPoolId", jsonutils.NewString(opts.BackendGroupId))
+	}
+	if opts.ListenerType == api.LB_LISTENER_TYPE_HTTPS {
+		params.Set("defaultTlsContainerRef", jsonutils.NewString(opts.CertificateId))
+		params.Set("http2Enable", jsonutils.NewBool(opts.EnableHTTP2))
+	}
+	resp, err := self.elbPost("/apiproxy/v3/elb/createListener", nil, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateLoadbalancerListener")
+	}
+	listenerId, err := resp.GetString("returnObj", "id")
+	if err != nil {
+		return nil, errors.Wrap(err, "SRegion.CreateLoadbalancerListener.GetId")
+	}
+	listener, err := self.GetLoadbalancerListener(listenerId)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.BackendGroupId) > 0 && opts.HealthCheck == api.LB_BOOL_ON {
+		group := listener.getBackendGroup()
+		if group != nil {
+			err = group.setHealthCheck(&opts.ListenerHealthCheckOptions)
+			if err != nil {
+				return nil, errors.Wrap(err, "setHealthCheck")
+			}
+		}
+	}
+	return listener, nil
+}
+
+func (self *SRegion) UpdateLoadbalancerListener(listenerId string, params jsonutils.JSONObject) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/updateListener", map[string]string{"listenerId": listenerId}, params)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.UpdateLoadbalancerListener")
+	}
+	return nil
+}
+
+func (self *SRegion) DeleteLoadbalancerListener(listenerId string) error {
+	_, err := self.elbPost("/apiproxy/v3/elb/deleteListener", map[string]string{"listenerId": listenerId}, nil)
+	if err != nil {
+		return errors.Wrap(err, "SRegion.DeleteLoadbalancerListener")
+	}
+	return nil
+}
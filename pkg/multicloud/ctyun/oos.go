@@ -0,0 +1,212 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctyun
+
+import (
+	"net/http"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/httputils"
+	"yunion.io/x/s3cli"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud/objectstore"
+)
+
+const (
+	// 对象存储OOS 为全局服务, 兼容S3协议
+	CTYUN_OOS_ENDPOINT = "https://oos-cn.ctyunapi.cn"
+)
+
+type SOosClient struct {
+	*objectstore.SObjectStoreClient
+
+	client *SCtyunClient
+}
+
+type SBucket struct {
+	*objectstore.SBucket
+
+	region *SRegion
+}
+
+func (self *SBucket) GetIRegion() cloudprovider.ICloudRegion {
+	return self.region
+}
+
+func (self *SBucket) GetLocation() string {
+	if len(self.Location) > 0 {
+		return self.Location
+	}
+	return self.region.GetId()
+}
+
+func (self *SOosClient) NewBucket(bucket s3cli.BucketInfo) cloudprovider.ICloudBucket {
+	b := self.SObjectStoreClient.NewBucket(bucket).(*objectstore.SBucket)
+	return &SBucket{SBucket: b}
+}
+
+func (client *SCtyunClient) getOosClient() (*SOosClient, error) {
+	if client.oosClient != nil {
+		return client.oosClient, nil
+	}
+	cfg := objectstore.NewObjectStoreClientConfig(
+		CTYUN_OOS_ENDPOINT, client.accessKey, client.accessSecret,
+	).Debug(client.debug).CloudproviderConfig(client.cpcfg)
+	s3store, err := objectstore.NewObjectStoreClientAndFetch(cfg, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewObjectStoreClientAndFetch")
+	}
+
+	tr := httputils.GetTransport(true)
+	tr.Proxy = client.cpcfg.ProxyFunc
+	s3store.S3Client().SetCustomTransport(cloudprovider.GetCheckTransport(tr, func(req *http.Request) (func(resp *http.Response), error) {
+		if client.cpcfg.ReadOnly {
+			if req.Method == "GET" || req.Method == "HEAD" {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(cloudprovider.ErrAccountReadOnly, "%s %s", req.Method, req.URL.Path)
+		}
+		return nil, nil
+	}))
+
+	oos := &SOosClient{
+		SObjectStoreClient: s3store,
+		client:             client,
+	}
+	oos.SetVirtualObject(oos)
+	client.oosClient = oos
+	return oos, nil
+}
+
+func (client *SCtyunClient) getBucketRegion(location string) *SRegion {
+	var defaultRegion *SRegion
+	for i := range client.iregions {
+		region := client.iregions[i].(*SRegion)
+		if region.GetId() == location {
+			return region
+		}
+		if region.GetId() == CTYUN_DEFAULT_REGION || defaultRegion == nil {
+			defaultRegion = region
+		}
+	}
+	return defaultRegion
+}
+
+func (client *SCtyunClient) invalidateIBuckets() {
+	client.iBuckets = nil
+}
+
+func (client *SCtyunClient) getIBuckets() ([]cloudprovider.ICloudBucket, error) {
+	if client.iBuckets == nil {
+		err := client.fetchBuckets()
+		if err != nil {
+			return nil, errors.Wrap(err, "fetchBuckets")
+		}
+	}
+	return client.iBuckets, nil
+}
+
+// 存储桶的数据位置与资源池不一一对应, 无法匹配的存储桶归属默认区域
+func (client *SCtyunClient) fetchBuckets() error {
+	oos, err := client.getOosClient()
+	if err != nil {
+		return errors.Wrap(err, "getOosClient")
+	}
+	buckets, err := oos.GetIBuckets()
+	if err != nil {
+		return errors.Wrap(err, "GetIBuckets")
+	}
+	ret := []cloudprovider.ICloudBucket{}
+	for i := range buckets {
+		bucket := buckets[i].(*SBucket)
+		location, err := oos.GetIBucketLocation(bucket.Name)
+		if err != nil {
+			log.Errorf("GetIBucketLocation %s: %v", bucket.Name, err)
+		}
+		bucket.Location = location
+		bucket.region = client.getBucketRegion(location)
+		if bucket.region == nil {
+			log.Errorf("no region for bucket %s at %s", bucket.Name, location)
+			continue
+		}
+		ret = append(ret, bucket)
+	}
+	client.iBuckets = ret
+	return nil
+}
+
+func (self *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
+	buckets, err := self.client.getIBuckets()
+	if err != nil {
+		return nil, errors.Wrap(err, "getIBuckets")
+	}
+	ret := []cloudprovider.ICloudBucket{}
+	for i := range buckets {
+		if buckets[i].(*SBucket).region.GetId() == self.GetId() {
+			ret = append(ret, buckets[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SRegion) CreateIBucket(name string, storageClassStr string, aclStr string) error {
+	oos, err := self.client.getOosClient()
+	if err != nil {
+		return errors.Wrap(err, "getOosClient")
+	}
+	err = oos.CreateIBucket(name, storageClassStr, aclStr)
+	if err != nil {
+		return errors.Wrap(err, "CreateIBucket")
+	}
+	self.client.invalidateIBuckets()
+	if len(aclStr) > 0 {
+		err = oos.SetIBucketAcl(name, cloudprovider.TBucketACLType(aclStr))
+		if err != nil {
+			return errors.Wrap(err, "SetIBucketAcl")
+		}
+	}
+	return nil
+}
+
+func (self *SRegion) DeleteIBucket(name string) error {
+	oos, err := self.client.getOosClient()
+	if err != nil {
+		return errors.Wrap(err, "getOosClient")
+	}
+	err = oos.DeleteIBucket(name)
+	if err != nil {
+		return errors.Wrap(err, "DeleteIBucket")
+	}
+	self.client.invalidateIBuckets()
+	return nil
+}
+
+func (self *SRegion) IBucketExist(name string) (bool, error) {
+	oos, err := self.client.getOosClient()
+	if err != nil {
+		return false, errors.Wrap(err, "getOosClient")
+	}
+	return oos.IBucketExist(name)
+}
+
+func (self *SRegion) GetIBucketById(name string) (cloudprovider.ICloudBucket, error) {
+	return cloudprovider.GetIBucketById(self, name)
+}
+
+func (self *SRegion) GetIBucketByName(name string) (cloudprovider.ICloudBucket, error) {
+	return cloudprovider.GetIBucketByName(self, name)
+}
@@ -17,9 +17,12 @@ package ctyun
 import (
 	"strings"
 
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
 )
 
 // GET http://ctyun-api-url/apiproxy/v3/ondemand/queryProjectIds
@@ -68,3 +71,91 @@ func (self *SCtyunClient) FetchProjects() ([]SProject, error) {
 func (self *SRegion) FetchProjects() ([]SProject, error) {
 	return self.client.FetchProjects()
 }
+
+// 企业项目
+// GET http://ctyun-api-url/apiproxy/v3/queryEnterpriseProjects
+type SEnterpriseProject struct {
+	multicloud.SProjectBase
+	CtyunTags
+
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      int    `json:"status"`
+}
+
+func (self *SEnterpriseProject) GetId() string {
+	return self.ID
+}
+
+func (self *SEnterpriseProject) GetGlobalId() string {
+	return self.ID
+}
+
+func (self *SEnterpriseProject) GetName() string {
+	return self.Name
+}
+
+func (self *SEnterpriseProject) GetStatus() string {
+	if self.Status == 1 {
+		return api.EXTERNAL_PROJECT_STATUS_AVAILABLE
+	}
+	return api.EXTERNAL_PROJECT_STATUS_UNAVAILABLE
+}
+
+func (self *SCtyunClient) GetEnterpriseProjects() ([]SEnterpriseProject, error) {
+	resp, err := self.DoGet("/apiproxy/v3/queryEnterpriseProjects", map[string]string{})
+	if err != nil {
+		return nil, errors.Wrap(err, "CtyunClient.GetEnterpriseProjects.DoGet")
+	}
+
+	projects := make([]SEnterpriseProject, 0)
+	err = resp.Unmarshal(&projects, "returnObj")
+	if err != nil {
+		return nil, errors.Wrap(err, "CtyunClient.GetEnterpriseProjects.Unmarshal")
+	}
+
+	return projects, nil
+}
+
+func (self *SCtyunClient) CreateEnterpriseProject(name, desc string) (*SEnterpriseProject, error) {
+	projectParams := jsonutils.NewDict()
+	projectParams.Set("name", jsonutils.NewString(name))
+	if len(desc) > 0 {
+		projectParams.Set("description", jsonutils.NewString(desc))
+	}
+
+	params := map[string]jsonutils.JSONObject{
+		"jsonStr": projectParams,
+	}
+
+	resp, err := self.DoPost("/apiproxy/v3/createEnterpriseProject", params)
+	if err != nil {
+		return nil, errors.Wrap(err, "CtyunClient.CreateEnterpriseProject.DoPost")
+	}
+
+	project := &SEnterpriseProject{}
+	err = resp.Unmarshal(project, "returnObj")
+	if err != nil {
+		return nil, errors.Wrap(err, "CtyunClient.CreateEnterpriseProject.Unmarshal")
+	}
+
+	return project, nil
+}
+
+func (self *SCtyunClient) GetIProjects() ([]cloudprovider.ICloudProject, error) {
+	projects, err := self.GetEnterpriseProjects()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetEnterpriseProjects")
+	}
+
+	ret := []cloudprovider.ICloudProject{}
+	for i := range projects {
+		ret = append(ret, &projects[i])
+	}
+	return ret, nil
+}
+
+func (self *SCtyunClient) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	return self.CreateEnterpriseProject(name, "")
+}
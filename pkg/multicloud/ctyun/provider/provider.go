@@ -174,6 +174,10 @@ func (self *SCtyunProvider) GetIProjects() ([]cloudprovider.ICloudProject, error
 	return self.client.GetIProjects()
 }
 
+func (self *SCtyunProvider) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	return self.client.CreateIProject(name)
+}
+
 func (self *SCtyunProvider) GetStorageClasses(regionId string) []string {
 	return []string{
 		"STANDARD", "WARM", "COLD",
@@ -29,7 +29,6 @@ import (
 type SRegion struct {
 	cloudprovider.SFakeOnPremiseRegion
 	multicloud.SRegion
-	multicloud.SNoObjectStorageRegion
 
 	client       *SCtyunClient
 	storageCache *SStoragecache
@@ -320,26 +319,6 @@ func (self *SRegion) GetISnapshotById(snapshotId string) (cloudprovider.ICloudSn
 	return nil, cloudprovider.ErrNotFound
 }
 
-func (self *SRegion) CreateSnapshotPolicy(*cloudprovider.SnapshotPolicyInput) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
-}
-
-func (self *SRegion) UpdateSnapshotPolicy(*cloudprovider.SnapshotPolicyInput, string) error {
-	return cloudprovider.ErrNotImplemented
-}
-
-func (self *SRegion) DeleteSnapshotPolicy(policyId string) error {
-	return cloudprovider.ErrNotImplemented
-}
-
-func (self *SRegion) ApplySnapshotPolicyToDisks(snapshotPolicyId string, diskId string) error {
-	return cloudprovider.ErrNotImplemented
-}
-
-func (self *SRegion) CancelSnapshotPolicyToDisks(snapshotPolicyId string, diskId string) error {
-	return cloudprovider.ErrNotImplemented
-}
-
 func (self *SRegion) GetISnapshotPolicies() ([]cloudprovider.ICloudSnapshotPolicy, error) {
 	polices, err := self.GetDiskBackupPolices()
 	if err != nil {
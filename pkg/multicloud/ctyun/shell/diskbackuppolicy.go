@@ -41,11 +41,16 @@ func init() {
 		Status        string `help:"status"`
 	}
 	shellutils.R(&PolicyCreateOptions{}, "policy-create", "Create policy", func(cli *ctyun.SRegion, args *PolicyCreateOptions) error {
-		e := cli.CreateDiskBackupPolicy(args.Name, args.StartTime, args.Frequency, args.RententionNum, args.FirstBackup, args.Status)
+		policyId, e := cli.CreateDiskBackupPolicy(args.Name, args.StartTime, args.Frequency, args.RententionNum, args.FirstBackup, args.Status)
 		if e != nil {
 			return e
 		}
 
+		policy, e := cli.GetDiskBackupPolicy(policyId)
+		if e != nil {
+			return e
+		}
+		printObject(policy)
 		return nil
 	})
 
@@ -75,4 +80,11 @@ func init() {
 
 		return nil
 	})
+
+	type DiskBackupPolicyIdOptions struct {
+		ID string `help:"policy id"`
+	}
+	shellutils.R(&DiskBackupPolicyIdOptions{}, "policy-delete", "Delete policy", func(cli *ctyun.SRegion, args *DiskBackupPolicyIdOptions) error {
+		return cli.DeleteDiskBackupPolicy(args.ID)
+	})
 }
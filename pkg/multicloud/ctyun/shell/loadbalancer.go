@@ -0,0 +1,90 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/multicloud/ctyun"
+)
+
+func init() {
+	type LoadbalancerListOptions struct {
+		ID string `help:"loadbalancer id"`
+	}
+	shellutils.R(&LoadbalancerListOptions{}, "lb-list", "List loadbalancers", func(cli *ctyun.SRegion, args *LoadbalancerListOptions) error {
+		lbs, e := cli.GetLoadbalancers(args.ID)
+		if e != nil {
+			return e
+		}
+		printList(lbs, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerIdOptions struct {
+		ID string `help:"loadbalancer id"`
+	}
+	shellutils.R(&LoadbalancerIdOptions{}, "lb-delete", "Delete loadbalancer", func(cli *ctyun.SRegion, args *LoadbalancerIdOptions) error {
+		return cli.DeleteLoadbalancer(args.ID)
+	})
+
+	shellutils.R(&LoadbalancerIdOptions{}, "lb-listener-list", "List loadbalancer listeners", func(cli *ctyun.SRegion, args *LoadbalancerIdOptions) error {
+		listeners, e := cli.GetLoadbalancerListeners(args.ID, "")
+		if e != nil {
+			return e
+		}
+		printList(listeners, 0, 0, 0, nil)
+		return nil
+	})
+
+	shellutils.R(&LoadbalancerIdOptions{}, "lb-backendgroup-list", "List loadbalancer backend groups", func(cli *ctyun.SRegion, args *LoadbalancerIdOptions) error {
+		groups, e := cli.GetLoadbalancerBackendGroups(args.ID)
+		if e != nil {
+			return e
+		}
+		printList(groups, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerBackendListOptions struct {
+		POOL string `help:"backend group id"`
+	}
+	shellutils.R(&LoadbalancerBackendListOptions{}, "lb-backend-list", "List loadbalancer backends", func(cli *ctyun.SRegion, args *LoadbalancerBackendListOptions) error {
+		backends, e := cli.GetLoadbalancerBackends(args.POOL)
+		if e != nil {
+			return e
+		}
+		printList(backends, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerCertListOptions struct {
+	}
+	shellutils.R(&LoadbalancerCertListOptions{}, "lb-cert-list", "List loadbalancer certificates", func(cli *ctyun.SRegion, args *LoadbalancerCertListOptions) error {
+		certs, e := cli.GetLoadbalancerCertificates()
+		if e != nil {
+			return e
+		}
+		printList(certs, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerCertIdOptions struct {
+		ID string `help:"certificate id"`
+	}
+	shellutils.R(&LoadbalancerCertIdOptions{}, "lb-cert-delete", "Delete loadbalancer certificate", func(cli *ctyun.SRegion, args *LoadbalancerCertIdOptions) error {
+		return cli.DeleteLoadbalancerCertificate(args.ID)
+	})
+}
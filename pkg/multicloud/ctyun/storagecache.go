@@ -72,8 +72,20 @@ func (self *SStoragecache) IsEmulated() bool {
 	return false
 }
 
+// 公共镜像及共享镜像, 私有镜像由 GetICustomizedCloudImages 返回
 func (self *SStoragecache) GetICloudImages() ([]cloudprovider.ICloudImage, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	ret := []cloudprovider.ICloudImage{}
+	for _, imageType := range []string{ImageOwnerPublic, ImageOwnerShared} {
+		images, err := self.region.GetImages(imageType)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetImages(%s)", imageType)
+		}
+		for i := range images {
+			images[i].storageCache = self
+			ret = append(ret, &images[i])
+		}
+	}
+	return ret, nil
 }
 
 func (self *SStoragecache) GetICustomizedCloudImages() ([]cloudprovider.ICloudImage, error) {
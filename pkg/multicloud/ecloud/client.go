@@ -144,9 +144,10 @@ func (ec *SEcloudClient) GetRegionById(id string) (*SRegion, error) {
 
 func (ec *SEcloudClient) GetCapabilities() []string {
 	caps := []string{
-		cloudprovider.CLOUD_CAPABILITY_COMPUTE + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_NETWORK + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_EIP + cloudprovider.READ_ONLY_SUFFIX,
+		cloudprovider.CLOUD_CAPABILITY_PROJECT,
+		cloudprovider.CLOUD_CAPABILITY_COMPUTE,
+		cloudprovider.CLOUD_CAPABILITY_NETWORK,
+		cloudprovider.CLOUD_CAPABILITY_EIP,
 	}
 	return caps
 }
@@ -227,6 +228,34 @@ func (ec *SEcloudClient) doList(ctx context.Context, r IRequest, result interfac
 	return datas.Unmarshal(result)
 }
 
+func (ec *SEcloudClient) doPost(ctx context.Context, r IRequest, result interface{}) error {
+	return ec.doAction(ctx, "POST", r, result)
+}
+
+func (ec *SEcloudClient) doPut(ctx context.Context, r IRequest, result interface{}) error {
+	return ec.doAction(ctx, "PUT", r, result)
+}
+
+func (ec *SEcloudClient) doDelete(ctx context.Context, r IRequest) error {
+	return ec.doAction(ctx, "DELETE", r, nil)
+}
+
+// doAction sends a mutating request, many of which answer with an empty body
+func (ec *SEcloudClient) doAction(ctx context.Context, method string, r IRequest, result interface{}) error {
+	r.SetMethod(method)
+	data, err := ec.request(ctx, r)
+	if err != nil {
+		if e, ok := err.(ErrMissKey); ok && e.Key == "body" {
+			return nil
+		}
+		return err
+	}
+	if result == nil || data == nil {
+		return nil
+	}
+	return data.Unmarshal(result)
+}
+
 func (ec *SEcloudClient) request(ctx context.Context, r IRequest) (jsonutils.JSONObject, error) {
 	jrbody, err := ec.doRequest(ctx, r)
 	if err != nil {
@@ -19,6 +19,9 @@ import (
 	"fmt"
 	"time"
 
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
 	billing_api "yunion.io/x/cloudmux/pkg/apis/billing"
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -48,6 +51,7 @@ type SDisk struct {
 	// 磁盘所在集群的ID
 	Metadata      string
 	Name          string
+	ProjectId     string
 	OperationFlag string
 	// 硬盘挂在主机ID列表
 	ServerId       []string
@@ -128,7 +132,17 @@ func (d *SDisk) GetStatus() string {
 }
 
 func (d *SDisk) Refresh() error {
-	return nil
+	if d.ManualAttr.IsVirtual {
+		return nil
+	}
+	disk, err := d.storage.zone.region.GetDisk(d.ID)
+	if err != nil {
+		return err
+	}
+	if disk.IsDelete {
+		return cloudprovider.ErrNotFound
+	}
+	return jsonutils.Update(d, disk)
 }
 
 func (d *SDisk) IsEmulated() bool {
@@ -142,7 +156,7 @@ func (s *SDisk) GetSysTags() map[string]string {
 }
 
 func (s *SDisk) GetProjectId() string {
-	return ""
+	return s.ProjectId
 }
 
 func (s *SDisk) GetIStorage() (cloudprovider.ICloudStorage, error) {
@@ -200,7 +214,14 @@ func (s *SDisk) GetAccessPath() string {
 }
 
 func (s *SDisk) Delete(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	if s.ManualAttr.IsVirtual {
+		return errors.Wrap(cloudprovider.ErrNotSupported, "system disk is deleted along with the instance")
+	}
+	err := s.storage.zone.region.DeleteDisk(s.ID)
+	if err != nil {
+		return errors.Wrap(err, "DeleteDisk")
+	}
+	return cloudprovider.WaitDeleted(s, 5*time.Second, 180*time.Second)
 }
 
 func (s *SDisk) CreateISnapshot(ctx context.Context, name string, desc string) (cloudprovider.ICloudSnapshot, error) {
@@ -274,3 +295,74 @@ func (s *SRegion) GetDisk(id string) (*SDisk, error) {
 	}
 	return &disk, nil
 }
+
+func (s *SRegion) CreateDisk(zoneRegion, storageType string, conf *cloudprovider.DiskCreateConfig) (string, error) {
+	params := map[string]interface{}{
+		"name":         conf.Name,
+		"size":         conf.SizeGb,
+		"resourceType": storageType,
+		"region":       zoneRegion,
+		"billingType":  "HOUR",
+		"quantity":     1,
+	}
+	if len(conf.Desc) > 0 {
+		params["description"] = conf.Desc
+	}
+	request := NewNovaRequest(NewApiRequest(s.ID, "/api/v2/volume/volume", nil, jsonutils.Marshal(params)))
+	ret := struct {
+		VolumeId  string
+		VolumeIds []string
+	}{}
+	err := s.client.doPost(context.Background(), request, &ret)
+	if err != nil {
+		return "", err
+	}
+	if len(ret.VolumeId) > 0 {
+		return ret.VolumeId, nil
+	}
+	if len(ret.VolumeIds) > 0 {
+		return ret.VolumeIds[0], nil
+	}
+	// the order api does not always return the volume id, look it up by name
+	id := ""
+	err = cloudprovider.Wait(5*time.Second, 180*time.Second, func() (bool, error) {
+		disks, err := s.GetDisks()
+		if err != nil {
+			return false, err
+		}
+		for i := range disks {
+			if disks[i].Name == conf.Name && disks[i].Region == zoneRegion && !disks[i].IsDelete {
+				id = disks[i].ID
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "wait disk %s created", conf.Name)
+	}
+	return id, nil
+}
+
+func (s *SRegion) DeleteDisk(id string) error {
+	request := NewNovaRequest(NewApiRequest(s.ID, fmt.Sprintf("/api/v2/volume/volume/%s", id), nil, nil))
+	return s.client.doDelete(context.Background(), request)
+}
+
+func (s *SRegion) AttachDisk(instanceId, diskId string) error {
+	params := jsonutils.Marshal(map[string]string{
+		"serverId": instanceId,
+		"volumeId": diskId,
+	})
+	request := NewNovaRequest(NewApiRequest(s.ID, "/api/v2/volume/volume/attach", nil, params))
+	return s.client.doPost(context.Background(), request, nil)
+}
+
+func (s *SRegion) DetachDisk(instanceId, diskId string) error {
+	params := jsonutils.Marshal(map[string]string{
+		"serverId": instanceId,
+		"volumeId": diskId,
+	})
+	request := NewNovaRequest(NewApiRequest(s.ID, "/api/v2/volume/volume/detach", nil, params))
+	return s.client.doPost(context.Background(), request, nil)
+}
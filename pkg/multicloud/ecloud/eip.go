@@ -19,6 +19,9 @@ import (
 	"fmt"
 	"time"
 
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
 	billing_api "yunion.io/x/cloudmux/pkg/apis/billing"
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -42,6 +45,7 @@ type SEip struct {
 	// 备案状态
 	IcpStatus     string
 	Id            string
+	ProjectId     string
 	IpType        string
 	Ipv6          string
 	Name          string //公网IPv4地址
@@ -76,7 +80,11 @@ func (e *SEip) GetStatus() string {
 }
 
 func (e *SEip) Refresh() error {
-	return cloudprovider.ErrNotImplemented
+	eip, err := e.region.GetEipById(e.Id)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(e, eip)
 }
 
 func (e *SEip) IsEmulated() bool {
@@ -121,7 +129,7 @@ func (e *SEip) GetExpiredAt() time.Time {
 }
 
 func (e *SEip) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return e.region.DeleteEip(e.Id)
 }
 
 func (e *SEip) GetBandwidth() int {
@@ -145,19 +153,49 @@ func (e *SEip) GetInternetChargeType() string {
 }
 
 func (e *SEip) Associate(conf *cloudprovider.AssociateConfig) error {
-	return cloudprovider.ErrNotImplemented
+	instance, err := e.region.GetInstanceById(conf.InstanceId)
+	if err != nil {
+		return errors.Wrapf(err, "GetInstanceById(%s)", conf.InstanceId)
+	}
+	if len(instance.PortDetail) == 0 {
+		return errors.Wrapf(cloudprovider.ErrNotFound, "no nic found for instance %s", conf.InstanceId)
+	}
+	err = e.region.AssociateEip(e.Id, instance.PortDetail[0].PortId)
+	if err != nil {
+		return errors.Wrap(err, "AssociateEip")
+	}
+	return cloudprovider.Wait(5*time.Second, 60*time.Second, func() (bool, error) {
+		err := e.Refresh()
+		if err != nil {
+			return false, err
+		}
+		return e.Bound, nil
+	})
 }
 
 func (e *SEip) Dissociate() error {
-	return cloudprovider.ErrNotImplemented
+	if !e.Bound {
+		return nil
+	}
+	err := e.region.DissociateEip(e.Id)
+	if err != nil {
+		return errors.Wrap(err, "DissociateEip")
+	}
+	return cloudprovider.Wait(5*time.Second, 60*time.Second, func() (bool, error) {
+		err := e.Refresh()
+		if err != nil {
+			return false, err
+		}
+		return !e.Bound, nil
+	})
 }
 
 func (e *SEip) ChangeBandwidth(bw int) error {
-	return cloudprovider.ErrNotImplemented
+	return e.region.ChangeEipBandwidth(e.Id, bw)
 }
 
 func (e *SEip) GetProjectId() string {
-	return ""
+	return e.ProjectId
 }
 
 func (r *SRegion) GetEipById(id string) (*SEip, error) {
@@ -167,5 +205,51 @@ func (r *SRegion) GetEipById(id string) (*SEip, error) {
 	if err != nil {
 		return nil, err
 	}
+	eip.region = r
 	return &eip, nil
 }
+
+func (r *SRegion) GetEips() ([]SEip, error) {
+	request := NewConsoleRequest(r.ID, "/api/v2/floatingIp/getRespWithBw", nil, nil)
+	eips := make([]SEip, 0)
+	err := r.client.doList(context.Background(), request, &eips)
+	if err != nil {
+		return nil, err
+	}
+	for i := range eips {
+		eips[i].region = r
+	}
+	return eips, nil
+}
+
+func (r *SRegion) DeleteEip(id string) error {
+	request := NewConsoleRequest(r.ID, fmt.Sprintf("/api/v2/floatingIp/%s", id), nil, nil)
+	return r.client.doDelete(context.Background(), request)
+}
+
+func (r *SRegion) AssociateEip(id, portId string) error {
+	params := jsonutils.Marshal(map[string]string{
+		"ipId":       id,
+		"resourceId": portId,
+		"type":       "vm",
+	})
+	request := NewConsoleRequest(r.ID, "/api/v2/floatingIp/bind", nil, params)
+	return r.client.doPost(context.Background(), request, nil)
+}
+
+func (r *SRegion) DissociateEip(id string) error {
+	params := jsonutils.Marshal(map[string]string{
+		"ipId": id,
+	})
+	request := NewConsoleRequest(r.ID, "/api/v2/floatingIp/unbind", nil, params)
+	return r.client.doPost(context.Background(), request, nil)
+}
+
+func (r *SRegion) ChangeEipBandwidth(id string, bw int) error {
+	params := jsonutils.Marshal(map[string]interface{}{
+		"ipId":          id,
+		"bandwidthSize": bw,
+	})
+	request := NewConsoleRequest(r.ID, "/api/v2/floatingIp/bandwidth", nil, params)
+	return r.client.doPut(context.Background(), request, nil)
+}
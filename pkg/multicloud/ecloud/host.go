@@ -16,6 +16,7 @@ package ecloud
 
 import (
 	"fmt"
+	"time"
 
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
@@ -156,11 +157,31 @@ func (h *SHost) GetVersion() string {
 }
 
 func (h *SHost) CreateVM(desc *cloudprovider.SManagedVMCreateConfig) (cloudprovider.ICloudVM, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	id, err := h.zone.region.CreateInstance(h.zone.Region, desc)
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateInstance")
+	}
+	var vm *SInstance
+	err = cloudprovider.Wait(5*time.Second, 300*time.Second, func() (bool, error) {
+		vm, err = h.zone.region.GetInstanceById(id)
+		if err != nil {
+			if errors.Cause(err) == cloudprovider.ErrNotFound {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetInstanceById(%s)", id)
+	}
+	vm.host = h
+	return vm, nil
 }
 
+// 公有云虚拟宿主机, 无法获取物理网卡信息
 func (h *SHost) GetIHostNics() ([]cloudprovider.ICloudHostNetInterface, error) {
-	return nil, cloudprovider.ErrNotSupported
+	return nil, errors.Wrap(cloudprovider.ErrNotSupported, "physical nics of ecloud virtual host")
 }
 
 func (h *SRegion) GetVMs() ([]SInstance, error) {
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"time"
 
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/billing"
 	"yunion.io/x/pkg/util/sets"
@@ -61,6 +62,7 @@ type SInstance struct {
 	dataDisks []cloudprovider.ICloudDisk
 
 	Id               string
+	ProjectId        string
 	Name             string
 	Vcpu             int
 	Vmemory          int
@@ -127,7 +129,13 @@ func (i *SInstance) GetStatus() string {
 }
 
 func (i *SInstance) Refresh() error {
-	// TODO
+	instance, err := i.host.zone.region.GetInstanceById(i.Id)
+	if err != nil {
+		return err
+	}
+	host := i.host
+	*i = *instance
+	i.host = host
 	return nil
 }
 
@@ -220,7 +228,7 @@ func (i *SInstance) GetInstanceType() string {
 }
 
 func (in *SInstance) GetProjectId() string {
-	return ""
+	return in.ProjectId
 }
 
 func (in *SInstance) GetIHost() cloudprovider.ICloudHost {
@@ -315,15 +323,27 @@ func (in *SInstance) GetHypervisor() string {
 }
 
 func (in *SInstance) StartVM(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	err := in.host.zone.region.StartInstance(in.Id)
+	if err != nil {
+		return errors.Wrap(err, "StartInstance")
+	}
+	return cloudprovider.WaitStatus(in, api.VM_RUNNING, 5*time.Second, 300*time.Second)
 }
 
 func (self *SInstance) StopVM(ctx context.Context, opts *cloudprovider.ServerStopOptions) error {
-	return cloudprovider.ErrNotImplemented
+	err := self.host.zone.region.StopInstance(self.Id)
+	if err != nil {
+		return errors.Wrap(err, "StopInstance")
+	}
+	return cloudprovider.WaitStatus(self, api.VM_READY, 5*time.Second, 300*time.Second)
 }
 
 func (self *SInstance) DeleteVM(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	err := self.host.zone.region.DeleteInstance(self.Id)
+	if err != nil {
+		return errors.Wrap(err, "DeleteInstance")
+	}
+	return cloudprovider.WaitDeleted(self, 10*time.Second, 300*time.Second)
 }
 
 func (self *SInstance) UpdateVM(ctx context.Context, name string) error {
@@ -335,15 +355,61 @@ func (self *SInstance) UpdateUserData(userData string) error {
 }
 
 func (self *SInstance) RebuildRoot(ctx context.Context, config *cloudprovider.SManagedVMRebuildRootConfig) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	region := self.host.zone.region
+	keypairName := ""
+	if len(config.PublicKey) > 0 {
+		var err error
+		keypairName, err = region.syncKeypair(config.PublicKey)
+		if err != nil {
+			return "", errors.Wrap(err, "syncKeypair")
+		}
+	}
+	err := region.RebuildInstance(self.Id, config.ImageId, config.Password, keypairName)
+	if err != nil {
+		return "", errors.Wrap(err, "RebuildInstance")
+	}
+	err = cloudprovider.WaitStatusWithDelay(self, api.VM_RUNNING, 10*time.Second, 5*time.Second, 600*time.Second)
+	if err != nil {
+		return "", errors.Wrap(err, "wait rebuild")
+	}
+	return self.SystemDiskId, nil
 }
 
 func (self *SInstance) DeployVM(ctx context.Context, name string, username string, password string, publicKey string, deleteKeypair bool, description string) error {
-	return cloudprovider.ErrNotImplemented
+	region := self.host.zone.region
+	if len(name) > 0 && name != self.Name {
+		err := region.RenameInstance(self.Id, name)
+		if err != nil {
+			return errors.Wrap(err, "RenameInstance")
+		}
+	}
+	if len(password) > 0 {
+		err := region.ResetInstancePassword(self.Id, password)
+		if err != nil {
+			return errors.Wrap(err, "ResetInstancePassword")
+		}
+	}
+	if len(publicKey) > 0 || deleteKeypair {
+		return errors.Wrap(cloudprovider.ErrNotSupported, "keypair can only be changed by rebuilding root")
+	}
+	return nil
 }
 
 func (in *SInstance) ChangeConfig(ctx context.Context, config *cloudprovider.SManagedVMChangeConfig) error {
-	return errors.ErrNotImplemented
+	if len(config.InstanceType) == 0 || config.InstanceType == in.FlavorRef {
+		return nil
+	}
+	err := in.host.zone.region.ResizeInstance(in.Id, config.InstanceType)
+	if err != nil {
+		return errors.Wrap(err, "ResizeInstance")
+	}
+	return cloudprovider.Wait(10*time.Second, 600*time.Second, func() (bool, error) {
+		err := in.Refresh()
+		if err != nil {
+			return false, err
+		}
+		return in.FlavorRef == config.InstanceType && in.GetStatus() != api.VM_STARTING, nil
+	})
 }
 
 func (in *SInstance) GetVNCInfo(input *cloudprovider.ServerVncInput) (*cloudprovider.ServerVncOutput, error) {
@@ -361,11 +427,29 @@ func (in *SInstance) GetVNCInfo(input *cloudprovider.ServerVncInput) (*cloudprov
 }
 
 func (in *SInstance) AttachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotImplemented
+	err := in.host.zone.region.AttachDisk(in.Id, diskId)
+	if err != nil {
+		return errors.Wrap(err, "AttachDisk")
+	}
+	return in.waitDiskStatus(diskId, "in-use")
 }
 
 func (in *SInstance) DetachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotImplemented
+	err := in.host.zone.region.DetachDisk(in.Id, diskId)
+	if err != nil {
+		return errors.Wrap(err, "DetachDisk")
+	}
+	return in.waitDiskStatus(diskId, "available")
+}
+
+func (in *SInstance) waitDiskStatus(diskId, status string) error {
+	return cloudprovider.Wait(5*time.Second, 180*time.Second, func() (bool, error) {
+		disk, err := in.host.zone.region.GetDisk(diskId)
+		if err != nil {
+			return false, err
+		}
+		return disk.Status == status, nil
+	})
 }
 
 func (self *SInstance) Renew(bc billing.SBillingCycle) error {
@@ -526,3 +610,156 @@ func (r *SRegion) GetInstanceVNCUrl(instanceId string) (string, error) {
 	}
 	return url, nil
 }
+
+func (r *SRegion) CreateInstance(zoneRegion string, desc *cloudprovider.SManagedVMCreateConfig) (string, error) {
+	params := map[string]interface{}{
+		"name":             desc.Name,
+		"region":           zoneRegion,
+		"specsName":        desc.InstanceType,
+		"imageId":          desc.ExternalImageId,
+		"billingType":      "HOUR",
+		"quantity":         1,
+		"securityGroupIds": desc.ExternalSecgroupIds,
+		"networks": map[string]string{
+			"networkId": desc.ExternalNetworkId,
+		},
+		"bootVolume": map[string]interface{}{
+			"volumeType": desc.SysDisk.StorageType,
+			"size":       desc.SysDisk.SizeGB,
+		},
+	}
+	if len(desc.Hostname) > 0 {
+		params["hostname"] = desc.Hostname
+	}
+	if len(desc.Password) > 0 {
+		params["password"] = desc.Password
+	}
+	if len(desc.PublicKey) > 0 {
+		keypairName, err := r.syncKeypair(desc.PublicKey)
+		if err != nil {
+			return "", errors.Wrap(err, "syncKeypair")
+		}
+		params["keypairName"] = keypairName
+	}
+	if len(desc.UserData) > 0 {
+		userData, err := desc.GetUserData()
+		if err != nil {
+			return "", errors.Wrap(err, "GetUserData")
+		}
+		params["userData"] = userData
+	}
+	dataVolumes := []map[string]interface{}{}
+	for _, disk := range desc.DataDisks {
+		dataVolumes = append(dataVolumes, map[string]interface{}{
+			"resourceType": disk.StorageType,
+			"size":         disk.SizeGB,
+		})
+	}
+	if len(dataVolumes) > 0 {
+		params["dataVolume"] = dataVolumes
+	}
+	if desc.PublicIpBw > 0 {
+		chargeMode := "bandwidthCharge"
+		if desc.PublicIpChargeType == cloudprovider.ElasticipChargeTypeByTraffic {
+			chargeMode = "trafficCharge"
+		}
+		params["ip"] = map[string]interface{}{
+			"bandwidthSize":  desc.PublicIpBw,
+			"chargeModeEnum": chargeMode,
+		}
+	}
+	// instances existing before the order, to tell the new one apart from others with the same name
+	instances, err := r.GetInstances(zoneRegion)
+	if err != nil {
+		return "", errors.Wrap(err, "GetInstances")
+	}
+	existing := map[string]bool{}
+	for i := range instances {
+		existing[instances[i].Id] = true
+	}
+	request := NewNovaRequest(NewApiRequest(r.ID, "/api/v2/server", nil, jsonutils.Marshal(params)))
+	ret := struct {
+		ServerId  string
+		ServerIds []string
+	}{}
+	err = r.client.doPost(context.Background(), request, &ret)
+	if err != nil {
+		return "", err
+	}
+	if len(ret.ServerId) > 0 {
+		return ret.ServerId, nil
+	}
+	if len(ret.ServerIds) > 0 {
+		return ret.ServerIds[0], nil
+	}
+	// the order api does not always return the server id, find the instance which did not exist before
+	id := ""
+	err = cloudprovider.Wait(5*time.Second, 300*time.Second, func() (bool, error) {
+		instances, err := r.GetInstances(zoneRegion)
+		if err != nil {
+			return false, err
+		}
+		ids := []string{}
+		for i := range instances {
+			if !existing[instances[i].Id] && instances[i].Name == desc.Name {
+				ids = append(ids, instances[i].Id)
+			}
+		}
+		if len(ids) > 1 {
+			return false, errors.Wrapf(cloudprovider.ErrDuplicateId, "new instances %s with name %s", ids, desc.Name)
+		}
+		if len(ids) == 1 {
+			id = ids[0]
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "wait instance %s created", desc.Name)
+	}
+	return id, nil
+}
+
+func (r *SRegion) doServerAction(id, action string, params jsonutils.JSONObject) error {
+	request := NewNovaRequest(NewApiRequest(r.ID, fmt.Sprintf("/api/v2/server/%s/%s", id, action), nil, params))
+	return r.client.doPut(context.Background(), request, nil)
+}
+
+func (r *SRegion) StartInstance(id string) error {
+	return r.doServerAction(id, "start", nil)
+}
+
+func (r *SRegion) StopInstance(id string) error {
+	return r.doServerAction(id, "stop", nil)
+}
+
+func (r *SRegion) RenameInstance(id, name string) error {
+	return r.doServerAction(id, "name", jsonutils.Marshal(map[string]string{"name": name}))
+}
+
+func (r *SRegion) ResetInstancePassword(id, password string) error {
+	return r.doServerAction(id, "password", jsonutils.Marshal(map[string]string{"password": password}))
+}
+
+func (r *SRegion) ResizeInstance(id, instanceType string) error {
+	return r.doServerAction(id, "resize", jsonutils.Marshal(map[string]string{"specsName": instanceType}))
+}
+
+func (r *SRegion) RebuildInstance(id, imageId, password, keypairName string) error {
+	params := map[string]string{}
+	if len(imageId) > 0 {
+		params["imageId"] = imageId
+	}
+	if len(password) > 0 {
+		params["password"] = password
+	}
+	if len(keypairName) > 0 {
+		params["keypairName"] = keypairName
+	}
+	return r.doServerAction(id, "rebuild", jsonutils.Marshal(params))
+}
+
+func (r *SRegion) DeleteInstance(id string) error {
+	request := NewNovaRequest(NewApiRequest(r.ID, fmt.Sprintf("/api/v2/server/%s", id), nil, nil))
+	return r.client.doDelete(context.Background(), request)
+}
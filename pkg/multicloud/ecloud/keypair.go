@@ -0,0 +1,86 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ecloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+)
+
+type SKeypair struct {
+	Id          string
+	Name        string
+	PublicKey   string
+	FingerPrint string
+}
+
+func (r *SRegion) GetKeypairs() ([]SKeypair, error) {
+	request := NewNovaRequest(NewApiRequest(r.ID, "/api/keypair", nil, nil))
+	keypairs := make([]SKeypair, 0)
+	err := r.client.doList(context.Background(), request, &keypairs)
+	if err != nil {
+		return nil, err
+	}
+	return keypairs, nil
+}
+
+func (r *SRegion) ImportKeypair(name, publicKey string) (*SKeypair, error) {
+	params := jsonutils.Marshal(map[string]string{
+		"name":      name,
+		"publicKey": publicKey,
+	})
+	request := NewNovaRequest(NewApiRequest(r.ID, "/api/keypair", nil, params))
+	keypair := &SKeypair{}
+	err := r.client.doPost(context.Background(), request, keypair)
+	if err != nil {
+		return nil, err
+	}
+	if len(keypair.Name) == 0 {
+		keypair.Name = name
+	}
+	return keypair, nil
+}
+
+// trimPublicKey drops the comment part of an authorized key
+func trimPublicKey(publicKey string) string {
+	fields := strings.Fields(publicKey)
+	if len(fields) >= 2 {
+		return fields[0] + " " + fields[1]
+	}
+	return strings.TrimSpace(publicKey)
+}
+
+func (r *SRegion) syncKeypair(publicKey string) (string, error) {
+	keypairs, err := r.GetKeypairs()
+	if err != nil {
+		return "", errors.Wrapf(err, "GetKeypairs")
+	}
+	for i := range keypairs {
+		if trimPublicKey(keypairs[i].PublicKey) == trimPublicKey(publicKey) {
+			return keypairs[i].Name, nil
+		}
+	}
+	name := fmt.Sprintf("keypair%d", time.Now().Unix())
+	keypair, err := r.ImportKeypair(name, publicKey)
+	if err != nil {
+		return "", errors.Wrapf(err, "ImportKeypair")
+	}
+	return keypair.Name, nil
+}
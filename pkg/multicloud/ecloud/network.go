@@ -16,7 +16,9 @@ package ecloud
 
 import (
 	"context"
+	"fmt"
 
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/util/netutils"
 	"yunion.io/x/pkg/util/rbacscope"
 
@@ -32,12 +34,13 @@ type SNetwork struct {
 
 	wire *SWire
 
-	Id       string
-	Name     string
-	Shared   bool
-	Enabled  bool
-	EcStatus string
-	Subnets  []SSubnet
+	Id        string
+	ProjectId string
+	Name      string
+	Shared    bool
+	Enabled   bool
+	EcStatus  string
+	Subnets   []SSubnet
 }
 
 type SSubnet struct {
@@ -92,7 +95,7 @@ func (n *SNetwork) IsEmulated() bool {
 }
 
 func (n *SNetwork) GetProjectId() string {
-	return ""
+	return n.ProjectId
 }
 
 func (n *SNetwork) GetIWire() cloudprovider.ICloudWire {
@@ -140,7 +143,7 @@ func (self *SNetwork) GetPublicScope() rbacscope.TRbacScope {
 }
 
 func (self *SNetwork) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return self.wire.vpc.region.DeleteNetwork(self.Id)
 }
 
 func (self *SNetwork) GetAllocTimeoutSeconds() int {
@@ -188,3 +191,34 @@ func (r *SRegion) GetNetworkById(routerId, zoneRegion, netId string) (*SNetwork,
 	}
 	return &networks[0], nil
 }
+
+func (r *SRegion) CreateNetwork(routerId, zoneRegion string, opts *cloudprovider.SNetworkCreateOptions) (string, error) {
+	params := jsonutils.Marshal(map[string]interface{}{
+		"networkCreateReq": map[string]interface{}{
+			"availabilityZoneHints": zoneRegion,
+			"networkName":           opts.Name,
+			"networkTypeEnum":       "VM",
+			"region":                zoneRegion,
+			"routerId":              routerId,
+			"subnets": []map[string]string{
+				{
+					"cidr":       opts.Cidr,
+					"ipVersion":  "4",
+					"subnetName": opts.Name,
+				},
+			},
+		},
+	})
+	request := NewConsoleRequest(r.ID, "/api/v2/netcenter/network", nil, params)
+	var id string
+	err := r.client.doPost(context.Background(), request, &id)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *SRegion) DeleteNetwork(id string) error {
+	request := NewConsoleRequest(r.ID, fmt.Sprintf("/api/v2/netcenter/network/%s", id), nil, nil)
+	return r.client.doDelete(context.Background(), request)
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ecloud
+
+import (
+	"context"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SProject struct {
+	multicloud.SProjectBase
+	EcloudTags
+	SCreateTime
+
+	ProjectId   string
+	ProjectName string
+	Description string
+	// 1: 正常
+	Status int
+}
+
+func (p *SProject) GetId() string {
+	return p.ProjectId
+}
+
+func (p *SProject) GetGlobalId() string {
+	return p.ProjectId
+}
+
+func (p *SProject) GetName() string {
+	return p.ProjectName
+}
+
+func (p *SProject) GetStatus() string {
+	if p.Status == 1 {
+		return api.EXTERNAL_PROJECT_STATUS_AVAILABLE
+	}
+	return api.EXTERNAL_PROJECT_STATUS_UNAVAILABLE
+}
+
+func (r *SRegion) GetProjects() ([]SProject, error) {
+	request := NewConsoleRequest(r.ID, "/api/v2/project", nil, nil)
+	projects := make([]SProject, 0, 5)
+	err := r.client.doList(context.Background(), request, &projects)
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *SRegion) CreateProject(name, desc string) (*SProject, error) {
+	params := jsonutils.Marshal(map[string]string{
+		"projectName": name,
+		"description": desc,
+	})
+	request := NewConsoleRequest(r.ID, "/api/v2/project", nil, params)
+	project := &SProject{}
+	err := r.client.doPost(context.Background(), request, project)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// 项目为账号级别资源, 任选一个区域查询即可
+func (ec *SEcloudClient) getDefaultRegion() (*SRegion, error) {
+	iregions := ec.GetIRegions()
+	if len(iregions) == 0 {
+		return nil, errors.Wrap(cloudprovider.ErrNotFound, "no region")
+	}
+	return iregions[0].(*SRegion), nil
+}
+
+func (ec *SEcloudClient) GetIProjects() ([]cloudprovider.ICloudProject, error) {
+	region, err := ec.getDefaultRegion()
+	if err != nil {
+		return nil, err
+	}
+	projects, err := region.GetProjects()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetProjects")
+	}
+	ret := []cloudprovider.ICloudProject{}
+	for i := range projects {
+		ret = append(ret, &projects[i])
+	}
+	return ret, nil
+}
+
+func (ec *SEcloudClient) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	region, err := ec.getDefaultRegion()
+	if err != nil {
+		return nil, err
+	}
+	return region.CreateProject(name, "")
+}
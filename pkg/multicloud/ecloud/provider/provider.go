@@ -154,7 +154,11 @@ func (p *SEcloudProvider) GetBalance() (float64, string, error) {
 }
 
 func (p *SEcloudProvider) GetIProjects() ([]cloudprovider.ICloudProject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return p.client.GetIProjects()
+}
+
+func (p *SEcloudProvider) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	return p.client.CreateIProject(name)
 }
 
 func (p *SEcloudProvider) GetStorageClasses(regionId string) []string {
@@ -205,7 +205,15 @@ func (r *SRegion) GetIVpcs() ([]cloudprovider.ICloudVpc, error) {
 }
 
 func (r *SRegion) GetIEips() ([]cloudprovider.ICloudEIP, error) {
-	return nil, cloudprovider.ErrNotSupported
+	eips, err := r.GetEips()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]cloudprovider.ICloudEIP, len(eips))
+	for i := range eips {
+		ret[i] = &eips[i]
+	}
+	return ret, nil
 }
 
 func (r *SRegion) GetIVpcById(id string) (cloudprovider.ICloudVpc, error) {
@@ -231,7 +239,7 @@ func (r *SRegion) GetIZoneById(id string) (cloudprovider.ICloudZone, error) {
 }
 
 func (r *SRegion) GetIEipById(id string) (cloudprovider.ICloudEIP, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return r.GetEipById(id)
 }
 
 func (r *SRegion) GetIVMById(id string) (cloudprovider.ICloudVM, error) {
@@ -15,8 +15,11 @@
 package shell
 
 import (
+	"fmt"
+
 	"yunion.io/x/pkg/util/shellutils"
 
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud/ecloud"
 )
 
@@ -31,4 +34,39 @@ func init() {
 		printList(disks, 0, 0, 0, nil)
 		return nil
 	})
+
+	type DiskCreateOptions struct {
+		ZONE_REGION  string `help:"zone region, e.g. N0571-ZJ-HZYH01"`
+		STORAGE_TYPE string `choices:"capebs|ebs|ssd|ssdebs"`
+		NAME         string
+		SIZE_GB      int
+		Desc         string
+	}
+	shellutils.R(&DiskCreateOptions{}, "disk-create", "Create disk", func(cli *ecloud.SRegion, args *DiskCreateOptions) error {
+		id, e := cli.CreateDisk(args.ZONE_REGION, args.STORAGE_TYPE, &cloudprovider.DiskCreateConfig{
+			Name:   args.NAME,
+			SizeGb: args.SIZE_GB,
+			Desc:   args.Desc,
+		})
+		if e != nil {
+			return e
+		}
+		fmt.Println(id)
+		return nil
+	})
+
+	type DiskIdOptions struct {
+		ID string
+	}
+	shellutils.R(&DiskIdOptions{}, "disk-delete", "Delete disk", func(cli *ecloud.SRegion, args *DiskIdOptions) error {
+		return cli.DeleteDisk(args.ID)
+	})
+
+	type SnapshotDeleteOptions struct {
+		ID     string
+		System bool `help:"snapshot of system disk"`
+	}
+	shellutils.R(&SnapshotDeleteOptions{}, "snapshot-delete", "Delete snapshot", func(cli *ecloud.SRegion, args *SnapshotDeleteOptions) error {
+		return cli.DeleteSnapshot(args.ID, args.System)
+	})
 }
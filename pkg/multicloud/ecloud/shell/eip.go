@@ -0,0 +1,73 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud/ecloud"
+)
+
+func init() {
+	type EipListOptions struct {
+	}
+	shellutils.R(&EipListOptions{}, "eip-list", "List eips", func(cli *ecloud.SRegion, args *EipListOptions) error {
+		eips, e := cli.GetEips()
+		if e != nil {
+			return e
+		}
+		printList(eips, 0, 0, 0, nil)
+		return nil
+	})
+
+	type EipIdOptions struct {
+		ID string
+	}
+	shellutils.R(&EipIdOptions{}, "eip-show", "Show eip", func(cli *ecloud.SRegion, args *EipIdOptions) error {
+		eip, e := cli.GetEipById(args.ID)
+		if e != nil {
+			return e
+		}
+		printObject(eip)
+		return nil
+	})
+	shellutils.R(&EipIdOptions{}, "eip-delete", "Delete eip", func(cli *ecloud.SRegion, args *EipIdOptions) error {
+		return cli.DeleteEip(args.ID)
+	})
+	shellutils.R(&EipIdOptions{}, "eip-dissociate", "Dissociate eip", func(cli *ecloud.SRegion, args *EipIdOptions) error {
+		return cli.DissociateEip(args.ID)
+	})
+
+	type EipAssociateOptions struct {
+		ID       string
+		INSTANCE string
+	}
+	shellutils.R(&EipAssociateOptions{}, "eip-associate", "Associate eip with instance", func(cli *ecloud.SRegion, args *EipAssociateOptions) error {
+		eip, e := cli.GetEipById(args.ID)
+		if e != nil {
+			return e
+		}
+		return eip.Associate(&cloudprovider.AssociateConfig{InstanceId: args.INSTANCE})
+	})
+
+	type EipBandwidthOptions struct {
+		ID        string
+		BANDWIDTH int
+	}
+	shellutils.R(&EipBandwidthOptions{}, "eip-change-bandwidth", "Change eip bandwidth", func(cli *ecloud.SRegion, args *EipBandwidthOptions) error {
+		return cli.ChangeEipBandwidth(args.ID, args.BANDWIDTH)
+	})
+}
@@ -15,8 +15,11 @@
 package shell
 
 import (
+	"fmt"
+
 	"yunion.io/x/pkg/util/shellutils"
 
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud/ecloud"
 )
 
@@ -66,4 +69,89 @@ func init() {
 		printList(disks, 0, 0, 0, []string{})
 		return nil
 	})
+
+	type InstanceCreateOptions struct {
+		ZONE_REGION  string `help:"zone region, e.g. N0571-ZJ-HZYH01"`
+		NAME         string
+		IMAGE        string
+		INSTANCETYPE string
+		NETWORK      string
+		SysDiskType  string `default:"ssd"`
+		SysDiskSize  int    `default:"40"`
+		Password     string
+		PublicKey    string
+		Secgroup     []string
+	}
+	shellutils.R(&InstanceCreateOptions{}, "instance-create", "Create intance", func(cli *ecloud.SRegion, args *InstanceCreateOptions) error {
+		id, e := cli.CreateInstance(args.ZONE_REGION, &cloudprovider.SManagedVMCreateConfig{
+			Name:                args.NAME,
+			ExternalImageId:     args.IMAGE,
+			InstanceType:        args.INSTANCETYPE,
+			ExternalNetworkId:   args.NETWORK,
+			ExternalSecgroupIds: args.Secgroup,
+			Password:            args.Password,
+			PublicKey:           args.PublicKey,
+			SysDisk: cloudprovider.SDiskInfo{
+				StorageType: args.SysDiskType,
+				SizeGB:      args.SysDiskSize,
+			},
+		})
+		if e != nil {
+			return e
+		}
+		fmt.Println(id)
+		return nil
+	})
+
+	shellutils.R(&InstanceShowOptions{}, "instance-start", "Start intance", func(cli *ecloud.SRegion, args *InstanceShowOptions) error {
+		return cli.StartInstance(args.ID)
+	})
+
+	shellutils.R(&InstanceShowOptions{}, "instance-stop", "Stop intance", func(cli *ecloud.SRegion, args *InstanceShowOptions) error {
+		return cli.StopInstance(args.ID)
+	})
+
+	shellutils.R(&InstanceShowOptions{}, "instance-delete", "Delete intance", func(cli *ecloud.SRegion, args *InstanceShowOptions) error {
+		return cli.DeleteInstance(args.ID)
+	})
+
+	type InstanceChangeConfigOptions struct {
+		ID           string
+		INSTANCETYPE string
+	}
+	shellutils.R(&InstanceChangeConfigOptions{}, "instance-change-config", "Change intance config", func(cli *ecloud.SRegion, args *InstanceChangeConfigOptions) error {
+		return cli.ResizeInstance(args.ID, args.INSTANCETYPE)
+	})
+
+	type InstanceRebuildOptions struct {
+		ID       string
+		Image    string
+		Password string
+		Keypair  string
+	}
+	shellutils.R(&InstanceRebuildOptions{}, "instance-rebuild-root", "Rebuild intance root disk", func(cli *ecloud.SRegion, args *InstanceRebuildOptions) error {
+		return cli.RebuildInstance(args.ID, args.Image, args.Password, args.Keypair)
+	})
+
+	type InstanceDiskOptions struct {
+		ID   string
+		DISK string
+	}
+	shellutils.R(&InstanceDiskOptions{}, "instance-attach-disk", "Attach disk to intance", func(cli *ecloud.SRegion, args *InstanceDiskOptions) error {
+		return cli.AttachDisk(args.ID, args.DISK)
+	})
+	shellutils.R(&InstanceDiskOptions{}, "instance-detach-disk", "Detach disk from intance", func(cli *ecloud.SRegion, args *InstanceDiskOptions) error {
+		return cli.DetachDisk(args.ID, args.DISK)
+	})
+
+	type KeypairListOptions struct {
+	}
+	shellutils.R(&KeypairListOptions{}, "keypair-list", "List keypairs", func(cli *ecloud.SRegion, args *KeypairListOptions) error {
+		keypairs, e := cli.GetKeypairs()
+		if e != nil {
+			return e
+		}
+		printList(keypairs, 0, 0, 0, nil)
+		return nil
+	})
 }
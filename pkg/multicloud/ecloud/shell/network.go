@@ -37,4 +37,11 @@ func init() {
 		printList(networks, 0, 0, 0, nil)
 		return nil
 	})
+
+	type NetworkIdOptions struct {
+		ID string
+	}
+	shellutils.R(&NetworkIdOptions{}, "subnet-delete", "Delete subnet", func(cli *ecloud.SRegion, args *NetworkIdOptions) error {
+		return cli.DeleteNetwork(args.ID)
+	})
 }
@@ -16,11 +16,11 @@ package ecloud
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
-	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
 )
 
@@ -35,6 +35,7 @@ type SSnapshot struct {
 	Description string
 	EcType      string
 	Id          string
+	ProjectId   string
 	Name        string
 	Size        int
 	VolumeId    string
@@ -96,11 +97,11 @@ func (s *SSnapshot) GetGlobalId() string {
 }
 
 func (s *SSnapshot) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return s.region.DeleteSnapshot(s.Id, s.IsSystem)
 }
 
 func (s *SSnapshot) GetProjectId() string {
-	return ""
+	return s.ProjectId
 }
 
 func (s *SRegion) GetSnapshots(snapshotId string, parentId string, isSystem bool) ([]SSnapshot, error) {
@@ -126,5 +127,17 @@ func (s *SRegion) GetSnapshots(snapshotId string, parentId string, isSystem bool
 	if err != nil {
 		return nil, err
 	}
+	for i := range snapshots {
+		snapshots[i].region = s
+	}
 	return snapshots, nil
 }
+
+func (s *SRegion) DeleteSnapshot(id string, isSystem bool) error {
+	path := fmt.Sprintf("/api/v2/volume/volumebackup/%s", id)
+	if isSystem {
+		path = fmt.Sprintf("/api/v2/vmBackup/%s", id)
+	}
+	request := NewNovaRequest(NewApiRequest(s.ID, path, nil, nil))
+	return s.client.doDelete(context.Background(), request)
+}
@@ -16,8 +16,10 @@ package ecloud
 
 import (
 	"fmt"
+	"time"
 
 	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -127,7 +129,19 @@ func (s *SStorage) GetEnabled() bool {
 }
 
 func (s *SStorage) CreateIDisk(conf *cloudprovider.DiskCreateConfig) (cloudprovider.ICloudDisk, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if s.storageType == api.STORAGE_ECLOUD_SYSTEM {
+		return nil, errors.Wrap(cloudprovider.ErrNotSupported, "unable to create system disk alone")
+	}
+	id, err := s.zone.region.CreateDisk(s.zone.Region, s.storageType, conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateDisk")
+	}
+	disk, err := s.zone.region.GetDisk(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetDisk(%s)", id)
+	}
+	disk.storage = s
+	return disk, cloudprovider.WaitStatus(disk, api.DISK_READY, 5*time.Second, 180*time.Second)
 }
 
 func (s *SStorage) GetIDiskById(idStr string) (cloudprovider.ICloudDisk, error) {
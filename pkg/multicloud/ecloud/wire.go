@@ -16,6 +16,9 @@ package ecloud
 
 import (
 	"fmt"
+	"time"
+
+	"yunion.io/x/pkg/errors"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -87,33 +90,40 @@ func (w *SWire) GetINetworkById(netid string) (cloudprovider.ICloudNetwork, erro
 }
 
 func (w *SWire) CreateINetwork(opts *cloudprovider.SNetworkCreateOptions) (cloudprovider.ICloudNetwork, error) {
-	return nil, nil
-}
-
-func (w *SWire) CreateNetworks() (*SNetwork, error) {
-	// data := jsonutils.NewDict()
-	// req := jsonutils.NewDict()
-	// req.Set("availabilityZoneHints", jsonutils.NewString("RegionOne"))
-	// req.Set("networkName", jsonutils.NewString("zyone"))
-	// req.Set("networkTypeEnum", jsonutils.NewString("VM"))
-	// req.Set("region", jsonutils.NewString("RegionOne"))
-	// req.Set("routerId", jsonutils.NewString(w.vpc.RouterId))
-	// subnet := jsonutils.NewDict()
-	// subnet.Set("cidr", jsonutils.NewString("192.168.46.0/24"))
-	// subnet.Set("ipVersion", jsonutils.NewString("4"))
-	// subnet.Set("subnetName", jsonutils.NewString("zyone"))
-	// req.Set("subnets", jsonutils.NewArray(subnet))
-	// data.Set("networkCreateReq", req)
-	// fmt.Printf("data:\n%s", data.PrettyString())
-	// request := NewConsoleRequest(w.vpc.region.ID, "/api/v2/netcenter/network", nil, req)
-	// request.SetMethod("POST")
-	// resp, err := w.vpc.region.client.request(context.Background(), request)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// fmt.Printf("resp:%s", resp)
-	// return nil, nil
-	return nil, cloudprovider.ErrNotImplemented
+	region := w.vpc.region
+	networks, err := region.GetNetworks(w.vpc.RouterId, w.zone.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetNetworks")
+	}
+	existing := map[string]bool{}
+	for i := range networks {
+		existing[networks[i].Id] = true
+	}
+	id, err := region.CreateNetwork(w.vpc.RouterId, w.zone.Region, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateNetwork")
+	}
+	var network *SNetwork
+	err = cloudprovider.Wait(5*time.Second, 60*time.Second, func() (bool, error) {
+		networks, err := region.GetNetworks(w.vpc.RouterId, w.zone.Region)
+		if err != nil {
+			return false, err
+		}
+		for i := range networks {
+			// without the id in response, the network is the new one with the same name
+			if (len(id) > 0 && networks[i].Id == id) || (len(id) == 0 && !existing[networks[i].Id] && networks[i].Name == opts.Name) {
+				network = &networks[i]
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "wait network %s created", opts.Name)
+	}
+	network.wire = w
+	w.inetworks = nil
+	return network, nil
 }
 
 func (w *SWire) GetNetworkById(netId string) (*SNetwork, error) {
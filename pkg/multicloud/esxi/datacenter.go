@@ -551,6 +551,10 @@ func (dc *SDatacenter) scanDcNetworks() error {
 
 	netMOBs := dc.getDatacenter().Network
 	for i := range netMOBs {
+		// switches are not networks, skip them
+		if strings.HasSuffix(netMOBs[i].Type, "DistributedVirtualSwitch") {
+			continue
+		}
 		dvport := mo.DistributedVirtualPortgroup{}
 		err := dc.manager.reference2Object(netMOBs[i], DVPORTGROUP_PROPS, &dvport)
 		if err == nil {
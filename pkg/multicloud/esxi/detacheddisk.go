@@ -0,0 +1,317 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esxi
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+const (
+	DETACHED_DISK_DIR_NAME = "detached_disks"
+)
+
+// SDetachedDisk is a standalone vmdk on a datastore which is not attached to any vm
+type SDetachedDisk struct {
+	multicloud.SDisk
+	multicloud.STagBase
+
+	datastore *SDatastore
+	filename  string
+	sizeMb    int
+	createdAt time.Time
+}
+
+func (disk *SDetachedDisk) GetId() string {
+	return disk.GetName()
+}
+
+func (disk *SDetachedDisk) GetName() string {
+	name := path.Base(disk.filename)
+	return strings.TrimSuffix(name, ".vmdk")
+}
+
+func (disk *SDetachedDisk) GetGlobalId() string {
+	return fmt.Sprintf("%s-%s", disk.datastore.GetGlobalId(), disk.GetId())
+}
+
+func (disk *SDetachedDisk) GetStatus() string {
+	return api.DISK_READY
+}
+
+func (disk *SDetachedDisk) Refresh() error {
+	return nil
+}
+
+func (disk *SDetachedDisk) IsEmulated() bool {
+	return false
+}
+
+func (disk *SDetachedDisk) GetIStorage() (cloudprovider.ICloudStorage, error) {
+	return disk.datastore, nil
+}
+
+func (disk *SDetachedDisk) GetIStorageId() string {
+	return disk.datastore.GetGlobalId()
+}
+
+func (disk *SDetachedDisk) GetDiskFormat() string {
+	return "vmdk"
+}
+
+func (disk *SDetachedDisk) GetDiskSizeMB() int {
+	return disk.sizeMb
+}
+
+func (disk *SDetachedDisk) GetIsAutoDelete() bool {
+	return false
+}
+
+func (disk *SDetachedDisk) GetTemplateId() string {
+	return ""
+}
+
+func (disk *SDetachedDisk) GetDiskType() string {
+	return api.DISK_TYPE_DATA
+}
+
+func (disk *SDetachedDisk) GetFsFormat() string {
+	return ""
+}
+
+func (disk *SDetachedDisk) GetIsNonPersistent() bool {
+	return false
+}
+
+func (disk *SDetachedDisk) GetDriver() string {
+	return "scsi"
+}
+
+func (disk *SDetachedDisk) GetCacheMode() string {
+	return "none"
+}
+
+func (disk *SDetachedDisk) GetMountpoint() string {
+	return ""
+}
+
+func (disk *SDetachedDisk) GetAccessPath() string {
+	return disk.datastore.GetFullPath(disk.filename)
+}
+
+func (disk *SDetachedDisk) GetCreatedAt() time.Time {
+	return disk.createdAt
+}
+
+func (disk *SDetachedDisk) getPathString() string {
+	return disk.datastore.getPathString(disk.filename)
+}
+
+func (disk *SDetachedDisk) Delete(ctx context.Context) error {
+	dm := object.NewVirtualDiskManager(disk.datastore.manager.client.Client)
+	task, err := dm.DeleteVirtualDisk(ctx, disk.getPathString(), disk.datastore.datacenter.getObjectDatacenter())
+	if err != nil {
+		return errors.Wrapf(err, "DeleteVirtualDisk %s", disk.getPathString())
+	}
+	return task.Wait(ctx)
+}
+
+func (disk *SDetachedDisk) CreateISnapshot(ctx context.Context, name string, desc string) (cloudprovider.ICloudSnapshot, error) {
+	return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "snapshot of detached disk")
+}
+
+func (disk *SDetachedDisk) GetISnapshots() ([]cloudprovider.ICloudSnapshot, error) {
+	return []cloudprovider.ICloudSnapshot{}, nil
+}
+
+func (disk *SDetachedDisk) Resize(ctx context.Context, newSizeMb int64) error {
+	cli := disk.datastore.manager.client.Client
+	dc := disk.datastore.datacenter.getObjectDatacenter().Reference()
+	req := types.ExtendVirtualDisk_Task{
+		This:          *cli.ServiceContent.VirtualDiskManager,
+		Name:          disk.getPathString(),
+		Datacenter:    &dc,
+		NewCapacityKb: newSizeMb * 1024,
+	}
+	res, err := methods.ExtendVirtualDisk_Task(ctx, cli, &req)
+	if err != nil {
+		return errors.Wrapf(err, "ExtendVirtualDisk_Task %s", disk.getPathString())
+	}
+	err = object.NewTask(cli, res.Returnval).Wait(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "wait ExtendVirtualDisk task")
+	}
+	disk.sizeMb = int(newSizeMb)
+	return nil
+}
+
+func (disk *SDetachedDisk) Reset(ctx context.Context, snapshotId string) (string, error) {
+	return "", errors.Wrapf(cloudprovider.ErrNotSupported, "snapshot of detached disk")
+}
+
+func (disk *SDetachedDisk) Rebuild(ctx context.Context) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func isVmdkDescriptor(name string) bool {
+	if !strings.HasSuffix(name, ".vmdk") {
+		return false
+	}
+	for _, suffix := range []string{"-flat.vmdk", "-delta.vmdk", "-ctk.vmdk", "-sesparse.vmdk"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// getDetachedDisks lists vmdks under DETACHED_DISK_DIR_NAME that are not used by any vm
+func (self *SDatastore) getDetachedDisks(ctx context.Context) ([]SDetachedDisk, error) {
+	results, err := self.ListPath(ctx, DETACHED_DISK_DIR_NAME)
+	if err != nil {
+		if errors.Cause(err) == errors.ErrNotFound {
+			return []SDetachedDisk{}, nil
+		}
+		return nil, errors.Wrapf(err, "ListPath %s", DETACHED_DISK_DIR_NAME)
+	}
+	used := map[string]bool{}
+	vms, err := self.getVMs()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getVMs")
+	}
+	for i := range vms {
+		vm := vms[i].(*SVirtualMachine)
+		for j := range vm.vdisks {
+			used[vm.vdisks[j].getBackingInfo().GetFileName()] = true
+		}
+	}
+	ret := []SDetachedDisk{}
+	for _, result := range results {
+		// the size of a disk is the size of its extent file in the same directory
+		sizes := map[string]int64{}
+		for _, file := range result.File {
+			info := file.GetFileInfo()
+			if strings.HasSuffix(info.Path, "-flat.vmdk") {
+				sizes[strings.TrimSuffix(info.Path, "-flat.vmdk")] = info.FileSize
+			}
+		}
+		for _, file := range result.File {
+			info := file.GetFileInfo()
+			if !isVmdkDescriptor(info.Path) {
+				continue
+			}
+			filename := path.Join(DETACHED_DISK_DIR_NAME, info.Path)
+			if used[self.getPathString(filename)] {
+				continue
+			}
+			disk := SDetachedDisk{
+				datastore: self,
+				filename:  filename,
+				sizeMb:    int(sizes[strings.TrimSuffix(info.Path, ".vmdk")] / 1024 / 1024),
+			}
+			if info.Modification != nil {
+				disk.createdAt = *info.Modification
+			}
+			ret = append(ret, disk)
+		}
+	}
+	return ret, nil
+}
+
+func (self *SDatastore) getDetachedDisk(ctx context.Context, id string) (*SDetachedDisk, error) {
+	disks, err := self.getDetachedDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range disks {
+		if disks[i].GetGlobalId() == id {
+			return &disks[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "detached disk %s", id)
+}
+
+var detachedDiskNameReg = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// getDetachedDiskFilename returns a vmdk path for name which is not used by other detached disks
+func (self *SDatastore) getDetachedDiskFilename(ctx context.Context, name string) (string, error) {
+	name = strings.Trim(detachedDiskNameReg.ReplaceAllString(name, "-"), "-.")
+	if len(name) == 0 {
+		name = "disk"
+	}
+	exists := map[string]bool{}
+	results, err := self.ListPath(ctx, DETACHED_DISK_DIR_NAME)
+	if err != nil && errors.Cause(err) != errors.ErrNotFound {
+		return "", errors.Wrapf(err, "ListPath %s", DETACHED_DISK_DIR_NAME)
+	}
+	for _, result := range results {
+		for _, file := range result.File {
+			exists[file.GetFileInfo().Path] = true
+		}
+	}
+	filename := name + ".vmdk"
+	for i := 1; exists[filename]; i++ {
+		filename = fmt.Sprintf("%s-%d.vmdk", name, i)
+	}
+	return path.Join(DETACHED_DISK_DIR_NAME, filename), nil
+}
+
+func (self *SDatastore) CreateIDisk(conf *cloudprovider.DiskCreateConfig) (cloudprovider.ICloudDisk, error) {
+	ctx := context.Background()
+	err := self.MakeDir(DETACHED_DISK_DIR_NAME)
+	if err != nil && !isFileAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "MakeDir %s", DETACHED_DISK_DIR_NAME)
+	}
+	filename, err := self.getDetachedDiskFilename(ctx, conf.Name)
+	if err != nil {
+		return nil, err
+	}
+	spec := &types.FileBackedVirtualDiskSpec{
+		VirtualDiskSpec: types.VirtualDiskSpec{
+			DiskType:    string(types.VirtualDiskTypeThin),
+			AdapterType: string(types.VirtualDiskAdapterTypeLsiLogic),
+		},
+		CapacityKb: int64(conf.SizeGb) * 1024 * 1024,
+	}
+	dm := object.NewVirtualDiskManager(self.manager.client.Client)
+	task, err := dm.CreateVirtualDisk(ctx, self.getPathString(filename), self.datacenter.getObjectDatacenter(), spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateVirtualDisk")
+	}
+	err = task.Wait(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "wait CreateVirtualDisk task")
+	}
+	return &SDetachedDisk{
+		datastore: self,
+		filename:  filename,
+		sizeMb:    conf.SizeGb * 1024,
+		createdAt: time.Now(),
+	}, nil
+}
@@ -28,6 +28,7 @@ import (
 	"yunion.io/x/log"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/netutils"
+	"yunion.io/x/pkg/util/osprofile"
 	"yunion.io/x/pkg/util/regutils"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
@@ -287,7 +288,15 @@ func (self *SHost) GetIVMById(id string) (cloudprovider.ICloudVM, error) {
 }
 
 func (self *SHost) GetIWires() ([]cloudprovider.ICloudWire, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	wires, err := self.getWires()
+	if err != nil {
+		return nil, errors.Wrap(err, "getWires")
+	}
+	ret := make([]cloudprovider.ICloudWire, len(wires))
+	for i := range wires {
+		ret[i] = wires[i]
+	}
+	return ret, nil
 }
 
 func (self *SHost) GetIStorages() ([]cloudprovider.ICloudStorage, error) {
@@ -406,7 +415,7 @@ func (self *SHost) fetchNicInfo(debug bool) []SHostNicInfo {
 		info.Driver = nic.Driver
 		info.Mac = netutils.FormatMacAddr(nic.Mac)
 		info.Index = int8(i)
-		info.LinkUp = false
+		info.LinkUp = nic.LinkSpeed != nil
 		nicInfoList = append(nicInfoList, info)
 	}
 
@@ -691,7 +700,175 @@ func (self *SHost) GetVersion() string {
 }
 
 func (self *SHost) CreateVM(desc *cloudprovider.SManagedVMCreateConfig) (cloudprovider.ICloudVM, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	ctx := context.Background()
+	ds, err := self.getCreateVMDatastore(desc.SysDisk.StorageExternalId, int64(desc.SysDisk.SizeGB)*1024)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getCreateVMDatastore")
+	}
+	params, err := self.getCreateVMParam(desc, ds)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getCreateVMParam")
+	}
+	needDeploy, vm, err := self.CreateVM2(ctx, ds, *params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateVM2")
+	}
+	if needDeploy {
+		hostname := desc.Hostname
+		if len(hostname) == 0 {
+			hostname = desc.NameEn
+		}
+		opts := &sGuestCustomizeOptions{
+			OsType:    desc.OsType,
+			Hostname:  hostname,
+			Username:  desc.Account,
+			Password:  desc.Password,
+			PublicKey: desc.PublicKey,
+			UserData:  desc.UserData,
+		}
+		err = vm.doGuestCustomize(ctx, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "doGuestCustomize")
+		}
+	}
+	return vm, nil
+}
+
+// getCreateVMDatastore returns the datastore with given id, or the one with most free space
+func (self *SHost) getCreateVMDatastore(storageId string, sizeMb int64) (*SDatastore, error) {
+	if len(storageId) > 0 {
+		return self.FindDataStoreById(storageId)
+	}
+	dss, err := self.GetDataStores()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetDataStores")
+	}
+	var ret *SDatastore
+	for i := range dss {
+		ds := dss[i].(*SDatastore)
+		if !ds.GetEnabled() || ds.GetCapacityMB()-ds.GetCapacityUsedMB() < sizeMb {
+			continue
+		}
+		if ret == nil || ds.GetCapacityMB()-ds.GetCapacityUsedMB() > ret.GetCapacityMB()-ret.GetCapacityUsedMB() {
+			ret = ds
+		}
+	}
+	if ret == nil {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "no datastore with %dMB free space on host %s", sizeMb, self.GetName())
+	}
+	return ret, nil
+}
+
+func (self *SHost) getCreateVMParam(desc *cloudprovider.SManagedVMCreateConfig, ds *SDatastore) (*SCreateVMParam, error) {
+	params := &SCreateVMParam{
+		Name: desc.NameEn,
+		Cpu:  desc.Cpu,
+		Mem:  desc.MemoryMB,
+	}
+	if len(params.Name) == 0 {
+		params.Name = desc.Name
+	}
+	switch {
+	case strings.EqualFold(desc.OsType, osprofile.OS_TYPE_WINDOWS):
+		params.OsName = osprofile.OS_TYPE_WINDOWS
+	default:
+		params.OsName = osprofile.OS_TYPE_LINUX
+	}
+
+	sysDisk := SDiskInfo{
+		Size:      int64(desc.SysDisk.SizeGB) * 1024,
+		StorageId: ds.GetGlobalId(),
+	}
+	if len(desc.ExternalImageId) > 0 {
+		imagePath, imageInfo, err := self.getCreateVMImage(desc.ExternalImageId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getCreateVMImage %s", desc.ExternalImageId)
+		}
+		sysDisk.ImagePath, sysDisk.ImageInfo = imagePath, imageInfo
+	}
+	params.Disks = append(params.Disks, sysDisk)
+	for _, disk := range desc.DataDisks {
+		storageId := disk.StorageExternalId
+		if len(storageId) == 0 {
+			storageId = ds.GetGlobalId()
+		}
+		params.Disks = append(params.Disks, SDiskInfo{
+			Size:      int64(disk.SizeGB) * 1024,
+			StorageId: storageId,
+		})
+	}
+
+	if len(desc.ExternalNetworkId) > 0 {
+		bridge, vlanId, err := self.getNetworkBridge(desc.ExternalNetworkId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getNetworkBridge %s", desc.ExternalNetworkId)
+		}
+		nic := jsonutils.NewDict()
+		nic.Set("index", jsonutils.NewInt(0))
+		nic.Set("bridge", jsonutils.NewString(bridge))
+		nic.Set("vlan", jsonutils.NewInt(int64(vlanId)))
+		params.Nics = append(params.Nics, nic)
+	}
+	return params, nil
+}
+
+// getCreateVMImage resolves an image id to a template vm or a vmdk in the image cache of the host datastores
+func (self *SHost) getCreateVMImage(imageId string) (string, SEsxiImageInfo, error) {
+	info := SEsxiImageInfo{ImageExternalId: imageId}
+	_, err := self.manager.SearchTemplateVM(imageId)
+	if err == nil {
+		info.ImageType = string(cloudprovider.ImageTypeSystem)
+		return "", info, nil
+	}
+	if errors.Cause(err) != errors.ErrNotFound {
+		return "", info, errors.Wrapf(err, "SearchTemplateVM")
+	}
+	dss, err := self.GetDataStores()
+	if err != nil {
+		return "", info, errors.Wrapf(err, "GetDataStores")
+	}
+	for i := range dss {
+		ds := dss[i].(*SDatastore)
+		images, err := ds.getStorageCache().GetIImageInImagecache()
+		if err != nil {
+			return "", info, errors.Wrapf(err, "GetIImageInImagecache")
+		}
+		for j := range images {
+			if images[j].GetGlobalId() != imageId {
+				continue
+			}
+			image := images[j].(*SImage)
+			info.ImageType = string(cloudprovider.ImageTypeCustomized)
+			return ds.GetFullPath(image.filename), info, nil
+		}
+	}
+	return "", info, errors.Wrapf(cloudprovider.ErrNotFound, "image %s", imageId)
+}
+
+// getNetworkBridge returns the bridge and vlan id used by NewVNICDev for a network or portgroup
+func (self *SHost) getNetworkBridge(networkId string) (string, int32, error) {
+	nets, err := self.GetNetworks()
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "GetNetworks")
+	}
+	for i := range nets {
+		if nets[i].GetId() != networkId && nets[i].GetName() != networkId {
+			continue
+		}
+		switch net := nets[i].(type) {
+		case *SDistributedVirtualPortgroup:
+			// NewVNICDev looks up the portgroup by id when vlan is not set
+			return net.GetId(), 1, nil
+		case *SNetwork:
+			for _, pg := range self.getHostSystem().Config.Network.Portgroup {
+				if pg.Spec.Name == net.GetName() {
+					return fmt.Sprintf("%s/%s", self.GetId(), pg.Spec.VswitchName), pg.Spec.VlanId, nil
+				}
+			}
+			return "", 0, errors.Wrapf(cloudprovider.ErrNotFound, "portgroup %s on host %s", net.GetName(), self.GetName())
+		}
+	}
+	return "", 0, errors.Wrapf(cloudprovider.ErrNotFound, "network %s", networkId)
 }
 
 type SCreateVMParam struct {
@@ -806,6 +983,14 @@ func (self *SHost) addDisks(ctx context.Context, dc *SDatacenter, ds *SDatastore
 		return getVM()
 	}
 
+	if len(uuid) == 0 {
+		evm, err := getVM()
+		if err != nil {
+			return nil, err
+		}
+		uuid = evm.GetGlobalId()
+	}
+
 	var (
 		scsiIdx    = 0
 		ideIdx     = 0
@@ -1341,8 +1526,13 @@ func (host *SHost) GetIHostNics() ([]cloudprovider.ICloudHostNetInterface, error
 
 func (host *SHost) GetIHostNicsInternal(debug bool) ([]cloudprovider.ICloudHostNetInterface, error) {
 	nics := host.getNicInfo(debug)
+	bridges, err := host.getPnicBridges()
+	if err != nil {
+		log.Errorf("getPnicBridges of host %s: %v", host.GetName(), err)
+	}
 	inics := make([]cloudprovider.ICloudHostNetInterface, len(nics))
 	for i := 0; i < len(nics); i += 1 {
+		nics[i].Bridge = bridges[nics[i].Dev]
 		inics[i] = &nics[i]
 	}
 	return inics, nil
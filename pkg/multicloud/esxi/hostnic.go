@@ -30,6 +30,8 @@ type SHostNicInfo struct {
 	NicType string
 
 	DVPortGroup string
+	// id of the wire the physical nic uplinks to
+	Bridge string
 
 	IpAddrPrefixLen  int8
 	IpAddr6PrefixLen int8
@@ -87,5 +89,8 @@ func (nic *SHostNicInfo) GetDVPortGroup() string {
 }
 
 func (nic *SHostNicInfo) GetBridge() string {
-	return nic.DVPortGroup
+	if len(nic.DVPortGroup) > 0 {
+		return nic.DVPortGroup
+	}
+	return nic.Bridge
 }
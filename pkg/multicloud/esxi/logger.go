@@ -24,6 +24,8 @@ type logger struct {
 	name string
 	chid chan progress.Report
 	over chan struct{}
+
+	callback func(progress float32)
 }
 
 func (l *logger) Sink() chan<- progress.Report {
@@ -42,6 +44,13 @@ func newLeaseLogger(name string, cap int) *logger {
 	}
 }
 
+// newProgressLogger reports the percentage of the lease transfer to callback
+func newProgressLogger(name string, cap int, callback func(progress float32)) *logger {
+	l := newLeaseLogger(name, cap)
+	l.callback = callback
+	return l
+}
+
 func (l *logger) Log() {
 	go func() {
 		var pre float32 = 0
@@ -58,6 +67,9 @@ func (l *logger) Log() {
 					log.Errorf("%s report, error: %s", l.name, r.Error())
 					break Loop
 				}
+				if l.callback != nil {
+					l.callback(r.Percentage())
+				}
 				if r.Percentage() >= pre {
 					log.Debugf("%s report: speed: %s, percentage: %f%%", l.name, r.Detail(), pre)
 					pre += 10
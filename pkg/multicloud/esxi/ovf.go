@@ -0,0 +1,466 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esxi
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vmware/govmomi/nfc"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vmdk"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+)
+
+const (
+	IMAGE_UPLOAD_DIR_NAME = "image_upload"
+)
+
+const (
+	imageFileFormatOva        = "ova"
+	imageFileFormatStreamVmdk = "streamOptimized"
+	imageFileFormatSparseVmdk = "monolithicSparse"
+
+	// SPARSE_MAGICNUMBER of vmdk header
+	vmdkSparseMagic = 0x564d444b
+	// SPARSEFLAG_COMPRESSED, set by streamOptimized vmdk
+	vmdkSparseFlagCompressed = 1 << 16
+)
+
+// getImageFileFormat detects the format of an image by its first 512 bytes
+func getImageFileFormat(header []byte) string {
+	if len(header) >= 262 && string(header[257:262]) == "ustar" {
+		return imageFileFormatOva
+	}
+	if len(header) >= 12 && binary.LittleEndian.Uint32(header) == vmdkSparseMagic {
+		if binary.LittleEndian.Uint32(header[8:])&vmdkSparseFlagCompressed != 0 {
+			return imageFileFormatStreamVmdk
+		}
+		return imageFileFormatSparseVmdk
+	}
+	return ""
+}
+
+func writeLocalFile(filename string, reader io.Reader) (int64, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return 0, errors.Wrapf(err, "os.Create %s", filename)
+	}
+	defer f.Close()
+	n, err := io.Copy(f, reader)
+	if err != nil {
+		return n, errors.Wrapf(err, "write %s", filename)
+	}
+	return n, nil
+}
+
+func (self *SDatastore) makeDirIfNotExists(remotePath string) error {
+	err := self.MakeDir(remotePath)
+	if err != nil && !isFileAlreadyExists(err) {
+		return errors.Wrapf(err, "MakeDir %s", remotePath)
+	}
+	return nil
+}
+
+// getImportHost returns the host through which images are imported into the datastore
+func (self *SDatastoreImageCache) getImportHost() (*SHost, error) {
+	if self.host != nil {
+		return self.host, nil
+	}
+	ihosts, err := self.datastore.GetAttachedHosts()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetAttachedHosts")
+	}
+	if len(ihosts) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "no host attached to datastore %s", self.datastore.GetName())
+	}
+	return ihosts[0].(*SHost), nil
+}
+
+// importSparseVmdk uploads a hosted sparse vmdk and converts it into the image cache,
+// copying a disk without destSpec converts it into a preallocated ESXi disk
+func (self *SDatastoreImageCache) importSparseVmdk(ctx context.Context, imageId string, reader io.Reader, size int64) error {
+	ds := self.datastore
+	for _, dir := range []string{IMAGE_UPLOAD_DIR_NAME, IMAGE_CACHE_DIR_NAME} {
+		err := ds.makeDirIfNotExists(dir)
+		if err != nil {
+			return err
+		}
+	}
+	tmpPath := path.Join(IMAGE_UPLOAD_DIR_NAME, imageId+".vmdk")
+	err := ds.UploadWithSize(ctx, tmpPath, reader, size)
+	if err != nil {
+		return errors.Wrapf(err, "UploadWithSize %s", tmpPath)
+	}
+	defer ds.Delete(ctx, tmpPath)
+
+	dc := ds.datacenter.getObjectDatacenter()
+	dm := object.NewVirtualDiskManager(ds.manager.client.Client)
+	dst := path.Join(IMAGE_CACHE_DIR_NAME, imageId+".vmdk")
+	task, err := dm.CopyVirtualDisk(ctx, ds.getPathString(tmpPath), dc, ds.getPathString(dst), dc, nil, true)
+	if err != nil {
+		return errors.Wrapf(err, "CopyVirtualDisk")
+	}
+	return task.Wait(ctx)
+}
+
+// importStreamVmdk imports a streamOptimized vmdk into the image cache through an OVF import
+func (self *SDatastoreImageCache) importStreamVmdk(ctx context.Context, imageId string, tmpPath string, reader io.Reader, callback func(float32)) error {
+	dir, err := ioutil.TempDir(tmpPath, "esxi-vmdk")
+	if err != nil {
+		return errors.Wrapf(err, "TempDir")
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, imageId+".vmdk")
+	_, err = writeLocalFile(filename, reader)
+	if err != nil {
+		return err
+	}
+
+	ds := self.datastore
+	err = ds.makeDirIfNotExists(IMAGE_CACHE_DIR_NAME)
+	if err != nil {
+		return err
+	}
+	host, err := self.getImportHost()
+	if err != nil {
+		return err
+	}
+	pool, err := host.SyncResourcePool("")
+	if err != nil {
+		return errors.Wrapf(err, "SyncResourcePool")
+	}
+	dc := ds.datacenter.getObjectDatacenter()
+	folders, err := dc.Folders(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "Folders")
+	}
+
+	lr := newProgressLogger("import vmdk", 5, callback)
+	lr.Log()
+	defer lr.End()
+
+	err = vmdk.Import(ctx, ds.manager.client.Client, filename, ds.getDatastoreObj(), vmdk.ImportParams{
+		Logger:     lr,
+		Type:       types.VirtualDiskTypeThin,
+		Force:      true,
+		Datacenter: dc,
+		Pool:       pool,
+		Folder:     folders.VmFolder,
+		Host:       host.GetoHostSystem(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "vmdk.Import")
+	}
+
+	// vmdk.Import leaves the disk at <imageId>/<imageId>.vmdk
+	src := path.Join(imageId, imageId+".vmdk")
+	dst := path.Join(IMAGE_CACHE_DIR_NAME, imageId+".vmdk")
+	dm := object.NewVirtualDiskManager(ds.manager.client.Client)
+	task, err := dm.MoveVirtualDisk(ctx, ds.getPathString(src), dc, ds.getPathString(dst), dc, true)
+	if err != nil {
+		return errors.Wrapf(err, "MoveVirtualDisk")
+	}
+	err = task.Wait(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "wait MoveVirtualDisk task")
+	}
+	return ds.Delete2(ctx, imageId, false, true)
+}
+
+// importOva imports an ova as a template vm
+func (self *SDatastoreImageCache) importOva(ctx context.Context, name string, tmpPath string, reader io.Reader, callback func(float32)) (*SVirtualMachine, error) {
+	dir, err := ioutil.TempDir(tmpPath, "esxi-ova")
+	if err != nil {
+		return nil, errors.Wrapf(err, "TempDir")
+	}
+	defer os.RemoveAll(dir)
+
+	descriptor := ""
+	tr := tar.NewReader(reader)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "read ova")
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		filename := filepath.Base(hdr.Name)
+		_, err = writeLocalFile(filepath.Join(dir, filename), tr)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(strings.ToLower(filename), ".ovf") {
+			descriptor = filename
+		}
+	}
+	if len(descriptor) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "no ovf descriptor in ova")
+	}
+	vm, err := self.importOvf(ctx, name, dir, descriptor, callback)
+	if err != nil {
+		return nil, err
+	}
+	err = vm.getVmObj().MarkAsTemplate(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "MarkAsTemplate")
+	}
+	return vm, nil
+}
+
+// importOvf imports the ovf descriptor and the files it references in dir as a vm
+func (self *SDatastoreImageCache) importOvf(ctx context.Context, name string, dir string, descriptor string, callback func(float32)) (*SVirtualMachine, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, descriptor))
+	if err != nil {
+		return nil, errors.Wrapf(err, "read %s", descriptor)
+	}
+	ds := self.datastore
+	client := ds.manager.client.Client
+	host, err := self.getImportHost()
+	if err != nil {
+		return nil, err
+	}
+	pool, err := host.SyncResourcePool("")
+	if err != nil {
+		return nil, errors.Wrapf(err, "SyncResourcePool")
+	}
+	folders, err := ds.datacenter.getObjectDatacenter().Folders(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Folders")
+	}
+
+	spec, err := ovf.NewManager(client).CreateImportSpec(ctx, string(content), pool, ds.getDatastoreObj(), types.OvfCreateImportSpecParams{
+		DiskProvisioning: string(types.VirtualDiskTypeThin),
+		EntityName:       name,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateImportSpec")
+	}
+	if len(spec.Error) > 0 {
+		return nil, errors.Error(spec.Error[0].LocalizedMessage)
+	}
+	for _, w := range spec.Warning {
+		log.Warningf("import ovf %s: %s", name, w.LocalizedMessage)
+	}
+
+	lease, err := pool.ImportVApp(ctx, spec.ImportSpec, folders.VmFolder, host.GetoHostSystem())
+	if err != nil {
+		return nil, errors.Wrapf(err, "ImportVApp")
+	}
+	info, err := lease.Wait(ctx, spec.FileItem)
+	if err != nil {
+		return nil, errors.Wrapf(err, "lease.Wait")
+	}
+	u := lease.StartUpdater(ctx, info)
+	defer u.Done()
+
+	for i := range info.Items {
+		idx := i
+		err = uploadLeaseItem(ctx, lease, info.Items[i], dir, func(progress float32) {
+			if callback != nil {
+				callback((float32(idx)*100 + progress) / float32(len(info.Items)))
+			}
+		})
+		if err != nil {
+			lease.Abort(ctx, nil)
+			return nil, err
+		}
+	}
+	err = lease.Complete(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "lease.Complete")
+	}
+
+	var movm mo.VirtualMachine
+	err = ds.manager.reference2Object(info.Entity, VIRTUAL_MACHINE_PROPS, &movm)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetch imported vm")
+	}
+	vm := NewVirtualMachine(ds.manager, &movm, ds.datacenter)
+	if vm == nil {
+		return nil, errors.Error("import successfully but unable to NewVirtualMachine")
+	}
+	return vm, nil
+}
+
+func uploadLeaseItem(ctx context.Context, lease *nfc.Lease, item nfc.FileItem, dir string, callback func(float32)) error {
+	filename := filepath.Join(dir, filepath.Base(item.Path))
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrapf(err, "open %s", item.Path)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "stat %s", item.Path)
+	}
+
+	lr := newProgressLogger("upload "+item.Path, 5, callback)
+	lr.Log()
+	defer lr.End()
+
+	err = lease.Upload(ctx, item, f, soap.Upload{ContentLength: fi.Size(), Progress: lr})
+	if err != nil {
+		return errors.Wrapf(err, "upload %s", item.Path)
+	}
+	return nil
+}
+
+// exportVmdks downloads all disks of the vm into dir as streamOptimized vmdks
+func (self *SVirtualMachine) exportVmdks(ctx context.Context, dir string, callback func(float32)) ([]types.OvfFile, error) {
+	lease, err := self.getVmObj().Export(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Export")
+	}
+	info, err := lease.Wait(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "lease.Wait")
+	}
+	u := lease.StartUpdater(ctx, info)
+	defer u.Done()
+
+	items := make([]nfc.FileItem, 0, len(info.Items))
+	for i := range info.Items {
+		if strings.HasSuffix(info.Items[i].Path, ".vmdk") {
+			items = append(items, info.Items[i])
+		}
+	}
+	files := make([]types.OvfFile, 0, len(items))
+	for i := range items {
+		idx := i
+		filename := filepath.Join(dir, filepath.Base(items[i].Path))
+		lr := newProgressLogger("download "+items[i].Path, 5, func(progress float32) {
+			if callback != nil {
+				callback((float32(idx)*100 + progress) / float32(len(items)))
+			}
+		})
+		lr.Log()
+		err = lease.DownloadFile(ctx, filename, items[i], soap.Download{Progress: lr})
+		lr.End()
+		if err != nil {
+			lease.Abort(ctx, nil)
+			return nil, errors.Wrapf(err, "DownloadFile %s", items[i].Path)
+		}
+		fi, err := os.Stat(filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "stat %s", filename)
+		}
+		file := items[i].File()
+		file.Path = filepath.Base(file.Path)
+		file.Size = fi.Size()
+		files = append(files, file)
+	}
+	err = lease.Complete(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "lease.Complete")
+	}
+	return files, nil
+}
+
+// ExportImage exports the vm as an ova if filename ends with .ova,
+// otherwise only the system disk is exported as a streamOptimized vmdk
+func (self *SVirtualMachine) ExportImage(ctx context.Context, filename string, callback func(float32)) (string, error) {
+	dir, err := ioutil.TempDir(filepath.Dir(filename), "esxi-export")
+	if err != nil {
+		return "", errors.Wrapf(err, "TempDir")
+	}
+	defer os.RemoveAll(dir)
+
+	files, err := self.exportVmdks(ctx, dir, callback)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "no disk exported from %s", self.GetName())
+	}
+
+	if !strings.HasSuffix(strings.ToLower(filename), ".ova") {
+		err = os.Rename(filepath.Join(dir, files[0].Path), filename)
+		if err != nil {
+			return "", errors.Wrapf(err, "rename")
+		}
+		return "vmdk", nil
+	}
+
+	desc, err := ovf.NewManager(self.manager.client.Client).CreateDescriptor(ctx, self.getVmObj(), types.OvfCreateDescriptorParams{
+		Name:     self.GetName(),
+		OvfFiles: files,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "CreateDescriptor")
+	}
+	if len(desc.Error) > 0 {
+		return "", errors.Error(desc.Error[0].LocalizedMessage)
+	}
+	err = writeOva(filename, self.GetName()+".ovf", desc.OvfDescriptor, dir, files)
+	if err != nil {
+		return "", err
+	}
+	return imageFileFormatOva, nil
+}
+
+// writeOva packs the descriptor and disks into an ova, the descriptor must be the first entry
+func writeOva(filename string, descName, descriptor string, dir string, files []types.OvfFile) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrapf(err, "os.Create %s", filename)
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	err = tw.WriteHeader(&tar.Header{Name: descName, Mode: 0644, Size: int64(len(descriptor))})
+	if err != nil {
+		return errors.Wrapf(err, "write header of %s", descName)
+	}
+	_, err = tw.Write([]byte(descriptor))
+	if err != nil {
+		return errors.Wrapf(err, "write %s", descName)
+	}
+	for _, file := range files {
+		err = tw.WriteHeader(&tar.Header{Name: file.Path, Mode: 0644, Size: file.Size})
+		if err != nil {
+			return errors.Wrapf(err, "write header of %s", file.Path)
+		}
+		src, err := os.Open(filepath.Join(dir, file.Path))
+		if err != nil {
+			return errors.Wrapf(err, "open %s", file.Path)
+		}
+		_, err = io.Copy(tw, src)
+		src.Close()
+		if err != nil {
+			return errors.Wrapf(err, "write %s", file.Path)
+		}
+	}
+	return tw.Close()
+}
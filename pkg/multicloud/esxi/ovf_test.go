@@ -0,0 +1,54 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esxi
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestGetImageFileFormat(t *testing.T) {
+	vmdkHeader := func(flags uint32) []byte {
+		header := make([]byte, 512)
+		binary.LittleEndian.PutUint32(header, vmdkSparseMagic)
+		binary.LittleEndian.PutUint32(header[4:], 3)
+		binary.LittleEndian.PutUint32(header[8:], flags)
+		return header
+	}
+	ova := &bytes.Buffer{}
+	tw := tar.NewWriter(ova)
+	tw.WriteHeader(&tar.Header{Name: "test.ovf", Mode: 0644, Size: 4})
+	tw.Write([]byte("test"))
+	tw.Close()
+
+	cases := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"ova", ova.Bytes()[:512], imageFileFormatOva},
+		{"streamOptimized", vmdkHeader(vmdkSparseFlagCompressed | 3), imageFileFormatStreamVmdk},
+		{"monolithicSparse", vmdkHeader(3), imageFileFormatSparseVmdk},
+		{"qcow2", append([]byte("QFI\xfb"), make([]byte, 508)...), ""},
+		{"short", []byte("KDMV"), ""},
+	}
+	for _, c := range cases {
+		if got := getImageFileFormat(c.header); got != c.want {
+			t.Errorf("%s: got %q want %q", c.name, got, c.want)
+		}
+	}
+}
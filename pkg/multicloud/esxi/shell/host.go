@@ -91,6 +91,38 @@ func init() {
 		return nil
 	})
 
+	shellutils.R(&HostShowOptions{}, "host-wire-list", "List wires of a given host", func(cli *esxi.SESXiClient, args *HostShowOptions) error {
+		host, err := cli.FindHostByIp(args.IP)
+		if err != nil {
+			return err
+		}
+		wires, err := host.GetIWires()
+		if err != nil {
+			return err
+		}
+		printList(wires, nil)
+		return nil
+	})
+
+	shellutils.R(&HostShowOptions{}, "host-wire-network-list", "List networks inferred on the wires of a given host", func(cli *esxi.SESXiClient, args *HostShowOptions) error {
+		host, err := cli.FindHostByIp(args.IP)
+		if err != nil {
+			return err
+		}
+		wires, err := host.GetIWires()
+		if err != nil {
+			return err
+		}
+		for i := range wires {
+			networks, err := wires[i].GetINetworks()
+			if err != nil {
+				return err
+			}
+			printList(networks, nil)
+		}
+		return nil
+	})
+
 	shellutils.R(&HostShowOptions{}, "host-cluster", "Show host cluster", func(cli *esxi.SESXiClient,
 		args *HostShowOptions) error {
 		host, err := cli.FindHostByIp(args.IP)
@@ -0,0 +1,466 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esxi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+)
+
+// newSimulatorClient starts a vcsim vCenter model and connects an SESXiClient to it
+func newSimulatorClient(t *testing.T) (*SESXiClient, func()) {
+	model := simulator.VPX()
+	model.Datastore = 1
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("model.Create: %v", err)
+	}
+	model.Service.TLS = new(tls.Config)
+	server := model.Service.NewServer()
+	cleanup := func() {
+		server.Close()
+		model.Remove()
+	}
+	u, err := url.Parse(server.URL.String())
+	if err != nil {
+		cleanup()
+		t.Fatalf("url.Parse: %v", err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+	password, _ := u.User.Password()
+	cli, err := NewESXiClient(NewESXiClientConfig(u.Hostname(), port, u.User.Username(), password))
+	if err != nil {
+		cleanup()
+		t.Fatalf("NewESXiClient: %v", err)
+	}
+	return cli, cleanup
+}
+
+// getSimulatorHost returns the first host of the simulator inventory
+func getSimulatorHost(t *testing.T, cli *SESXiClient) *SHost {
+	hosts, err := cli.GetIHosts()
+	if err != nil {
+		t.Fatalf("GetIHosts: %v", err)
+	}
+	if len(hosts) == 0 {
+		t.Fatalf("empty hosts")
+	}
+	return hosts[0].(*SHost)
+}
+
+func getVmExtraConfig(t *testing.T, cli *SESXiClient, vm *SVirtualMachine) map[string]string {
+	var movm mo.VirtualMachine
+	err := cli.reference2Object(vm.getVirtualMachine().Self, []string{"config.extraConfig"}, &movm)
+	if err != nil {
+		t.Fatalf("fetch extraConfig: %v", err)
+	}
+	ret := map[string]string{}
+	for _, opt := range movm.Config.ExtraConfig {
+		val := opt.GetOptionValue()
+		ret[val.Key] = fmt.Sprintf("%v", val.Value)
+	}
+	return ret
+}
+
+func TestHostCreateVM(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	host := getSimulatorHost(t, cli)
+
+	desc := &cloudprovider.SManagedVMCreateConfig{
+		Name:              "test-create-vm",
+		Hostname:          "test-create-vm",
+		OsType:            "Linux",
+		Cpu:               2,
+		MemoryMB:          2048,
+		ExternalNetworkId: "DC0_DVPG0",
+		SysDisk:           cloudprovider.SDiskInfo{SizeGB: 10},
+		DataDisks:         []cloudprovider.SDiskInfo{{SizeGB: 20}},
+		Password:          "Test@123456",
+	}
+	ivm, err := host.CreateVM(desc)
+	if err != nil {
+		t.Fatalf("CreateVM: %v", err)
+	}
+	vm := ivm.(*SVirtualMachine)
+	if vm.GetName() != desc.Name {
+		t.Errorf("name: got %s want %s", vm.GetName(), desc.Name)
+	}
+	if vm.GetVcpuCount() != desc.Cpu || vm.GetVmemSizeMB() != desc.MemoryMB {
+		t.Errorf("cpu/mem: got %d/%d want %d/%d", vm.GetVcpuCount(), vm.GetVmemSizeMB(), desc.Cpu, desc.MemoryMB)
+	}
+	disks, err := vm.GetIDisks()
+	if err != nil {
+		t.Fatalf("GetIDisks: %v", err)
+	}
+	if len(disks) != 2 {
+		t.Fatalf("disks: got %d want 2", len(disks))
+	}
+	if disks[0].GetDiskSizeMB() != 10*1024 || disks[1].GetDiskSizeMB() != 20*1024 {
+		t.Errorf("disk size: got %d/%d", disks[0].GetDiskSizeMB(), disks[1].GetDiskSizeMB())
+	}
+	nics, err := vm.GetINics()
+	if err != nil {
+		t.Fatalf("GetINics: %v", err)
+	}
+	if len(nics) != 1 {
+		t.Fatalf("nics: got %d want 1", len(nics))
+	}
+	extra := getVmExtraConfig(t, cli, vm)
+	userData, err := base64.StdEncoding.DecodeString(extra["guestinfo.userdata"])
+	if err != nil {
+		t.Fatalf("decode guestinfo.userdata: %v", err)
+	}
+	if !strings.Contains(string(userData), "root") {
+		t.Errorf("user data without root password: %s", userData)
+	}
+	metadata, _ := base64.StdEncoding.DecodeString(extra["guestinfo.metadata"])
+	if !strings.Contains(string(metadata), desc.Hostname) {
+		t.Errorf("metadata without hostname: %s", metadata)
+	}
+}
+
+func TestHostCreateVMFromTemplate(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	host := getSimulatorHost(t, cli)
+	ctx := context.Background()
+
+	ivms, err := host.GetIVMs()
+	if err != nil || len(ivms) == 0 {
+		t.Fatalf("GetIVMs: %v", err)
+	}
+	tmpl := ivms[0].(*SVirtualMachine)
+	err = tmpl.StopVM(ctx, &cloudprovider.ServerStopOptions{})
+	if err != nil {
+		t.Fatalf("StopVM: %v", err)
+	}
+	err = tmpl.getVmObj().MarkAsTemplate(ctx)
+	if err != nil {
+		t.Fatalf("MarkAsTemplate: %v", err)
+	}
+
+	desc := &cloudprovider.SManagedVMCreateConfig{
+		Name:            "test-clone-vm",
+		ExternalImageId: tmpl.GetGlobalId(),
+		Cpu:             1,
+		MemoryMB:        1024,
+		SysDisk:         cloudprovider.SDiskInfo{SizeGB: 30},
+	}
+	ivm, err := host.CreateVM(desc)
+	if err != nil {
+		t.Fatalf("CreateVM: %v", err)
+	}
+	if ivm.GetGlobalId() == tmpl.GetGlobalId() {
+		t.Fatalf("clone got the template itself")
+	}
+	if ivm.GetName() != desc.Name {
+		t.Errorf("name: got %s want %s", ivm.GetName(), desc.Name)
+	}
+	disks, err := ivm.GetIDisks()
+	if err != nil || len(disks) == 0 {
+		t.Fatalf("GetIDisks: %v", err)
+	}
+	if disks[0].GetDiskSizeMB() != 30*1024 {
+		t.Errorf("system disk size: got %d want %d", disks[0].GetDiskSizeMB(), 30*1024)
+	}
+}
+
+func getSimulatorVM(t *testing.T, host *SHost) *SVirtualMachine {
+	ivms, err := host.GetIVMs()
+	if err != nil || len(ivms) == 0 {
+		t.Fatalf("GetIVMs: %v", err)
+	}
+	return ivms[0].(*SVirtualMachine)
+}
+
+func TestDiskSnapshot(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	vm := getSimulatorVM(t, getSimulatorHost(t, cli))
+	ctx := context.Background()
+
+	disks, err := vm.GetIDisks()
+	if err != nil || len(disks) == 0 {
+		t.Fatalf("GetIDisks: %v", err)
+	}
+	disk := disks[0].(*SVirtualDisk)
+	snapshot, err := disk.CreateISnapshot(ctx, "snap0", "")
+	if err != nil {
+		t.Fatalf("CreateISnapshot: %v", err)
+	}
+	if snapshot.GetName() != "snap0" || snapshot.GetDiskId() != disk.GetGlobalId() {
+		t.Errorf("snapshot: got name %s disk %s", snapshot.GetName(), snapshot.GetDiskId())
+	}
+	snapshots, err := disk.GetISnapshots()
+	if err != nil {
+		t.Fatalf("GetISnapshots: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].GetGlobalId() != snapshot.GetGlobalId() {
+		t.Fatalf("GetISnapshots: got %d snapshots", len(snapshots))
+	}
+	_, err = disk.GetISnapshot(snapshot.GetGlobalId())
+	if err != nil {
+		t.Fatalf("GetISnapshot: %v", err)
+	}
+	diskId, err := disk.Reset(ctx, snapshot.GetGlobalId())
+	if err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if diskId != disk.GetId() {
+		t.Errorf("Reset: got disk id %s want %s", diskId, disk.GetId())
+	}
+	err = snapshot.Delete()
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
+
+func TestDatastoreCreateAndAttachDisk(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	host := getSimulatorHost(t, cli)
+	vm := getSimulatorVM(t, host)
+	ctx := context.Background()
+
+	storages, err := host.GetIStorages()
+	if err != nil || len(storages) == 0 {
+		t.Fatalf("GetIStorages: %v", err)
+	}
+	ds := storages[0].(*SDatastore)
+	idisk, err := ds.CreateIDisk(&cloudprovider.DiskCreateConfig{Name: "data", SizeGb: 5})
+	if err != nil {
+		t.Fatalf("CreateIDisk: %v", err)
+	}
+	if idisk.GetDiskSizeMB() != 5*1024 {
+		t.Errorf("disk size: got %d want %d", idisk.GetDiskSizeMB(), 5*1024)
+	}
+	found, err := ds.GetIDiskById(idisk.GetGlobalId())
+	if err != nil {
+		t.Fatalf("GetIDiskById: %v", err)
+	}
+	if found.GetGlobalId() != idisk.GetGlobalId() {
+		t.Errorf("GetIDiskById: got %s want %s", found.GetGlobalId(), idisk.GetGlobalId())
+	}
+
+	disks, _ := vm.GetIDisks()
+	diskCnt := len(disks)
+	err = vm.AttachDisk(ctx, idisk.GetGlobalId())
+	if err != nil {
+		t.Fatalf("AttachDisk: %v", err)
+	}
+	disks, _ = vm.GetIDisks()
+	if len(disks) != diskCnt+1 {
+		t.Fatalf("disks after attach: got %d want %d", len(disks), diskCnt+1)
+	}
+	_, err = ds.getDetachedDisk(ctx, idisk.GetGlobalId())
+	if errors.Cause(err) != cloudprovider.ErrNotFound {
+		t.Errorf("attached disk is still listed as detached: %v", err)
+	}
+}
+
+func TestImageCacheCreateIImage(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	host := getSimulatorHost(t, cli)
+	vm := getSimulatorVM(t, host)
+
+	storages, err := host.GetIStorages()
+	if err != nil || len(storages) == 0 {
+		t.Fatalf("GetIStorages: %v", err)
+	}
+	cache := storages[0].(*SDatastore).getStorageCache()
+	image, err := cache.CreateIImage(vm.GetGlobalId(), "test-template", "Linux", "")
+	if err != nil {
+		t.Fatalf("CreateIImage: %v", err)
+	}
+	if image.GetName() != "test-template" {
+		t.Errorf("name: got %s want test-template", image.GetName())
+	}
+	tmpl, err := cache.GetIImageById(image.GetGlobalId())
+	if err != nil {
+		t.Fatalf("GetIImageById: %v", err)
+	}
+	if !tmpl.(*SVMTemplate).vm.IsTemplate() {
+		t.Errorf("image %s is not a template", tmpl.GetName())
+	}
+	if _, err := cache.datastore.datacenter.FetchVMById(vm.GetGlobalId()); err != nil {
+		t.Errorf("source vm is gone: %v", err)
+	}
+}
+
+func TestVMDeployAndUpdate(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	vm := getSimulatorVM(t, getSimulatorHost(t, cli))
+	ctx := context.Background()
+
+	err := vm.DeployVM(ctx, "test-deploy", "root", "Test@123456", "", false, "")
+	if err != nil {
+		t.Fatalf("DeployVM: %v", err)
+	}
+	err = vm.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if vm.GetName() != "test-deploy" {
+		t.Errorf("name: got %s want test-deploy", vm.GetName())
+	}
+	extra := getVmExtraConfig(t, cli, vm)
+	metadata, _ := base64.StdEncoding.DecodeString(extra["guestinfo.metadata"])
+	if !strings.Contains(string(metadata), "test-deploy") || !strings.Contains(string(metadata), vm.GetGlobalId()+"-") {
+		t.Errorf("metadata: %s", metadata)
+	}
+	if !strings.Contains(vm.getUserData(), "root") {
+		t.Errorf("user data without root password: %s", vm.getUserData())
+	}
+
+	err = vm.UpdateVM(ctx, "test-update")
+	if err != nil {
+		t.Fatalf("UpdateVM: %v", err)
+	}
+	vm.Refresh()
+	if vm.GetName() != "test-update" {
+		t.Errorf("name: got %s want test-update", vm.GetName())
+	}
+}
+
+func TestVMRebuildRoot(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	host := getSimulatorHost(t, cli)
+	ctx := context.Background()
+
+	ivms, err := host.GetIVMs()
+	if err != nil || len(ivms) < 2 {
+		t.Fatalf("GetIVMs: %v", err)
+	}
+	tmpl := ivms[0].(*SVirtualMachine)
+	vm := ivms[1].(*SVirtualMachine)
+	err = tmpl.StopVM(ctx, &cloudprovider.ServerStopOptions{})
+	if err != nil {
+		t.Fatalf("StopVM: %v", err)
+	}
+	err = tmpl.getVmObj().MarkAsTemplate(ctx)
+	if err != nil {
+		t.Fatalf("MarkAsTemplate: %v", err)
+	}
+	err = vm.StopVM(ctx, &cloudprovider.ServerStopOptions{})
+	if err != nil {
+		t.Fatalf("StopVM: %v", err)
+	}
+
+	sysSizeMb := vm.vdisks[0].GetDiskSizeMB()
+	diskId, err := vm.RebuildRoot(ctx, &cloudprovider.SManagedVMRebuildRootConfig{
+		ImageId:  tmpl.GetGlobalId(),
+		Password: "Test@123456",
+	})
+	if err != nil {
+		t.Fatalf("RebuildRoot: %v", err)
+	}
+	if len(vm.vdisks) == 0 || diskId != vm.vdisks[0].GetGlobalId() {
+		t.Fatalf("RebuildRoot: got disk %s", diskId)
+	}
+	if vm.vdisks[0].GetDiskSizeMB() != sysSizeMb {
+		t.Errorf("system disk size: got %d want %d", vm.vdisks[0].GetDiskSizeMB(), sysSizeMb)
+	}
+}
+
+func TestHostWires(t *testing.T) {
+	cli, cleanup := newSimulatorClient(t)
+	defer cleanup()
+	host := getSimulatorHost(t, cli)
+	ctx := context.Background()
+
+	vm := getSimulatorVM(t, host)
+	task, err := vm.getVmObj().Reconfigure(ctx, types.VirtualMachineConfigSpec{
+		ExtraConfig: []types.BaseOptionValue{
+			&types.OptionValue{Key: "SET.guest.ipAddress", Value: "10.10.0.10"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	err = task.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+
+	wires, err := host.GetIWires()
+	if err != nil {
+		t.Fatalf("GetIWires: %v", err)
+	}
+	nets := map[string]cloudprovider.ICloudNetwork{}
+	bridges := []string{}
+	for _, wire := range wires {
+		if !wire.(*SWire).IsDistributed() {
+			vs, err := findVirtualSwitch(host, wire.GetGlobalId())
+			if err != nil || vs == nil {
+				t.Errorf("findVirtualSwitch %s: %v", wire.GetGlobalId(), err)
+			}
+		}
+		bridges = append(bridges, wire.GetGlobalId())
+		inets, err := wire.GetINetworks()
+		if err != nil {
+			t.Fatalf("GetINetworks: %v", err)
+		}
+		for _, net := range inets {
+			nets[net.GetIpStart()] = net
+		}
+	}
+	net, ok := nets["10.10.0.1"]
+	if !ok {
+		t.Fatalf("network of 10.10.0.10 not found")
+	}
+	if net.GetIpEnd() != "10.10.0.254" || net.GetIpMask() != 24 {
+		t.Errorf("network range: got %s-%s/%d", net.GetIpStart(), net.GetIpEnd(), net.GetIpMask())
+	}
+	if vlan := net.(*SWireNetwork).GetVlanId(); vlan != net.(*SWireNetwork).portgroup.GetVlanId() {
+		t.Errorf("vlan: got %d", vlan)
+	}
+
+	nics, err := host.GetIHostNics()
+	if err != nil {
+		t.Fatalf("GetIHostNics: %v", err)
+	}
+	uplinks := 0
+	for _, nic := range nics {
+		if len(nic.GetBridge()) == 0 {
+			continue
+		}
+		if !utils.IsInStringArray(nic.GetBridge(), bridges) {
+			t.Errorf("nic %s: unknown bridge %s", nic.GetDevice(), nic.GetBridge())
+		}
+		uplinks++
+	}
+	if uplinks == 0 {
+		t.Errorf("no host nic uplinks to the wires")
+	}
+}
@@ -16,6 +16,7 @@ package esxi
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/methods"
@@ -85,3 +86,53 @@ func (s *SVirtualMachineSnapshot) Delete() error {
 	}
 	return object.NewTask(s.vm.manager.client.Client, res.Returnval).Wait(s.vm.manager.context)
 }
+
+// SVirtualDiskSnapshot is the view of a vm snapshot from one of the disks it covers
+type SVirtualDiskSnapshot struct {
+	multicloud.SResourceBase
+	multicloud.STagBase
+	snapshot SVirtualMachineSnapshot
+	disk     *SVirtualDisk
+}
+
+func (s *SVirtualDiskSnapshot) GetId() string {
+	return s.GetGlobalId()
+}
+
+func (s *SVirtualDiskSnapshot) GetName() string {
+	return s.snapshot.GetName()
+}
+
+// <vm snapshot>/<disk key>
+func (s *SVirtualDiskSnapshot) GetGlobalId() string {
+	return fmt.Sprintf("%s/%d", s.snapshot.GetGlobalId(), s.disk.getKey())
+}
+
+func (s *SVirtualDiskSnapshot) GetStatus() string {
+	return api.SNAPSHOT_READY
+}
+
+func (s *SVirtualDiskSnapshot) GetProjectId() string {
+	return s.snapshot.GetProjectId()
+}
+
+func (s *SVirtualDiskSnapshot) GetCreatedAt() time.Time {
+	return s.snapshot.snapshotTree.CreateTime
+}
+
+func (s *SVirtualDiskSnapshot) GetSizeMb() int32 {
+	return int32(s.disk.GetDiskSizeMB())
+}
+
+func (s *SVirtualDiskSnapshot) GetDiskId() string {
+	return s.disk.GetGlobalId()
+}
+
+func (s *SVirtualDiskSnapshot) GetDiskType() string {
+	return s.disk.GetDiskType()
+}
+
+// Delete removes the whole vm snapshot, which is shared by all disks of the vm
+func (s *SVirtualDiskSnapshot) Delete() error {
+	return s.snapshot.Delete()
+}
@@ -146,6 +146,12 @@ func (self *SDatastore) getVolumeId() (string, error) {
 		return fsInfo.Vmfs.Uuid, nil
 	case *types.NasDatastoreInfo:
 		return fmt.Sprintf("%s:%s", fsInfo.Nas.RemoteHost, fsInfo.Nas.RemotePath), nil
+	case *types.LocalDatastoreInfo:
+		host, err := self.getLocalHost()
+		if err == nil {
+			return fmt.Sprintf("%s:%s", host.GetAccessIp(), fsInfo.Path), nil
+		}
+		return fsInfo.Path, nil
 	}
 	if moStore.Summary.Type == "vsan" {
 		vsanId := moStore.Summary.Url
@@ -355,6 +361,10 @@ func (self *SDatastore) GetIDiskById(idStr string) (cloudprovider.ICloudDisk, er
 			return disk, nil
 		}
 	}
+	disk, err := self.getDetachedDisk(context.Background(), idStr)
+	if err == nil {
+		return disk, nil
+	}
 	return nil, cloudprovider.ErrNotFound
 }
 
@@ -371,6 +381,13 @@ func (self *SDatastore) fetchDisks() error {
 		}
 		allDisks = append(allDisks, disks...)
 	}
+	detachedDisks, err := self.getDetachedDisks(context.Background())
+	if err != nil {
+		log.Errorf("getDetachedDisks of datastore %s: %v", self.GetName(), err)
+	}
+	for i := range detachedDisks {
+		allDisks = append(allDisks, &detachedDisks[i])
+	}
 	self.idisks = allDisks
 	return nil
 }
@@ -520,10 +537,6 @@ func (self *SDatastore) GetFullPath(remotePath string) string {
 	return path.Join(self.GetUrl(), remotePath)
 }
 
-func (self *SDatastore) CreateIDisk(conf *cloudprovider.DiskCreateConfig) (cloudprovider.ICloudDisk, error) {
-	return nil, cloudprovider.ErrNotImplemented
-}
-
 func (self *SDatastore) FileGetContent(ctx context.Context, remotePath string) ([]byte, error) {
 	url := self.GetPathUrl(remotePath)
 
@@ -693,6 +706,16 @@ func isInvalid(err error) bool {
 	return false
 }
 
+func isFileAlreadyExists(err error) bool {
+	if f, ok := errors.Cause(err).(types.HasFault); ok {
+		switch f.Fault().(type) {
+		case *types.FileAlreadyExists:
+			return true
+		}
+	}
+	return false
+}
+
 func (self *SDatastore) CheckFile(ctx context.Context, remotePath string) (*SDatastoreFileInfo, error) {
 	url := self.GetPathUrl(remotePath)
 
@@ -765,12 +788,20 @@ func (self *SDatastore) Download(ctx context.Context, remotePath string, writer
 }
 
 func (self *SDatastore) Upload(ctx context.Context, remotePath string, body io.Reader) error {
+	return self.UploadWithSize(ctx, remotePath, body, 0)
+}
+
+// UploadWithSize uploads a stream of known size, which is required by ESXi for large files
+func (self *SDatastore) UploadWithSize(ctx context.Context, remotePath string, body io.Reader, size int64) error {
 	url := self.GetPathUrl(remotePath)
 
 	req, err := http.NewRequest("PUT", url, body)
 	if err != nil {
 		return err
 	}
+	if size > 0 {
+		req.ContentLength = size
+	}
 
 	err = self.manager.client.Do(ctx, req, func(resp *http.Response) error {
 		if resp.StatusCode >= 400 {
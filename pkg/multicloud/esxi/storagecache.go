@@ -15,14 +15,23 @@
 package esxi
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
 	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/qemuimgfmt"
+	"yunion.io/x/pkg/util/vmdkutils"
 
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
@@ -278,14 +287,193 @@ func (self *SDatastoreImageCache) GetIImageById(extId string) (cloudprovider.ICl
 	return nil, cloudprovider.ErrNotFound
 }
 
+// CreateIImage clones the vm whose id is snapshotId into a template on the datastore
 func (self *SDatastoreImageCache) CreateIImage(snapshotId, imageName, osType, imageDesc string) (cloudprovider.ICloudImage, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	ctx := context.Background()
+	ds := self.datastore
+	vm, err := ds.datacenter.FetchVMById(ds.manager.getPrivateId(snapshotId))
+	if err != nil {
+		return nil, errors.Wrapf(err, "FetchVMById %s", snapshotId)
+	}
+	tmpl, err := vm.CloneToTemplate(ctx, ds, imageName, imageDesc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CloneToTemplate")
+	}
+	return NewVMTemplate(tmpl, self), nil
 }
 
 func (self *SDatastoreImageCache) DownloadImage(imageId string, extId string, path string) (jsonutils.JSONObject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return self.ExportImage(context.Background(), extId, &multicloud.SImageDownloadOptions{
+		Path: path,
+		Callback: func(progress float32) {
+			log.Debugf("download image %s(%s) progress: %.2f%%", imageId, extId, progress)
+		},
+	})
+}
+
+// ExportImage saves the image through multicloud.SaveImage, which verifies the size and
+// checksums and removes the partially saved image on failure
+func (self *SDatastoreImageCache) ExportImage(ctx context.Context, extId string, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	iimage, err := self.GetIImageById(extId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetIImageById %s", extId)
+	}
+	switch image := iimage.(type) {
+	case *SVMTemplate:
+		return self.exportTemplate(ctx, image, opts)
+	case *SImage:
+		return self.exportCachedImage(ctx, image, opts)
+	default:
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "image %s", extId)
+	}
+}
+
+// exportTemplate exports a template as ova or vmdk into a temporary file first,
+// an ova is packed when the target name ends with .ova
+func (self *SDatastoreImageCache) exportTemplate(ctx context.Context, image *SVMTemplate, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	name, dir := opts.Key, ""
+	if opts.Bucket == nil {
+		name, dir = opts.Path, filepath.Dir(opts.Path)
+	}
+	tmp, err := ioutil.TempFile(dir, "esxi-export-*"+strings.ToLower(filepath.Ext(name)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "TempFile")
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	exportCallback := func(progress float32) {
+		if opts.Callback != nil {
+			opts.Callback(progress * 0.5)
+		}
+	}
+	format, err := image.vm.ExportImage(ctx, tmp.Name(), exportCallback)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ExportImage")
+	}
+	saveOpts := *opts
+	saveOpts.Callback = func(progress float32) {
+		if opts.Callback != nil {
+			opts.Callback(50 + progress*0.5)
+		}
+	}
+	return multicloud.SaveImageFile(ctx, tmp.Name(), format, &saveOpts)
+}
+
+var (
+	vmdkCreateTypeReg = regexp.MustCompile(`(?m)^\s*createType\s*=\s*"?([A-Za-z0-9]+)"?`)
+	vmdkExtentReg     = regexp.MustCompile(`(?m)^\s*RW\s+(\d+)\s+`)
+)
+
+// getVmdkExtentFormat returns the format of the extent file by the createType of the descriptor
+func getVmdkExtentFormat(createType string) (string, error) {
+	switch createType {
+	case "vmfs", "monolithicFlat", "twoGbMaxExtentFlat", "vmfsPreallocated", "vmfsEagerZeroedThick":
+		return string(qemuimgfmt.RAW), nil
+	case "monolithicSparse", "streamOptimized":
+		return string(qemuimgfmt.VMDK), nil
+	}
+	return "", errors.Wrapf(cloudprovider.ErrNotSupported, "vmdk createType %s", createType)
+}
+
+// exportCachedImage streams the extent file of a disk in image cache
+func (self *SDatastoreImageCache) exportCachedImage(ctx context.Context, image *SImage, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	return self.datastore.exportVmdk(ctx, image.filename, opts)
+}
+
+// exportVmdk streams the extent file of the vmdk descriptor filename through multicloud.SaveImage
+func (self *SDatastore) exportVmdk(ctx context.Context, filename string, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	filename = self.cleanPath(filename)
+	content, err := self.FileGetContent(ctx, filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "FileGetContent %s", filename)
+	}
+	vmdkInfo, err := vmdkutils.Parse(string(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse vmdk %s", filename)
+	}
+	if len(vmdkInfo.ExtentFile) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "vmdk %s without extent file", filename)
+	}
+	createType := ""
+	if match := vmdkCreateTypeReg.FindStringSubmatch(string(content)); len(match) > 1 {
+		createType = match[1]
+	}
+	format, err := getVmdkExtentFormat(createType)
+	if err != nil {
+		return nil, err
+	}
+	// the flat extent is exactly the sectors declared in the descriptor
+	size := int64(0)
+	if format == string(qemuimgfmt.RAW) {
+		if match := vmdkExtentReg.FindStringSubmatch(string(content)); len(match) > 1 {
+			sectors, _ := strconv.ParseInt(match[1], 10, 64)
+			size = sectors * 512
+		}
+	}
+	extent := path.Join(path.Dir(filename), vmdkInfo.ExtentFile)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(self.Download(ctx, extent, writer))
+	}()
+	return multicloud.SaveImage(ctx, &multicloud.SImageStream{
+		Body:   reader,
+		Size:   size,
+		Format: format,
+	}, opts)
 }
 
 func (self *SDatastoreImageCache) UploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(progress float32)) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.VMDK, callback)
+	if err != nil {
+		return "", errors.Wrapf(err, "GetImageReader")
+	}
+	defer reader.Close()
+	size, callback := reader.Size, reader.Callback
+	name := image.ImageName
+	if len(name) == 0 {
+		name = image.ImageId
+	}
+	body := bufio.NewReader(multicloud.NewProgress(size, 50, reader, callback))
+	header, err := body.Peek(512)
+	if err != nil {
+		return "", errors.Wrapf(err, "read image header")
+	}
+	importCallback := func(progress float32) {
+		if callback != nil {
+			callback(50 + progress*0.45)
+		}
+	}
+
+	var id string
+	switch getImageFileFormat(header) {
+	case imageFileFormatOva:
+		vm, err := self.importOva(ctx, name, image.TmpPath, body, importCallback)
+		if err != nil {
+			return "", errors.Wrapf(err, "importOva")
+		}
+		id = NewVMTemplate(vm, self).GetGlobalId()
+	case imageFileFormatStreamVmdk:
+		err = self.importStreamVmdk(ctx, image.ImageId, image.TmpPath, body, importCallback)
+		if err != nil {
+			return "", errors.Wrapf(err, "importStreamVmdk")
+		}
+		id = (&SImage{filename: image.ImageId + ".vmdk"}).GetGlobalId()
+	case imageFileFormatSparseVmdk:
+		err = self.importSparseVmdk(ctx, image.ImageId, body, size)
+		if err != nil {
+			return "", errors.Wrapf(err, "importSparseVmdk")
+		}
+		id = (&SImage{filename: image.ImageId + ".vmdk"}).GetGlobalId()
+	default:
+		return "", errors.Wrapf(cloudprovider.ErrNotSupported, "unknown format of image %s", image.ImageId)
+	}
+	if callback != nil {
+		callback(100)
+	}
+	return id, nil
 }
@@ -24,7 +24,9 @@ import (
 
 	"github.com/vmware/govmomi/vim25/types"
 
+	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -437,16 +439,71 @@ func (disk *SVirtualDisk) Delete(ctx context.Context) error {
 	return ds.Delete2(ctx, disk.getBackingInfo().GetFileName(), false, false)
 }
 
+// isIndependent reports whether the disk is excluded from vm snapshots
+func (disk *SVirtualDisk) isIndependent() bool {
+	return strings.HasPrefix(disk.getDiskMode(), "independent")
+}
+
+// CreateISnapshot takes a vm snapshot, vSphere does not support snapshots of a single disk
 func (disk *SVirtualDisk) CreateISnapshot(ctx context.Context, name string, desc string) (cloudprovider.ICloudSnapshot, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if disk.isIndependent() {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "independent disk %s", disk.GetName())
+	}
+	isp, err := disk.vm.CreateInstanceSnapshot(ctx, name, desc)
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateInstanceSnapshot")
+	}
+	return &SVirtualDiskSnapshot{
+		snapshot: *isp.(*SVirtualMachineSnapshot),
+		disk:     disk,
+	}, nil
 }
 
 func (disk *SVirtualDisk) GetISnapshot(idStr string) (cloudprovider.ICloudSnapshot, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	snapshots, err := disk.getSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		if snapshots[i].GetGlobalId() == idStr {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "snapshot %s", idStr)
+}
+
+// getSnapshots returns the vm snapshots whose layout contains this disk
+func (disk *SVirtualDisk) getSnapshots() ([]SVirtualDiskSnapshot, error) {
+	ret := []SVirtualDiskSnapshot{}
+	if disk.isIndependent() {
+		return ret, nil
+	}
+	if disk.vm.snapshots == nil {
+		disk.vm.fetchSnapshots()
+	}
+	key := disk.getKey()
+	for i := range disk.vm.snapshots {
+		if !disk.vm.isDiskInSnapshot(disk.vm.snapshots[i].snapshotTree.Snapshot, key) {
+			continue
+		}
+		ret = append(ret, SVirtualDiskSnapshot{
+			snapshot: disk.vm.snapshots[i],
+			disk:     disk,
+		})
+	}
+	return ret, nil
 }
 
 func (disk *SVirtualDisk) GetISnapshots() ([]cloudprovider.ICloudSnapshot, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	snapshots, err := disk.getSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudSnapshot{}
+	for i := range snapshots {
+		ret = append(ret, &snapshots[i])
+	}
+	return ret, nil
 }
 
 func (disk *SVirtualDisk) Resize(ctx context.Context, newSizeMb int64) error {
@@ -504,8 +561,28 @@ func (disk *SVirtualDisk) ResizePartition(ctx context.Context, accessInfo vcente
 }
 */
 
+// Reset reverts the vm to the snapshot, all the other disks are reverted too
 func (disk *SVirtualDisk) Reset(ctx context.Context, snapshotId string) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	isp, err := disk.GetISnapshot(snapshotId)
+	if err != nil {
+		return "", errors.Wrapf(err, "GetISnapshot")
+	}
+	snapshot := isp.(*SVirtualDiskSnapshot)
+	key := disk.getKey()
+	err = disk.vm.ResetToInstanceSnapshot(ctx, snapshot.snapshot.GetGlobalId())
+	if err != nil {
+		return "", errors.Wrapf(err, "ResetToInstanceSnapshot")
+	}
+	err = disk.vm.Refresh()
+	if err != nil {
+		return "", errors.Wrapf(err, "Refresh")
+	}
+	for i := range disk.vm.vdisks {
+		if disk.vm.vdisks[i].getKey() == key {
+			return disk.vm.vdisks[i].GetId(), nil
+		}
+	}
+	return "", errors.Wrapf(cloudprovider.ErrNotFound, "disk with key %d after reset", key)
 }
 
 func (disk *SVirtualDisk) GetBillingType() string {
@@ -537,13 +614,30 @@ func (disk *SVirtualDisk) GetExpiredAt() time.Time {
 }
 
 func (disk *SVirtualDisk) Rebuild(ctx context.Context) error {
-	return disk.vm.rebuildDisk(ctx, disk, "")
+	return disk.vm.rebuildDisk(ctx, disk, "", 0)
 }
 
 func (disk *SVirtualDisk) GetProjectId() string {
 	return disk.vm.GetProjectId()
 }
 
+// ExportDisk streams the extent of the disk, the current data of a disk with snapshots
+// lives in the delta disks and the vm must be exported as a whole instead
+func (disk *SVirtualDisk) ExportDisk(ctx context.Context, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if disk.getBackingInfo().GetParent() != nil {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "disk %s has snapshots", disk.GetName())
+	}
+	istore, err := disk.GetIStorage()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetIStorage")
+	}
+	return istore.(*SDatastore).exportVmdk(ctx, disk.GetFilename(), opts)
+}
+
 func (disk *SVirtualDisk) GetFilename() string {
 	return disk.getBackingInfo().GetFileName()
 }
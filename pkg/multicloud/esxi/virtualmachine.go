@@ -16,6 +16,7 @@ package esxi
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"reflect"
 	"sort"
@@ -33,8 +34,10 @@ import (
 	"yunion.io/x/log"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/billing"
+	"yunion.io/x/pkg/util/cloudinit"
 	"yunion.io/x/pkg/util/imagetools"
 	"yunion.io/x/pkg/util/netutils"
+	"yunion.io/x/pkg/util/osprofile"
 	"yunion.io/x/pkg/util/reflectutils"
 	"yunion.io/x/pkg/util/regutils"
 	"yunion.io/x/pkg/util/version"
@@ -50,7 +53,7 @@ var (
 	vmSummaryProps = []string{"summary.runtime.powerState", "summary.config.uuid", "summary.config.memorySizeMB", "summary.config.numCpu", "summary.customValue"}
 	// vmConfigProps   = []string{"config.template", "config.alternateGuestName", "config.hardware", "config.guestId", "config.guestFullName", "config.firmware", "config.version", "config.createDate"}
 	vmGuestProps    = []string{"guest.net", "guest.guestState", "guest.toolsStatus", "guest.toolsRunningStatus", "guest.toolsVersion"}
-	vmLayoutExProps = []string{"layoutEx.file"}
+	vmLayoutExProps = []string{"layoutEx.file", "layoutEx.snapshot"}
 )
 
 var VIRTUAL_MACHINE_PROPS = []string{"name", "parent", "resourcePool", "snapshot", "config", "availableField", "datastore"}
@@ -214,24 +217,105 @@ func (self *SVirtualMachine) GetInstanceType() string {
 	return ""
 }
 
+// DeployVM renames the vm and injects hostname and login credentials by guest customization,
+// linux guests apply them at the next boot
 func (self *SVirtualMachine) DeployVM(ctx context.Context, name string, username string, password string, publicKey string, deleteKeypair bool, description string) error {
-	return cloudprovider.ErrNotImplemented
+	err := self.updateNameAndDesc(ctx, name, description)
+	if err != nil {
+		return err
+	}
+	if len(password) == 0 && len(publicKey) == 0 && len(name) == 0 {
+		return nil
+	}
+	opts := &sGuestCustomizeOptions{
+		InstanceId: self.newInstanceId(),
+		OsType:     string(self.GetOsType()),
+		Hostname:   name,
+		Username:   username,
+		Password:   password,
+		PublicKey:  publicKey,
+		UserData:   self.getUserData(),
+	}
+	return self.doGuestCustomize(ctx, opts)
 }
 
+// RebuildRoot replaces the system disk with a copy of the system disk of a template
+// or a disk in image cache, and then customizes the guest again
 func (self *SVirtualMachine) RebuildRoot(ctx context.Context, desc *cloudprovider.SManagedVMRebuildRootConfig) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	if len(self.vdisks) == 0 {
+		return "", errors.Wrapf(errors.ErrNotFound, "empty vdisks")
+	}
+	imagePath, err := self.getRebuildImagePath(desc.ImageId)
+	if err != nil {
+		return "", errors.Wrapf(err, "getRebuildImagePath %s", desc.ImageId)
+	}
+	err = self.rebuildDisk(ctx, &self.vdisks[0], imagePath, desc.SysSizeGB*1024)
+	if err != nil {
+		return "", errors.Wrapf(err, "rebuildDisk")
+	}
+	err = self.Refresh()
+	if err != nil {
+		return "", errors.Wrapf(err, "Refresh")
+	}
+	if len(self.vdisks) == 0 {
+		return "", errors.Wrapf(errors.ErrNotFound, "no system disk after rebuild")
+	}
+	osType := desc.OsType
+	if len(osType) == 0 {
+		osType = string(self.GetOsType())
+	}
+	opts := &sGuestCustomizeOptions{
+		InstanceId: self.newInstanceId(),
+		OsType:     osType,
+		Hostname:   self.GetHostname(),
+		Username:   desc.Account,
+		Password:   desc.Password,
+		PublicKey:  desc.PublicKey,
+		UserData:   self.getUserData(),
+	}
+	err = self.doGuestCustomize(ctx, opts)
+	if err != nil {
+		return "", errors.Wrapf(err, "doGuestCustomize")
+	}
+	return self.vdisks[0].GetGlobalId(), nil
+}
+
+// getRebuildImagePath returns the path of the system disk of a template, or of a vmdk in image cache
+func (self *SVirtualMachine) getRebuildImagePath(imageId string) (string, error) {
+	tmpl, err := self.manager.SearchTemplateVM(imageId)
+	if err == nil {
+		if len(tmpl.vdisks) == 0 {
+			return "", errors.Wrapf(errors.ErrNotFound, "template %s without disk", tmpl.GetName())
+		}
+		return tmpl.vdisks[0].GetFilename(), nil
+	}
+	if errors.Cause(err) != errors.ErrNotFound {
+		return "", errors.Wrapf(err, "SearchTemplateVM")
+	}
+	host, ok := self.GetIHost().(*SHost)
+	if !ok || host == nil {
+		return "", fmt.Errorf("unable to get host of virtualmachine %s", self.GetName())
+	}
+	imagePath, _, err := host.getCreateVMImage(imageId)
+	if err != nil {
+		return "", err
+	}
+	return imagePath, nil
 }
 
 func (self *SVirtualMachine) DoRebuildRoot(ctx context.Context, imagePath string, uuid string) error {
 	if len(self.vdisks) == 0 {
 		return errors.Wrapf(errors.ErrNotFound, "empty vdisks")
 	}
-	return self.rebuildDisk(ctx, &self.vdisks[0], imagePath)
+	return self.rebuildDisk(ctx, &self.vdisks[0], imagePath, 0)
 }
 
-func (self *SVirtualMachine) rebuildDisk(ctx context.Context, disk *SVirtualDisk, imagePath string) error {
+// rebuildDisk recreates the disk from imagePath, the disk keeps its size if sizeMb is smaller
+func (self *SVirtualMachine) rebuildDisk(ctx context.Context, disk *SVirtualDisk, imagePath string, sizeMb int) error {
 	uuid := disk.GetId()
-	sizeMb := disk.GetDiskSizeMB()
+	if sizeMb < disk.GetDiskSizeMB() {
+		sizeMb = disk.GetDiskSizeMB()
+	}
 	diskKey := disk.getKey()
 	ctlKey := disk.getControllerKey()
 	unitNumber := *disk.dev.GetVirtualDevice().UnitNumber
@@ -252,7 +336,26 @@ func (self *SVirtualMachine) rebuildDisk(ctx context.Context, disk *SVirtualDisk
 }
 
 func (self *SVirtualMachine) UpdateVM(ctx context.Context, name string) error {
-	return cloudprovider.ErrNotImplemented
+	return self.updateNameAndDesc(ctx, name, "")
+}
+
+// updateNameAndDesc renames the vm in place and updates its annotation, empty values are left unchanged
+func (self *SVirtualMachine) updateNameAndDesc(ctx context.Context, name string, desc string) error {
+	spec := types.VirtualMachineConfigSpec{}
+	if len(name) > 0 && name != self.GetName() {
+		spec.Name = name
+	}
+	if len(desc) > 0 {
+		spec.Annotation = desc
+	}
+	if len(spec.Name) == 0 && len(spec.Annotation) == 0 {
+		return nil
+	}
+	task, err := self.getVmObj().Reconfigure(ctx, spec)
+	if err != nil {
+		return errors.Wrap(err, "Reconfigure")
+	}
+	return task.Wait(ctx)
 }
 
 // TODO: detach disk to a separate directory, so as to keep disk independent of VM
@@ -265,8 +368,77 @@ func (self *SVirtualMachine) DetachDisk(ctx context.Context, diskId string) erro
 	return self.doDetachDisk(ctx, vdisk.(*SVirtualDisk), false)
 }
 
+// AttachDisk attaches a disk created by SDatastore.CreateIDisk on one of the host datastores
 func (self *SVirtualMachine) AttachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotImplemented
+	disk, err := self.findDetachedDisk(ctx, diskId)
+	if err != nil {
+		return errors.Wrapf(err, "findDetachedDisk %s", diskId)
+	}
+
+	deviceChange := make([]types.BaseVirtualDeviceConfigSpec, 0, 2)
+	devs, err := self.FindController(ctx, "scsi")
+	if err != nil {
+		return errors.Wrapf(err, "FindController")
+	}
+	var ctrlKey int32
+	unitNumber := 0
+	if len(devs) == 0 {
+		ctrlKey = self.FindMinDiffKey(1000)
+		deviceChange = append(deviceChange, addDevSpec(NewSCSIDev(ctrlKey, 100, "pvscsi")))
+	} else {
+		ctrlKey = devs[0].getKey()
+		unitNumber = self.devNumWithCtrlKey(ctrlKey)
+		for i := 1; i < len(devs); i++ {
+			num := self.devNumWithCtrlKey(devs[i].getKey())
+			if num < unitNumber {
+				ctrlKey, unitNumber = devs[i].getKey(), num
+			}
+		}
+		// the scsi controller itself occupies unit 7
+		if unitNumber >= 7 {
+			unitNumber++
+		}
+	}
+
+	devSpec := NewDiskDev(0, SDiskConfig{
+		ControllerKey: ctrlKey,
+		UnitNumber:    int32(unitNumber),
+		Key:           self.FindMinDiffKey(2000),
+		ImagePath:     disk.datastore.getPathString(disk.filename),
+		Datastore:     disk.datastore,
+	})
+	configSpec := types.VirtualMachineConfigSpec{}
+	configSpec.DeviceChange = append(deviceChange, addDevSpec(devSpec))
+	task, err := self.getVmObj().Reconfigure(ctx, configSpec)
+	if err != nil {
+		return errors.Wrapf(err, "Reconfigure")
+	}
+	err = task.Wait(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "wait reconfigure task")
+	}
+	return self.Refresh()
+}
+
+func (self *SVirtualMachine) findDetachedDisk(ctx context.Context, diskId string) (*SDetachedDisk, error) {
+	host := self.GetIHost()
+	if host == nil {
+		return nil, fmt.Errorf("unable to get host of virtualmachine %s", self.GetName())
+	}
+	storages, err := host.GetIStorages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetIStorages")
+	}
+	for i := range storages {
+		disk, err := storages[i].(*SDatastore).getDetachedDisk(ctx, diskId)
+		if err == nil {
+			return disk, nil
+		}
+		if errors.Cause(err) != cloudprovider.ErrNotFound {
+			return nil, err
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
 }
 
 func (self *SVirtualMachine) getUuid() string {
@@ -769,7 +941,10 @@ func (self *SVirtualMachine) GetExpiredAt() time.Time {
 }
 
 func (self *SVirtualMachine) UpdateUserData(userData string) error {
-	return nil
+	return self.setGuestInfo(context.Background(), map[string]string{
+		"guestinfo.userdata":          base64.StdEncoding.EncodeToString([]byte(userData)),
+		"guestinfo.userdata.encoding": "base64",
+	})
 }
 
 func (self *SVirtualMachine) fetchHardwareInfo() error {
@@ -962,6 +1137,26 @@ func (self *SVirtualMachine) getLayoutEx() *types.VirtualMachineFileLayoutEx {
 	return vm.LayoutEx
 }
 
+// isDiskInSnapshot checks the disk layout of the snapshot, disks added after the snapshot are not covered
+func (self *SVirtualMachine) isDiskInSnapshot(snapshot types.ManagedObjectReference, diskKey int32) bool {
+	layoutEx := self.getLayoutEx()
+	if layoutEx == nil || len(layoutEx.Snapshot) == 0 {
+		return true
+	}
+	for _, layout := range layoutEx.Snapshot {
+		if layout.Key.Value != snapshot.Value {
+			continue
+		}
+		for _, disk := range layout.Disk {
+			if disk.Key == diskKey {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
 func (self *SVirtualMachine) CreateDisk(ctx context.Context, opts *cloudprovider.GuestDiskCreateOptions) (string, error) {
 	if opts.Driver == "pvscsi" {
 		opts.Driver = "scsi"
@@ -1052,11 +1247,17 @@ func (self *SVirtualMachine) createDriverAndDisk(ctx context.Context, ds *SDatas
 }
 
 func (self *SVirtualMachine) getDatastoreAndRootImagePath() (string, *SDatastore, error) {
-	layoutEx := self.getLayoutEx()
-	if layoutEx == nil || len(layoutEx.File) == 0 {
-		return "", nil, fmt.Errorf("invalid LayoutEx")
+	var file string
+	// the vmx file is in the vm directory, while the first file of layoutEx is not necessarily
+	if moVM := self.getVirtualMachine(); moVM.Config != nil && len(moVM.Config.Files.VmPathName) > 0 {
+		file = moVM.Config.Files.VmPathName
+	} else {
+		layoutEx := self.getLayoutEx()
+		if layoutEx == nil || len(layoutEx.File) == 0 {
+			return "", nil, fmt.Errorf("invalid LayoutEx")
+		}
+		file = layoutEx.File[0].Name
 	}
-	file := layoutEx.File[0].Name
 	// find stroage
 	host := self.GetIHost()
 	storages, err := host.GetIStorages()
@@ -1367,6 +1568,211 @@ func (self *SVirtualMachine) DoCustomize(ctx context.Context, params jsonutils.J
 	return task.Wait(ctx)
 }
 
+type sGuestCustomizeOptions struct {
+	// cloud-init runs again only when the instance id changes
+	InstanceId string
+	OsType     string
+	Hostname   string
+	// login user of linux guests, root if empty; windows guests always use Administrator
+	Username  string
+	Password  string
+	PublicKey string
+	UserData  string
+}
+
+func (self *SVirtualMachine) newInstanceId() string {
+	return fmt.Sprintf("%s-%d", self.GetGlobalId(), time.Now().Unix())
+}
+
+// getUserData returns the user data saved in guestinfo
+func (self *SVirtualMachine) getUserData() string {
+	moVM := self.getVirtualMachine()
+	if moVM.Config == nil {
+		return ""
+	}
+	for _, opt := range moVM.Config.ExtraConfig {
+		val := opt.GetOptionValue()
+		if val.Key != "guestinfo.userdata" {
+			continue
+		}
+		userData, err := base64.StdEncoding.DecodeString(fmt.Sprintf("%v", val.Value))
+		if err != nil {
+			return ""
+		}
+		return string(userData)
+	}
+	return ""
+}
+
+// setGuestInfo writes guestinfo.* keys into the vmx extra config
+func (self *SVirtualMachine) setGuestInfo(ctx context.Context, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	spec := types.VirtualMachineConfigSpec{}
+	for _, k := range keys {
+		spec.ExtraConfig = append(spec.ExtraConfig, &types.OptionValue{Key: k, Value: values[k]})
+	}
+	task, err := self.getVmObj().Reconfigure(ctx, spec)
+	if err != nil {
+		return errors.Wrap(err, "Reconfigure")
+	}
+	return task.Wait(ctx)
+}
+
+// doGuestCustomize injects hostname, login credentials and user data into the guest.
+// Linux guests receive them through the cloud-init guestinfo datasource,
+// Windows guests through a sysprep customization.
+func (self *SVirtualMachine) doGuestCustomize(ctx context.Context, opts *sGuestCustomizeOptions) error {
+	values := map[string]string{}
+	userData := opts.UserData
+	if strings.EqualFold(opts.OsType, osprofile.OS_TYPE_WINDOWS) {
+		if len(opts.Password) > 0 || len(opts.Hostname) > 0 {
+			err := self.doSysprep(ctx, opts.Hostname, opts.Password)
+			if err != nil {
+				return errors.Wrap(err, "doSysprep")
+			}
+		}
+	} else if len(opts.Password) > 0 || len(opts.PublicKey) > 0 {
+		config := &cloudinit.SCloudConfig{}
+		if len(userData) > 0 {
+			var err error
+			config, err = cloudinit.ParseUserData(userData)
+			if err != nil {
+				log.Warningf("invalid cloud-config user data, ignore it: %v", err)
+				config = &cloudinit.SCloudConfig{}
+			}
+		}
+		username := opts.Username
+		if len(username) == 0 {
+			username = "root"
+		}
+		user := cloudinit.NewUser(username)
+		if len(opts.Password) > 0 {
+			user.Password(opts.Password)
+			config.SshPwauth = cloudinit.SSH_PASSWORD_AUTH_ON
+		}
+		if len(opts.PublicKey) > 0 {
+			user.SshKey(opts.PublicKey)
+		}
+		if username == "root" {
+			config.DisableRoot = 0
+		} else {
+			user.SudoPolicy(cloudinit.USER_SUDO_NOPASSWD)
+		}
+		config.MergeUser(user)
+		userData = config.UserData()
+	}
+	metadata := jsonutils.NewDict()
+	instanceId := opts.InstanceId
+	if len(instanceId) == 0 {
+		instanceId = self.GetGlobalId()
+	}
+	metadata.Set("instance-id", jsonutils.NewString(instanceId))
+	if len(opts.Hostname) > 0 {
+		metadata.Set("local-hostname", jsonutils.NewString(opts.Hostname))
+	}
+	values["guestinfo.metadata"] = base64.StdEncoding.EncodeToString([]byte(metadata.String()))
+	values["guestinfo.metadata.encoding"] = "base64"
+	if len(userData) > 0 {
+		values["guestinfo.userdata"] = base64.StdEncoding.EncodeToString([]byte(userData))
+		values["guestinfo.userdata.encoding"] = "base64"
+	}
+	return self.setGuestInfo(ctx, values)
+}
+
+// doSysprep customizes a windows guest, vSphere only customizes powered off vms
+func (self *SVirtualMachine) doSysprep(ctx context.Context, hostname, password string) error {
+	if status := self.GetStatus(); status != api.VM_READY {
+		return errors.Wrapf(cloudprovider.ErrInvalidStatus, "vm %s status %s, stop it before sysprep", self.GetName(), status)
+	}
+	spec := types.CustomizationSpec{}
+	for i := range self.vnics {
+		spec.NicSettingMap = append(spec.NicSettingMap, types.CustomizationAdapterMapping{
+			MacAddress: self.vnics[i].GetMAC(),
+			Adapter: types.CustomizationIPSettings{
+				Ip: &types.CustomizationDhcpIpGenerator{},
+			},
+		})
+	}
+	var computerName types.BaseCustomizationName = &types.CustomizationVirtualMachineName{}
+	if len(hostname) > 0 {
+		computerName = &types.CustomizationFixedName{Name: hostname}
+	}
+	sysPrep := types.CustomizationSysprep{
+		GuiUnattended: types.CustomizationGuiUnattended{
+			TimeZone:  210,
+			AutoLogon: false,
+		},
+		UserData: types.CustomizationUserData{
+			FullName:     "Administrator",
+			OrgName:      "Yunion",
+			ComputerName: computerName,
+		},
+		Identification: types.CustomizationIdentification{},
+	}
+	if len(password) > 0 {
+		sysPrep.GuiUnattended.Password = &types.CustomizationPassword{
+			Value:     password,
+			PlainText: true,
+		}
+	}
+	spec.Identity = &sysPrep
+	task, err := self.getVmObj().Customize(ctx, spec)
+	if err != nil {
+		return errors.Wrap(err, "object.VirtualMachine.Customize")
+	}
+	return task.Wait(ctx)
+}
+
+// CloneToTemplate clones the vm to the datastore and marks the clone as a template
+func (self *SVirtualMachine) CloneToTemplate(ctx context.Context, ds *SDatastore, name string, desc string) (*SVirtualMachine, error) {
+	folders, err := self.datacenter.getObjectDatacenter().Folders(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "object.DataCenter.Folders")
+	}
+	dsref := ds.getDatastoreObj().Reference()
+	spec := types.VirtualMachineCloneSpec{
+		Location: types.VirtualMachineRelocateSpec{
+			Datastore: &dsref,
+		},
+		Config: &types.VirtualMachineConfigSpec{
+			Annotation: desc,
+		},
+	}
+	task, err := self.getVmObj().Clone(ctx, folders.VmFolder, name, spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "object.VirtualMachine.Clone")
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Task.WaitForResult")
+	}
+	ref, ok := info.Result.(types.ManagedObjectReference)
+	if !ok {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "clone result of %s", self.GetName())
+	}
+	err = object.NewVirtualMachine(self.manager.client.Client, ref).MarkAsTemplate(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "MarkAsTemplate")
+	}
+	var moVM mo.VirtualMachine
+	err = self.manager.reference2Object(ref, VIRTUAL_MACHINE_PROPS, &moVM)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to fetch template just created")
+	}
+	vm := NewVirtualMachine(self.manager, &moVM, self.datacenter)
+	if vm == nil {
+		return nil, errors.Error("clone successfully but unable to NewVirtualMachine")
+	}
+	return vm, nil
+}
+
 func (self *SVirtualMachine) ExportTemplate(ctx context.Context, idx int, diskPath string) error {
 	lease, err := self.getVmObj().Export(ctx)
 	if err != nil {
@@ -1574,11 +1980,19 @@ func (self *SVirtualMachine) CreateInstanceSnapshot(ctx context.Context, name st
 	if err != nil {
 		return nil, errors.Wrap(err, "task.Wait")
 	}
-	sp := info.Result.(types.ManagedObjectReference)
 	err = self.Refresh()
 	if err != nil {
 		return nil, errors.Wrap(err, "create successfully")
 	}
+	sp, ok := info.Result.(types.ManagedObjectReference)
+	if !ok {
+		// some servers do not return the snapshot in task result, the new one is the current snapshot
+		movm := self.getVirtualMachine()
+		if movm.Snapshot == nil || movm.Snapshot.CurrentSnapshot == nil {
+			return nil, errors.Wrap(errors.ErrNotFound, "create successfully")
+		}
+		sp = *movm.Snapshot.CurrentSnapshot
+	}
 	self.fetchSnapshots()
 	for i := range self.snapshots {
 		if self.snapshots[i].snapshotTree.Snapshot == sp {
@@ -0,0 +1,119 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esxi
+
+import (
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// SVpc is the default vpc of a datacenter, the wires of all its hosts belong to it
+type SVpc struct {
+	multicloud.SVpc
+	multicloud.STagBase
+
+	datacenter *SDatacenter
+}
+
+func (dc *SDatacenter) getDefaultVpc() *SVpc {
+	return &SVpc{datacenter: dc}
+}
+
+func (vpc *SVpc) GetId() string {
+	return vpc.datacenter.GetId()
+}
+
+func (vpc *SVpc) GetName() string {
+	return "Default"
+}
+
+func (vpc *SVpc) GetGlobalId() string {
+	return vpc.GetId()
+}
+
+func (vpc *SVpc) GetStatus() string {
+	return api.VPC_STATUS_AVAILABLE
+}
+
+func (vpc *SVpc) IsEmulated() bool {
+	return true
+}
+
+func (vpc *SVpc) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (vpc *SVpc) GetCidrBlock() string {
+	return "0.0.0.0/0"
+}
+
+func (vpc *SVpc) GetIsDefault() bool {
+	return true
+}
+
+func (vpc *SVpc) GetRegion() cloudprovider.ICloudRegion {
+	return vpc.datacenter.manager
+}
+
+func (vpc *SVpc) GetIRouteTables() ([]cloudprovider.ICloudRouteTable, error) {
+	return []cloudprovider.ICloudRouteTable{}, nil
+}
+
+func (vpc *SVpc) GetIRouteTableById(routeTableId string) (cloudprovider.ICloudRouteTable, error) {
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (vpc *SVpc) GetISecurityGroups() ([]cloudprovider.ICloudSecurityGroup, error) {
+	return []cloudprovider.ICloudSecurityGroup{}, nil
+}
+
+// GetIWires returns the wires of all hosts in the datacenter,
+// a distributed switch joined by several hosts is returned once
+func (vpc *SVpc) GetIWires() ([]cloudprovider.ICloudWire, error) {
+	hosts, err := vpc.datacenter.GetIHosts()
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudWire{}
+	ids := map[string]bool{}
+	for i := range hosts {
+		wires, err := hosts[i].(*SHost).getWires()
+		if err != nil {
+			return nil, err
+		}
+		for j := range wires {
+			if ids[wires[j].GetGlobalId()] {
+				continue
+			}
+			ids[wires[j].GetGlobalId()] = true
+			ret = append(ret, wires[j])
+		}
+	}
+	return ret, nil
+}
+
+func (vpc *SVpc) GetIWireById(id string) (cloudprovider.ICloudWire, error) {
+	wires, err := vpc.GetIWires()
+	if err != nil {
+		return nil, err
+	}
+	for i := range wires {
+		if wires[i].GetGlobalId() == id {
+			return wires[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
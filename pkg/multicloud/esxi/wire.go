@@ -0,0 +1,508 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esxi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/netutils"
+	"yunion.io/x/pkg/util/rbacscope"
+	"yunion.io/x/pkg/util/regutils"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+var WIRE_VM_PROPS = []string{"name", "config.template", "config.hardware.device", "guest.net", "guest.ipStack"}
+
+const DEFAULT_GUEST_IP_MASKLEN = 24
+
+// SWire is a standard vSwitch of a host or a distributed virtual switch the host joins,
+// its id has the same format as the bridge accepted by findVirtualSwitch
+type SWire struct {
+	multicloud.SResourceBase
+	multicloud.STagBase
+
+	host *SHost
+
+	id          string
+	name        string
+	distributed bool
+	pnics       []string
+	portgroups  []IVMNetwork
+}
+
+func (wire *SWire) GetId() string {
+	return wire.id
+}
+
+func (wire *SWire) GetName() string {
+	return wire.name
+}
+
+func (wire *SWire) GetGlobalId() string {
+	return wire.id
+}
+
+func (wire *SWire) GetStatus() string {
+	return api.WIRE_STATUS_AVAILABLE
+}
+
+func (wire *SWire) IsDistributed() bool {
+	return wire.distributed
+}
+
+func (wire *SWire) GetIVpc() cloudprovider.ICloudVpc {
+	return wire.host.datacenter.getDefaultVpc()
+}
+
+func (wire *SWire) GetIZone() cloudprovider.ICloudZone {
+	return wire.host.datacenter.getDefaultZone()
+}
+
+// GetBandwidth returns the max link speed of the uplink physical nics in Mbps
+func (wire *SWire) GetBandwidth() int {
+	bandwidth := 0
+	moHost := wire.host.getHostSystem()
+	if moHost.Config != nil && moHost.Config.Network != nil {
+		for _, pnic := range moHost.Config.Network.Pnic {
+			if !utils.IsInStringArray(pnic.Key, wire.pnics) || pnic.LinkSpeed == nil {
+				continue
+			}
+			if int(pnic.LinkSpeed.SpeedMb) > bandwidth {
+				bandwidth = int(pnic.LinkSpeed.SpeedMb)
+			}
+		}
+	}
+	if bandwidth == 0 {
+		bandwidth = 10000
+	}
+	return bandwidth
+}
+
+func (wire *SWire) CreateINetwork(opts *cloudprovider.SNetworkCreateOptions) (cloudprovider.ICloudNetwork, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (wire *SWire) GetINetworks() ([]cloudprovider.ICloudNetwork, error) {
+	ret := []cloudprovider.ICloudNetwork{}
+	for i := range wire.portgroups {
+		nets, err := wire.getPortgroupNetworks(wire.portgroups[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "getPortgroupNetworks %s", wire.portgroups[i].GetName())
+		}
+		for j := range nets {
+			ret = append(ret, nets[j])
+		}
+	}
+	return ret, nil
+}
+
+func (wire *SWire) GetINetworkById(id string) (cloudprovider.ICloudNetwork, error) {
+	nets, err := wire.GetINetworks()
+	if err != nil {
+		return nil, err
+	}
+	for i := range nets {
+		if nets[i].GetGlobalId() == id {
+			return nets[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, id)
+}
+
+func (wire *SWire) getPortgroupId(pg IVMNetwork) string {
+	if wire.distributed {
+		return pg.GetId()
+	}
+	// standard portgroups with the same name on different hosts share one network object
+	return fmt.Sprintf("%s/%s", wire.id, pg.GetName())
+}
+
+type sGuestIp struct {
+	ip      netutils.IPV4Addr
+	maskLen int8
+	gateway string
+}
+
+// fetchPortgroupVMs returns the vms of the host, together with the vms of the other
+// member hosts for a distributed portgroup
+func (wire *SWire) fetchPortgroupVMs(pg IVMNetwork) ([]mo.VirtualMachine, error) {
+	refs := []types.ManagedObjectReference{}
+	if net, ok := pg.(*SDistributedVirtualPortgroup); ok {
+		var dvpg mo.DistributedVirtualPortgroup
+		err := wire.host.manager.reference2Object(net.getMODVPortgroup().Self, []string{"vm"}, &dvpg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetch vms of dvportgroup %s", net.GetName())
+		}
+		refs = append(refs, dvpg.Vm...)
+	}
+	for _, ref := range wire.host.getHostSystem().Vm {
+		if !isInRefArray(ref, refs) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	var vms []mo.VirtualMachine
+	err := wire.host.manager.references2Objects(refs, WIRE_VM_PROPS, &vms)
+	if err != nil {
+		return nil, errors.Wrap(err, "references2Objects")
+	}
+	return vms, nil
+}
+
+func isGuestIp(ip string) bool {
+	if !regutils.MatchIP4Addr(ip) || !vmIPV4Filter.Contains(ip) {
+		return false
+	}
+	addr, _ := netutils.NewIPV4Addr(ip)
+	return !netutils.IsLinkLocal(addr)
+}
+
+// isGuestNicOnPortgroup matches the guest nic with the backing of its ethernet card,
+// falls back to the network name reported by vmware tools
+func isGuestNicOnPortgroup(vm *mo.VirtualMachine, nic types.GuestNicInfo, pg IVMNetwork) bool {
+	for _, device := range vm.Config.Hardware.Device {
+		bcard, ok := device.(types.BaseVirtualEthernetCard)
+		if !ok || bcard.GetVirtualEthernetCard().Key != nic.DeviceConfigId {
+			continue
+		}
+		switch bk := bcard.GetVirtualEthernetCard().Backing.(type) {
+		case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+			dvpg, ok := pg.(*SDistributedVirtualPortgroup)
+			return ok && bk.Port.PortgroupKey == dvpg.getMODVPortgroup().Key
+		case *types.VirtualEthernetCardNetworkBackingInfo:
+			_, ok := pg.(*SNetwork)
+			return ok && bk.DeviceName == pg.GetName()
+		}
+	}
+	return nic.Network == pg.GetName()
+}
+
+func getGuestDefaultGateway(vm *mo.VirtualMachine) string {
+	for _, stack := range vm.Guest.IpStack {
+		if stack.IpRouteConfig == nil {
+			continue
+		}
+		for _, route := range stack.IpRouteConfig.IpRoute {
+			if route.Network == "0.0.0.0" && route.PrefixLength == 0 && regutils.MatchIP4Addr(route.Gateway.IpAddress) {
+				return route.Gateway.IpAddress
+			}
+		}
+	}
+	return ""
+}
+
+// getPortgroupIps collects the guest ips of the vms and the vmkernel ips of the host on the portgroup
+func (wire *SWire) getPortgroupIps(pg IVMNetwork) ([]sGuestIp, error) {
+	vms, err := wire.fetchPortgroupVMs(pg)
+	if err != nil {
+		return nil, err
+	}
+	ret := []sGuestIp{}
+	for i := range vms {
+		vm := &vms[i]
+		if vm.Config == nil || vm.Config.Template || vm.Guest == nil {
+			continue
+		}
+		gateway := getGuestDefaultGateway(vm)
+		for _, nic := range vm.Guest.Net {
+			if !isGuestNicOnPortgroup(vm, nic, pg) {
+				continue
+			}
+			if nic.IpConfig != nil && len(nic.IpConfig.IpAddress) > 0 {
+				for _, addr := range nic.IpConfig.IpAddress {
+					if !isGuestIp(addr.IpAddress) {
+						continue
+					}
+					ip, _ := netutils.NewIPV4Addr(addr.IpAddress)
+					ret = append(ret, sGuestIp{ip: ip, maskLen: int8(addr.PrefixLength), gateway: gateway})
+				}
+				continue
+			}
+			for _, addr := range nic.IpAddress {
+				if !isGuestIp(addr) {
+					continue
+				}
+				ip, _ := netutils.NewIPV4Addr(addr)
+				ret = append(ret, sGuestIp{ip: ip, maskLen: DEFAULT_GUEST_IP_MASKLEN, gateway: gateway})
+			}
+		}
+	}
+
+	moHost := wire.host.getHostSystem()
+	if moHost.Config == nil || moHost.Config.Network == nil {
+		return ret, nil
+	}
+	gateway := ""
+	if moHost.Config.Network.IpRouteConfig != nil {
+		gateway = moHost.Config.Network.IpRouteConfig.GetHostIpRouteConfig().DefaultGateway
+	}
+	for _, vnic := range moHost.Config.Network.Vnic {
+		if wire.distributed {
+			dvpg := pg.(*SDistributedVirtualPortgroup)
+			if vnic.Spec.DistributedVirtualPort == nil || vnic.Spec.DistributedVirtualPort.PortgroupKey != dvpg.getMODVPortgroup().Key {
+				continue
+			}
+		} else if vnic.Portgroup != pg.GetName() {
+			continue
+		}
+		if vnic.Spec.Ip == nil || !isGuestIp(vnic.Spec.Ip.IpAddress) {
+			continue
+		}
+		ip, _ := netutils.NewIPV4Addr(vnic.Spec.Ip.IpAddress)
+		ret = append(ret, sGuestIp{ip: ip, maskLen: mask2len(vnic.Spec.Ip.SubnetMask), gateway: gateway})
+	}
+	return ret, nil
+}
+
+// getPortgroupNetworks infers one network for each subnet the ips on the portgroup belong to
+func (wire *SWire) getPortgroupNetworks(pg IVMNetwork) ([]*SWireNetwork, error) {
+	ips, err := wire.getPortgroupIps(pg)
+	if err != nil {
+		return nil, err
+	}
+	netMap := map[string]*SWireNetwork{}
+	for _, ip := range ips {
+		if ip.maskLen <= 0 || ip.maskLen >= 31 {
+			ip.maskLen = DEFAULT_GUEST_IP_MASKLEN
+		}
+		prefix := netutils.IPV4Prefix{Address: ip.ip.NetAddr(ip.maskLen), MaskLen: ip.maskLen}
+		key := prefix.String()
+		net, ok := netMap[key]
+		if !ok {
+			net = &SWireNetwork{
+				wire:      wire,
+				portgroup: pg,
+				prefix:    prefix,
+			}
+			netMap[key] = net
+		}
+		if len(net.gateway) == 0 && len(ip.gateway) > 0 {
+			gw, err := netutils.NewIPV4Addr(ip.gateway)
+			if err == nil && gw.NetAddr(prefix.MaskLen) == prefix.Address {
+				net.gateway = ip.gateway
+			}
+		}
+	}
+	ret := make([]*SWireNetwork, 0, len(netMap))
+	for _, net := range netMap {
+		ret = append(ret, net)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].prefix.Address < ret[j].prefix.Address
+	})
+	return ret, nil
+}
+
+// SWireNetwork is a subnet inferred from the ips on a portgroup
+type SWireNetwork struct {
+	multicloud.SResourceBase
+	multicloud.STagBase
+
+	wire      *SWire
+	portgroup IVMNetwork
+	prefix    netutils.IPV4Prefix
+	gateway   string
+}
+
+func (net *SWireNetwork) GetId() string {
+	return fmt.Sprintf("%s/%s", net.wire.getPortgroupId(net.portgroup), net.prefix.String())
+}
+
+func (net *SWireNetwork) GetGlobalId() string {
+	return net.GetId()
+}
+
+func (net *SWireNetwork) GetName() string {
+	return fmt.Sprintf("%s-%s", net.portgroup.GetName(), net.prefix.Address.String())
+}
+
+func (net *SWireNetwork) GetStatus() string {
+	return api.NETWORK_STATUS_AVAILABLE
+}
+
+func (net *SWireNetwork) GetVlanId() int32 {
+	return net.portgroup.GetVlanId()
+}
+
+func (net *SWireNetwork) GetIWire() cloudprovider.ICloudWire {
+	return net.wire
+}
+
+func (net *SWireNetwork) GetIpStart() string {
+	start := net.prefix.Address.StepUp()
+	if start.String() == net.gateway {
+		start = start.StepUp()
+	}
+	return start.String()
+}
+
+func (net *SWireNetwork) GetIpEnd() string {
+	end := net.prefix.Address.BroadcastAddr(net.prefix.MaskLen).StepDown()
+	if end.String() == net.gateway {
+		end = end.StepDown()
+	}
+	return end.String()
+}
+
+func (net *SWireNetwork) GetIpMask() int8 {
+	return net.prefix.MaskLen
+}
+
+func (net *SWireNetwork) GetGateway() string {
+	return net.gateway
+}
+
+func (net *SWireNetwork) GetServerType() string {
+	return api.NETWORK_TYPE_GUEST
+}
+
+func (net *SWireNetwork) GetPublicScope() rbacscope.TRbacScope {
+	return rbacscope.ScopeDomain
+}
+
+func (net *SWireNetwork) GetProjectId() string {
+	return ""
+}
+
+func (net *SWireNetwork) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (net *SWireNetwork) GetAllocTimeoutSeconds() int {
+	return 120
+}
+
+func (host *SHost) getDistributedVirtualSwitches(dvpgs []*SDistributedVirtualPortgroup) ([]mo.DistributedVirtualSwitch, error) {
+	refs := []types.ManagedObjectReference{}
+	for _, dvpg := range dvpgs {
+		ref := dvpg.getMODVPortgroup().Config.DistributedVirtualSwitch
+		if ref == nil || isInRefArray(*ref, refs) {
+			continue
+		}
+		refs = append(refs, *ref)
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	var dvss []mo.DistributedVirtualSwitch
+	err := host.manager.references2Objects(refs, []string{"name", "uuid"}, &dvss)
+	if err != nil {
+		return nil, errors.Wrap(err, "references2Objects")
+	}
+	return dvss, nil
+}
+
+func (host *SHost) getWires() ([]*SWire, error) {
+	moHost := host.getHostSystem()
+	if moHost.Config == nil || moHost.Config.Network == nil {
+		return nil, nil
+	}
+	nets, err := host.GetNetworks()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetNetworks")
+	}
+	ret := []*SWire{}
+	for _, vs := range moHost.Config.Network.Vswitch {
+		wire := &SWire{
+			host:  host,
+			id:    fmt.Sprintf("%s/%s", moHost.Self.Value, vs.Name),
+			name:  fmt.Sprintf("%s/%s", moHost.Name, vs.Name),
+			pnics: vs.Pnic,
+		}
+		for i := range nets {
+			net, ok := nets[i].(*SNetwork)
+			if ok && net.HostPortGroup.Vswitch == vs.Key {
+				wire.portgroups = append(wire.portgroups, net)
+			}
+		}
+		ret = append(ret, wire)
+	}
+
+	dvpgs := []*SDistributedVirtualPortgroup{}
+	for i := range nets {
+		dvpg, ok := nets[i].(*SDistributedVirtualPortgroup)
+		if !ok || dvpg.getMODVPortgroup().Config.Uplink != nil && *dvpg.getMODVPortgroup().Config.Uplink {
+			continue
+		}
+		dvpgs = append(dvpgs, dvpg)
+	}
+	dvss, err := host.getDistributedVirtualSwitches(dvpgs)
+	if err != nil {
+		return nil, errors.Wrap(err, "getDistributedVirtualSwitches")
+	}
+	for i := range dvss {
+		wire := &SWire{
+			host:        host,
+			id:          dvss[i].Self.Value,
+			name:        dvss[i].Name,
+			distributed: true,
+		}
+		for _, ps := range moHost.Config.Network.ProxySwitch {
+			if ps.DvsUuid == dvss[i].Uuid {
+				wire.pnics = append(wire.pnics, ps.Pnic...)
+			}
+		}
+		for _, dvpg := range dvpgs {
+			ref := dvpg.getMODVPortgroup().Config.DistributedVirtualSwitch
+			if ref != nil && ref.Value == dvss[i].Self.Value {
+				wire.portgroups = append(wire.portgroups, dvpg)
+			}
+		}
+		ret = append(ret, wire)
+	}
+	return ret, nil
+}
+
+// getPnicBridges returns the id of the wire each physical nic uplinks to, keyed by nic device
+func (host *SHost) getPnicBridges() (map[string]string, error) {
+	wires, err := host.getWires()
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]string{}
+	moHost := host.getHostSystem()
+	if moHost.Config == nil || moHost.Config.Network == nil {
+		return ret, nil
+	}
+	for _, pnic := range moHost.Config.Network.Pnic {
+		for _, wire := range wires {
+			if utils.IsInStringArray(pnic.Key, wire.pnics) {
+				ret[pnic.Device] = wire.GetGlobalId()
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+func isInRefArray(ref types.ManagedObjectReference, refs []types.ManagedObjectReference) bool {
+	for i := range refs {
+		if refs[i].Value == ref.Value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package esxi
+
+import (
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// SZone is the default zone of a datacenter which contains all its hosts and datastores
+type SZone struct {
+	multicloud.SResourceBase
+	multicloud.STagBase
+
+	datacenter *SDatacenter
+}
+
+func (dc *SDatacenter) getDefaultZone() *SZone {
+	return &SZone{datacenter: dc}
+}
+
+func (zone *SZone) GetId() string {
+	return zone.datacenter.GetId()
+}
+
+func (zone *SZone) GetName() string {
+	return zone.datacenter.GetName()
+}
+
+func (zone *SZone) GetGlobalId() string {
+	return zone.GetId()
+}
+
+func (zone *SZone) GetStatus() string {
+	return api.ZONE_ENABLE
+}
+
+func (zone *SZone) GetI18n() cloudprovider.SModelI18nTable {
+	table := cloudprovider.SModelI18nTable{}
+	table["name"] = cloudprovider.NewSModelI18nEntry(zone.GetName()).CN(zone.GetName())
+	return table
+}
+
+func (zone *SZone) GetIRegion() cloudprovider.ICloudRegion {
+	return zone.datacenter.manager
+}
+
+func (zone *SZone) GetIHosts() ([]cloudprovider.ICloudHost, error) {
+	return zone.datacenter.GetIHosts()
+}
+
+func (zone *SZone) GetIHostById(id string) (cloudprovider.ICloudHost, error) {
+	hosts, err := zone.GetIHosts()
+	if err != nil {
+		return nil, err
+	}
+	for i := range hosts {
+		if hosts[i].GetGlobalId() == id {
+			return hosts[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
+
+func (zone *SZone) GetIStorages() ([]cloudprovider.ICloudStorage, error) {
+	return zone.datacenter.GetIStorages()
+}
+
+func (zone *SZone) GetIStorageById(id string) (cloudprovider.ICloudStorage, error) {
+	storages, err := zone.GetIStorages()
+	if err != nil {
+		return nil, err
+	}
+	for i := range storages {
+		if storages[i].GetGlobalId() == id {
+			return storages[i], nil
+		}
+	}
+	return nil, cloudprovider.ErrNotFound
+}
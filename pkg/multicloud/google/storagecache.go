@@ -111,10 +111,12 @@ func (region *SRegion) checkAndCreateBucket(bucketName string) (*SBucket, error)
 }
 
 func (cache *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(progress float32)) (string, error) {
-	reader, sizeBytes, err := image.GetReader(image.ImageId, string(qemuimgfmt.QCOW2))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.QCOW2, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeBytes, callback := reader.Size, reader.Callback
 
 	bucketName := fmt.Sprintf("imagecache-%s", image.ImageId)
 	bucket, err := cache.region.checkAndCreateBucket(bucketName)
@@ -162,10 +162,12 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 	}
 	defer self.region.DeleteIBucket(bucketName)
 
-	reader, sizeByte, err := image.GetReader(image.ImageId, string(qemuimgfmt.VMDK))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.VMDK, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeByte, callback := reader.Size, reader.Callback
 
 	minDiskGB := int64(math.Ceil(float64(image.MinDiskMb) / 1024))
 	// 在使用OBS桶的外部镜像文件制作镜像时生效且为必选字段。取值为40～1024GB。
@@ -139,18 +139,12 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 	}
 	defer self.region.DeleteIBucket(bucketName)
 
-	reader, sizeByte, err := image.GetReader(image.ImageId, string(qemuimgfmt.VMDK))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.VMDK, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
-	}
-
-	minDiskGB := int64(math.Ceil(float64(image.MinDiskMb) / 1024))
-	// 在使用OBS桶的外部镜像文件制作镜像时生效且为必选字段。取值为40～1024GB。
-	if minDiskGB < 40 {
-		minDiskGB = 40
-	} else if minDiskGB > 1024 {
-		minDiskGB = 1024
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeByte, callback := reader.Size, reader.Callback
 
 	bucket, err := self.region.GetIBucketByName(bucketName)
 	if err != nil {
@@ -165,6 +159,23 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 
 	defer bucket.DeleteObject(context.Background(), image.ImageId)
 
+	return self.importImage(bucketName, image.ImageId, image, callback)
+}
+
+// ImportImageFromBucket 从obs存储桶中的镜像文件导入镜像, 不删除镜像文件
+func (self *SStoragecache) ImportImageFromBucket(ctx context.Context, bucketName, key string, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
+	return self.importImage(bucketName, key, image, callback)
+}
+
+func (self *SStoragecache) importImage(bucketName, key string, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
+	minDiskGB := int64(math.Ceil(float64(image.MinDiskMb) / 1024))
+	// 在使用OBS桶的外部镜像文件制作镜像时生效且为必选字段。取值为40～1024GB。
+	if minDiskGB < 40 {
+		minDiskGB = 40
+	} else if minDiskGB > 1024 {
+		minDiskGB = 1024
+	}
+
 	// check image name, avoid name conflict
 	imageBaseName := image.ImageId
 	if imageBaseName[0] >= '0' && imageBaseName[0] <= '9' {
@@ -174,7 +185,7 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 	nameIdx := 1
 
 	for {
-		_, err = self.region.GetImageByName(imageName)
+		_, err := self.region.GetImageByName(imageName)
 		if err != nil {
 			if errors.Cause(err) == cloudprovider.ErrNotFound {
 				break
@@ -188,10 +199,10 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 		log.Debugf("uploadImage Match remote name %s", imageName)
 	}
 
-	jobId, err := self.region.ImportImageJob(imageName, image.OsDistribution, image.OsVersion, image.OsArch, bucketName, image.ImageId, int64(minDiskGB))
+	jobId, err := self.region.ImportImageJob(imageName, image.OsDistribution, image.OsVersion, image.OsArch, bucketName, key, int64(minDiskGB))
 
 	if err != nil {
-		log.Errorf("ImportImage error %s %s %s %s", jobId, image.ImageId, bucketName, err)
+		log.Errorf("ImportImage error %s %s %s %s", jobId, key, bucketName, err)
 		return "", err
 	}
 
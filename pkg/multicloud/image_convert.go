@@ -0,0 +1,336 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multicloud
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/qemuimgfmt"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+)
+
+const (
+	// share of the upload progress used by image conversion
+	imageConvertPercent = 20
+
+	imageSparseBlockSize = 64 * 1024
+	vhdFooterSize        = 512
+
+	// disk type in vhd footer, 2 for fixed, 3 for dynamic and 4 for differencing
+	vhdDiskTypeOffset = 60
+	vhdDiskTypeFixed  = 2
+)
+
+var (
+	qemuImg = "qemu-img"
+
+	qemuImgProgressReg = regexp.MustCompile(`\((\d+(\.\d+)?)/100%\)`)
+
+	// some clouds require a specific subformat, e.g. azure only accepts fixed size vhd
+	// and vmware imports stream optimized vmdk
+	imageConvertOptions = map[qemuimgfmt.TImageFormat]string{
+		qemuimgfmt.VHD:  "subformat=fixed,force_size",
+		qemuimgfmt.VMDK: "subformat=streamOptimized",
+	}
+)
+
+// DetectImageFormat detects qcow2/vmdk/vhd by image header, raw is returned for unknown headers
+func DetectImageFormat(header []byte) qemuimgfmt.TImageFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("QFI\xfb")):
+		return qemuimgfmt.QCOW2
+	case bytes.HasPrefix(header, []byte("KDMV")), bytes.HasPrefix(header, []byte("# Disk DescriptorFile")):
+		return qemuimgfmt.VMDK
+	case bytes.HasPrefix(header, []byte("conectix")):
+		return qemuimgfmt.VHD
+	}
+	return qemuimgfmt.RAW
+}
+
+func isFixedVhd(footer []byte) bool {
+	if !bytes.HasPrefix(footer, []byte("conectix")) || len(footer) < vhdDiskTypeOffset+4 {
+		return false
+	}
+	return binary.BigEndian.Uint32(footer[vhdDiskTypeOffset:]) == vhdDiskTypeFixed
+}
+
+// isFixedVhdFile checks the footer at the end of file, dynamic vhd also keeps a copy of footer at the beginning
+func isFixedVhdFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "Open %s", path)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return false, errors.Wrapf(err, "Stat")
+	}
+	if stat.Size() < vhdFooterSize {
+		return false, nil
+	}
+	footer := make([]byte, vhdFooterSize)
+	_, err = f.ReadAt(footer, stat.Size()-vhdFooterSize)
+	if err != nil {
+		return false, errors.Wrapf(err, "read footer")
+	}
+	return isFixedVhd(footer), nil
+}
+
+// DetectImageFileFormat detects image format of a local file,
+// fixed size vhd only has a footer and is checked at the end of file
+func DetectImageFileFormat(path string) (qemuimgfmt.TImageFormat, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "Open %s", path)
+	}
+	defer f.Close()
+	header := make([]byte, vhdFooterSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", errors.Wrapf(err, "read header")
+	}
+	format := DetectImageFormat(header[:n])
+	if format != qemuimgfmt.RAW {
+		return format, nil
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return "", errors.Wrapf(err, "Stat")
+	}
+	if stat.Size() > vhdFooterSize {
+		_, err = f.ReadAt(header, stat.Size()-vhdFooterSize)
+		if err != nil {
+			return "", errors.Wrapf(err, "read footer")
+		}
+		if DetectImageFormat(header) == qemuimgfmt.VHD {
+			return qemuimgfmt.VHD, nil
+		}
+	}
+	return qemuimgfmt.RAW, nil
+}
+
+// ConvertImageFile converts image file by qemu-img, zero blocks are skipped to keep the output sparse
+func ConvertImageFile(ctx context.Context, src, dest string, format qemuimgfmt.TImageFormat, callback func(float32)) error {
+	args := []string{"convert", "-p", "-S", "4k", "-O", format.String()}
+	if opts, ok := imageConvertOptions[format]; ok {
+		args = append(args, "-o", opts)
+	}
+	args = append(args, src, dest)
+	cmd := exec.CommandContext(ctx, qemuImg, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.Wrapf(err, "StdoutPipe")
+	}
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stderr = stderr
+	err = cmd.Start()
+	if err != nil {
+		return errors.Wrapf(err, "start %s", qemuImg)
+	}
+	// qemu-img refreshes progress with \r
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	for scanner.Scan() {
+		match := qemuImgProgressReg.FindStringSubmatch(scanner.Text())
+		if len(match) > 1 && callback != nil {
+			percent, _ := strconv.ParseFloat(match[1], 32)
+			callback(float32(percent))
+		}
+	}
+	err = cmd.Wait()
+	if err != nil {
+		os.Remove(dest)
+		return errors.Wrapf(err, "%s %v: %s", qemuImg, args, stderr.String())
+	}
+	return nil
+}
+
+// WriteSparse copies reader into file and seeks over zero blocks instead of writing them
+func WriteSparse(f *os.File, reader io.Reader) (int64, error) {
+	buf := make([]byte, imageSparseBlockSize)
+	zero := make([]byte, imageSparseBlockSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			if bytes.Equal(buf[:n], zero[:n]) {
+				_, serr := f.Seek(int64(n), io.SeekCurrent)
+				if serr != nil {
+					return offset, errors.Wrapf(serr, "Seek")
+				}
+			} else {
+				_, werr := f.Write(buf[:n])
+				if werr != nil {
+					return offset, errors.Wrapf(werr, "Write")
+				}
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return offset, err
+		}
+	}
+	// trailing zero blocks are only seeked over, extend file to the real size
+	return offset, f.Truncate(offset)
+}
+
+// SImageReader is the image in the format required by a driver
+type SImageReader struct {
+	io.Reader
+
+	Size   int64
+	Format qemuimgfmt.TImageFormat
+	// Path is the local sparse file of the image when it was spooled, removed by Close
+	Path string
+
+	// Callback reports the remaining upload progress after image conversion
+	Callback func(progress float32)
+
+	closer io.Closer
+	files  []string
+}
+
+func (r *SImageReader) Close() error {
+	if r.closer != nil {
+		r.closer.Close()
+	}
+	for _, file := range r.files {
+		os.Remove(file)
+	}
+	return nil
+}
+
+func scaleProgress(callback func(float32), start, share float32) func(float32) {
+	if callback == nil {
+		return nil
+	}
+	return func(progress float32) {
+		callback(start + progress*share/100)
+	}
+}
+
+// GetImageReader gets image reader from the upload options and converts it to the given format
+// when the image provided by caller is in another format. Images already in the right format are
+// streamed without touching local disk, otherwise the image is spooled to a sparse file under
+// TmpPath and converted by qemu-img. Callers must Close the reader to remove temporary files.
+func GetImageReader(ctx context.Context, image *cloudprovider.SImageCreateOption, format qemuimgfmt.TImageFormat, callback func(float32)) (*SImageReader, error) {
+	reader, size, err := image.GetReader(image.ImageId, string(format))
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetReader")
+	}
+	ret := &SImageReader{
+		Size:     size,
+		Format:   format,
+		Callback: callback,
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		ret.closer = closer
+	}
+	body := bufio.NewReaderSize(reader, vhdFooterSize)
+	header, err := body.Peek(vhdFooterSize)
+	if err != nil && err != io.EOF {
+		ret.Close()
+		return nil, errors.Wrapf(err, "read image header")
+	}
+	// fixed size vhd looks like raw by header, it is checked again by DetectImageFileFormat after spooled,
+	// a vhd header at the beginning means a dynamic vhd which is converted to fixed size
+	if DetectImageFormat(header) == format && (format != qemuimgfmt.VHD || isFixedVhd(header)) {
+		ret.Reader = body
+		return ret, nil
+	}
+
+	tmpDir := image.TmpPath
+	if len(tmpDir) == 0 {
+		tmpDir = os.TempDir()
+	}
+	src, err := ioutil.TempFile(tmpDir, "image-src-")
+	if err != nil {
+		ret.Close()
+		return nil, errors.Wrapf(err, "TempFile")
+	}
+	ret.files = append(ret.files, src.Name())
+	half := float32(imageConvertPercent) / 2
+	_, err = WriteSparse(src, NewProgress(size, int(half), body, callback))
+	src.Close()
+	if err != nil {
+		ret.Close()
+		return nil, errors.Wrapf(err, "save image to %s", src.Name())
+	}
+	srcFormat, err := DetectImageFileFormat(src.Name())
+	if err != nil {
+		ret.Close()
+		return nil, err
+	}
+	needConvert := srcFormat != format
+	if !needConvert && format == qemuimgfmt.VHD {
+		fixed, err := isFixedVhdFile(src.Name())
+		if err != nil {
+			ret.Close()
+			return nil, err
+		}
+		needConvert = !fixed
+	}
+	path := src.Name()
+	if needConvert {
+		log.Infof("convert image %s from %s to %s", image.ImageId, srcFormat, format)
+		path = src.Name() + "." + string(format)
+		ret.files = append(ret.files, path)
+		err = ConvertImageFile(ctx, src.Name(), path, format, scaleProgress(callback, half, half))
+		if err != nil {
+			ret.Close()
+			return nil, errors.Wrapf(err, "ConvertImageFile")
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		ret.Close()
+		return nil, errors.Wrapf(err, "Open %s", path)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		ret.Close()
+		return nil, errors.Wrapf(err, "Stat %s", path)
+	}
+	if ret.closer != nil {
+		ret.closer.Close()
+	}
+	ret.Reader, ret.closer, ret.Size, ret.Path = f, f, stat.Size(), path
+	ret.Callback = scaleProgress(callback, imageConvertPercent, 100-imageConvertPercent)
+	return ret, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multicloud
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yunion.io/x/pkg/util/qemuimgfmt"
+)
+
+func TestDetectImageFormat(t *testing.T) {
+	cases := []struct {
+		header []byte
+		want   qemuimgfmt.TImageFormat
+	}{
+		{[]byte("QFI\xfb\x00\x00\x00\x03"), qemuimgfmt.QCOW2},
+		{[]byte("KDMV\x01\x00\x00\x00"), qemuimgfmt.VMDK},
+		{[]byte("# Disk DescriptorFile\nversion=1"), qemuimgfmt.VMDK},
+		{[]byte("conectix\x00\x00\x00\x02"), qemuimgfmt.VHD},
+		{[]byte("\xeb\x63\x90\x10"), qemuimgfmt.RAW},
+		{[]byte{}, qemuimgfmt.RAW},
+	}
+	for _, c := range cases {
+		if got := DetectImageFormat(c.header); got != c.want {
+			t.Errorf("DetectImageFormat(%q) = %s, want %s", c.header, got, c.want)
+		}
+	}
+}
+
+func TestWriteSparse(t *testing.T) {
+	dir := t.TempDir()
+	data := make([]byte, imageSparseBlockSize*4+100)
+	copy(data[imageSparseBlockSize:], []byte("data"))
+	f, err := os.Create(filepath.Join(dir, "disk"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	n, err := WriteSparse(f, bytes.NewReader(data))
+	f.Close()
+	if err != nil {
+		t.Fatalf("WriteSparse: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("written %d bytes, want %d", n, len(data))
+	}
+	got, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("sparse file content mismatch")
+	}
+
+	// fixed size vhd is detected by its footer
+	vhd := append(make([]byte, 4096), []byte("conectix")...)
+	vhd = append(vhd, make([]byte, vhdFooterSize-8)...)
+	path := filepath.Join(dir, "fixed.vhd")
+	err = ioutil.WriteFile(path, vhd, 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	format, err := DetectImageFileFormat(path)
+	if err != nil {
+		t.Fatalf("DetectImageFileFormat: %v", err)
+	}
+	if format != qemuimgfmt.VHD {
+		t.Errorf("DetectImageFileFormat = %s, want vhd", format)
+	}
+}
+
+func TestIsFixedVhd(t *testing.T) {
+	footer := func(diskType byte) []byte {
+		ret := make([]byte, vhdFooterSize)
+		copy(ret, []byte("conectix"))
+		ret[vhdDiskTypeOffset+3] = diskType
+		return ret
+	}
+	cases := []struct {
+		footer []byte
+		want   bool
+	}{
+		{footer(2), true},
+		{footer(3), false},
+		{footer(4), false},
+		{[]byte("conectix"), false},
+		{make([]byte, vhdFooterSize), false},
+	}
+	for i, c := range cases {
+		if got := isFixedVhd(c.footer); got != c.want {
+			t.Errorf("case %d: isFixedVhd = %v, want %v", i, got, c.want)
+		}
+	}
+}
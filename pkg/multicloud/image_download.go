@@ -0,0 +1,243 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multicloud
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+)
+
+// SImageDownloadOptions describes where an exported image is written to,
+// either a local file or an object in a bucket
+type SImageDownloadOptions struct {
+	Path string
+
+	Bucket cloudprovider.ICloudBucket
+	Key    string
+
+	Callback func(progress float32)
+}
+
+// SImageStream is an image exported by a driver
+type SImageStream struct {
+	Body   io.ReadCloser
+	Size   int64
+	Format string
+
+	// expected checksums, skipped when empty
+	Md5    string
+	Sha256 string
+}
+
+func (opts *SImageDownloadOptions) Validate() error {
+	if opts.Bucket != nil {
+		if len(opts.Key) == 0 {
+			return errors.Wrap(cloudprovider.ErrInputParameter, "missing object key")
+		}
+		return nil
+	}
+	if len(opts.Path) == 0 {
+		return errors.Wrap(cloudprovider.ErrInputParameter, "missing path")
+	}
+	return nil
+}
+
+func (opts *SImageDownloadOptions) progress(p float32) {
+	if opts.Callback != nil {
+		opts.Callback(p)
+	}
+}
+
+type sImageChecksum struct {
+	md5    hash.Hash
+	sha256 hash.Hash
+}
+
+func newImageChecksum() *sImageChecksum {
+	return &sImageChecksum{md5: md5.New(), sha256: sha256.New()}
+}
+
+func (c *sImageChecksum) Write(p []byte) (int, error) {
+	c.md5.Write(p)
+	c.sha256.Write(p)
+	return len(p), nil
+}
+
+func (c *sImageChecksum) verify(stream *SImageStream) (string, string, error) {
+	md5sum := hex.EncodeToString(c.md5.Sum(nil))
+	sha256sum := hex.EncodeToString(c.sha256.Sum(nil))
+	if len(stream.Md5) > 0 && !strings.EqualFold(stream.Md5, md5sum) {
+		return md5sum, sha256sum, fmt.Errorf("md5 mismatch, expect %s got %s", stream.Md5, md5sum)
+	}
+	if len(stream.Sha256) > 0 && !strings.EqualFold(stream.Sha256, sha256sum) {
+		return md5sum, sha256sum, fmt.Errorf("sha256 mismatch, expect %s got %s", stream.Sha256, sha256sum)
+	}
+	return md5sum, sha256sum, nil
+}
+
+// SaveImage writes an exported image to a local path or a bucket, reporting
+// progress and verifying the checksums provided by the driver
+func SaveImage(ctx context.Context, stream *SImageStream, opts *SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	defer stream.Body.Close()
+
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := newImageChecksum()
+	reader := io.TeeReader(NewProgress(stream.Size, 99, &sContextReader{ctx: ctx, reader: stream.Body}, opts.Callback), checksum)
+
+	ret := jsonutils.NewDict()
+	var written int64
+	if opts.Bucket != nil {
+		written, err = saveImageToBucket(ctx, reader, stream.Size, opts)
+	} else {
+		written, err = saveImageToFile(reader, opts.Path)
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil && stream.Size > 0 && written != stream.Size {
+		err = fmt.Errorf("size mismatch, expect %d got %d", stream.Size, written)
+	}
+	if err != nil {
+		// do not leave a truncated image behind
+		removeSavedImage(opts)
+		return nil, errors.Wrapf(err, "save image")
+	}
+
+	md5sum, sha256sum, err := checksum.verify(stream)
+	if err != nil {
+		removeSavedImage(opts)
+		return nil, errors.Wrapf(err, "verify checksum")
+	}
+
+	if opts.Bucket != nil {
+		ret.Add(jsonutils.NewString(opts.Bucket.GetName()), "bucket")
+		ret.Add(jsonutils.NewString(opts.Key), "key")
+	} else {
+		ret.Add(jsonutils.NewString(opts.Path), "path")
+	}
+	opts.progress(100)
+	ret.Add(jsonutils.NewInt(written), "size")
+	ret.Add(jsonutils.NewString(md5sum), "md5")
+	ret.Add(jsonutils.NewString(sha256sum), "sha256")
+	if len(stream.Format) > 0 {
+		ret.Add(jsonutils.NewString(stream.Format), "format")
+	}
+	return ret, nil
+}
+
+func saveImageToFile(reader io.Reader, path string) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "os.Create(%s)", path)
+	}
+	defer file.Close()
+	written, err := io.Copy(file, reader)
+	if err != nil {
+		return written, errors.Wrapf(err, "io.Copy")
+	}
+	return written, nil
+}
+
+// sContextReader stops reading once the context is cancelled
+type sContextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *sContextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+func saveImageToBucket(ctx context.Context, reader io.Reader, size int64, opts *SImageDownloadOptions) (int64, error) {
+	// multipart upload needs the object size, spool to a temporary file when unknown
+	if size <= 0 {
+		tmp, err := ioutil.TempFile("", "image-download")
+		if err != nil {
+			return 0, errors.Wrapf(err, "TempFile")
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		size, err = io.Copy(tmp, reader)
+		if err != nil {
+			return 0, errors.Wrapf(err, "io.Copy")
+		}
+		_, err = tmp.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Seek")
+		}
+		reader = tmp
+	}
+	err := cloudprovider.UploadObject(ctx, opts.Bucket, opts.Key, 0, reader, size, "", "", nil, false)
+	if err != nil {
+		return 0, errors.Wrapf(err, "UploadObject(%s)", opts.Key)
+	}
+	return size, nil
+}
+
+func removeSavedImage(opts *SImageDownloadOptions) {
+	var err error
+	if opts.Bucket != nil {
+		// the caller context may already be cancelled
+		err = opts.Bucket.DeleteObject(context.Background(), opts.Key)
+	} else {
+		err = os.Remove(opts.Path)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		log.Warningf("remove corrupted image error: %v", err)
+	}
+}
+
+// SaveImageFile stores an image already exported to a local file
+func SaveImageFile(ctx context.Context, filename, format string, opts *SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "os.Open(%s)", filename)
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "Stat(%s)", filename)
+	}
+	stream := &SImageStream{
+		Body:   file,
+		Size:   fi.Size(),
+		Format: format,
+	}
+	return SaveImage(ctx, stream, opts)
+}
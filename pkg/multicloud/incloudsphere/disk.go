@@ -115,8 +115,16 @@ func (self *SDisk) CreateISnapshot(ctx context.Context, name, desc string) (clou
 	return nil, cloudprovider.ErrNotSupported
 }
 
+func (self *SDisk) GetTags() (map[string]string, error) {
+	return self.region.GetResourceTags(TAG_TARGET_VOLUME, self.Id)
+}
+
+func (self *SDisk) SetTags(tags map[string]string, replace bool) error {
+	return self.region.SetResourceTags(TAG_TARGET_VOLUME, self.Id, tags, replace)
+}
+
 func (self *SDisk) Delete(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	return self.region.DeleteDisk(self.Id)
 }
 
 func (self *SDisk) GetCacheMode() string {
@@ -244,3 +252,7 @@ func (self *SRegion) CreateDisk(name, storageId string, sizeGb int) (*SDisk, err
 	}
 	return self.GetDisk(diskId)
 }
+
+func (self *SRegion) DeleteDisk(id string) error {
+	return self.del("/volumes/"+id, url.Values{}, nil)
+}
@@ -373,7 +373,17 @@ func (self *SHost) CreateVM(opts *cloudprovider.SManagedVMCreateConfig) (cloudpr
 }
 
 func (self *SHost) GetIHostNics() ([]cloudprovider.ICloudHostNetInterface, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	nics, err := self.zone.region.GetHostNics(self.Id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetHostNics")
+	}
+	ret := []cloudprovider.ICloudHostNetInterface{}
+	for i := range nics {
+		nics[i].host = self
+		nics[i].index = int8(i)
+		ret = append(ret, &nics[i])
+	}
+	return ret, nil
 }
 
 func (self *SHost) GetIVMs() ([]cloudprovider.ICloudVM, error) {
@@ -0,0 +1,112 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incloudsphere
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"yunion.io/x/pkg/tristate"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+)
+
+type SHostNic struct {
+	host  *SHost
+	index int8
+
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Mac         string `json:"mac"`
+	Driver      string `json:"driver"`
+	Model       string `json:"model"`
+	Status      string `json:"status"`
+	LinkStatus  string `json:"linkStatus"`
+	Speed       string `json:"speed"`
+	Mtu         int32  `json:"mtu"`
+	IP          string `json:"ip"`
+	Netmask     string `json:"netmask"`
+	VswitchId   string `json:"vswitchId"`
+	VswitchName string `json:"vswitchName"`
+	HostId      string `json:"hostId"`
+	Management  bool   `json:"management"`
+}
+
+func (self *SHostNic) GetDevice() string {
+	return self.Name
+}
+
+func (self *SHostNic) GetDriver() string {
+	return strings.ToLower(self.Driver)
+}
+
+func (self *SHostNic) GetMac() string {
+	return strings.ToLower(self.Mac)
+}
+
+func (self *SHostNic) GetIndex() int8 {
+	return self.index
+}
+
+func (self *SHostNic) IsLinkUp() tristate.TriState {
+	status := self.LinkStatus
+	if len(status) == 0 {
+		status = self.Status
+	}
+	switch strings.ToUpper(status) {
+	case "UP", "CONNECTED", "OK":
+		return tristate.True
+	case "DOWN", "DISCONNECTED":
+		return tristate.False
+	}
+	return tristate.None
+}
+
+func (self *SHostNic) GetIpAddr() string {
+	if len(self.IP) > 0 {
+		return self.IP
+	}
+	if self.isManagement() {
+		return self.host.IP
+	}
+	return ""
+}
+
+func (self *SHostNic) GetMtu() int32 {
+	return self.Mtu
+}
+
+func (self *SHostNic) isManagement() bool {
+	return self.Management || (len(self.IP) > 0 && self.IP == self.host.IP)
+}
+
+func (self *SHostNic) GetNicType() string {
+	if self.isManagement() {
+		return api.NIC_TYPE_ADMIN
+	}
+	return ""
+}
+
+// 物理网卡上联的分布式交换机
+func (self *SHostNic) GetBridge() string {
+	return self.VswitchId
+}
+
+func (self *SRegion) GetHostNics(hostId string) ([]SHostNic, error) {
+	ret := []SHostNic{}
+	res := fmt.Sprintf("/hosts/%s/pnics", hostId)
+	return ret, self.get(res, url.Values{}, &ret)
+}
@@ -61,7 +61,7 @@ func (self *SImage) GetName() string {
 }
 
 func (self *SImage) Delete(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	return self.cache.zone.region.DeleteImage(self.DataStoreID, self.Path, self.Name)
 }
 
 func (self *SImage) GetGlobalId() string {
@@ -296,6 +296,14 @@ func (self *SInstance) ChangeConfig(ctx context.Context, opts *cloudprovider.SMa
 	return self.host.zone.region.ChangeConfig(self.Id, opts.Cpu, opts.MemoryMB)
 }
 
+func (self *SInstance) GetTags() (map[string]string, error) {
+	return self.host.zone.region.GetResourceTags(TAG_TARGET_VM, self.Id)
+}
+
+func (self *SInstance) SetTags(tags map[string]string, replace bool) error {
+	return self.host.zone.region.SetResourceTags(TAG_TARGET_VM, self.Id, tags, replace)
+}
+
 func (self *SInstance) DeleteVM(ctx context.Context) error {
 	return self.host.zone.region.DeleteVM(self.Id)
 }
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/netutils"
 	"yunion.io/x/pkg/util/rbacscope"
 
@@ -98,8 +99,16 @@ func (self *SNetwork) Refresh() error {
 	return jsonutils.Update(self, ret)
 }
 
+func (self *SNetwork) GetTags() (map[string]string, error) {
+	return self.wire.region.GetResourceTags(TAG_TARGET_NETWORK, self.Id)
+}
+
+func (self *SNetwork) SetTags(tags map[string]string, replace bool) error {
+	return self.wire.region.SetResourceTags(TAG_TARGET_NETWORK, self.Id, tags, replace)
+}
+
 func (self *SNetwork) Delete() error {
-	return cloudprovider.ErrNotFound
+	return self.wire.region.DeleteNetwork(self.Id)
 }
 
 func (self *SNetwork) GetAllocTimeoutSeconds() int {
@@ -182,3 +191,41 @@ func (self *SRegion) GetNetwork(id string) (*SNetwork, error) {
 	res := fmt.Sprintf("/networks/%s", id)
 	return ret, self.get(res, url.Values{}, ret)
 }
+
+func (self *SRegion) CreateNetwork(vsId string, opts *cloudprovider.SNetworkCreateOptions) (*SNetwork, error) {
+	prefix, err := netutils.NewIPV4Prefix(opts.Cidr)
+	if err != nil {
+		return nil, errors.Wrapf(cloudprovider.ErrInputParameter, "invalid cidr %s", opts.Cidr)
+	}
+	gateway := prefix.Address.NetAddr(prefix.MaskLen).StepUp()
+	body := map[string]interface{}{
+		"name":        opts.Name,
+		"vswitchId":   vsId,
+		"vlanFlag":    false,
+		"mtu":         1500,
+		"connectMode": "BRIDGE",
+		"cidr":        prefix.String(),
+		"gateway":     gateway.String(),
+		"dhcpEnabled": false,
+		// 未开启dhcp, 描述信息里面放置<gateway>/<netmask>
+		"description": fmt.Sprintf("%s/%d", gateway.String(), prefix.MaskLen),
+	}
+	res := fmt.Sprintf("/vswitchs/%s/networks", vsId)
+	resp, err := self.post(res, jsonutils.Marshal(body))
+	if err != nil {
+		return nil, err
+	}
+	taskId, err := resp.GetString("taskId")
+	if err != nil {
+		return nil, err
+	}
+	netId, err := self.client.waitTask(taskId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "waitTask(%s)", taskId)
+	}
+	return self.GetNetwork(netId)
+}
+
+func (self *SRegion) DeleteNetwork(id string) error {
+	return self.del("/networks/"+id, url.Values{}, nil)
+}
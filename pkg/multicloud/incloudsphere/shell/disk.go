@@ -70,4 +70,8 @@ func init() {
 		return nil
 	})
 
+	shellutils.R(&DiskIdOptions{}, "disk-delete", "delete disk", func(cli *incloudsphere.SRegion, args *DiskIdOptions) error {
+		return cli.DeleteDisk(args.ID)
+	})
+
 }
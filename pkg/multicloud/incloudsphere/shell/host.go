@@ -46,4 +46,13 @@ func init() {
 		return nil
 	})
 
+	shellutils.R(&HostIdOptions{}, "host-nic-list", "list host physical nics", func(cli *incloudsphere.SRegion, args *HostIdOptions) error {
+		nics, err := cli.GetHostNics(args.ID)
+		if err != nil {
+			return err
+		}
+		printList(nics, 0, 0, 0, []string{})
+		return nil
+	})
+
 }
@@ -17,6 +17,7 @@ package shell
 import (
 	"yunion.io/x/pkg/util/shellutils"
 
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud/incloudsphere"
 )
 
@@ -46,4 +47,41 @@ func init() {
 		return nil
 	})
 
+	shellutils.R(&NetworkIdOptions{}, "network-delete", "delete network", func(cli *incloudsphere.SRegion, args *NetworkIdOptions) error {
+		return cli.DeleteNetwork(args.ID)
+	})
+
+	type NetworkCreateOptions struct {
+		VSWITCH_ID string
+		NAME       string
+		CIDR       string
+	}
+
+	shellutils.R(&NetworkCreateOptions{}, "network-create", "create network", func(cli *incloudsphere.SRegion, args *NetworkCreateOptions) error {
+		opts := &cloudprovider.SNetworkCreateOptions{
+			Name: args.NAME,
+			Cidr: args.CIDR,
+		}
+		ret, err := cli.CreateNetwork(args.VSWITCH_ID, opts)
+		if err != nil {
+			return err
+		}
+		printObject(ret)
+		return nil
+	})
+
+	type TagListOptions struct {
+		TARGET_TYPE string `choices:"vms|volumes|networks"`
+		TARGET_ID   string
+	}
+
+	shellutils.R(&TagListOptions{}, "resource-tag-list", "list resource tags", func(cli *incloudsphere.SRegion, args *TagListOptions) error {
+		tags, err := cli.GetResourceTagList(args.TARGET_TYPE, args.TARGET_ID)
+		if err != nil {
+			return err
+		}
+		printList(tags, 0, 0, 0, []string{})
+		return nil
+	})
+
 }
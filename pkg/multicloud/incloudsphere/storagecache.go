@@ -123,3 +123,11 @@ func (self *SRegion) GetImageList(storageId string) ([]SImage, error) {
 	ret := []SImage{}
 	return ret, self.list(res, url.Values{}, &ret)
 }
+
+func (self *SRegion) DeleteImage(storageId, path, name string) error {
+	params := url.Values{}
+	params.Set("path", path)
+	params.Set("name", name)
+	res := fmt.Sprintf("/storages/%s/files", storageId)
+	return self.del(res, params, nil)
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incloudsphere
+
+import (
+	"fmt"
+	"net/url"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+)
+
+const (
+	TAG_TARGET_VM      = "vms"
+	TAG_TARGET_VOLUME  = "volumes"
+	TAG_TARGET_NETWORK = "networks"
+)
+
+type STag struct {
+	Id          string `json:"id"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+func (self *SRegion) GetTagList(key, value string) ([]STag, error) {
+	params := url.Values{}
+	if len(key) > 0 {
+		params.Set("key", key)
+	}
+	if len(value) > 0 {
+		params.Set("value", value)
+	}
+	ret := []STag{}
+	return ret, self.list("/tags", params, &ret)
+}
+
+func (self *SRegion) GetResourceTagList(targetType, targetId string) ([]STag, error) {
+	ret := []STag{}
+	res := fmt.Sprintf("/%s/%s/tags", targetType, targetId)
+	return ret, self.get(res, url.Values{}, &ret)
+}
+
+func (self *SRegion) CreateTag(key, value string) (*STag, error) {
+	body := map[string]string{
+		"key":   key,
+		"value": value,
+	}
+	resp, err := self.post("/tags", jsonutils.Marshal(body))
+	if err != nil {
+		return nil, err
+	}
+	ret := &STag{}
+	return ret, resp.Unmarshal(ret)
+}
+
+// 查找已存在的标签, 不存在时创建
+func (self *SRegion) getOrCreateTag(key, value string) (*STag, error) {
+	tags, err := self.GetTagList(key, value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetTagList")
+	}
+	for i := range tags {
+		if tags[i].Key == key && tags[i].Value == value {
+			return &tags[i], nil
+		}
+	}
+	return self.CreateTag(key, value)
+}
+
+func (self *SRegion) bindTag(action, tagId, targetType, targetId string) error {
+	params := url.Values{}
+	params.Set("action", action)
+	body := map[string]interface{}{
+		"targetType": targetType,
+		"targetIds":  []string{targetId},
+	}
+	return self.put("/tags/"+tagId, params, jsonutils.Marshal(body), nil)
+}
+
+func (self *SRegion) GetResourceTags(targetType, targetId string) (map[string]string, error) {
+	tags, err := self.GetResourceTagList(targetType, targetId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetResourceTagList(%s, %s)", targetType, targetId)
+	}
+	ret := map[string]string{}
+	for _, tag := range tags {
+		ret[tag.Key] = tag.Value
+	}
+	return ret, nil
+}
+
+func (self *SRegion) SetResourceTags(targetType, targetId string, tags map[string]string, replace bool) error {
+	oldTags, err := self.GetResourceTagList(targetType, targetId)
+	if err != nil {
+		return errors.Wrapf(err, "GetResourceTagList(%s, %s)", targetType, targetId)
+	}
+	exists := map[string]bool{}
+	for _, tag := range oldTags {
+		value, ok := tags[tag.Key]
+		if ok && value == tag.Value {
+			exists[tag.Key] = true
+			continue
+		}
+		if ok || replace {
+			err = self.bindTag("unbind", tag.Id, targetType, targetId)
+			if err != nil {
+				return errors.Wrapf(err, "unbind tag %s", tag.Key)
+			}
+		}
+	}
+	for k, v := range tags {
+		if exists[k] {
+			continue
+		}
+		tag, err := self.getOrCreateTag(k, v)
+		if err != nil {
+			return errors.Wrapf(err, "getOrCreateTag(%s, %s)", k, v)
+		}
+		err = self.bindTag("bind", tag.Id, targetType, targetId)
+		if err != nil {
+			return errors.Wrapf(err, "bind tag %s", k)
+		}
+	}
+	return nil
+}
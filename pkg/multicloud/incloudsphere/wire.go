@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"net/url"
 
+	"yunion.io/x/pkg/errors"
+
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
@@ -83,7 +85,12 @@ func (self *SWire) GetGlobalId() string {
 }
 
 func (self *SWire) CreateINetwork(opts *cloudprovider.SNetworkCreateOptions) (cloudprovider.ICloudNetwork, error) {
-	return nil, cloudprovider.ErrNotSupported
+	net, err := self.region.CreateNetwork(self.Id, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateNetwork")
+	}
+	net.wire = self
+	return net, nil
 }
 
 func (self *SWire) GetBandwidth() int {
@@ -24,6 +24,9 @@ import (
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/disk/client"
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/disk/models"
 
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/utils"
+
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
@@ -92,6 +95,11 @@ func (d *SDisk) GetStatus() string {
 }
 
 func (d *SDisk) Refresh() error {
+	disk, err := d.storage.zone.region.GetDiskById(d.DiskId)
+	if err != nil {
+		return err
+	}
+	d.Disk = disk.Disk
 	return nil
 }
 
@@ -106,7 +114,7 @@ func (d *SDisk) GetSysTags() map[string]string {
 }
 
 func (d *SDisk) GetProjectId() string {
-	return ""
+	return d.storage.zone.region.client.getResourceGroupId(d.DiskId)
 }
 
 func (d *SDisk) GetIStorage() (cloudprovider.ICloudStorage, error) {
@@ -165,11 +173,25 @@ func (d *SDisk) GetAccessPath() string {
 }
 
 func (d *SDisk) Delete(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	err := d.storage.zone.region.DeleteDisk(d.DiskId)
+	if err != nil {
+		return errors.Wrapf(err, "DeleteDisk")
+	}
+	return cloudprovider.WaitDeleted(d, 5*time.Second, 180*time.Second)
 }
 
 func (d *SDisk) CreateISnapshot(ctx context.Context, name string, desc string) (cloudprovider.ICloudSnapshot, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	region := d.storage.zone.region
+	id, err := region.CreateSnapshot(d.DiskId, name, desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateSnapshot")
+	}
+	snapshot, err := region.GetSnapshotById(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetSnapshotById(%s)", id)
+	}
+	snapshot.disk = d
+	return snapshot, nil
 }
 
 func (d *SDisk) GetISnapshot(id string) (cloudprovider.ICloudSnapshot, error) {
@@ -208,11 +230,19 @@ func (s *SDisk) GetExtSnapshotPolicyIds() ([]string, error) {
 }
 
 func (s *SDisk) Resize(ctx context.Context, newSizeMB int64) error {
-	return cloudprovider.ErrNotImplemented
+	err := s.storage.zone.region.ResizeDisk(s.DiskId, int(newSizeMB/1024))
+	if err != nil {
+		return errors.Wrapf(err, "ResizeDisk")
+	}
+	return cloudprovider.WaitStatusWithDelay(s, api.DISK_READY, 5*time.Second, 5*time.Second, 180*time.Second)
 }
 
 func (s *SDisk) Reset(ctx context.Context, snapshotId string) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	err := s.storage.zone.region.ResetDisk(s.DiskId, snapshotId)
+	if err != nil {
+		return "", errors.Wrapf(err, "ResetDisk")
+	}
+	return s.DiskId, cloudprovider.WaitStatusWithDelay(s, api.DISK_READY, 5*time.Second, 5*time.Second, 300*time.Second)
 }
 
 func (s *SDisk) Rebuild(ctx context.Context) error {
@@ -277,9 +307,81 @@ func (r *SRegion) GetDiskById(id string) (*SDisk, error) {
 		return nil, err
 	}
 	if resp.Error.Code >= 400 {
+		if resp.Error.Code == 404 {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, resp.Error.Message)
+		}
 		return nil, fmt.Errorf(resp.Error.Message)
 	}
 	return &SDisk{
 		Disk: resp.Result.Disk,
 	}, nil
 }
+
+func (r *SRegion) CreateDisk(zoneId, diskType, name string, sizeGb int, desc string) (string, error) {
+	spec := &models.DiskSpec{
+		Az:         zoneId,
+		Name:       name,
+		DiskType:   diskType,
+		DiskSizeGB: sizeGb,
+		Charge:     chargeSpec(nil),
+	}
+	if len(desc) > 0 {
+		spec.Description = &desc
+	}
+	req := apis.NewCreateDisksRequest(r.ID, spec, 1, utils.GenRequestId(20))
+	client := client.NewDiskClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.CreateDisks(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error.Code >= 400 {
+		return "", fmt.Errorf(resp.Error.Message)
+	}
+	if len(resp.Result.DiskIds) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "no disk created")
+	}
+	return resp.Result.DiskIds[0], nil
+}
+
+func (r *SRegion) DeleteDisk(id string) error {
+	req := apis.NewDeleteDiskRequest(r.ID, id)
+	client := client.NewDiskClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DeleteDisk(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) ResizeDisk(id string, sizeGb int) error {
+	req := apis.NewExtendDiskRequest(r.ID, id, sizeGb)
+	client := client.NewDiskClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.ExtendDisk(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) ResetDisk(id, snapshotId string) error {
+	req := apis.NewRestoreDiskRequest(r.ID, id, snapshotId)
+	client := client.NewDiskClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.RestoreDisk(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
@@ -18,10 +18,13 @@ import (
 	"fmt"
 	"time"
 
+	chargemodels "github.com/jdcloud-api/jdcloud-sdk-go/services/charge/models"
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/vpc/apis"
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/vpc/client"
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/vpc/models"
 
+	"yunion.io/x/pkg/errors"
+
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
@@ -52,6 +55,11 @@ func (e *SEip) GetStatus() string {
 }
 
 func (e *SEip) Refresh() error {
+	eip, err := e.region.GetEIPById(e.ElasticIpId)
+	if err != nil {
+		return err
+	}
+	e.ElasticIp = eip.ElasticIp
 	return nil
 }
 
@@ -95,7 +103,7 @@ func (e *SEip) GetExpiredAt() time.Time {
 }
 
 func (e *SEip) Delete() error {
-	return nil
+	return e.region.DeleteEip(e.ElasticIpId)
 }
 
 func (e *SEip) GetBandwidth() int {
@@ -118,19 +126,45 @@ func (e *SEip) GetInternetChargeType() string {
 }
 
 func (e *SEip) Associate(conf *cloudprovider.AssociateConfig) error {
-	return nil
+	err := e.region.AssociateEip(conf.InstanceId, e.ElasticIpId)
+	if err != nil {
+		return errors.Wrapf(err, "AssociateEip")
+	}
+	return cloudprovider.Wait(5*time.Second, 60*time.Second, func() (bool, error) {
+		err := e.Refresh()
+		if err != nil {
+			return false, err
+		}
+		return e.InstanceId == conf.InstanceId, nil
+	})
 }
 
 func (e *SEip) Dissociate() error {
-	return nil
+	if len(e.InstanceId) == 0 {
+		return nil
+	}
+	if e.InstanceType != "compute" {
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "dissociate from %s", e.InstanceType)
+	}
+	err := e.region.DissociateEip(e.InstanceId, e.ElasticIpId)
+	if err != nil {
+		return errors.Wrapf(err, "DissociateEip")
+	}
+	return cloudprovider.Wait(5*time.Second, 60*time.Second, func() (bool, error) {
+		err := e.Refresh()
+		if err != nil {
+			return false, err
+		}
+		return len(e.InstanceId) == 0, nil
+	})
 }
 
 func (e *SEip) ChangeBandwidth(bw int) error {
-	return nil
+	return e.region.ChangeEipBandwidth(e.ElasticIpId, bw)
 }
 
 func (e *SEip) GetProjectId() string {
-	return ""
+	return e.region.client.getResourceGroupId(e.ElasticIpId)
 }
 
 func (r *SRegion) GetEIPById(id string) (*SEip, error) {
@@ -142,6 +176,9 @@ func (r *SRegion) GetEIPById(id string) (*SEip, error) {
 		return nil, err
 	}
 	if resp.Error.Code >= 400 {
+		if resp.Error.Code == 404 {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, resp.Error.Message)
+		}
 		return nil, fmt.Errorf(resp.Error.Message)
 	}
 	return &SEip{
@@ -149,3 +186,86 @@ func (r *SRegion) GetEIPById(id string) (*SEip, error) {
 		ElasticIp: resp.Result.ElasticIp,
 	}, nil
 }
+
+func (r *SRegion) GetEips(pageNumber, pageSize int) ([]SEip, int, error) {
+	req := apis.NewDescribeElasticIpsRequest(r.ID)
+	req.SetPageNumber(pageNumber)
+	req.SetPageSize(pageSize)
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DescribeElasticIps(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.Error.Code >= 400 {
+		return nil, 0, fmt.Errorf(resp.Error.Message)
+	}
+	eips := make([]SEip, len(resp.Result.ElasticIps))
+	for i := range eips {
+		eips[i] = SEip{
+			region:    r,
+			ElasticIp: resp.Result.ElasticIps[i],
+		}
+	}
+	return eips, resp.Result.TotalCount, nil
+}
+
+func (r *SRegion) CreateEip(opts *cloudprovider.SEip) (string, error) {
+	mode := "postpaid_by_duration"
+	if opts.ChargeType == api.EIP_CHARGE_TYPE_BY_TRAFFIC {
+		mode = "postpaid_by_usage"
+	}
+	provider := opts.BGPType
+	if len(provider) == 0 {
+		provider = "bgp"
+	}
+	spec := &models.ElasticIpSpec{
+		BandwidthMbps: opts.BandwidthMbps,
+		Provider:      provider,
+		ChargeSpec: &chargemodels.ChargeSpec{
+			ChargeMode: &mode,
+		},
+	}
+	req := apis.NewCreateElasticIpsRequest(r.ID, 1, spec)
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.CreateElasticIps(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error.Code >= 400 {
+		return "", fmt.Errorf(resp.Error.Message)
+	}
+	if len(resp.Result.ElasticIpIds) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "no eip created")
+	}
+	return resp.Result.ElasticIpIds[0], nil
+}
+
+func (r *SRegion) DeleteEip(id string) error {
+	req := apis.NewDeleteElasticIpRequest(r.ID, id)
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DeleteElasticIp(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) ChangeEipBandwidth(id string, bw int) error {
+	req := apis.NewModifyElasticIpRequest(r.ID, id, bw)
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.ModifyElasticIp(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
@@ -17,6 +17,11 @@ package jdcloud
 import (
 	"fmt"
 
+	chargemodels "github.com/jdcloud-api/jdcloud-sdk-go/services/charge/models"
+	diskmodels "github.com/jdcloud-api/jdcloud-sdk-go/services/disk/models"
+	"github.com/jdcloud-api/jdcloud-sdk-go/services/vm/models"
+	vpcmodels "github.com/jdcloud-api/jdcloud-sdk-go/services/vpc/models"
+
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/sets"
@@ -179,9 +184,105 @@ func (h *SHost) GetVersion() string {
 }
 
 func (h *SHost) CreateVM(desc *cloudprovider.SManagedVMCreateConfig) (cloudprovider.ICloudVM, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	region := h.zone.region
+	spec := &models.InstanceSpec{
+		Az:           &h.zone.ID,
+		InstanceType: &desc.InstanceType,
+		ImageId:      &desc.ExternalImageId,
+		Name:         desc.Name,
+		Charge:       chargeSpec(desc.BillingCycle),
+	}
+	if len(desc.Hostname) > 0 {
+		spec.Hostname = &desc.Hostname
+	}
+	if len(desc.Description) > 0 {
+		spec.Description = &desc.Description
+	}
+	if len(desc.Password) > 0 {
+		spec.Password = &desc.Password
+	}
+	if len(desc.PublicKey) > 0 {
+		keyName, err := region.syncKeypair(desc.PublicKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "syncKeypair")
+		}
+		spec.KeyNames = []string{keyName}
+	}
+	userData, err := desc.GetUserData()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetUserData")
+	}
+	if len(userData) > 0 {
+		key := "launch-script"
+		spec.Userdata = []models.Userdata{{Key: &key, Value: &userData}}
+	}
+
+	deviceIndex := 1
+	nic := &vpcmodels.NetworkInterfaceSpec{
+		SubnetId:       desc.ExternalNetworkId,
+		Az:             &h.zone.ID,
+		SecurityGroups: desc.ExternalSecgroupIds,
+	}
+	if len(desc.IpAddr) > 0 {
+		nic.PrimaryIpAddress = &desc.IpAddr
+	}
+	spec.PrimaryNetworkInterface = &models.InstanceNetworkInterfaceAttachmentSpec{
+		DeviceIndex:      &deviceIndex,
+		NetworkInterface: nic,
+	}
+
+	if desc.PublicIpBw > 0 {
+		eipCharge := spec.Charge
+		if desc.PublicIpChargeType == cloudprovider.ElasticipChargeTypeByTraffic {
+			mode := "postpaid_by_usage"
+			eipCharge = &chargemodels.ChargeSpec{ChargeMode: &mode}
+		}
+		spec.ElasticIp = &vpcmodels.ElasticIpSpec{
+			BandwidthMbps: desc.PublicIpBw,
+			Provider:      "bgp",
+			ChargeSpec:    eipCharge,
+		}
+	}
+
+	category, autoDelete := "cloud", true
+	spec.SystemDisk = &models.InstanceDiskAttachmentSpec{
+		DiskCategory: &category,
+		AutoDelete:   &autoDelete,
+		CloudDiskSpec: &diskmodels.DiskSpec{
+			Az:         h.zone.ID,
+			Name:       desc.Name,
+			DiskType:   desc.SysDisk.StorageType,
+			DiskSizeGB: desc.SysDisk.SizeGB,
+			Charge:     spec.Charge,
+		},
+	}
+	for i, disk := range desc.DataDisks {
+		spec.DataDisks = append(spec.DataDisks, models.InstanceDiskAttachmentSpec{
+			DiskCategory: &category,
+			AutoDelete:   &autoDelete,
+			CloudDiskSpec: &diskmodels.DiskSpec{
+				Az:         h.zone.ID,
+				Name:       fmt.Sprintf("%s-%d", desc.Name, i+1),
+				DiskType:   disk.StorageType,
+				DiskSizeGB: disk.SizeGB,
+				Charge:     spec.Charge,
+			},
+		})
+	}
+
+	id, err := region.CreateInstance(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateInstance")
+	}
+	vm, err := region.GetInstanceById(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetInstanceById(%s)", id)
+	}
+	vm.host = h
+	return vm, nil
 }
 
+// 公有云虚拟宿主机, 无法获取物理网卡信息
 func (h *SHost) GetIHostNics() ([]cloudprovider.ICloudHostNetInterface, error) {
-	return nil, cloudprovider.ErrNotSupported
+	return nil, errors.Wrap(cloudprovider.ErrNotSupported, "physical nics of jdcloud virtual host")
 }
@@ -98,6 +98,13 @@ func (i *SInstance) GetStatus() string {
 }
 
 func (i *SInstance) Refresh() error {
+	instance, err := i.host.zone.region.GetInstanceById(i.InstanceId)
+	if err != nil {
+		return err
+	}
+	i.image = nil
+	i.instanceType = nil
+	i.Instance = instance.Instance
 	return nil
 }
 
@@ -194,7 +201,7 @@ func (i *SInstance) GetInstanceType() string {
 }
 
 func (in *SInstance) GetProjectId() string {
-	return ""
+	return in.host.zone.region.client.getResourceGroupId(in.InstanceId)
 }
 
 func (in *SInstance) GetIHost() cloudprovider.ICloudHost {
@@ -306,11 +313,20 @@ func (in *SInstance) GetVmemSizeMB() int {
 }
 
 func (in *SInstance) AssignSecurityGroup(id string) error {
-	return cloudprovider.ErrNotImplemented
+	ids := []string{}
+	for _, sg := range in.PrimaryNetworkInterface.NetworkInterface.SecurityGroups {
+		if sg.GroupId == id {
+			return nil
+		}
+		ids = append(ids, sg.GroupId)
+	}
+	ids = append(ids, id)
+	return in.SetSecurityGroups(ids)
 }
 
 func (in *SInstance) SetSecurityGroups(ids []string) error {
-	return cloudprovider.ErrNotImplemented
+	nicId := in.PrimaryNetworkInterface.NetworkInterface.NetworkInterfaceId
+	return in.host.zone.region.SetNetworkInterfaceSecurityGroups(nicId, ids)
 }
 
 func (in *SInstance) GetHypervisor() string {
@@ -318,15 +334,27 @@ func (in *SInstance) GetHypervisor() string {
 }
 
 func (in *SInstance) StartVM(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	err := in.host.zone.region.StartInstance(in.InstanceId)
+	if err != nil {
+		return errors.Wrapf(err, "StartInstance")
+	}
+	return cloudprovider.WaitStatus(in, api.VM_RUNNING, 5*time.Second, 300*time.Second)
 }
 
 func (in *SInstance) StopVM(ctx context.Context, opts *cloudprovider.ServerStopOptions) error {
-	return cloudprovider.ErrNotImplemented
+	err := in.host.zone.region.StopInstance(in.InstanceId, opts.StopCharging)
+	if err != nil {
+		return errors.Wrapf(err, "StopInstance")
+	}
+	return cloudprovider.WaitStatus(in, api.VM_READY, 5*time.Second, 300*time.Second)
 }
 
 func (in *SInstance) DeleteVM(ctx context.Context) error {
-	return cloudprovider.ErrNotImplemented
+	err := in.host.zone.region.DeleteInstance(in.InstanceId)
+	if err != nil {
+		return errors.Wrapf(err, "DeleteInstance")
+	}
+	return cloudprovider.WaitDeleted(in, 10*time.Second, 300*time.Second)
 }
 
 func (in *SInstance) UpdateVM(ctx context.Context, name string) error {
@@ -338,15 +366,58 @@ func (in *SInstance) UpdateUserData(userData string) error {
 }
 
 func (self *SInstance) RebuildRoot(ctx context.Context, config *cloudprovider.SManagedVMRebuildRootConfig) (string, error) {
-	return "", cloudprovider.ErrNotImplemented
+	region := self.host.zone.region
+	keyName := ""
+	if len(config.PublicKey) > 0 {
+		var err error
+		keyName, err = region.syncKeypair(config.PublicKey)
+		if err != nil {
+			return "", errors.Wrapf(err, "syncKeypair")
+		}
+	}
+	err := region.RebuildInstance(self.InstanceId, config.ImageId, config.Password, keyName)
+	if err != nil {
+		return "", errors.Wrapf(err, "RebuildInstance")
+	}
+	err = cloudprovider.WaitStatusWithDelay(self, api.VM_READY, 10*time.Second, 5*time.Second, 600*time.Second)
+	if err != nil {
+		return "", errors.Wrapf(err, "wait rebuild")
+	}
+	if self.SystemDisk.DiskCategory == "local" {
+		return "", nil
+	}
+	return self.SystemDisk.CloudDisk.DiskId, nil
 }
 
 func (self *SInstance) DeployVM(ctx context.Context, name string, username string, password string, publicKey string, deleteKeypair bool, description string) error {
-	return cloudprovider.ErrNotImplemented
+	region := self.host.zone.region
+	if len(name) > 0 || len(description) > 0 {
+		err := region.ModifyInstanceAttribute(self.InstanceId, name, description)
+		if err != nil {
+			return errors.Wrapf(err, "ModifyInstanceAttribute")
+		}
+	}
+	if len(password) > 0 {
+		err := region.ModifyInstancePassword(self.InstanceId, password)
+		if err != nil {
+			return errors.Wrapf(err, "ModifyInstancePassword")
+		}
+	}
+	if len(publicKey) > 0 || deleteKeypair {
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "keypair can only be changed by rebuilding root")
+	}
+	return nil
 }
 
 func (in *SInstance) ChangeConfig(ctx context.Context, config *cloudprovider.SManagedVMChangeConfig) error {
-	return cloudprovider.ErrNotImplemented
+	if len(config.InstanceType) == 0 || config.InstanceType == in.InstanceType {
+		return nil
+	}
+	err := in.host.zone.region.ResizeInstance(in.InstanceId, config.InstanceType)
+	if err != nil {
+		return errors.Wrapf(err, "ResizeInstance")
+	}
+	return cloudprovider.WaitStatusWithDelay(in, api.VM_READY, 10*time.Second, 5*time.Second, 300*time.Second)
 }
 
 func (in *SInstance) GetVNCInfo(input *cloudprovider.ServerVncInput) (*cloudprovider.ServerVncOutput, error) {
@@ -369,11 +440,37 @@ func (in *SInstance) GetVNCInfo(input *cloudprovider.ServerVncInput) (*cloudprov
 }
 
 func (in *SInstance) AttachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotImplemented
+	region := in.host.zone.region
+	err := region.AttachDisk(in.InstanceId, diskId)
+	if err != nil {
+		return errors.Wrapf(err, "AttachDisk")
+	}
+	disk, err := region.GetDiskById(diskId)
+	if err != nil {
+		return errors.Wrapf(err, "GetDiskById")
+	}
+	disk.storage, err = in.host.zone.getStorageByType(disk.DiskType)
+	if err != nil {
+		return errors.Wrapf(err, "getStorageByType(%s)", disk.DiskType)
+	}
+	return cloudprovider.WaitStatusWithDelay(disk, api.DISK_READY, 5*time.Second, 5*time.Second, 180*time.Second)
 }
 
 func (in *SInstance) DetachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotImplemented
+	region := in.host.zone.region
+	err := region.DetachDisk(in.InstanceId, diskId)
+	if err != nil {
+		return errors.Wrapf(err, "DetachDisk")
+	}
+	disk, err := region.GetDiskById(diskId)
+	if err != nil {
+		return errors.Wrapf(err, "GetDiskById")
+	}
+	disk.storage, err = in.host.zone.getStorageByType(disk.DiskType)
+	if err != nil {
+		return errors.Wrapf(err, "getStorageByType(%s)", disk.DiskType)
+	}
+	return cloudprovider.WaitStatusWithDelay(disk, api.DISK_READY, 5*time.Second, 5*time.Second, 180*time.Second)
 }
 
 func (self *SInstance) Renew(bc billing.SBillingCycle) error {
@@ -425,6 +522,12 @@ func (r *SRegion) GetInstanceById(id string) (*SInstance, error) {
 	if err != nil {
 		return nil, err
 	}
+	if resp.Error.Code >= 400 {
+		if resp.Error.Code == 404 {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, resp.Error.Message)
+		}
+		return nil, fmt.Errorf(resp.Error.Message)
+	}
 	return &SInstance{
 		Instance: resp.Result.Instance,
 	}, nil
@@ -441,3 +544,192 @@ func (r *SRegion) FillHost(instance *SInstance) {
 		zone: zone,
 	}
 }
+
+func (r *SRegion) CreateInstance(spec *models.InstanceSpec) (string, error) {
+	req := apis.NewCreateInstancesRequest(r.ID, spec)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.CreateInstances(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error.Code >= 400 {
+		return "", fmt.Errorf(resp.Error.Message)
+	}
+	if len(resp.Result.InstanceIds) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "no instance created")
+	}
+	return resp.Result.InstanceIds[0], nil
+}
+
+func (r *SRegion) StartInstance(id string) error {
+	req := apis.NewStartInstanceRequest(r.ID, id)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.StartInstance(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) StopInstance(id string, stopCharging bool) error {
+	req := apis.NewStopInstanceRequest(r.ID, id)
+	if stopCharging {
+		req.SetChargeOnStopped("stopCharging")
+	}
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.StopInstance(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) DeleteInstance(id string) error {
+	req := apis.NewDeleteInstanceRequest(r.ID, id)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DeleteInstance(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) ResizeInstance(id, instanceType string) error {
+	req := apis.NewResizeInstanceRequest(r.ID, id, instanceType)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.ResizeInstance(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) RebuildInstance(id, imageId, password, keyName string) error {
+	req := apis.NewRebuildInstanceRequest(r.ID, id)
+	if len(imageId) > 0 {
+		req.SetImageId(imageId)
+	}
+	if len(password) > 0 {
+		req.SetPassword(password)
+	}
+	if len(keyName) > 0 {
+		req.SetKeyNames([]string{keyName})
+	}
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.RebuildInstance(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) ModifyInstanceAttribute(id, name, description string) error {
+	req := apis.NewModifyInstanceAttributeRequest(r.ID, id)
+	if len(name) > 0 {
+		req.SetName(name)
+	}
+	if len(description) > 0 {
+		req.SetDescription(description)
+	}
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.ModifyInstanceAttribute(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) ModifyInstancePassword(id, password string) error {
+	req := apis.NewModifyInstancePasswordRequest(r.ID, id, password)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.ModifyInstancePassword(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) AttachDisk(instanceId, diskId string) error {
+	req := apis.NewAttachDiskRequest(r.ID, instanceId, diskId)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.AttachDisk(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) DetachDisk(instanceId, diskId string) error {
+	req := apis.NewDetachDiskRequest(r.ID, instanceId, diskId)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DetachDisk(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) AssociateEip(instanceId, eipId string) error {
+	req := apis.NewAssociateElasticIpRequest(r.ID, instanceId, eipId)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.AssociateElasticIp(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
+
+func (r *SRegion) DissociateEip(instanceId, eipId string) error {
+	req := apis.NewDisassociateElasticIpRequest(r.ID, instanceId, eipId)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DisassociateElasticIp(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
@@ -15,6 +15,8 @@
 package jdcloud
 
 import (
+	"sync"
+
 	"github.com/jdcloud-api/jdcloud-sdk-go/core"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
@@ -25,6 +27,9 @@ type SJDCloudClient struct {
 	*JDCloudClientConfig
 
 	iregion []cloudprovider.ICloudRegion
+	rgLock  sync.RWMutex
+	// resource id => resource group id
+	resourceGroups map[string]string
 }
 
 type JDCloudClientConfig struct {
@@ -101,3 +106,13 @@ func (self *SJDCloudClient) GetRegion(regionId string) *SRegion {
 	}
 	return nil
 }
+
+func (self *SJDCloudClient) GetCapabilities() []string {
+	return []string{
+		cloudprovider.CLOUD_CAPABILITY_PROJECT,
+		cloudprovider.CLOUD_CAPABILITY_COMPUTE,
+		cloudprovider.CLOUD_CAPABILITY_NETWORK,
+		cloudprovider.CLOUD_CAPABILITY_EIP,
+		cloudprovider.CLOUD_CAPABILITY_RDS + cloudprovider.READ_ONLY_SUFFIX,
+	}
+}
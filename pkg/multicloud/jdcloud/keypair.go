@@ -0,0 +1,116 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jdcloud
+
+import (
+	"fmt"
+	"strings"
+
+	commodels "github.com/jdcloud-api/jdcloud-sdk-go/services/common/models"
+	"github.com/jdcloud-api/jdcloud-sdk-go/services/vm/apis"
+	"github.com/jdcloud-api/jdcloud-sdk-go/services/vm/client"
+	"github.com/jdcloud-api/jdcloud-sdk-go/services/vm/models"
+	"golang.org/x/crypto/ssh"
+
+	"yunion.io/x/pkg/errors"
+)
+
+type SKeypair struct {
+	models.Keypair
+}
+
+func (r *SRegion) GetKeypairs(keyNames []string, pageNumber, pageSize int) ([]SKeypair, int, error) {
+	req := apis.NewDescribeKeypairsRequest(r.ID)
+	req.SetPageNumber(pageNumber)
+	req.SetPageSize(pageSize)
+	if len(keyNames) > 0 {
+		req.SetFilters([]commodels.Filter{
+			{
+				Name:   "keyNames",
+				Values: keyNames,
+			},
+		})
+	}
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DescribeKeypairs(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.Error.Code >= 400 {
+		return nil, 0, fmt.Errorf(resp.Error.Message)
+	}
+	keypairs := make([]SKeypair, len(resp.Result.Keypairs))
+	for i := range keypairs {
+		keypairs[i] = SKeypair{
+			Keypair: resp.Result.Keypairs[i],
+		}
+	}
+	return keypairs, resp.Result.TotalCount, nil
+}
+
+func (r *SRegion) ImportKeypair(name, publicKey string) (*SKeypair, error) {
+	req := apis.NewImportKeypairRequest(r.ID, name, publicKey)
+	client := client.NewVmClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.ImportKeypair(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error.Code >= 400 {
+		return nil, fmt.Errorf(resp.Error.Message)
+	}
+	keypair := &SKeypair{}
+	keypair.KeyName = resp.Result.KeyName
+	keypair.KeyFingerprint = resp.Result.KeyFingerprint
+	return keypair, nil
+}
+
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// syncKeypair returns the name of the keypair holding publicKey, importing it when missing
+func (r *SRegion) syncKeypair(publicKey string) (string, error) {
+	pk, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return "", errors.Wrapf(err, "ParseAuthorizedKey")
+	}
+	fingerprint := normalizeFingerprint(ssh.FingerprintLegacyMD5(pk))
+	keypairs := make([]SKeypair, 0)
+	n := 1
+	for {
+		parts, total, err := r.GetKeypairs(nil, n, 100)
+		if err != nil {
+			return "", errors.Wrapf(err, "GetKeypairs")
+		}
+		keypairs = append(keypairs, parts...)
+		if len(keypairs) >= total || len(parts) == 0 {
+			break
+		}
+		n++
+	}
+	for i := range keypairs {
+		if normalizeFingerprint(keypairs[i].KeyFingerprint) == fingerprint {
+			return keypairs[i].KeyName, nil
+		}
+	}
+	name := "k" + fingerprint[:16]
+	keypair, err := r.ImportKeypair(name, publicKey)
+	if err != nil {
+		return "", errors.Wrapf(err, "ImportKeypair")
+	}
+	return keypair.KeyName, nil
+}
@@ -64,7 +64,7 @@ func (n *SNetwork) IsEmulated() bool {
 }
 
 func (n *SNetwork) GetProjectId() string {
-	return ""
+	return n.wire.vpc.region.client.getResourceGroupId(n.SubnetId)
 }
 
 func (n *SNetwork) GetIWire() cloudprovider.ICloudWire {
@@ -105,7 +105,7 @@ func (n *SNetwork) GetPublicScope() rbacscope.TRbacScope {
 }
 
 func (n *SNetwork) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return n.wire.vpc.region.DeleteNetwork(n.SubnetId)
 }
 
 func (n *SNetwork) GetAllocTimeoutSeconds() int {
@@ -154,3 +154,37 @@ func (r *SRegion) GetNetworkById(id string) (*SNetwork, error) {
 		Subnet: resp.Result.Subnet,
 	}, nil
 }
+
+func (r *SRegion) CreateNetwork(vpcId, zoneId string, opts *cloudprovider.SNetworkCreateOptions) (string, error) {
+	req := apis.NewCreateSubnetRequest(r.ID, vpcId, opts.Name, opts.Cidr)
+	if len(opts.Desc) > 0 {
+		req.SetDescription(opts.Desc)
+	}
+	if len(zoneId) > 0 {
+		req.SetAz(zoneId)
+	}
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.CreateSubnet(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error.Code >= 400 {
+		return "", fmt.Errorf(resp.Error.Message)
+	}
+	return resp.Result.SubnetId, nil
+}
+
+func (r *SRegion) DeleteNetwork(id string) error {
+	req := apis.NewDeleteSubnetRequest(r.ID, id)
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DeleteSubnet(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
@@ -15,6 +15,10 @@
 package jdcloud
 
 import (
+	"fmt"
+
+	"github.com/jdcloud-api/jdcloud-sdk-go/services/vpc/apis"
+	"github.com/jdcloud-api/jdcloud-sdk-go/services/vpc/client"
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/vpc/models"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
@@ -105,3 +109,18 @@ func (nic *SNetworkInterface) GetICloudInterfaceAddresses() ([]cloudprovider.ICl
 	}
 	return address, nil
 }
+
+func (r *SRegion) SetNetworkInterfaceSecurityGroups(id string, secgroupIds []string) error {
+	req := apis.NewModifyNetworkInterfaceRequest(r.ID, id)
+	req.SetSecurityGroups(secgroupIds)
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.ModifyNetworkInterface(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jdcloud
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jdcloud-api/jdcloud-sdk-go/core"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// 资源组服务未包含在sdk中, 这里按sdk的方式自行构造请求
+const (
+	RESOURCE_GROUP_SERVICE  = "rgm"
+	RESOURCE_GROUP_ENDPOINT = "rgm.jdcloud-api.com"
+	RESOURCE_GROUP_REGION   = "cn-north-1"
+)
+
+type SResourceGroup struct {
+	multicloud.SProjectBase
+	JdcloudTags
+
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	CreateTime  string `json:"createTime"`
+}
+
+func (rg *SResourceGroup) GetId() string {
+	return rg.Id
+}
+
+func (rg *SResourceGroup) GetGlobalId() string {
+	return rg.Id
+}
+
+func (rg *SResourceGroup) GetName() string {
+	return rg.Name
+}
+
+func (rg *SResourceGroup) GetStatus() string {
+	if len(rg.Status) == 0 || rg.Status == "normal" {
+		return api.EXTERNAL_PROJECT_STATUS_AVAILABLE
+	}
+	return api.EXTERNAL_PROJECT_STATUS_UNAVAILABLE
+}
+
+type SResourceGroupResource struct {
+	ResourceId   string `json:"resourceId"`
+	ResourceType string `json:"resourceType"`
+}
+
+type describeResourceGroupsRequest struct {
+	core.JDCloudRequest
+
+	RegionId   string `json:"regionId"`
+	PageNumber int    `json:"pageNumber"`
+	PageSize   int    `json:"pageSize"`
+}
+
+func (r describeResourceGroupsRequest) GetRegionId() string {
+	return r.RegionId
+}
+
+type describeResourceGroupsResponse struct {
+	RequestID string             `json:"requestId"`
+	Error     core.ErrorResponse `json:"error"`
+	Result    struct {
+		ResourceGroups []SResourceGroup `json:"resourceGroups"`
+		TotalCount     int              `json:"totalCount"`
+	} `json:"result"`
+}
+
+type describeResourceGroupResourcesRequest struct {
+	core.JDCloudRequest
+
+	RegionId        string `json:"regionId"`
+	ResourceGroupId string `json:"resourceGroupId"`
+	PageNumber      int    `json:"pageNumber"`
+	PageSize        int    `json:"pageSize"`
+}
+
+func (r describeResourceGroupResourcesRequest) GetRegionId() string {
+	return r.RegionId
+}
+
+type describeResourceGroupResourcesResponse struct {
+	RequestID string             `json:"requestId"`
+	Error     core.ErrorResponse `json:"error"`
+	Result    struct {
+		Resources  []SResourceGroupResource `json:"resources"`
+		TotalCount int                      `json:"totalCount"`
+	} `json:"result"`
+}
+
+type createResourceGroupRequest struct {
+	core.JDCloudRequest
+
+	RegionId    string `json:"regionId"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+func (r createResourceGroupRequest) GetRegionId() string {
+	return r.RegionId
+}
+
+type createResourceGroupResponse struct {
+	RequestID string             `json:"requestId"`
+	Error     core.ErrorResponse `json:"error"`
+	Result    struct {
+		ResourceGroupId string `json:"resourceGroupId"`
+	} `json:"result"`
+}
+
+func (self *SJDCloudClient) rgmRequest(req core.RequestInterface, retVal interface{}) error {
+	config := core.NewConfig()
+	config.SetEndpoint(RESOURCE_GROUP_ENDPOINT)
+	client := core.JDCloudClient{
+		Credential:  *self.getCredential(),
+		Config:      *config,
+		ServiceName: RESOURCE_GROUP_SERVICE,
+		Revision:    "1.0.0",
+		Logger:      Logger{debug: self.debug},
+	}
+	resp, err := client.Send(req, client.ServiceName)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(resp, retVal)
+	if err != nil {
+		return errors.Wrapf(err, "Unmarshal %s", string(resp))
+	}
+	return nil
+}
+
+func (self *SJDCloudClient) GetResourceGroups() ([]SResourceGroup, error) {
+	ret := []SResourceGroup{}
+	req := describeResourceGroupsRequest{
+		JDCloudRequest: core.JDCloudRequest{
+			URL:     "/regions/{regionId}/resourceGroups",
+			Method:  "GET",
+			Version: "v1",
+		},
+		RegionId: RESOURCE_GROUP_REGION,
+		PageSize: 100,
+	}
+	for {
+		req.PageNumber++
+		resp := describeResourceGroupsResponse{}
+		err := self.rgmRequest(req, &resp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "DescribeResourceGroups")
+		}
+		if resp.Error.Code >= 400 {
+			return nil, fmt.Errorf("%s: %s", resp.Error.Status, resp.Error.Message)
+		}
+		ret = append(ret, resp.Result.ResourceGroups...)
+		if len(ret) >= resp.Result.TotalCount || len(resp.Result.ResourceGroups) == 0 {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func (self *SJDCloudClient) GetResourceGroupResources(groupId string) ([]SResourceGroupResource, error) {
+	ret := []SResourceGroupResource{}
+	req := describeResourceGroupResourcesRequest{
+		JDCloudRequest: core.JDCloudRequest{
+			URL:     "/regions/{regionId}/resourceGroups/{resourceGroupId}/resources",
+			Method:  "GET",
+			Version: "v1",
+		},
+		RegionId:        RESOURCE_GROUP_REGION,
+		ResourceGroupId: groupId,
+		PageSize:        100,
+	}
+	for {
+		req.PageNumber++
+		resp := describeResourceGroupResourcesResponse{}
+		err := self.rgmRequest(req, &resp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "DescribeResourceGroupResources")
+		}
+		if resp.Error.Code >= 400 {
+			return nil, fmt.Errorf("%s: %s", resp.Error.Status, resp.Error.Message)
+		}
+		ret = append(ret, resp.Result.Resources...)
+		if len(ret) >= resp.Result.TotalCount || len(resp.Result.Resources) == 0 {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func (self *SJDCloudClient) CreateResourceGroup(name, desc string) (*SResourceGroup, error) {
+	req := createResourceGroupRequest{
+		JDCloudRequest: core.JDCloudRequest{
+			URL:     "/regions/{regionId}/resourceGroup",
+			Method:  "POST",
+			Version: "v1",
+		},
+		RegionId:    RESOURCE_GROUP_REGION,
+		Name:        name,
+		Description: desc,
+	}
+	resp := createResourceGroupResponse{}
+	err := self.rgmRequest(req, &resp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateResourceGroup")
+	}
+	if resp.Error.Code >= 400 {
+		return nil, fmt.Errorf("%s: %s", resp.Error.Status, resp.Error.Message)
+	}
+	return &SResourceGroup{
+		Id:          resp.Result.ResourceGroupId,
+		Name:        name,
+		Description: desc,
+	}, nil
+}
+
+// 刷新资源与资源组的对应关系, 每次同步项目时更新
+func (self *SJDCloudClient) refreshResourceGroups(groups []SResourceGroup) error {
+	resourceGroups := map[string]string{}
+	for i := range groups {
+		resources, err := self.GetResourceGroupResources(groups[i].Id)
+		if err != nil {
+			return errors.Wrapf(err, "GetResourceGroupResources(%s)", groups[i].Id)
+		}
+		for _, res := range resources {
+			resourceGroups[res.ResourceId] = groups[i].Id
+		}
+	}
+	self.rgLock.Lock()
+	defer self.rgLock.Unlock()
+	self.resourceGroups = resourceGroups
+	return nil
+}
+
+func (self *SJDCloudClient) getResourceGroupId(resourceId string) string {
+	self.rgLock.RLock()
+	resourceGroups := self.resourceGroups
+	self.rgLock.RUnlock()
+	if resourceGroups == nil {
+		groups, err := self.GetResourceGroups()
+		if err != nil {
+			log.Warningf("GetResourceGroups error: %v", err)
+			return ""
+		}
+		err = self.refreshResourceGroups(groups)
+		if err != nil {
+			log.Warningf("refreshResourceGroups error: %v", err)
+			return ""
+		}
+	}
+	self.rgLock.RLock()
+	defer self.rgLock.RUnlock()
+	return self.resourceGroups[resourceId]
+}
+
+func (self *SJDCloudClient) GetIProjects() ([]cloudprovider.ICloudProject, error) {
+	groups, err := self.GetResourceGroups()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetResourceGroups")
+	}
+	err = self.refreshResourceGroups(groups)
+	if err != nil {
+		return nil, errors.Wrap(err, "refreshResourceGroups")
+	}
+	ret := []cloudprovider.ICloudProject{}
+	for i := range groups {
+		ret = append(ret, &groups[i])
+	}
+	return ret, nil
+}
+
+func (self *SJDCloudClient) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	return self.CreateResourceGroup(name, "")
+}
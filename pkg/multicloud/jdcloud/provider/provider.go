@@ -159,7 +159,11 @@ func (p *SJdcloudProvider) GetBalance() (float64, string, error) {
 }
 
 func (p *SJdcloudProvider) GetIProjects() ([]cloudprovider.ICloudProject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return p.client.GetIProjects()
+}
+
+func (p *SJdcloudProvider) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	return p.client.CreateIProject(name)
 }
 
 func (p *SJdcloudProvider) GetStorageClasses(regionId string) []string {
@@ -180,17 +184,7 @@ func (p *SJdcloudProvider) GetCloudRegionExternalIdPrefix() string {
 }
 
 func (p *SJdcloudProvider) GetCapabilities() []string {
-	iRegions := p.GetIRegions()
-	if len(iRegions) > 0 {
-		return iRegions[0].GetCapabilities()
-	}
-	caps := []string{
-		cloudprovider.CLOUD_CAPABILITY_COMPUTE + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_NETWORK + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_EIP + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_RDS + cloudprovider.READ_ONLY_SUFFIX,
-	}
-	return caps
+	return p.client.GetCapabilities()
 }
 
 func (self *SJdcloudProvider) GetMetrics(opts *cloudprovider.MetricListOptions) ([]cloudprovider.MetricValues, error) {
@@ -19,6 +19,8 @@ import (
 
 	"github.com/jdcloud-api/jdcloud-sdk-go/core"
 
+	"yunion.io/x/pkg/errors"
+
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
@@ -158,7 +160,36 @@ func (r *SRegion) GetIVpcs() ([]cloudprovider.ICloudVpc, error) {
 }
 
 func (r *SRegion) GetIEips() ([]cloudprovider.ICloudEIP, error) {
-	return nil, nil
+	eips := make([]SEip, 0)
+	n := 1
+	for {
+		parts, total, err := r.GetEips(n, 100)
+		if err != nil {
+			return nil, err
+		}
+		eips = append(eips, parts...)
+		if len(eips) >= total || len(parts) == 0 {
+			break
+		}
+		n++
+	}
+	ret := make([]cloudprovider.ICloudEIP, len(eips))
+	for i := range eips {
+		ret[i] = &eips[i]
+	}
+	return ret, nil
+}
+
+func (r *SRegion) CreateEIP(opts *cloudprovider.SEip) (cloudprovider.ICloudEIP, error) {
+	id, err := r.CreateEip(opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateEip")
+	}
+	eip, err := r.GetEIPById(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetEIPById(%s)", id)
+	}
+	return eip, nil
 }
 
 func (r *SRegion) GetIVpcById(id string) (cloudprovider.ICloudVpc, error) {
@@ -196,7 +227,11 @@ func (r *SRegion) getIZoneByRealId(id string) (cloudprovider.ICloudZone, error)
 }
 
 func (r *SRegion) GetIEipById(id string) (cloudprovider.ICloudEIP, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	eip, err := r.GetEIPById(id)
+	if err != nil {
+		return nil, err
+	}
+	return eip, nil
 }
 
 func (r *SRegion) GetIVMById(id string) (cloudprovider.ICloudVM, error) {
@@ -303,10 +338,5 @@ func (r *SRegion) GetProvider() string {
 }
 
 func (r *SRegion) GetCapabilities() []string {
-	return []string{
-		cloudprovider.CLOUD_CAPABILITY_COMPUTE + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_NETWORK + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_EIP + cloudprovider.READ_ONLY_SUFFIX,
-		cloudprovider.CLOUD_CAPABILITY_RDS + cloudprovider.READ_ONLY_SUFFIX,
-	}
+	return r.client.GetCapabilities()
 }
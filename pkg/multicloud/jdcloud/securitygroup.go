@@ -115,11 +115,11 @@ func (sg *SSecurityGroup) Refresh() error {
 }
 
 func (sg *SSecurityGroup) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return sg.vpc.region.DeleteSecurityGroup(sg.NetworkSecurityGroupId)
 }
 
 func (sg *SSecurityGroup) GetProjectId() string {
-	return ""
+	return sg.vpc.region.client.getResourceGroupId(sg.NetworkSecurityGroupId)
 }
 
 func (sg *SSecurityGroup) SyncRules(common, inAdds, outAdds, inDels, outDels []cloudprovider.SecurityRule) error {
@@ -160,3 +160,17 @@ func (r *SRegion) GetSecurityGroups(vpcId string, securityGroupIds []string, pag
 	}
 	return sgs, total, nil
 }
+
+func (r *SRegion) DeleteSecurityGroup(id string) error {
+	req := apis.NewDeleteNetworkSecurityGroupRequest(r.ID, id)
+	client := client.NewVpcClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DeleteNetworkSecurityGroup(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud/jdcloud"
+)
+
+func init() {
+	type EipListOptions struct {
+		ListOptions
+	}
+	shellutils.R(&EipListOptions{}, "eip-list", "List eips", func(cli *jdcloud.SRegion, args *EipListOptions) error {
+		eips, total, e := cli.GetEips(args.Offset+1, args.Limit)
+		if e != nil {
+			return e
+		}
+		printList(eips, total, args.Offset, args.Limit, []string{})
+		return nil
+	})
+
+	type EipCreateOptions struct {
+		BANDWIDTH  int
+		ChargeType string `choices:"traffic|bandwidth"`
+		BgpType    string
+	}
+	shellutils.R(&EipCreateOptions{}, "eip-create", "Create eip", func(cli *jdcloud.SRegion, args *EipCreateOptions) error {
+		eip, e := cli.CreateEIP(&cloudprovider.SEip{
+			BandwidthMbps: args.BANDWIDTH,
+			ChargeType:    args.ChargeType,
+			BGPType:       args.BgpType,
+		})
+		if e != nil {
+			return e
+		}
+		printObject(eip)
+		return nil
+	})
+
+	type EipIdOptions struct {
+		ID string
+	}
+	shellutils.R(&EipIdOptions{}, "eip-delete", "Delete eip", func(cli *jdcloud.SRegion, args *EipIdOptions) error {
+		return cli.DeleteEip(args.ID)
+	})
+}
@@ -69,4 +69,43 @@ func init() {
 		printList(disks, 0, 0, 0, []string{})
 		return nil
 	})
+
+	shellutils.R(&InstanceShowOptions{}, "instance-start", "Start intance", func(cli *jdcloud.SRegion, args *InstanceShowOptions) error {
+		return cli.StartInstance(args.ID)
+	})
+
+	type InstanceStopOptions struct {
+		ID           string
+		StopCharging bool
+	}
+	shellutils.R(&InstanceStopOptions{}, "instance-stop", "Stop intance", func(cli *jdcloud.SRegion, args *InstanceStopOptions) error {
+		return cli.StopInstance(args.ID, args.StopCharging)
+	})
+
+	shellutils.R(&InstanceShowOptions{}, "instance-delete", "Delete intance", func(cli *jdcloud.SRegion, args *InstanceShowOptions) error {
+		return cli.DeleteInstance(args.ID)
+	})
+
+	type InstanceDiskOptions struct {
+		ID     string
+		DISKID string
+	}
+	shellutils.R(&InstanceDiskOptions{}, "instance-attach-disk", "Attach disk to intance", func(cli *jdcloud.SRegion, args *InstanceDiskOptions) error {
+		return cli.AttachDisk(args.ID, args.DISKID)
+	})
+	shellutils.R(&InstanceDiskOptions{}, "instance-detach-disk", "Detach disk from intance", func(cli *jdcloud.SRegion, args *InstanceDiskOptions) error {
+		return cli.DetachDisk(args.ID, args.DISKID)
+	})
+
+	type KeypairListOptions struct {
+		ListOptions
+	}
+	shellutils.R(&KeypairListOptions{}, "keypair-list", "List keypairs", func(cli *jdcloud.SRegion, args *KeypairListOptions) error {
+		keypairs, total, e := cli.GetKeypairs(nil, args.Offset+1, args.Limit)
+		if e != nil {
+			return e
+		}
+		printList(keypairs, total, args.Offset, args.Limit, []string{})
+		return nil
+	})
 }
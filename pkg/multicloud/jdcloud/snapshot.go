@@ -23,6 +23,8 @@ import (
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/disk/models"
 
 	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/utils"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -93,6 +95,11 @@ func (s *SSnapshot) GetDiskType() string {
 }
 
 func (s *SSnapshot) Refresh() error {
+	snapshot, err := s.region.GetSnapshotById(s.SnapshotId)
+	if err != nil {
+		return err
+	}
+	s.Snapshot = snapshot.Snapshot
 	return nil
 }
 
@@ -105,11 +112,11 @@ func (s *SSnapshot) IsEmulated() bool {
 }
 
 func (s *SSnapshot) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	return s.region.DeleteSnapshot(s.SnapshotId)
 }
 
 func (s *SSnapshot) GetProjectId() string {
-	return ""
+	return s.region.client.getResourceGroupId(s.SnapshotId)
 }
 
 func (r *SRegion) GetSnapshots(diskId string, pageNumber, pageSize int) ([]SSnapshot, int, error) {
@@ -130,7 +137,7 @@ func (r *SRegion) GetSnapshots(diskId string, pageNumber, pageSize int) ([]SSnap
 	if resp.Error.Code >= 400 {
 		return nil, 0, fmt.Errorf(resp.Error.Message)
 	}
-	snapshots := make([]SSnapshot, len(resp.Result.Snapshots))
+	snapshots := make([]SSnapshot, 0, len(resp.Result.Snapshots))
 	for i := range resp.Result.Snapshots {
 		snapshots = append(snapshots, SSnapshot{
 			region:   r,
@@ -149,6 +156,9 @@ func (r *SRegion) GetSnapshotById(id string) (*SSnapshot, error) {
 		return nil, err
 	}
 	if resp.Error.Code >= 400 {
+		if resp.Error.Code == 404 {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, resp.Error.Message)
+		}
 		return nil, fmt.Errorf(resp.Error.Message)
 	}
 	snapshot := SSnapshot{
@@ -157,3 +167,38 @@ func (r *SRegion) GetSnapshotById(id string) (*SSnapshot, error) {
 	}
 	return &snapshot, nil
 }
+
+func (r *SRegion) CreateSnapshot(diskId, name, desc string) (string, error) {
+	spec := &models.SnapshotSpec{
+		Name:   name,
+		DiskId: diskId,
+	}
+	if len(desc) > 0 {
+		spec.Description = &desc
+	}
+	req := apis.NewCreateSnapshotRequest(r.ID, spec, utils.GenRequestId(20))
+	client := client.NewDiskClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.CreateSnapshot(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error.Code >= 400 {
+		return "", fmt.Errorf(resp.Error.Message)
+	}
+	return resp.Result.SnapshotId, nil
+}
+
+func (r *SRegion) DeleteSnapshot(id string) error {
+	req := apis.NewDeleteSnapshotRequest(r.ID, id)
+	client := client.NewDiskClient(r.getCredential())
+	client.Logger = Logger{debug: r.client.debug}
+	resp, err := client.DeleteSnapshot(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error.Code >= 400 {
+		return fmt.Errorf(resp.Error.Message)
+	}
+	return nil
+}
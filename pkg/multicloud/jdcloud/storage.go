@@ -16,8 +16,10 @@ package jdcloud
 
 import (
 	"fmt"
+	"time"
 
 	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -120,7 +122,16 @@ func (s *SStorage) GetEnabled() bool {
 }
 
 func (s *SStorage) CreateIDisk(conf *cloudprovider.DiskCreateConfig) (cloudprovider.ICloudDisk, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	id, err := s.zone.region.CreateDisk(s.zone.ID, s.storageType, conf.Name, conf.SizeGb, conf.Desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateDisk")
+	}
+	disk, err := s.zone.region.GetDiskById(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetDiskById(%s)", id)
+	}
+	disk.storage = s
+	return disk, cloudprovider.WaitStatus(disk, api.DISK_READY, 5*time.Second, 180*time.Second)
 }
 
 func (s *SStorage) GetIDiskById(idStr string) (cloudprovider.ICloudDisk, error) {
@@ -19,6 +19,8 @@ import (
 
 	"github.com/jdcloud-api/jdcloud-sdk-go/services/charge/models"
 
+	"yunion.io/x/pkg/util/billing"
+
 	billing_api "yunion.io/x/cloudmux/pkg/apis/billing"
 )
 
@@ -44,3 +46,24 @@ func expireAt(charge *models.Charge) time.Time {
 	}
 	return parseTime(charge.ChargeExpiredTime)
 }
+
+// chargeSpec returns a prepaid charge spec for bc, or postpaid by duration when bc is nil
+func chargeSpec(bc *billing.SBillingCycle) *models.ChargeSpec {
+	mode := "postpaid_by_duration"
+	spec := &models.ChargeSpec{
+		ChargeMode: &mode,
+	}
+	if bc == nil {
+		return spec
+	}
+	mode = "prepaid_by_duration"
+	unit, duration := "month", bc.GetMonths()
+	if years := bc.GetYears(); years > 0 {
+		unit, duration = "year", years
+	}
+	autoRenew := bc.AutoRenew
+	spec.ChargeUnit = &unit
+	spec.ChargeDuration = &duration
+	spec.AutoRenew = &autoRenew
+	return spec
+}
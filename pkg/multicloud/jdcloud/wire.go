@@ -17,6 +17,8 @@ package jdcloud
 import (
 	"fmt"
 
+	"yunion.io/x/pkg/errors"
+
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud"
@@ -112,7 +114,17 @@ func (w *SWire) GetINetworkById(netid string) (cloudprovider.ICloudNetwork, erro
 }
 
 func (w *SWire) CreateINetwork(opts *cloudprovider.SNetworkCreateOptions) (cloudprovider.ICloudNetwork, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	id, err := w.vpc.region.CreateNetwork(w.vpc.VpcId, "", opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateNetwork")
+	}
+	network, err := w.vpc.region.GetNetworkById(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetNetworkById(%s)", id)
+	}
+	network.wire = w
+	w.inetworks = nil
+	return network, nil
 }
 
 func (w *SWire) CreateNetworks() (*SNetwork, error) {
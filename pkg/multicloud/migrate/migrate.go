@@ -0,0 +1,749 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/qemuimgfmt"
+	"yunion.io/x/pkg/util/stringutils"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SMigrateOptions struct {
+	SourceVmId string
+
+	// 工作目录, 存放导出及转换后的镜像
+	WorkDir string
+	// 状态文件, 默认为 <WorkDir>/<SourceVmId>.json
+	StateFile string
+	// 预先转换的镜像格式, 为空时按目标平台上传镜像时要求的格式转换
+	Format    string
+	ForceStop bool
+
+	ImageName string
+	// 目标平台的存储桶, 指定时先将镜像文件上传至存储桶, 再由目标平台从存储桶导入镜像
+	Bucket string
+
+	TargetZoneId         string
+	TargetHostId         string
+	TargetStoragecacheId string
+
+	Name         string
+	InstanceType string
+	// 为空时使用源虚拟机配置
+	Cpu         int
+	MemoryMb    int
+	SysDiskType string
+	// 数据盘的存储类型, 为空时使用目标主机第一个可由镜像创建磁盘的存储
+	DataDiskType string
+	NetworkId    string
+	SecgroupIds  []string
+	Password     string
+	PublicKey    string
+
+	Callback func(stage string, percent float32)
+}
+
+func (opts *SMigrateOptions) Validate() error {
+	if len(opts.SourceVmId) == 0 {
+		return errors.Wrap(cloudprovider.ErrInputParameter, "missing source vm id")
+	}
+	if len(opts.WorkDir) == 0 {
+		return errors.Wrap(cloudprovider.ErrInputParameter, "missing work dir")
+	}
+	if len(opts.Format) > 0 && !qemuimgfmt.IsSupportedImageFormat(string(qemuimgfmt.String2ImageFormat(opts.Format))) {
+		return errors.Wrapf(cloudprovider.ErrInputParameter, "unsupported image format %s", opts.Format)
+	}
+	if len(opts.StateFile) == 0 {
+		opts.StateFile = filepath.Join(opts.WorkDir, fileName(opts.SourceVmId)+".json")
+	}
+	return nil
+}
+
+func fileName(id string) string {
+	return strings.NewReplacer("/", "_", ":", "_", "\\", "_").Replace(id)
+}
+
+// IVmExporter 可直接导出系统盘的虚拟机, 无需先在源平台生成镜像, 返回导出文件的格式
+type IVmExporter interface {
+	ExportImage(ctx context.Context, filename string, callback func(float32)) (string, error)
+}
+
+// IDiskExporter 可直接导出数据的磁盘, 数据盘须支持导出才能迁移
+type IDiskExporter interface {
+	ExportDisk(ctx context.Context, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error)
+}
+
+// IImageDiskCreator 可由镜像创建磁盘的目标存储, 用于创建迁移后的数据盘
+type IImageDiskCreator interface {
+	CreateIDiskFromImage(ctx context.Context, imageId string, conf *cloudprovider.DiskCreateConfig) (cloudprovider.ICloudDisk, error)
+}
+
+// IBucketImageImporter 可从存储桶中的镜像文件导入镜像的目标镜像缓存
+type IBucketImageImporter interface {
+	ImportImageFromBucket(ctx context.Context, bucketName, key string, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error)
+}
+
+// SVmMigrator 将虚拟机从源平台迁移至目标平台, 每个阶段完成后记录状态, 中断后可从未完成的阶段继续
+type SVmMigrator struct {
+	opts   *SMigrateOptions
+	source cloudprovider.ICloudRegion
+	target cloudprovider.ICloudRegion
+	state  *SMigrateState
+
+	vm cloudprovider.ICloudVM
+	// 与state.Disks一一对应
+	disks []cloudprovider.ICloudDisk
+}
+
+func NewVmMigrator(source, target cloudprovider.ICloudRegion, opts *SMigrateOptions) (*SVmMigrator, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(opts.WorkDir, 0755)
+	if err != nil {
+		return nil, errors.Wrapf(err, "MkdirAll %s", opts.WorkDir)
+	}
+	state, err := LoadState(opts.StateFile, opts.SourceVmId)
+	if err != nil {
+		return nil, err
+	}
+	return &SVmMigrator{
+		opts:   opts,
+		source: source,
+		target: target,
+		state:  state,
+	}, nil
+}
+
+func (self *SVmMigrator) GetState() *SMigrateState {
+	return self.state
+}
+
+func (self *SVmMigrator) Run(ctx context.Context) error {
+	var err error
+	self.vm, err = self.source.GetIVMById(self.opts.SourceVmId)
+	if err != nil {
+		return errors.Wrapf(err, "GetIVMById(%s)", self.opts.SourceVmId)
+	}
+	self.disks, err = self.getSourceDisks()
+	if err != nil {
+		return err
+	}
+	// 在停止源虚拟机前检查
+	err = self.check()
+	if err != nil {
+		return err
+	}
+	for _, stage := range Stages {
+		if self.state.IsDone(stage) {
+			log.Infof("migrate %s: stage %s already done, skip", self.opts.SourceVmId, stage)
+			continue
+		}
+		log.Infof("migrate %s: start stage %s", self.opts.SourceVmId, stage)
+		switch stage {
+		case STAGE_STOP:
+			err = self.stop(ctx)
+		case STAGE_EXPORT:
+			err = self.export(ctx)
+		case STAGE_CONVERT:
+			err = self.convert(ctx)
+		case STAGE_UPLOAD:
+			err = self.upload(ctx)
+		case STAGE_CREATE:
+			err = self.create(ctx)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "stage %s", stage)
+		}
+		err = self.state.Done(stage)
+		if err != nil {
+			return errors.Wrapf(err, "save state")
+		}
+		self.progress(stage)(100)
+	}
+	return nil
+}
+
+// check 检查各磁盘能否导出, 以及目标平台能否导入镜像和创建数据盘
+func (self *SVmMigrator) check() error {
+	if !self.state.IsDone(STAGE_EXPORT) {
+		for i := 1; i < len(self.disks); i++ {
+			if _, ok := self.disks[i].(IDiskExporter); !ok {
+				return errors.Wrapf(cloudprovider.ErrNotSupported, "export data disk %s from %s", self.disks[i].GetName(), self.source.GetProvider())
+			}
+		}
+	}
+	if len(self.opts.Bucket) > 0 && !self.state.IsDone(STAGE_UPLOAD) {
+		cache, err := self.getTargetStoragecache()
+		if err != nil {
+			return err
+		}
+		if _, ok := cache.(IBucketImageImporter); !ok {
+			return errors.Wrapf(cloudprovider.ErrNotSupported, "import image from bucket to %s", self.target.GetProvider())
+		}
+	}
+	if len(self.disks) > 1 && len(self.state.TargetVmId) == 0 {
+		host, err := self.getTargetHost()
+		if err != nil {
+			return err
+		}
+		_, err = self.getTargetDataStorage(host)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *SVmMigrator) progress(stage string) func(float32) {
+	return func(percent float32) {
+		if self.opts.Callback != nil {
+			self.opts.Callback(stage, percent)
+		}
+	}
+}
+
+// diskProgress 将第idx块磁盘的进度折算为整个阶段的进度
+func (self *SVmMigrator) diskProgress(stage string, idx int) func(float32) {
+	callback := self.progress(stage)
+	return func(percent float32) {
+		callback((float32(idx)*100 + percent) / float32(len(self.state.Disks)))
+	}
+}
+
+func (self *SVmMigrator) stop(ctx context.Context) error {
+	if self.vm.GetStatus() == api.VM_READY {
+		return nil
+	}
+	err := self.vm.StopVM(ctx, &cloudprovider.ServerStopOptions{IsForce: self.opts.ForceStop})
+	if err != nil {
+		return errors.Wrapf(err, "StopVM")
+	}
+	return cloudprovider.WaitStatus(self.vm, api.VM_READY, time.Second*5, time.Minute*10)
+}
+
+func (self *SVmMigrator) getSourceImage() (cloudprovider.ICloudImage, error) {
+	caches, err := self.source.GetIStoragecaches()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetIStoragecaches")
+	}
+	for i := range caches {
+		image, err := caches[i].GetIImageById(self.state.SourceImageId)
+		if err == nil {
+			return image, nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "source image %s", self.state.SourceImageId)
+}
+
+func (self *SVmMigrator) export(ctx context.Context) error {
+	for i := range self.state.Disks {
+		disk := &self.state.Disks[i]
+		if len(disk.ExportPath) > 0 {
+			if _, err := os.Stat(disk.ExportPath); err == nil {
+				continue
+			}
+		}
+		path := filepath.Join(self.opts.WorkDir, fmt.Sprintf("%s-%d.export", fileName(self.opts.SourceVmId), i))
+		err := self.exportDisk(ctx, i, path, self.diskProgress(STAGE_EXPORT, i))
+		if err != nil {
+			return err
+		}
+		disk.ExportPath = path
+		err = self.state.Save()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportDisk 优先直接导出磁盘, 系统盘也可由虚拟机导出或先在源平台生成镜像再下载
+func (self *SVmMigrator) exportDisk(ctx context.Context, idx int, path string, callback func(float32)) error {
+	if exporter, ok := self.disks[idx].(IDiskExporter); ok {
+		_, err := exporter.ExportDisk(ctx, &multicloud.SImageDownloadOptions{Path: path, Callback: callback})
+		if err != nil {
+			return errors.Wrapf(err, "ExportDisk %s", self.disks[idx].GetName())
+		}
+		return nil
+	}
+	if idx > 0 {
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "export data disk %s from %s", self.disks[idx].GetName(), self.source.GetProvider())
+	}
+	if exporter, ok := self.vm.(IVmExporter); ok {
+		_, err := exporter.ExportImage(ctx, path, callback)
+		if err != nil {
+			return errors.Wrapf(err, "ExportImage")
+		}
+		return nil
+	}
+
+	var image cloudprovider.ICloudImage
+	var err error
+	if len(self.state.SourceImageId) > 0 {
+		image, err = self.getSourceImage()
+		if err != nil {
+			return err
+		}
+	} else {
+		image, err = self.vm.SaveImage(&cloudprovider.SaveImageOptions{
+			Name:  self.getImageName(),
+			Notes: fmt.Sprintf("migrate from %s", self.vm.GetGlobalId()),
+		})
+		if err != nil {
+			if errors.Cause(err) == cloudprovider.ErrNotImplemented {
+				err = errors.Wrapf(cloudprovider.ErrNotSupported, "export vm from %s", self.source.GetProvider())
+			}
+			return errors.Wrapf(err, "SaveImage")
+		}
+		self.state.SourceImageId = image.GetGlobalId()
+		err = self.state.Save()
+		if err != nil {
+			return err
+		}
+	}
+	err = cloudprovider.WaitStatus(image, cloudprovider.IMAGE_STATUS_ACTIVE, time.Second*10, time.Hour)
+	if err != nil {
+		return errors.Wrapf(err, "wait image %s active", image.GetGlobalId())
+	}
+	cache := image.GetIStoragecache()
+	if cache == nil {
+		return errors.Wrapf(cloudprovider.ErrNotFound, "storagecache of image %s", image.GetGlobalId())
+	}
+	_, err = cache.DownloadImage(image.GetId(), image.GetGlobalId(), path)
+	if err != nil {
+		return errors.Wrapf(err, "DownloadImage")
+	}
+	return nil
+}
+
+func (self *SVmMigrator) convert(ctx context.Context) error {
+	for i := range self.state.Disks {
+		disk := &self.state.Disks[i]
+		format, err := multicloud.DetectImageFileFormat(disk.ExportPath)
+		if err != nil {
+			return err
+		}
+		disk.ExportFormat = string(format)
+		disk.Images[string(format)] = disk.ExportPath
+		if len(self.opts.Format) > 0 {
+			_, err = self.convertTo(ctx, i, self.opts.Format)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return self.state.Save()
+}
+
+// convertTo 返回第idx块磁盘指定格式的镜像文件, 不存在时从导出的镜像转换
+func (self *SVmMigrator) convertTo(ctx context.Context, idx int, format string) (string, error) {
+	disk := &self.state.Disks[idx]
+	imgFmt := qemuimgfmt.String2ImageFormat(format)
+	if path, ok := disk.Images[string(imgFmt)]; ok {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	path := filepath.Join(self.opts.WorkDir, fmt.Sprintf("%s-%d.%s", fileName(self.opts.SourceVmId), idx, imgFmt))
+	err := multicloud.ConvertImageFile(ctx, disk.ExportPath, path, imgFmt, self.diskProgress(STAGE_CONVERT, idx))
+	if err != nil {
+		return "", errors.Wrapf(err, "convert %s to %s", disk.ExportPath, imgFmt)
+	}
+	disk.Images[string(imgFmt)] = path
+	return path, self.state.Save()
+}
+
+func (self *SVmMigrator) getImageName() string {
+	if len(self.opts.ImageName) > 0 {
+		return self.opts.ImageName
+	}
+	return fmt.Sprintf("%s-migrate", self.vm.GetName())
+}
+
+func (self *SVmMigrator) getTargetStoragecache() (cloudprovider.ICloudStoragecache, error) {
+	if len(self.opts.TargetStoragecacheId) > 0 {
+		return self.target.GetIStoragecacheById(self.opts.TargetStoragecacheId)
+	}
+	caches, err := self.target.GetIStoragecaches()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetIStoragecaches")
+	}
+	if len(caches) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "no storagecache in region %s", self.target.GetGlobalId())
+	}
+	return caches[0], nil
+}
+
+func (self *SVmMigrator) getDisks() (cloudprovider.ICloudDisk, []cloudprovider.ICloudDisk, error) {
+	disks, err := self.vm.GetIDisks()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "GetIDisks")
+	}
+	var sysDisk cloudprovider.ICloudDisk
+	dataDisks := []cloudprovider.ICloudDisk{}
+	for i := range disks {
+		if disks[i].GetDiskType() == api.DISK_TYPE_SYS && sysDisk == nil {
+			sysDisk = disks[i]
+			continue
+		}
+		dataDisks = append(dataDisks, disks[i])
+	}
+	if sysDisk == nil {
+		return nil, nil, errors.Wrapf(cloudprovider.ErrNotFound, "system disk of vm %s", self.vm.GetGlobalId())
+	}
+	return sysDisk, dataDisks, nil
+}
+
+// getSourceDisks 首次运行时记录源虚拟机的磁盘, 之后按记录的顺序返回磁盘
+func (self *SVmMigrator) getSourceDisks() ([]cloudprovider.ICloudDisk, error) {
+	sysDisk, dataDisks, err := self.getDisks()
+	if err != nil {
+		return nil, err
+	}
+	disks := append([]cloudprovider.ICloudDisk{sysDisk}, dataDisks...)
+	if len(self.state.Disks) == 0 {
+		for i := range disks {
+			diskType := api.DISK_TYPE_DATA
+			if i == 0 {
+				diskType = api.DISK_TYPE_SYS
+			}
+			self.state.Disks = append(self.state.Disks, SMigrateDisk{
+				SourceDiskId: disks[i].GetGlobalId(),
+				DiskType:     diskType,
+				SizeMb:       disks[i].GetDiskSizeMB(),
+				Images:       map[string]string{},
+			})
+		}
+		return disks, self.state.Save()
+	}
+	ret := make([]cloudprovider.ICloudDisk, len(self.state.Disks))
+	for i := range self.state.Disks {
+		for j := range disks {
+			if disks[j].GetGlobalId() == self.state.Disks[i].SourceDiskId {
+				ret[i] = disks[j]
+				break
+			}
+		}
+		if ret[i] == nil {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, "disk %s of vm %s", self.state.Disks[i].SourceDiskId, self.vm.GetGlobalId())
+		}
+	}
+	return ret, nil
+}
+
+func (self *SVmMigrator) upload(ctx context.Context) error {
+	cache, err := self.getTargetStoragecache()
+	if err != nil {
+		return err
+	}
+	files := []*os.File{}
+	defer func() {
+		for i := range files {
+			files[i].Close()
+		}
+	}()
+	for i := range self.state.Disks {
+		disk := &self.state.Disks[i]
+		if len(disk.TargetImageId) == 0 {
+			idx := i
+			imageName := self.getImageName()
+			if idx > 0 {
+				imageName = fmt.Sprintf("%s-disk%d", imageName, idx)
+			}
+			opts := &cloudprovider.SImageCreateOption{
+				ImageId:        stringutils.UUID4(),
+				ImageName:      imageName,
+				Description:    fmt.Sprintf("migrate from %s", self.vm.GetGlobalId()),
+				MinDiskMb:      disk.SizeMb,
+				MinRamMb:       self.vm.GetVmemSizeMB(),
+				OsType:         string(self.vm.GetOsType()),
+				OsArch:         self.vm.GetOsArch(),
+				OsDistribution: self.vm.GetOsDist(),
+				OsVersion:      self.vm.GetOsVersion(),
+				OsFullVersion:  self.vm.GetFullOsName(),
+				TmpPath:        self.opts.WorkDir,
+				GetReader: func(imageId, format string) (io.Reader, int64, error) {
+					file, size, err := self.openImage(ctx, idx, format)
+					if err != nil {
+						return nil, 0, err
+					}
+					files = append(files, file)
+					return file, size, nil
+				},
+			}
+			callback := self.diskProgress(STAGE_UPLOAD, idx)
+			if len(self.opts.Bucket) > 0 {
+				disk.TargetImageId, err = self.importFromBucket(ctx, cache, idx, opts, callback)
+				if err != nil {
+					return errors.Wrapf(err, "import image of disk %d from bucket %s", idx, self.opts.Bucket)
+				}
+			} else {
+				disk.TargetImageId, err = cache.UploadImage(ctx, opts, callback)
+				if err != nil {
+					return errors.Wrapf(err, "UploadImage of disk %d", idx)
+				}
+			}
+			err = self.state.Save()
+			if err != nil {
+				return err
+			}
+		}
+		image, err := cache.GetIImageById(disk.TargetImageId)
+		if err != nil {
+			return errors.Wrapf(err, "GetIImageById(%s)", disk.TargetImageId)
+		}
+		err = cloudprovider.WaitStatus(image, cloudprovider.IMAGE_STATUS_ACTIVE, time.Second*10, time.Hour)
+		if err != nil {
+			return errors.Wrapf(err, "wait image %s active", disk.TargetImageId)
+		}
+	}
+	return nil
+}
+
+// openImage 打开第idx块磁盘指定格式的镜像文件, 返回文件大小
+func (self *SVmMigrator) openImage(ctx context.Context, idx int, format string) (*os.File, int64, error) {
+	path, err := self.convertTo(ctx, idx, format)
+	if err != nil {
+		return nil, 0, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "Open %s", path)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, errors.Wrapf(err, "Stat %s", path)
+	}
+	return file, stat.Size(), nil
+}
+
+// importFromBucket 将镜像文件上传至目标平台的存储桶, 由目标平台导入镜像后删除镜像文件
+func (self *SVmMigrator) importFromBucket(ctx context.Context, cache cloudprovider.ICloudStoragecache, idx int, opts *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
+	importer, ok := cache.(IBucketImageImporter)
+	if !ok {
+		return "", errors.Wrapf(cloudprovider.ErrNotSupported, "import image from bucket to %s", self.target.GetProvider())
+	}
+	bucket, err := self.target.GetIBucketById(self.opts.Bucket)
+	if err != nil {
+		return "", errors.Wrapf(err, "GetIBucketById(%s)", self.opts.Bucket)
+	}
+	disk := &self.state.Disks[idx]
+	if len(disk.BucketKey) == 0 {
+		format := self.opts.Format
+		if len(format) == 0 {
+			format = string(qemuimgfmt.QCOW2)
+		}
+		file, size, err := self.openImage(ctx, idx, format)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		key := fmt.Sprintf("%s-%d.%s", fileName(self.opts.SourceVmId), idx, qemuimgfmt.String2ImageFormat(format))
+		body := multicloud.NewProgress(size, 80, file, callback)
+		err = cloudprovider.UploadObject(ctx, bucket, key, 0, body, size, "", "", nil, false)
+		if err != nil {
+			return "", errors.Wrapf(err, "UploadObject %s", key)
+		}
+		disk.BucketKey = key
+		err = self.state.Save()
+		if err != nil {
+			return "", err
+		}
+	}
+	imageId, err := importer.ImportImageFromBucket(ctx, self.opts.Bucket, disk.BucketKey, opts, callback)
+	if err != nil {
+		return "", errors.Wrapf(err, "ImportImageFromBucket %s", disk.BucketKey)
+	}
+	err = bucket.DeleteObject(ctx, disk.BucketKey)
+	if err != nil {
+		log.Errorf("delete %s from bucket %s error: %v", disk.BucketKey, self.opts.Bucket, err)
+	}
+	return imageId, nil
+}
+
+func (self *SVmMigrator) getTargetHost() (cloudprovider.ICloudHost, error) {
+	if len(self.opts.TargetHostId) > 0 {
+		return self.target.GetIHostById(self.opts.TargetHostId)
+	}
+	var zone cloudprovider.ICloudZone
+	if len(self.opts.TargetZoneId) > 0 {
+		var err error
+		zone, err = self.target.GetIZoneById(self.opts.TargetZoneId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetIZoneById(%s)", self.opts.TargetZoneId)
+		}
+	} else {
+		zones, err := self.target.GetIZones()
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetIZones")
+		}
+		if len(zones) == 0 {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, "no zone in region %s", self.target.GetGlobalId())
+		}
+		zone = zones[0]
+	}
+	hosts, err := zone.GetIHosts()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetIHosts")
+	}
+	if len(hosts) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "no host in zone %s", zone.GetGlobalId())
+	}
+	return hosts[0], nil
+}
+
+// getTargetDataStorage 返回目标主机上可由镜像创建数据盘的存储
+func (self *SVmMigrator) getTargetDataStorage(host cloudprovider.ICloudHost) (IImageDiskCreator, error) {
+	storages, err := host.GetIStorages()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetIStorages")
+	}
+	for i := range storages {
+		if len(self.opts.DataDiskType) > 0 && storages[i].GetStorageType() != self.opts.DataDiskType {
+			continue
+		}
+		if creator, ok := storages[i].(IImageDiskCreator); ok {
+			return creator, nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "create data disk %s from image on %s", self.opts.DataDiskType, self.target.GetProvider())
+}
+
+func sizeGB(sizeMb int) int {
+	return (sizeMb + 1023) / 1024
+}
+
+// create 使用上传的系统盘镜像创建虚拟机, 再由数据盘镜像创建磁盘并挂载
+func (self *SVmMigrator) create(ctx context.Context) error {
+	if len(self.state.TargetVmId) == 0 {
+		host, err := self.getTargetHost()
+		if err != nil {
+			return err
+		}
+		sysDisk := self.state.Disks[0]
+		opts := &cloudprovider.SManagedVMCreateConfig{
+			Name:                self.opts.Name,
+			Hostname:            self.vm.GetHostname(),
+			ExternalImageId:     sysDisk.TargetImageId,
+			OsType:              string(self.vm.GetOsType()),
+			OsDistribution:      self.vm.GetOsDist(),
+			OsVersion:           self.vm.GetOsVersion(),
+			InstanceType:        self.opts.InstanceType,
+			Cpu:                 self.opts.Cpu,
+			MemoryMB:            self.opts.MemoryMb,
+			ExternalNetworkId:   self.opts.NetworkId,
+			ExternalSecgroupIds: self.opts.SecgroupIds,
+			Description:         fmt.Sprintf("migrate from %s", self.vm.GetGlobalId()),
+			Password:            self.opts.Password,
+			PublicKey:           self.opts.PublicKey,
+			SysDisk: cloudprovider.SDiskInfo{
+				StorageType: self.opts.SysDiskType,
+				SizeGB:      sizeGB(sysDisk.SizeMb),
+			},
+		}
+		if len(opts.Name) == 0 {
+			opts.Name = self.vm.GetName()
+		}
+		if opts.Cpu == 0 {
+			opts.Cpu = self.vm.GetVcpuCount()
+		}
+		if opts.MemoryMB == 0 {
+			opts.MemoryMB = self.vm.GetVmemSizeMB()
+		}
+		if len(opts.ExternalSecgroupIds) > 0 {
+			opts.ExternalSecgroupId = opts.ExternalSecgroupIds[0]
+		}
+		vm, err := host.CreateVM(opts)
+		if err != nil {
+			return errors.Wrapf(err, "CreateVM")
+		}
+		self.state.TargetVmId = vm.GetGlobalId()
+		err = self.state.Save()
+		if err != nil {
+			return err
+		}
+	}
+	vm, err := self.target.GetIVMById(self.state.TargetVmId)
+	if err != nil {
+		return errors.Wrapf(err, "GetIVMById(%s)", self.state.TargetVmId)
+	}
+	err = cloudprovider.WaitStatus(vm, api.VM_RUNNING, time.Second*10, time.Minute*20)
+	if err != nil {
+		return err
+	}
+	return self.attachDataDisks(ctx, vm)
+}
+
+// attachDataDisks 由数据盘镜像创建磁盘并挂载至新虚拟机, 已挂载的磁盘跳过
+func (self *SVmMigrator) attachDataDisks(ctx context.Context, vm cloudprovider.ICloudVM) error {
+	if len(self.state.Disks) < 2 {
+		return nil
+	}
+	creator, err := self.getTargetDataStorage(vm.GetIHost())
+	if err != nil {
+		return err
+	}
+	disks, err := vm.GetIDisks()
+	if err != nil {
+		return errors.Wrapf(err, "GetIDisks")
+	}
+	attached := map[string]bool{}
+	for i := range disks {
+		attached[disks[i].GetGlobalId()] = true
+	}
+	for i := 1; i < len(self.state.Disks); i++ {
+		disk := &self.state.Disks[i]
+		if len(disk.TargetDiskId) == 0 {
+			idisk, err := creator.CreateIDiskFromImage(ctx, disk.TargetImageId, &cloudprovider.DiskCreateConfig{
+				Name:   fmt.Sprintf("%s-disk%d", vm.GetName(), i),
+				SizeGb: sizeGB(disk.SizeMb),
+				Desc:   fmt.Sprintf("migrate from %s", disk.SourceDiskId),
+			})
+			if err != nil {
+				return errors.Wrapf(err, "CreateIDiskFromImage(%s)", disk.TargetImageId)
+			}
+			disk.TargetDiskId = idisk.GetGlobalId()
+			err = self.state.Save()
+			if err != nil {
+				return err
+			}
+		}
+		if attached[disk.TargetDiskId] {
+			continue
+		}
+		err = vm.AttachDisk(ctx, disk.TargetDiskId)
+		if err != nil {
+			return errors.Wrapf(err, "AttachDisk(%s)", disk.TargetDiskId)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+)
+
+const (
+	STAGE_STOP    = "stop"
+	STAGE_EXPORT  = "export"
+	STAGE_CONVERT = "convert"
+	STAGE_UPLOAD  = "upload"
+	STAGE_CREATE  = "create"
+)
+
+// 迁移阶段, 按顺序执行
+var Stages = []string{
+	STAGE_STOP,
+	STAGE_EXPORT,
+	STAGE_CONVERT,
+	STAGE_UPLOAD,
+	STAGE_CREATE,
+}
+
+// SMigrateDisk 单块磁盘的迁移状态, 系统盘在前
+type SMigrateDisk struct {
+	SourceDiskId string
+	DiskType     string
+	SizeMb       int
+
+	ExportPath   string
+	ExportFormat string
+	// 各格式镜像文件路径
+	Images map[string]string
+	// 已上传至存储桶的镜像文件
+	BucketKey string
+
+	TargetImageId string
+	TargetDiskId  string
+}
+
+type SMigrateState struct {
+	path string
+
+	SourceVmId string
+	// 已完成的阶段
+	Stages []string
+
+	// 源端由虚拟机生成的镜像
+	SourceImageId string
+	Disks         []SMigrateDisk
+
+	TargetVmId string
+
+	UpdatedAt time.Time
+}
+
+// LoadState 读取状态文件, 文件不存在时返回新的状态
+func LoadState(path, sourceVmId string) (*SMigrateState, error) {
+	state := &SMigrateState{
+		path:       path,
+		SourceVmId: sourceVmId,
+		Stages:     []string{},
+		Disks:      []SMigrateDisk{},
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, errors.Wrapf(err, "ReadFile %s", path)
+	}
+	obj, err := jsonutils.Parse(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Parse %s", path)
+	}
+	err = obj.Unmarshal(state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unmarshal %s", path)
+	}
+	if state.SourceVmId != sourceVmId {
+		return nil, errors.Wrapf(cloudprovider.ErrInputParameter, "state file %s belongs to vm %s", path, state.SourceVmId)
+	}
+	for i := range state.Disks {
+		if state.Disks[i].Images == nil {
+			state.Disks[i].Images = map[string]string{}
+		}
+	}
+	return state, nil
+}
+
+func (self *SMigrateState) IsDone(stage string) bool {
+	return utils.IsInStringArray(stage, self.Stages)
+}
+
+func (self *SMigrateState) Done(stage string) error {
+	if !self.IsDone(stage) {
+		self.Stages = append(self.Stages, stage)
+	}
+	return self.Save()
+}
+
+// Save 先写临时文件再重命名, 避免中断时状态文件损坏
+func (self *SMigrateState) Save() error {
+	self.UpdatedAt = time.Now()
+	err := os.MkdirAll(filepath.Dir(self.path), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "MkdirAll")
+	}
+	tmp := self.path + ".tmp"
+	err = ioutil.WriteFile(tmp, []byte(jsonutils.Marshal(self).PrettyString()), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "WriteFile %s", tmp)
+	}
+	return os.Rename(tmp, self.path)
+}
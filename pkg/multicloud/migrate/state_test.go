@@ -0,0 +1,58 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vm.json")
+	state, err := LoadState(path, "vm-1")
+	if err != nil {
+		t.Fatalf("load new state: %v", err)
+	}
+	if len(state.Stages) != 0 {
+		t.Fatalf("new state should have no finished stages, got %v", state.Stages)
+	}
+	state.SourceImageId = "img-1"
+	state.Disks = append(state.Disks, SMigrateDisk{SourceDiskId: "disk-1", Images: map[string]string{"qcow2": "/tmp/vm.qcow2"}}, SMigrateDisk{SourceDiskId: "disk-2"})
+	for _, stage := range []string{STAGE_STOP, STAGE_EXPORT, STAGE_EXPORT} {
+		err = state.Done(stage)
+		if err != nil {
+			t.Fatalf("done %s: %v", stage, err)
+		}
+	}
+
+	state, err = LoadState(path, "vm-1")
+	if err != nil {
+		t.Fatalf("reload state: %v", err)
+	}
+	if len(state.Stages) != 2 || !state.IsDone(STAGE_STOP) || !state.IsDone(STAGE_EXPORT) || state.IsDone(STAGE_CONVERT) {
+		t.Errorf("unexpected stages %v", state.Stages)
+	}
+	if state.SourceImageId != "img-1" || len(state.Disks) != 2 || state.Disks[0].Images["qcow2"] != "/tmp/vm.qcow2" {
+		t.Errorf("unexpected state %#v", state)
+	}
+	if state.Disks[1].SourceDiskId != "disk-2" || state.Disks[1].Images == nil {
+		t.Errorf("unexpected data disk state %#v", state.Disks[1])
+	}
+
+	_, err = LoadState(path, "vm-2")
+	if err == nil {
+		t.Errorf("state of another vm should not be loaded")
+	}
+}
@@ -209,12 +209,24 @@ func (self *SDisk) GetGlobalId() string {
 	return self.UUID
 }
 
+// CreateISnapshot takes a snapshot of the vm the disk attached to, nutanix has no per-disk snapshot
 func (self *SDisk) CreateISnapshot(ctx context.Context, name, desc string) (cloudprovider.ICloudSnapshot, error) {
-	return nil, cloudprovider.ErrNotSupported
+	if len(self.AttachedVMUUID) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "disk %s not attached", self.UUID)
+	}
+	snapshot, err := self.storage.zone.region.CreateSnapshot(self.AttachedVMUUID, name)
+	if err != nil {
+		return nil, err
+	}
+	return &SDiskSnapshot{snapshot: *snapshot, disk: self}, nil
 }
 
+// Delete removes the volume group created by CreateDisk, vdisks of vms are removed with the vm
 func (self *SDisk) Delete(ctx context.Context) error {
-	return cloudprovider.ErrNotSupported
+	if len(self.AttachedVolumeGroupID) == 0 {
+		return cloudprovider.ErrNotSupported
+	}
+	return self.storage.zone.region.DeleteVolumeGroup(self.AttachedVolumeGroupID)
 }
 
 func (self *SDisk) GetAccessPath() string {
@@ -285,8 +297,27 @@ func (self *SDisk) Rebuild(ctx context.Context) error {
 	return cloudprovider.ErrNotSupported
 }
 
+// Reset restores the vm to the snapshot, the vdisk at the same address is returned as it may be recreated
 func (self *SDisk) Reset(ctx context.Context, snapshotId string) (string, error) {
-	return "", cloudprovider.ErrNotSupported
+	snapshot, err := self.GetISnapshot(snapshotId)
+	if err != nil {
+		return "", errors.Wrapf(err, "GetISnapshot(%s)", snapshotId)
+	}
+	region := self.storage.zone.region
+	err = region.ResetToSnapshot(self.AttachedVMUUID, snapshot.(*SDiskSnapshot).snapshot.UUID)
+	if err != nil {
+		return "", err
+	}
+	disks, err := region.GetDisks("", self.AttachedVMUUID)
+	if err != nil {
+		return "", errors.Wrapf(err, "GetDisks")
+	}
+	for i := range disks {
+		if disks[i].DiskAddress == self.DiskAddress {
+			return disks[i].GetGlobalId(), nil
+		}
+	}
+	return "", errors.Wrapf(cloudprovider.ErrNotFound, "disk %s after reset", self.DiskAddress)
 }
 
 func (self *SDisk) Resize(ctx context.Context, sizeMb int64) error {
@@ -325,11 +356,40 @@ func (self *SDisk) GetIStorage() (cloudprovider.ICloudStorage, error) {
 }
 
 func (self *SDisk) GetISnapshot(snapshotId string) (cloudprovider.ICloudSnapshot, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	id, diskId, err := parseDiskSnapshotId(snapshotId)
+	if err != nil {
+		return nil, err
+	}
+	if diskId != self.UUID {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, snapshotId)
+	}
+	snapshot, err := self.storage.zone.region.GetSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.VMUUID != self.AttachedVMUUID {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, snapshotId)
+	}
+	return &SDiskSnapshot{snapshot: *snapshot, disk: self}, nil
 }
 
 func (self *SDisk) GetISnapshots() ([]cloudprovider.ICloudSnapshot, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	ret := []cloudprovider.ICloudSnapshot{}
+	if len(self.AttachedVMUUID) == 0 {
+		return ret, nil
+	}
+	snapshots, err := self.storage.zone.region.GetSnapshots(self.AttachedVMUUID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		// the disk may be attached after the snapshot was taken
+		if _, err := snapshots[i].getDiskClone(self.DiskAddress); err != nil {
+			continue
+		}
+		ret = append(ret, &SDiskSnapshot{snapshot: snapshots[i], disk: self})
+	}
+	return ret, nil
 }
 
 func (self *SRegion) GetDisks(storageId, vmId string) ([]SDisk, error) {
@@ -353,3 +413,74 @@ func (self *SRegion) GetDisk(id string) (*SDisk, error) {
 	disk := &SDisk{}
 	return disk, self.get("virtual_disks", id, url.Values{}, disk)
 }
+
+// CreateDisk creates a volume group with a single vdisk, nutanix does not support standalone vdisks
+func (self *SRegion) CreateDisk(storageId string, name, desc string, sizeGb int) (*SDisk, error) {
+	params := map[string]interface{}{
+		"name":        name,
+		"description": desc,
+		"is_shared":   false,
+		"disk_list": []map[string]interface{}{
+			{
+				"vm_disk_create": map[string]interface{}{
+					"storage_container_uuid": storageId,
+					"size":                   int64(sizeGb) * 1024 * 1024 * 1024,
+				},
+			},
+		},
+	}
+	ret := struct {
+		TaskUUID string
+	}{}
+	err := self.post("volume_groups", jsonutils.Marshal(params), &ret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create volume group")
+	}
+	vgId, err := self.cli.wait(ret.TaskUUID)
+	if err != nil {
+		return nil, err
+	}
+	vg := struct {
+		DiskList []struct {
+			VmdiskUUID string `json:"vmdisk_uuid"`
+		} `json:"disk_list"`
+	}{}
+	err = self.get("volume_groups", vgId, url.Values{}, &vg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get volume group %s", vgId)
+	}
+	if len(vg.DiskList) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "disk of volume group %s", vgId)
+	}
+	return self.GetDisk(vg.DiskList[0].VmdiskUUID)
+}
+
+func (self *SRegion) DeleteVolumeGroup(vgId string) error {
+	return self.delete("volume_groups", vgId)
+}
+
+// AttachVolumeGroup attaches all vdisks of the volume group to the vm
+func (self *SRegion) AttachVolumeGroup(vgId, vmId string) error {
+	return self.operateVolumeGroup(vgId, vmId, "attach")
+}
+
+func (self *SRegion) DetachVolumeGroup(vgId, vmId string) error {
+	return self.operateVolumeGroup(vgId, vmId, "detach")
+}
+
+func (self *SRegion) operateVolumeGroup(vgId, vmId, action string) error {
+	params := map[string]interface{}{
+		"operation": strings.ToUpper(action),
+		"vm_uuid":   vmId,
+	}
+	ret := struct {
+		TaskUUID string
+	}{}
+	res := fmt.Sprintf("volume_groups/%s/%s", vgId, action)
+	err := self.post(res, jsonutils.Marshal(params), &ret)
+	if err != nil {
+		return errors.Wrapf(err, "%s volume group %s", action, vgId)
+	}
+	_, err = self.cli.wait(ret.TaskUUID)
+	return err
+}
@@ -423,7 +423,17 @@ func (self *SHost) GetHostStatus() string {
 }
 
 func (self *SHost) GetIHostNics() ([]cloudprovider.ICloudHostNetInterface, error) {
-	return []cloudprovider.ICloudHostNetInterface{}, nil
+	nics, err := self.zone.region.GetHostNics(self.UUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetHostNics")
+	}
+	ret := []cloudprovider.ICloudHostNetInterface{}
+	for i := range nics {
+		nics[i].host = self
+		nics[i].index = int8(i)
+		ret = append(ret, &nics[i])
+	}
+	return ret, nil
 }
 
 func (self *SHost) GetIsMaintenance() bool {
@@ -0,0 +1,91 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutanix
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/pkg/tristate"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+)
+
+type SHostNic struct {
+	host  *SHost
+	index int8
+
+	UUID               string   `json:"uuid"`
+	HostUUID           string   `json:"host_uuid"`
+	NodeUUID           string   `json:"node_uuid"`
+	Name               string   `json:"name"`
+	MacAddress         string   `json:"mac_address"`
+	MtuInBytes         int32    `json:"mtu_in_bytes"`
+	LinkSpeedInKbps    int64    `json:"link_speed_in_kbps"`
+	LinkCapacityInMbps int64    `json:"link_capacity_in_mbps"`
+	Ipv4Addresses      []string `json:"ipv4_addresses"`
+	Ipv6Addresses      []string `json:"ipv6_addresses"`
+}
+
+func (self *SHostNic) GetDevice() string {
+	return self.Name
+}
+
+func (self *SHostNic) GetDriver() string {
+	return ""
+}
+
+func (self *SHostNic) GetMac() string {
+	return strings.ToLower(self.MacAddress)
+}
+
+func (self *SHostNic) GetIndex() int8 {
+	return self.index
+}
+
+func (self *SHostNic) IsLinkUp() tristate.TriState {
+	if self.LinkSpeedInKbps > 0 {
+		return tristate.True
+	}
+	return tristate.False
+}
+
+func (self *SHostNic) GetIpAddr() string {
+	if len(self.Ipv4Addresses) > 0 {
+		return self.Ipv4Addresses[0]
+	}
+	return ""
+}
+
+func (self *SHostNic) GetMtu() int32 {
+	return self.MtuInBytes
+}
+
+func (self *SHostNic) GetNicType() string {
+	if len(self.host.HypervisorAddress) > 0 && utils.IsInStringArray(self.host.HypervisorAddress, self.Ipv4Addresses) {
+		return api.NIC_TYPE_ADMIN
+	}
+	return ""
+}
+
+func (self *SHostNic) GetBridge() string {
+	return ""
+}
+
+func (self *SRegion) GetHostNics(hostId string) ([]SHostNic, error) {
+	nics := []SHostNic{}
+	return nics, self.get("hosts", fmt.Sprintf("%s/host_nics", hostId), nil, &nics)
+}
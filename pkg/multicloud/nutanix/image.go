@@ -247,3 +247,29 @@ func (self *SRegion) CreateImage(storageId string, opts *cloudprovider.SImageCre
 	}
 	return self.GetImage(imageId)
 }
+
+func (self *SRegion) CreateImageFromDisk(storageId, diskId, name, desc string) (*SImage, error) {
+	params := map[string]interface{}{
+		"image_type": "DISK_IMAGE",
+		"name":       name,
+		"annotation": desc,
+		"vm_disk_clone": map[string]interface{}{
+			"disk_address": map[string]interface{}{
+				"vmdisk_uuid": diskId,
+			},
+			"storage_container_uuid": storageId,
+		},
+	}
+	ret := struct {
+		TaskUUID string
+	}{}
+	err := self.post("images", jsonutils.Marshal(params), &ret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create image from disk %s", diskId)
+	}
+	imageId, err := self.cli.wait(ret.TaskUUID)
+	if err != nil {
+		return nil, err
+	}
+	return self.GetImage(imageId)
+}
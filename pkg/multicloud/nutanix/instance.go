@@ -16,6 +16,7 @@ package nutanix
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/url"
 	"sort"
@@ -187,8 +188,16 @@ func (self *SInstance) CreateDisk(ctx context.Context, opts *cloudprovider.Guest
 	return self.host.zone.region.cli.wait(ret.TaskUUID)
 }
 
+// AttachDisk attaches the volume group of a disk created by SStorage.CreateIDisk
 func (self *SInstance) AttachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotSupported
+	disk, err := self.host.zone.region.GetDisk(diskId)
+	if err != nil {
+		return errors.Wrapf(err, "GetDisk(%s)", diskId)
+	}
+	if len(disk.AttachedVolumeGroupID) == 0 {
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "disk %s not in volume group", diskId)
+	}
+	return self.host.zone.region.AttachVolumeGroup(disk.AttachedVolumeGroupID, self.UUID)
 }
 
 func (self *SInstance) ChangeConfig(ctx context.Context, opts *cloudprovider.SManagedVMChangeConfig) error {
@@ -209,7 +218,7 @@ func (self *SInstance) DeployVM(ctx context.Context, name string, username strin
 }
 
 func (self *SInstance) DetachDisk(ctx context.Context, diskId string) error {
-	return cloudprovider.ErrNotImplemented
+	return self.host.zone.region.DetachDisk(self.UUID, diskId)
 }
 
 func (self *SInstance) GetBios() cloudprovider.TBiosType {
@@ -357,7 +366,7 @@ func (self *SInstance) GetSecurityGroupIds() ([]string, error) {
 }
 
 func (self *SInstance) GetVNCInfo(input *cloudprovider.ServerVncInput) (*cloudprovider.ServerVncOutput, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return self.host.zone.region.GetVNCInfo(self.UUID, self.Name)
 }
 
 func (self *SInstance) GetVcpuCount() int {
@@ -396,8 +405,39 @@ func (self *SInstance) StopVM(ctx context.Context, opts *cloudprovider.ServerSto
 	return self.host.zone.region.SetInstancePowerState(self.UUID, act)
 }
 
+func (self *SInstance) CreateInstanceSnapshot(ctx context.Context, name string, desc string) (cloudprovider.ICloudInstanceSnapshot, error) {
+	return self.host.zone.region.CreateSnapshot(self.UUID, name)
+}
+
+func (self *SInstance) GetInstanceSnapshot(idStr string) (cloudprovider.ICloudInstanceSnapshot, error) {
+	snapshot, err := self.host.zone.region.GetSnapshot(idStr)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.VMUUID != self.UUID {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "snapshot %s of vm %s", idStr, self.UUID)
+	}
+	return snapshot, nil
+}
+
+func (self *SInstance) GetInstanceSnapshots() ([]cloudprovider.ICloudInstanceSnapshot, error) {
+	snapshots, err := self.host.zone.region.GetSnapshots(self.UUID)
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudInstanceSnapshot{}
+	for i := range snapshots {
+		ret = append(ret, &snapshots[i])
+	}
+	return ret, nil
+}
+
+func (self *SInstance) ResetToInstanceSnapshot(ctx context.Context, idStr string) error {
+	return self.host.zone.region.ResetToSnapshot(self.UUID, idStr)
+}
+
 func (self *SInstance) UpdateUserData(userData string) error {
-	return cloudprovider.ErrNotImplemented
+	return self.host.zone.region.UpdateUserData(self.UUID, userData)
 }
 
 func (self *SInstance) UpdateVM(ctx context.Context, name string) error {
@@ -420,3 +460,108 @@ func (self *SRegion) SetInstancePowerState(id string, state string) error {
 func (self *SRegion) DeleteVM(id string) error {
 	return self.delete("vms", id)
 }
+
+func (self *SRegion) DetachDisk(vmId, diskId string) error {
+	vm, err := self.GetInstance(vmId)
+	if err != nil {
+		return errors.Wrapf(err, "GetInstance(%s)", vmId)
+	}
+	for _, disk := range vm.VMDiskInfo {
+		if disk.DiskAddress.VmdiskUUID != diskId {
+			continue
+		}
+		params := map[string]interface{}{
+			"vm_disks": []map[string]interface{}{
+				{
+					"disk_address": map[string]interface{}{
+						"vmdisk_uuid":  disk.DiskAddress.VmdiskUUID,
+						"device_bus":   disk.DiskAddress.DeviceBus,
+						"device_index": disk.DiskAddress.DeviceIndex,
+					},
+				},
+			},
+		}
+		ret := struct {
+			TaskUUID string
+		}{}
+		res := fmt.Sprintf("vms/%s/disks/detach", vmId)
+		err = self.post(res, jsonutils.Marshal(params), &ret)
+		if err != nil {
+			return err
+		}
+		_, err = self.cli.wait(ret.TaskUUID)
+		return err
+	}
+	disk, err := self.GetDisk(diskId)
+	if err != nil {
+		return errors.Wrapf(err, "GetDisk(%s)", diskId)
+	}
+	if len(disk.AttachedVolumeGroupID) > 0 {
+		return self.DetachVolumeGroup(disk.AttachedVolumeGroupID, vmId)
+	}
+	// already detached
+	return nil
+}
+
+// GetVNCInfo returns the prism console websocket, which is authenticated by the prism session cookies
+func (self *SRegion) GetVNCInfo(vmId, name string) (*cloudprovider.ServerVncOutput, error) {
+	cookies, err := self.cli.getSessionCookies()
+	if err != nil {
+		return nil, err
+	}
+	values := []string{}
+	for _, cookie := range cookies {
+		values = append(values, fmt.Sprintf("%s=%s", cookie.Name, cookie.Value))
+	}
+	ret := &cloudprovider.ServerVncOutput{
+		Protocol:      CLOUD_PROVIDER_NUTANIX,
+		InstanceId:    vmId,
+		InstanceName:  name,
+		Host:          self.cli.host,
+		Port:          int64(self.cli.port),
+		Url:           fmt.Sprintf("wss://%s:%d/vnc/vm/%s/proxy", self.cli.host, self.cli.port, vmId),
+		ConnectParams: strings.Join(values, "; "),
+		Hypervisor:    api.HYPERVISOR_NUTANIX,
+	}
+	return ret, nil
+}
+
+// UpdateUserData replaces the cloud-init user data through the v3 vm intent spec,
+// it takes effect when cloud-init runs in the guest again
+func (self *SRegion) UpdateUserData(vmId, userData string) error {
+	vm, err := self.getV3("vms", vmId)
+	if err != nil {
+		return errors.Wrapf(err, "get vm %s", vmId)
+	}
+	spec, err := vm.GetMap("spec")
+	if err != nil {
+		return errors.Wrapf(err, "get spec")
+	}
+	resources, err := vm.Get("spec", "resources")
+	if err != nil {
+		return errors.Wrapf(err, "get resources")
+	}
+	metadata, err := vm.Get("metadata")
+	if err != nil {
+		return errors.Wrapf(err, "get metadata")
+	}
+	customization := jsonutils.Marshal(map[string]interface{}{
+		"cloud_init": map[string]interface{}{
+			"user_data": base64.StdEncoding.EncodeToString([]byte(userData)),
+		},
+	})
+	resourcesDict, ok := resources.(*jsonutils.JSONDict)
+	if !ok {
+		return errors.Errorf("invalid vm %s resources", vmId)
+	}
+	resourcesDict.Set("guest_customization", customization)
+	specDict := jsonutils.NewDict()
+	for k, v := range spec {
+		specDict.Set(k, v)
+	}
+	specDict.Set("resources", resourcesDict)
+	body := jsonutils.NewDict()
+	body.Set("spec", specDict)
+	body.Set("metadata", metadata)
+	return self.updateV3("vms", vmId, body)
+}
@@ -179,6 +179,64 @@ func (self *SNutanixClient) upload(res, id string, header http.Header, body io.R
 	return self._upload(res, id, header, body)
 }
 
+func (self *SNutanixClient) getBaseDomainV3() string {
+	return self._getBaseDomain(NUTANIX_VERSION_V3)
+}
+
+func (self *SNutanixClient) getV3(res, id string) (jsonutils.JSONObject, error) {
+	url := fmt.Sprintf("%s/%s/%s", self.getBaseDomainV3(), res, id)
+	resp, err := self.jsonRequest(httputils.GET, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get v3 %s/%s", res, id)
+	}
+	return resp, nil
+}
+
+// updateV3 puts the v3 intent spec and waits the task in the returned execution context
+func (self *SNutanixClient) updateV3(res, id string, body jsonutils.JSONObject) error {
+	url := fmt.Sprintf("%s/%s/%s", self.getBaseDomainV3(), res, id)
+	resp, err := self.jsonRequest(httputils.PUT, url, body)
+	if err != nil {
+		return errors.Wrapf(err, "update v3 %s/%s", res, id)
+	}
+	taskId, _ := resp.GetString("status", "execution_context", "task_uuid")
+	if len(taskId) > 0 {
+		_, err = self.wait(taskId)
+		return err
+	}
+	return nil
+}
+
+// download returns the raw response of a v3 file api, the caller should close the body
+func (self *SNutanixClient) download(res, id string) (*http.Response, error) {
+	url := fmt.Sprintf("%s/%s/%s/file", self.getBaseDomainV3(), res, id)
+	client := self.getDefaultClient(time.Hour * 5)
+	resp, err := _rawRequest(client, httputils.GET, url, nil, nil, self.debug)
+	if err != nil {
+		return nil, errors.Wrapf(err, "download %s/%s", res, id)
+	}
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, errors.Errorf("download %s/%s status code: %d", res, id, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// getSessionCookies logins prism with basic auth, the session cookies are needed by the console websocket
+func (self *SNutanixClient) getSessionCookies() ([]*http.Cookie, error) {
+	url := fmt.Sprintf("%s/clusters", self.getBaseDomain())
+	client := self.getDefaultClient(time.Duration(0))
+	resp, err := _rawRequest(client, httputils.GET, url, nil, nil, self.debug)
+	if err != nil {
+		return nil, errors.Wrapf(err, "login")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, errors.Errorf("login status code: %d", resp.StatusCode)
+	}
+	return resp.Cookies(), nil
+}
+
 func (self *SNutanixClient) _delete(res, id string) (jsonutils.JSONObject, error) {
 	url := fmt.Sprintf("%s/%s/%s", self.getBaseDomain(), res, id)
 	return self.jsonRequest(httputils.DELETE, url, nil)
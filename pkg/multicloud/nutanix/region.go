@@ -220,3 +220,15 @@ func (self *SRegion) getTask(id string) (*STask, error) {
 	task := &STask{}
 	return task, self.get("tasks", id, nil, task)
 }
+
+func (self *SRegion) getV3(res string, id string) (jsonutils.JSONObject, error) {
+	return self.cli.getV3(res, id)
+}
+
+func (self *SRegion) updateV3(res string, id string, body jsonutils.JSONObject) error {
+	return self.cli.updateV3(res, id, body)
+}
+
+func (self *SRegion) download(res string, id string) (*http.Response, error) {
+	return self.cli.download(res, id)
+}
@@ -47,4 +47,20 @@ func init() {
 		return nil
 	})
 
+	type DiskCreateOptions struct {
+		STORAGE_ID string
+		NAME       string
+		SIZE_GB    int
+		Desc       string
+	}
+
+	shellutils.R(&DiskCreateOptions{}, "disk-create", "create disk", func(cli *nutanix.SRegion, args *DiskCreateOptions) error {
+		disk, err := cli.CreateDisk(args.STORAGE_ID, args.NAME, args.Desc, args.SIZE_GB)
+		if err != nil {
+			return err
+		}
+		printObject(disk)
+		return nil
+	})
+
 }
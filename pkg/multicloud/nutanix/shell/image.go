@@ -78,4 +78,39 @@ func init() {
 		return nil
 	})
 
+	type ImageCreateOptions struct {
+		STORAGE_ID string
+		DISK_ID    string
+		NAME       string
+		Desc       string
+	}
+
+	shellutils.R(&ImageCreateOptions{}, "image-create", "create image from disk", func(cli *nutanix.SRegion, args *ImageCreateOptions) error {
+		image, err := cli.CreateImageFromDisk(args.STORAGE_ID, args.DISK_ID, args.NAME, args.Desc)
+		if err != nil {
+			return err
+		}
+		printObject(image)
+		return nil
+	})
+
+	type ImageDownloadOptions struct {
+		STORAGE_ID string
+		ID         string
+		PATH       string
+	}
+
+	shellutils.R(&ImageDownloadOptions{}, "image-download", "download image", func(cli *nutanix.SRegion, args *ImageDownloadOptions) error {
+		cache, err := cli.GetIStoragecacheById(args.STORAGE_ID)
+		if err != nil {
+			return err
+		}
+		ret, err := cache.DownloadImage(args.ID, args.ID, args.PATH)
+		if err != nil {
+			return err
+		}
+		printObject(ret)
+		return nil
+	})
+
 }
@@ -45,4 +45,70 @@ func init() {
 		return nil
 	})
 
+	shellutils.R(&InstanceIdOptions{}, "instance-vnc", "show instance vnc", func(cli *nutanix.SRegion, args *InstanceIdOptions) error {
+		vm, err := cli.GetInstance(args.ID)
+		if err != nil {
+			return err
+		}
+		info, err := cli.GetVNCInfo(vm.UUID, vm.Name)
+		if err != nil {
+			return err
+		}
+		printObject(info)
+		return nil
+	})
+
+	type InstanceDiskOptions struct {
+		ID      string
+		DISK_ID string
+	}
+
+	shellutils.R(&InstanceDiskOptions{}, "instance-detach-disk", "detach instance disk", func(cli *nutanix.SRegion, args *InstanceDiskOptions) error {
+		return cli.DetachDisk(args.ID, args.DISK_ID)
+	})
+
+	type InstanceUserDataOptions struct {
+		ID        string
+		USER_DATA string
+	}
+
+	shellutils.R(&InstanceUserDataOptions{}, "instance-update-userdata", "update instance user data", func(cli *nutanix.SRegion, args *InstanceUserDataOptions) error {
+		return cli.UpdateUserData(args.ID, args.USER_DATA)
+	})
+
+	type InstanceSnapshotListOptions struct {
+		ID string
+	}
+
+	shellutils.R(&InstanceSnapshotListOptions{}, "instance-snapshot-list", "list instance snapshots", func(cli *nutanix.SRegion, args *InstanceSnapshotListOptions) error {
+		snapshots, err := cli.GetSnapshots(args.ID)
+		if err != nil {
+			return err
+		}
+		printList(snapshots, 0, 0, 0, []string{})
+		return nil
+	})
+
+	type InstanceSnapshotCreateOptions struct {
+		ID   string
+		NAME string
+	}
+
+	shellutils.R(&InstanceSnapshotCreateOptions{}, "instance-snapshot-create", "create instance snapshot", func(cli *nutanix.SRegion, args *InstanceSnapshotCreateOptions) error {
+		snapshot, err := cli.CreateSnapshot(args.ID, args.NAME)
+		if err != nil {
+			return err
+		}
+		printObject(snapshot)
+		return nil
+	})
+
+	type SnapshotIdOptions struct {
+		ID string
+	}
+
+	shellutils.R(&SnapshotIdOptions{}, "snapshot-delete", "delete snapshot", func(cli *nutanix.SRegion, args *SnapshotIdOptions) error {
+		return cli.DeleteSnapshot(args.ID)
+	})
+
 }
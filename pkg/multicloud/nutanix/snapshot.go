@@ -0,0 +1,254 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutanix
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SnapshotVMDisk struct {
+	IsCdrom     bool        `json:"is_cdrom"`
+	DiskAddress DiskAddress `json:"disk_address"`
+	VMDiskClone struct {
+		DiskAddress DiskAddress `json:"disk_address"`
+	} `json:"vm_disk_clone"`
+}
+
+type SVMCreateSpec struct {
+	Name    string           `json:"name"`
+	VMDisks []SnapshotVMDisk `json:"vm_disks"`
+}
+
+type SSnapshot struct {
+	multicloud.SResourceBase
+	multicloud.STagBase
+
+	region *SRegion
+
+	UUID             string        `json:"uuid"`
+	SnapshotName     string        `json:"snapshot_name"`
+	VMUUID           string        `json:"vm_uuid"`
+	GroupUUID        string        `json:"group_uuid"`
+	Deleted          bool          `json:"deleted"`
+	CreatedTime      int64         `json:"created_time"`
+	LogicalTimestamp int           `json:"logical_timestamp"`
+	VMCreateSpec     SVMCreateSpec `json:"vm_create_spec"`
+}
+
+// getDiskClone returns the vdisk kept by the snapshot for the disk at the same address
+func (self *SSnapshot) getDiskClone(diskAddress string) (string, error) {
+	for _, disk := range self.VMCreateSpec.VMDisks {
+		if disk.IsCdrom {
+			continue
+		}
+		addr := fmt.Sprintf("%s.%d", disk.DiskAddress.DeviceBus, disk.DiskAddress.DeviceIndex)
+		if addr == diskAddress && len(disk.VMDiskClone.DiskAddress.VmdiskUUID) > 0 {
+			return disk.VMDiskClone.DiskAddress.VmdiskUUID, nil
+		}
+	}
+	return "", errors.Wrapf(cloudprovider.ErrNotFound, "disk %s in snapshot %s", diskAddress, self.UUID)
+}
+
+// SDiskSnapshot is the view of a vm snapshot from one of the disks it covers
+type SDiskSnapshot struct {
+	multicloud.SResourceBase
+	multicloud.STagBase
+
+	snapshot SSnapshot
+	disk     *SDisk
+}
+
+func (self *SDiskSnapshot) GetId() string {
+	return self.GetGlobalId()
+}
+
+func (self *SDiskSnapshot) GetName() string {
+	return self.snapshot.SnapshotName
+}
+
+func (self *SDiskSnapshot) GetGlobalId() string {
+	return fmt.Sprintf("%s/%s", self.snapshot.UUID, self.disk.GetGlobalId())
+}
+
+func (self *SDiskSnapshot) GetStatus() string {
+	return api.SNAPSHOT_READY
+}
+
+func (self *SDiskSnapshot) GetProjectId() string {
+	return ""
+}
+
+func (self *SDiskSnapshot) GetCreatedAt() time.Time {
+	return self.snapshot.GetCreatedAt()
+}
+
+func (self *SDiskSnapshot) GetSizeMb() int32 {
+	return int32(self.disk.GetDiskSizeMB())
+}
+
+func (self *SDiskSnapshot) GetDiskId() string {
+	return self.disk.GetGlobalId()
+}
+
+func (self *SDiskSnapshot) GetDiskType() string {
+	return self.disk.GetDiskType()
+}
+
+// Delete removes the whole vm snapshot, which is shared by all disks of the vm
+func (self *SDiskSnapshot) Delete() error {
+	return self.snapshot.Delete()
+}
+
+// parseDiskSnapshotId splits the disk snapshot id into vm snapshot id and disk id
+func parseDiskSnapshotId(id string) (string, string, error) {
+	info := strings.Split(id, "/")
+	if len(info) != 2 {
+		return "", "", errors.Wrapf(cloudprovider.ErrNotFound, "invalid disk snapshot id %s", id)
+	}
+	return info[0], info[1], nil
+}
+
+func (self *SSnapshot) GetId() string {
+	return self.UUID
+}
+
+func (self *SSnapshot) GetName() string {
+	return self.SnapshotName
+}
+
+func (self *SSnapshot) GetGlobalId() string {
+	return self.UUID
+}
+
+func (self *SSnapshot) GetDescription() string {
+	return ""
+}
+
+func (self *SSnapshot) GetStatus() string {
+	return api.INSTANCE_SNAPSHOT_READY
+}
+
+func (self *SSnapshot) GetCreatedAt() time.Time {
+	return time.Unix(self.CreatedTime/1000000, self.CreatedTime%1000000*1000)
+}
+
+func (self *SSnapshot) GetProjectId() string {
+	return ""
+}
+
+func (self *SSnapshot) Refresh() error {
+	snapshot, err := self.region.GetSnapshot(self.UUID)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, snapshot)
+}
+
+func (self *SSnapshot) Delete() error {
+	return self.region.DeleteSnapshot(self.UUID)
+}
+
+func (self *SRegion) GetSnapshots(vmId string) ([]SSnapshot, error) {
+	snapshots := []SSnapshot{}
+	params := url.Values{}
+	if len(vmId) > 0 {
+		params.Set("vm_uuid", vmId)
+	}
+	err := self.listAll("snapshots", params, &snapshots)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SSnapshot{}
+	for i := range snapshots {
+		if !snapshots[i].Deleted {
+			snapshots[i].region = self
+			ret = append(ret, snapshots[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetSnapshot(id string) (*SSnapshot, error) {
+	snapshot := &SSnapshot{region: self}
+	return snapshot, self.get("snapshots", id, url.Values{}, snapshot)
+}
+
+func (self *SRegion) CreateSnapshot(vmId, name string) (*SSnapshot, error) {
+	params := map[string]interface{}{
+		"snapshot_specs": []map[string]interface{}{
+			{
+				"vm_uuid":       vmId,
+				"snapshot_name": name,
+			},
+		},
+	}
+	ret := struct {
+		TaskUUID string
+	}{}
+	err := self.post("snapshots", jsonutils.Marshal(params), &ret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create snapshot")
+	}
+	_, err = self.cli.wait(ret.TaskUUID)
+	if err != nil {
+		return nil, err
+	}
+	// the task entities contain both the vm and the snapshot, so find the latest snapshot by name
+	snapshots, err := self.GetSnapshots(vmId)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot *SSnapshot
+	for i := range snapshots {
+		if snapshots[i].SnapshotName == name && (snapshot == nil || snapshots[i].CreatedTime > snapshot.CreatedTime) {
+			snapshot = &snapshots[i]
+		}
+	}
+	if snapshot == nil {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "snapshot %s of vm %s", name, vmId)
+	}
+	return snapshot, nil
+}
+
+func (self *SRegion) DeleteSnapshot(id string) error {
+	return self.delete("snapshots", id)
+}
+
+func (self *SRegion) ResetToSnapshot(vmId, id string) error {
+	params := map[string]interface{}{
+		"snapshot_uuid":                 id,
+		"restore_network_configuration": true,
+	}
+	ret := struct {
+		TaskUUID string
+	}{}
+	res := fmt.Sprintf("vms/%s/restore", vmId)
+	err := self.post(res, jsonutils.Marshal(params), &ret)
+	if err != nil {
+		return errors.Wrapf(err, "restore vm %s to snapshot %s", vmId, id)
+	}
+	_, err = self.cli.wait(ret.TaskUUID)
+	return err
+}
@@ -229,7 +229,12 @@ func (self *SStorage) GetIDisks() ([]cloudprovider.ICloudDisk, error) {
 }
 
 func (self *SStorage) CreateIDisk(conf *cloudprovider.DiskCreateConfig) (cloudprovider.ICloudDisk, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	disk, err := self.zone.region.CreateDisk(self.StorageContainerUUID, conf.Name, conf.Desc, conf.SizeGb)
+	if err != nil {
+		return nil, err
+	}
+	disk.storage = self
+	return disk, nil
 }
 
 func (self *SStorage) GetCapacityMB() int64 {
@@ -16,8 +16,10 @@ package nutanix
 
 import (
 	"context"
+	"strings"
 
 	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/qemuimgfmt"
 
@@ -82,19 +84,72 @@ func (self *SStoragecache) GetPath() string {
 	return ""
 }
 
+// CreateIImage clones a vdisk into a disk image, the snapshotId is either a disk snapshot or a vdisk id
 func (self *SStoragecache) CreateIImage(snapshotId, imageName, osType, imageDesc string) (cloudprovider.ICloudImage, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	diskId := snapshotId
+	if strings.Contains(snapshotId, "/") {
+		id, _diskId, err := parseDiskSnapshotId(snapshotId)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, err := self.region.GetSnapshot(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetSnapshot(%s)", id)
+		}
+		disk, err := self.region.GetDisk(_diskId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetDisk(%s)", _diskId)
+		}
+		diskId, err = snapshot.getDiskClone(disk.DiskAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+	image, err := self.region.CreateImageFromDisk(self.storage.StorageContainerUUID, diskId, imageName, imageDesc)
+	if err != nil {
+		return nil, err
+	}
+	image.cache = self
+	return image, nil
 }
 
 func (self *SStoragecache) DownloadImage(imageId string, extId string, path string) (jsonutils.JSONObject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return self.ExportImage(context.Background(), extId, &multicloud.SImageDownloadOptions{
+		Path: path,
+		Callback: func(progress float32) {
+			log.Debugf("download image %s(%s) progress: %.2f%%", imageId, extId, progress)
+		},
+	})
+}
+
+func (self *SStoragecache) ExportImage(ctx context.Context, imageId string, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	image, err := self.region.GetImage(imageId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetImage(%s)", imageId)
+	}
+	resp, err := self.region.download("images", imageId)
+	if err != nil {
+		return nil, err
+	}
+	size := resp.ContentLength
+	if size <= 0 {
+		size = image.VMDiskSize
+	}
+	stream := &multicloud.SImageStream{
+		Body:   resp.Body,
+		Size:   size,
+		Format: image.GetImageFormat(),
+	}
+	return multicloud.SaveImage(ctx, stream, opts)
 }
 
 func (self *SStoragecache) UploadImage(ctx context.Context, opts *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
-	reader, size, err := opts.GetReader(opts.ImageId, string(qemuimgfmt.QCOW2))
+	reader, err := multicloud.GetImageReader(ctx, opts, qemuimgfmt.QCOW2, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	size, callback := reader.Size, reader.Callback
 
 	image, err := self.region.CreateImage(self.storage.StorageContainerUUID, opts, size, reader, callback)
 	if err != nil {
@@ -0,0 +1,532 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openstack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/httputils"
+	"yunion.io/x/pkg/util/stringutils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+const (
+	SWIFT_META_OBJECT_PREFIX    = "X-Object-Meta-"
+	SWIFT_META_CONTAINER_PREFIX = "X-Container-Meta-"
+
+	SWIFT_ACL_PUBLIC_READ  = ".r:*,.rlistings"
+	SWIFT_ACL_PUBLIC_WRITE = "*:*"
+
+	// swift json listing time has no timezone, it is always UTC
+	SWIFT_TIME_FORMAT = "2006-01-02T15:04:05.999999"
+)
+
+type SBucket struct {
+	multicloud.SBaseBucket
+	OpenStackTags
+
+	region *SRegion
+
+	Name         string `json:"name"`
+	Count        int    `json:"count"`
+	Bytes        int64  `json:"bytes"`
+	LastModified string `json:"last_modified"`
+
+	readAcl       string
+	writeAcl      string
+	storagePolicy string
+	createdAt     time.Time
+	fetched       bool
+}
+
+func parseSwiftTime(str string) time.Time {
+	tm, _ := time.Parse(SWIFT_TIME_FORMAT, str)
+	return tm
+}
+
+// swiftPath escape container and object name, but keep the "/" in object name
+func swiftPath(container, key string) string {
+	path := "/" + url.PathEscape(container)
+	if len(key) > 0 {
+		segs := strings.Split(key, "/")
+		for i := range segs {
+			segs[i] = url.PathEscape(segs[i])
+		}
+		path += "/" + strings.Join(segs, "/")
+	}
+	return path
+}
+
+func (b *SBucket) GetId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetGlobalId() string {
+	return b.Name
+}
+
+func (b *SBucket) GetName() string {
+	return b.Name
+}
+
+func (b *SBucket) GetProjectId() string {
+	return b.region.client.tokenCredential.GetTenantId()
+}
+
+func (b *SBucket) GetLocation() string {
+	return b.region.Name
+}
+
+func (b *SBucket) GetIRegion() cloudprovider.ICloudRegion {
+	return b.region
+}
+
+func (b *SBucket) Refresh() error {
+	bucket, err := b.region.GetBucket(b.Name)
+	if err != nil {
+		return errors.Wrapf(err, "GetBucket(%s)", b.Name)
+	}
+	*b = *bucket
+	return nil
+}
+
+func (b *SBucket) fetchMeta() {
+	if b.fetched {
+		return
+	}
+	bucket, err := b.region.GetBucket(b.Name)
+	if err != nil {
+		return
+	}
+	*b = *bucket
+}
+
+func (b *SBucket) GetCreatedAt() time.Time {
+	b.fetchMeta()
+	if !b.createdAt.IsZero() {
+		return b.createdAt
+	}
+	return parseSwiftTime(b.LastModified)
+}
+
+func (b *SBucket) GetStorageClass() string {
+	b.fetchMeta()
+	return b.storagePolicy
+}
+
+func (b *SBucket) GetAcl() cloudprovider.TBucketACLType {
+	b.fetchMeta()
+	if strings.Contains(b.writeAcl, SWIFT_ACL_PUBLIC_WRITE) {
+		return cloudprovider.ACLPublicReadWrite
+	}
+	if strings.Contains(b.readAcl, ".r:*") {
+		return cloudprovider.ACLPublicRead
+	}
+	return cloudprovider.ACLPrivate
+}
+
+func aclToSwiftHeader(acl cloudprovider.TBucketACLType, header http.Header) {
+	switch acl {
+	case cloudprovider.ACLPublicReadWrite:
+		header.Set("X-Container-Read", SWIFT_ACL_PUBLIC_READ)
+		header.Set("X-Container-Write", SWIFT_ACL_PUBLIC_WRITE)
+	case cloudprovider.ACLPublicRead:
+		header.Set("X-Container-Read", SWIFT_ACL_PUBLIC_READ)
+		header.Set("X-Remove-Container-Write", "x")
+	default:
+		header.Set("X-Remove-Container-Read", "x")
+		header.Set("X-Remove-Container-Write", "x")
+	}
+}
+
+func (b *SBucket) SetAcl(acl cloudprovider.TBucketACLType) error {
+	header := http.Header{}
+	aclToSwiftHeader(acl, header)
+	_, _, err := b.region.ossRequest(httputils.POST, swiftPath(b.Name, ""), nil, header, nil)
+	if err != nil {
+		return errors.Wrapf(err, "set acl %s", acl)
+	}
+	b.fetched = false
+	return nil
+}
+
+func (b *SBucket) GetAccessUrls() []cloudprovider.SBucketAccessUrl {
+	serviceUrl, err := b.region.client.getServiceUrl(b.region.Name, OPENSTACK_SERVICE_OBJECT_STORE)
+	if err != nil {
+		return nil
+	}
+	return []cloudprovider.SBucketAccessUrl{
+		{
+			Url:         strings.TrimSuffix(serviceUrl, "/") + swiftPath(b.Name, ""),
+			Description: "swift",
+			Primary:     true,
+		},
+	}
+}
+
+func (b *SBucket) GetStats() cloudprovider.SBucketStats {
+	return cloudprovider.SBucketStats{
+		SizeBytes:   b.Bytes,
+		ObjectCount: b.Count,
+	}
+}
+
+type sSwiftObject struct {
+	Name         string `json:"name"`
+	Hash         string `json:"hash"`
+	Bytes        int64  `json:"bytes"`
+	ContentType  string `json:"content_type"`
+	LastModified string `json:"last_modified"`
+	Subdir       string `json:"subdir"`
+}
+
+func (b *SBucket) ListObjects(prefix string, marker string, delimiter string, maxCount int) (cloudprovider.SListObjectResult, error) {
+	result := cloudprovider.SListObjectResult{}
+	objs, err := b.region.listObjects(b.Name, prefix, marker, delimiter, maxCount)
+	if err != nil {
+		return result, errors.Wrap(err, "listObjects")
+	}
+	result.Objects = make([]cloudprovider.ICloudObject, 0)
+	for _, obj := range objs {
+		if len(obj.Subdir) > 0 {
+			result.CommonPrefixes = append(result.CommonPrefixes, &SObject{
+				bucket:           b,
+				SBaseCloudObject: cloudprovider.SBaseCloudObject{Key: obj.Subdir},
+			})
+			continue
+		}
+		result.Objects = append(result.Objects, &SObject{
+			bucket: b,
+			SBaseCloudObject: cloudprovider.SBaseCloudObject{
+				Key:          obj.Name,
+				SizeBytes:    obj.Bytes,
+				ETag:         obj.Hash,
+				LastModified: parseSwiftTime(obj.LastModified),
+			},
+		})
+	}
+	// swift does not tell whether the listing is truncated
+	if maxCount > 0 && len(objs) >= maxCount {
+		result.IsTruncated = true
+		last := objs[len(objs)-1]
+		result.NextMarker = last.Name
+		if len(last.Subdir) > 0 {
+			result.NextMarker = last.Subdir
+		}
+	}
+	return result, nil
+}
+
+func (b *SBucket) PutObject(ctx context.Context, key string, body io.Reader, sizeBytes int64, cannedAcl cloudprovider.TBucketACLType, storageClassStr string, meta http.Header) error {
+	if sizeBytes < 0 {
+		return errors.Error("content length expected")
+	}
+	header := cloudprovider.MetaToHttpHeader(SWIFT_META_OBJECT_PREFIX, meta)
+	header.Set("Content-Length", strconv.FormatInt(sizeBytes, 10))
+	_, _, err := b.region.ossRequest(httputils.PUT, swiftPath(b.Name, key), nil, header, body)
+	if err != nil {
+		return errors.Wrapf(err, "put object %s", key)
+	}
+	return nil
+}
+
+func (b *SBucket) GetObject(ctx context.Context, key string, rangeOpt *cloudprovider.SGetObjectRange) (io.ReadCloser, error) {
+	header := http.Header{}
+	if rangeOpt != nil {
+		header.Set("Range", rangeOpt.String())
+	}
+	resp, err := b.region.ossRawRequest(httputils.GET, swiftPath(b.Name, key), nil, header, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get object %s", key)
+	}
+	return resp.Body, nil
+}
+
+func (b *SBucket) DeleteObject(ctx context.Context, key string) error {
+	_, _, err := b.region.ossRequest(httputils.DELETE, swiftPath(b.Name, key), nil, nil, nil)
+	if err != nil && errors.Cause(err) != cloudprovider.ErrNotFound {
+		return errors.Wrapf(err, "delete object %s", key)
+	}
+	return nil
+}
+
+func (b *SBucket) CopyObject(ctx context.Context, destKey string, srcBucket, srcKey string, cannedAcl cloudprovider.TBucketACLType, storageClassStr string, meta http.Header) error {
+	header := http.Header{}
+	if meta != nil {
+		header = cloudprovider.MetaToHttpHeader(SWIFT_META_OBJECT_PREFIX, meta)
+		header.Set("X-Fresh-Metadata", "true")
+	}
+	header.Set("X-Copy-From", swiftPath(srcBucket, srcKey))
+	header.Set("Content-Length", "0")
+	_, _, err := b.region.ossRequest(httputils.PUT, swiftPath(b.Name, destKey), nil, header, nil)
+	if err != nil {
+		return errors.Wrapf(err, "copy object %s/%s to %s", srcBucket, srcKey, destKey)
+	}
+	return nil
+}
+
+// https://docs.openstack.org/swift/latest/api/temporary_url_middleware.html
+func (b *SBucket) GetTempUrl(method string, key string, expire time.Duration) (string, error) {
+	header, _, err := b.region.ossRequest(httputils.HEAD, "", nil, nil, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "head account")
+	}
+	secret := header.Get("X-Account-Meta-Temp-Url-Key")
+	if len(secret) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotSupported, "account temp url key not set")
+	}
+	serviceUrl, err := b.region.client.getServiceUrl(b.region.Name, OPENSTACK_SERVICE_OBJECT_STORE)
+	if err != nil {
+		return "", errors.Wrap(err, "getServiceUrl")
+	}
+	serviceUrl = strings.TrimSuffix(serviceUrl, "/")
+	u, err := url.Parse(serviceUrl)
+	if err != nil {
+		return "", errors.Wrapf(err, "url.Parse(%s)", serviceUrl)
+	}
+	path := u.Path + swiftPath(b.Name, key)
+	expires := time.Now().Add(expire).Unix()
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%s\n%d\n%s", strings.ToUpper(method), expires, path)))
+	query := url.Values{}
+	query.Set("temp_url_sig", hex.EncodeToString(mac.Sum(nil)))
+	query.Set("temp_url_expires", fmt.Sprintf("%d", expires))
+	return fmt.Sprintf("%s%s?%s", serviceUrl, swiftPath(b.Name, key), query.Encode()), nil
+}
+
+// multipart upload is emulated by swift static large object, segments are saved in <bucket>_segments
+func (b *SBucket) getSegmentBucket() string {
+	return b.Name + "_segments"
+}
+
+func (b *SBucket) getSegmentPrefix(key, uploadId string) string {
+	return fmt.Sprintf("%s/%s", key, uploadId)
+}
+
+func (b *SBucket) NewMultipartUpload(ctx context.Context, key string, cannedAcl cloudprovider.TBucketACLType, storageClassStr string, meta http.Header) (string, error) {
+	segBucket := b.getSegmentBucket()
+	exist, err := b.region.IBucketExist(segBucket)
+	if err != nil {
+		return "", errors.Wrapf(err, "IBucketExist(%s)", segBucket)
+	}
+	if !exist {
+		err = b.region.CreateBucket(segBucket, b.GetStorageClass(), cloudprovider.ACLPrivate)
+		if err != nil {
+			return "", errors.Wrapf(err, "CreateBucket(%s)", segBucket)
+		}
+	}
+	uploadId := stringutils.UUID4()
+	// keep object meta in an empty object, it will be applied to the manifest when upload completed
+	header := cloudprovider.MetaToHttpHeader(SWIFT_META_OBJECT_PREFIX, meta)
+	header.Set("Content-Length", "0")
+	_, _, err = b.region.ossRequest(httputils.PUT, swiftPath(segBucket, b.getSegmentPrefix(key, uploadId)), nil, header, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "put upload meta")
+	}
+	return uploadId, nil
+}
+
+func (b *SBucket) UploadPart(ctx context.Context, key string, uploadId string, partIndex int, input io.Reader, partSize int64, offset, totalSize int64) (string, error) {
+	segment := fmt.Sprintf("%s/%08d", b.getSegmentPrefix(key, uploadId), partIndex)
+	header := http.Header{}
+	header.Set("Content-Length", strconv.FormatInt(partSize, 10))
+	respHeader, _, err := b.region.ossRequest(httputils.PUT, swiftPath(b.getSegmentBucket(), segment), nil, header, input)
+	if err != nil {
+		return "", errors.Wrapf(err, "upload part %d", partIndex)
+	}
+	return strings.Trim(respHeader.Get("Etag"), "\""), nil
+}
+
+func (b *SBucket) CopyPart(ctx context.Context, key string, uploadId string, partIndex int, srcBucketName string, srcKey string, srcOffset int64, srcLength int64) (string, error) {
+	return "", cloudprovider.ErrNotSupported
+}
+
+func (b *SBucket) CompleteMultipartUpload(ctx context.Context, key string, uploadId string, partEtags []string) error {
+	segBucket, prefix := b.getSegmentBucket(), b.getSegmentPrefix(key, uploadId)
+	header, _, err := b.region.ossRequest(httputils.HEAD, swiftPath(segBucket, prefix), nil, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "head upload meta")
+	}
+	meta := cloudprovider.FetchMetaFromHttpHeader(SWIFT_META_OBJECT_PREFIX, header)
+	manifest := []map[string]string{}
+	for i, etag := range partEtags {
+		manifest = append(manifest, map[string]string{
+			"path": swiftPath(segBucket, fmt.Sprintf("%s/%08d", prefix, i+1)),
+			"etag": etag,
+		})
+	}
+	body := jsonutils.Marshal(manifest).String()
+	header = cloudprovider.MetaToHttpHeader(SWIFT_META_OBJECT_PREFIX, meta)
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	query := url.Values{}
+	query.Set("multipart-manifest", "put")
+	_, _, err = b.region.ossRequest(httputils.PUT, swiftPath(b.Name, key), query, header, strings.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "put manifest")
+	}
+	_, _, err = b.region.ossRequest(httputils.DELETE, swiftPath(segBucket, prefix), nil, nil, nil)
+	if err != nil && errors.Cause(err) != cloudprovider.ErrNotFound {
+		return errors.Wrap(err, "delete upload meta")
+	}
+	return nil
+}
+
+func (b *SBucket) AbortMultipartUpload(ctx context.Context, key string, uploadId string) error {
+	segBucket, prefix := b.getSegmentBucket(), b.getSegmentPrefix(key, uploadId)
+	marker := ""
+	for {
+		objs, err := b.region.listObjects(segBucket, prefix, marker, "", 1000)
+		if err != nil {
+			return errors.Wrapf(err, "list segments %s", prefix)
+		}
+		for _, obj := range objs {
+			_, _, err = b.region.ossRequest(httputils.DELETE, swiftPath(segBucket, obj.Name), nil, nil, nil)
+			if err != nil && errors.Cause(err) != cloudprovider.ErrNotFound {
+				return errors.Wrapf(err, "delete segment %s", obj.Name)
+			}
+		}
+		if len(objs) < 1000 {
+			break
+		}
+		marker = objs[len(objs)-1].Name
+	}
+	return nil
+}
+
+func (region *SRegion) listObjects(container, prefix, marker, delimiter string, limit int) ([]sSwiftObject, error) {
+	query := url.Values{}
+	query.Set("format", "json")
+	if len(prefix) > 0 {
+		query.Set("prefix", prefix)
+	}
+	if len(marker) > 0 {
+		query.Set("marker", marker)
+	}
+	if len(delimiter) > 0 {
+		query.Set("delimiter", delimiter)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	_, body, err := region.ossRequest(httputils.GET, swiftPath(container, ""), query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := []sSwiftObject{}
+	if len(body) == 0 {
+		return ret, nil
+	}
+	obj, err := jsonutils.Parse(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "jsonutils.Parse")
+	}
+	return ret, obj.Unmarshal(&ret)
+}
+
+func (region *SRegion) GetBuckets() ([]SBucket, error) {
+	ret := []SBucket{}
+	query := url.Values{}
+	query.Set("format", "json")
+	for {
+		_, body, err := region.ossRequest(httputils.GET, "", query, nil, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "list containers")
+		}
+		part := []SBucket{}
+		if len(body) > 0 {
+			obj, err := jsonutils.Parse(body)
+			if err != nil {
+				return nil, errors.Wrap(err, "jsonutils.Parse")
+			}
+			err = obj.Unmarshal(&part)
+			if err != nil {
+				return nil, errors.Wrap(err, "Unmarshal")
+			}
+		}
+		if len(part) == 0 {
+			break
+		}
+		ret = append(ret, part...)
+		query.Set("marker", part[len(part)-1].Name)
+	}
+	return ret, nil
+}
+
+func (region *SRegion) GetBucket(name string) (*SBucket, error) {
+	header, _, err := region.ossRequest(httputils.HEAD, swiftPath(name, ""), nil, nil, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "head container %s", name)
+	}
+	bucket := &SBucket{region: region, Name: name, fetched: true}
+	bucket.Count, _ = strconv.Atoi(header.Get("X-Container-Object-Count"))
+	bucket.Bytes, _ = strconv.ParseInt(header.Get("X-Container-Bytes-Used"), 10, 64)
+	bucket.readAcl = header.Get("X-Container-Read")
+	bucket.writeAcl = header.Get("X-Container-Write")
+	bucket.storagePolicy = header.Get("X-Storage-Policy")
+	if ts, err := strconv.ParseFloat(header.Get("X-Timestamp"), 64); err == nil {
+		bucket.createdAt = time.Unix(int64(ts), 0)
+	}
+	if lm, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		bucket.LastModified = lm.UTC().Format(SWIFT_TIME_FORMAT)
+	}
+	bucket.Metadata = map[string]string{}
+	for k := range header {
+		if strings.HasPrefix(k, SWIFT_META_CONTAINER_PREFIX) {
+			bucket.Metadata[strings.TrimPrefix(k, SWIFT_META_CONTAINER_PREFIX)] = header.Get(k)
+		}
+	}
+	return bucket, nil
+}
+
+func (region *SRegion) CreateBucket(name string, storageClassStr string, acl cloudprovider.TBucketACLType) error {
+	header := http.Header{}
+	aclToSwiftHeader(acl, header)
+	header.Del("X-Remove-Container-Read")
+	header.Del("X-Remove-Container-Write")
+	if len(storageClassStr) > 0 {
+		header.Set("X-Storage-Policy", storageClassStr)
+	}
+	header.Set("Content-Length", "0")
+	_, _, err := region.ossRequest(httputils.PUT, swiftPath(name, ""), nil, header, nil)
+	if err != nil {
+		return errors.Wrapf(err, "create container %s", name)
+	}
+	return nil
+}
+
+func (region *SRegion) DeleteBucket(name string) error {
+	_, _, err := region.ossRequest(httputils.DELETE, swiftPath(name, ""), nil, nil, nil)
+	if err != nil {
+		if errors.Cause(err) == cloudprovider.ErrNotFound {
+			return nil
+		}
+		return errors.Wrapf(err, "delete container %s", name)
+	}
+	return nil
+}
@@ -146,6 +146,10 @@ func (region *SRegion) GetDisks() ([]SDisk, error) {
 	return disks, nil
 }
 
+func (disk *SDisk) SetTags(tags map[string]string, replace bool) error {
+	return disk.storage.zone.region.SetDiskMetadata(disk.Id, tags, replace)
+}
+
 func (disk *SDisk) GetId() string {
 	return disk.Id
 }
@@ -248,6 +252,7 @@ func (disk *SDisk) Refresh() error {
 	if err != nil {
 		return err
 	}
+	disk.Metadata = nil
 	return jsonutils.Update(disk, _disk)
 }
 
@@ -352,6 +357,60 @@ func (region *SRegion) CreateDisk(imageRef string, volumeType string, name strin
 	return disk, nil
 }
 
+// ExportDisk 将云硬盘上传为临时镜像后从glance下载, 完成后删除临时镜像
+func (disk *SDisk) ExportDisk(ctx context.Context, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	region := disk.storage.zone.region
+	imageId, err := region.UploadDiskToImage(disk.Id, fmt.Sprintf("%s-export-%d", disk.Name, time.Now().Unix()), "qcow2")
+	if err != nil {
+		return nil, errors.Wrapf(err, "UploadDiskToImage")
+	}
+	defer func() {
+		err := region.DeleteImage(imageId)
+		if err != nil {
+			log.Errorf("delete temporary image %s of disk %s error: %v", imageId, disk.Id, err)
+		}
+	}()
+	err = cloudprovider.Wait(time.Second*10, time.Hour, func() (bool, error) {
+		status, err := region.GetImageStatus(imageId)
+		if err != nil {
+			return false, errors.Wrapf(err, "GetImageStatus(%s)", imageId)
+		}
+		switch status {
+		case ACTIVE:
+			return true, nil
+		case KILLED, DELETED, DEACTIVATED, PENDING_DELETE:
+			return false, errors.Errorf("upload disk %s to image %s failed, image status %s", disk.Id, imageId, status)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return region.getStoragecache().ExportImage(ctx, imageId, opts)
+}
+
+// UploadDiskToImage 将云硬盘上传为镜像, 返回镜像id
+func (region *SRegion) UploadDiskToImage(diskId, imageName, diskFormat string) (string, error) {
+	params := map[string]map[string]interface{}{
+		"os-volume_upload_image": {
+			"image_name":       imageName,
+			"disk_format":      diskFormat,
+			"container_format": "bare",
+			"force":            true,
+		},
+	}
+	resource := fmt.Sprintf("/volumes/%s/action", diskId)
+	resp, err := region.bsPost(resource, params)
+	if err != nil {
+		return "", errors.Wrap(err, "bsPost")
+	}
+	return resp.GetString("os-volume_upload_image", "image_id")
+}
+
 func (region *SRegion) GetDisk(diskId string) (*SDisk, error) {
 	resource := fmt.Sprintf("/volumes/%s", diskId)
 	resp, err := region.bsGet(resource)
@@ -446,3 +505,21 @@ func (disk *SDisk) Rebuild(ctx context.Context) error {
 func (disk *SDisk) GetProjectId() string {
 	return disk.TenantId
 }
+
+// https://docs.openstack.org/api-ref/block-storage/v3/#volumes-volumes
+func (region *SRegion) SetDiskMetadata(diskId string, metadata map[string]string, replace bool) error {
+	params := map[string]interface{}{
+		"metadata": metadata,
+	}
+	resource := fmt.Sprintf("/volumes/%s/metadata", diskId)
+	var err error
+	if replace {
+		_, err = region.bsUpdate(resource, params)
+	} else {
+		_, err = region.bsPost(resource, params)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "set disk %s metadata", diskId)
+	}
+	return nil
+}
@@ -217,6 +217,10 @@ func (instance *SInstance) GetIHost() cloudprovider.ICloudHost {
 	return instance.host
 }
 
+func (instance *SInstance) SetTags(tags map[string]string, replace bool) error {
+	return instance.host.zone.region.SetInstanceMetadata(instance.Id, tags, replace)
+}
+
 func (instance *SInstance) GetId() string {
 	return instance.Id
 }
@@ -442,6 +446,7 @@ func (instance *SInstance) Refresh() error {
 	instance.VolumesAttached = nil
 	instance.SecurityGroups = nil
 	instance.Tags = nil
+	instance.Metadata = nil
 	return jsonutils.Update(instance, _instance)
 }
 
@@ -562,6 +567,63 @@ func (instance *SInstance) DeployVM(ctx context.Context, name string, username s
 	return instance.host.zone.region.DeployVM(instance.Id, name, password, publicKey, deleteKeypair, description)
 }
 
+func (instance *SInstance) SaveImage(opts *cloudprovider.SaveImageOptions) (cloudprovider.ICloudImage, error) {
+	image, err := instance.host.zone.region.SaveImage(instance, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "SaveImage")
+	}
+	return image, nil
+}
+
+// nova 2.1 版本的 createImage 不返回镜像id, 通过同名镜像中新出现的镜像确定
+func (region *SRegion) SaveImage(instance *SInstance, opts *cloudprovider.SaveImageOptions) (*SImage, error) {
+	if instance.getImage() == nil {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "volume backed instance %s", instance.Id)
+	}
+	images, err := region.GetImages(opts.Name, "", "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetImages(%s)", opts.Name)
+	}
+	exists := map[string]bool{}
+	for i := range images {
+		exists[images[i].Id] = true
+	}
+	params := map[string]interface{}{
+		"createImage": map[string]interface{}{
+			"name": opts.Name,
+			"metadata": map[string]string{
+				"description": opts.Notes,
+			},
+		},
+	}
+	resource := fmt.Sprintf("/servers/%s/action", instance.Id)
+	_, err = region.ecsPost(resource, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "ecsPost")
+	}
+	var image *SImage
+	err = cloudprovider.Wait(time.Second*5, time.Minute, func() (bool, error) {
+		images, err := region.GetImages(opts.Name, "", "")
+		if err != nil {
+			return false, errors.Wrapf(err, "GetImages(%s)", opts.Name)
+		}
+		for i := range images {
+			if !exists[images[i].Id] {
+				if image != nil {
+					return false, errors.Wrapf(cloudprovider.ErrDuplicateId, "more than one image named %s", opts.Name)
+				}
+				image = &images[i]
+			}
+		}
+		return image != nil, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "wait image %s created", opts.Name)
+	}
+	image.storageCache = region.getStoragecache()
+	return image, nil
+}
+
 func (instance *SInstance) RebuildRoot(ctx context.Context, desc *cloudprovider.SManagedVMRebuildRootConfig) (string, error) {
 	return instance.Id, instance.host.zone.region.ReplaceSystemDisk(instance.Id, desc.ImageId, desc.Password, desc.PublicKey, desc.SysSizeGB)
 }
@@ -1021,6 +1083,24 @@ func (region *SRegion) GetInstanceMetadata(instanceId string) (map[string]string
 	return result.Metadata, nil
 }
 
+// https://docs.openstack.org/api-ref/compute/#server-metadata-servers-metadata
+func (region *SRegion) SetInstanceMetadata(instanceId string, metadata map[string]string, replace bool) error {
+	params := map[string]interface{}{
+		"metadata": metadata,
+	}
+	resource := fmt.Sprintf("/servers/%s/metadata", instanceId)
+	var err error
+	if replace {
+		_, err = region.ecsUpdate(resource, params)
+	} else {
+		_, err = region.ecsPost(resource, params)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "set instance %s metadata", instanceId)
+	}
+	return nil
+}
+
 func (zone *SZone) CreateVM(hypervisor string, opts *cloudprovider.SManagedVMCreateConfig) (*SInstance, error) {
 	region := zone.region
 	network, err := region.GetNetwork(opts.ExternalNetworkId)
@@ -0,0 +1,308 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openstack
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+const (
+	BARBICAN_SECRET_CERTIFICATE = "certificate"
+	BARBICAN_SECRET_PRIVATE_KEY = "private_key"
+)
+
+type SSecretRef struct {
+	Name      string `json:"name"`
+	SecretRef string `json:"secret_ref"`
+}
+
+// octavia tls listener certificate, backed by barbican certificate container
+type SLoadbalancerCert struct {
+	multicloud.SResourceBase
+	OpenStackTags
+	region *SRegion
+
+	ContainerRef string       `json:"container_ref"`
+	Name         string       `json:"name"`
+	Type         string       `json:"type"`
+	Status       string       `json:"status"`
+	Created      time.Time    `json:"created"`
+	Updated      time.Time    `json:"updated"`
+	SecretRefs   []SSecretRef `json:"secret_refs"`
+
+	certificate string
+	privateKey  string
+}
+
+// getRefId return the uuid at the end of barbican secret or container ref
+func getRefId(ref string) string {
+	ref = strings.TrimSuffix(ref, "/")
+	if idx := strings.LastIndex(ref, "/"); idx >= 0 {
+		return ref[idx+1:]
+	}
+	return ref
+}
+
+func (cert *SLoadbalancerCert) GetId() string {
+	return getRefId(cert.ContainerRef)
+}
+
+func (cert *SLoadbalancerCert) GetGlobalId() string {
+	return cert.GetId()
+}
+
+func (cert *SLoadbalancerCert) GetName() string {
+	return cert.Name
+}
+
+func (cert *SLoadbalancerCert) GetStatus() string {
+	switch cert.Status {
+	case "ACTIVE":
+		return api.LB_STATUS_ENABLED
+	default:
+		return api.LB_STATUS_UNKNOWN
+	}
+}
+
+func (cert *SLoadbalancerCert) GetCreatedAt() time.Time {
+	return cert.Created
+}
+
+func (cert *SLoadbalancerCert) Refresh() error {
+	_cert, err := cert.region.GetLoadbalancerCertificate(cert.GetId())
+	if err != nil {
+		return errors.Wrapf(err, "GetLoadbalancerCertificate(%s)", cert.GetId())
+	}
+	cert.certificate, cert.privateKey = "", ""
+	return jsonutils.Update(cert, _cert)
+}
+
+func (cert *SLoadbalancerCert) GetProjectId() string {
+	return ""
+}
+
+func (cert *SLoadbalancerCert) getSecretRef(name string) string {
+	for _, ref := range cert.SecretRefs {
+		if ref.Name == name {
+			return ref.SecretRef
+		}
+	}
+	return ""
+}
+
+func (cert *SLoadbalancerCert) GetPublickKey() string {
+	if len(cert.certificate) == 0 {
+		ref := cert.getSecretRef(BARBICAN_SECRET_CERTIFICATE)
+		if len(ref) == 0 {
+			return ""
+		}
+		payload, err := cert.region.GetSecretPayload(getRefId(ref))
+		if err != nil {
+			log.Errorf("get certificate %s payload error: %v", cert.Name, err)
+			return ""
+		}
+		cert.certificate = payload
+	}
+	return cert.certificate
+}
+
+func (cert *SLoadbalancerCert) GetPrivateKey() string {
+	if len(cert.privateKey) == 0 {
+		ref := cert.getSecretRef(BARBICAN_SECRET_PRIVATE_KEY)
+		if len(ref) == 0 {
+			return ""
+		}
+		payload, err := cert.region.GetSecretPayload(getRefId(ref))
+		if err != nil {
+			log.Errorf("get certificate %s private key error: %v", cert.Name, err)
+			return ""
+		}
+		cert.privateKey = payload
+	}
+	return cert.privateKey
+}
+
+func (cert *SLoadbalancerCert) parseCertificate() (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(cert.GetPublickKey()))
+	if block == nil {
+		return nil, fmt.Errorf("invalid certificate %s", cert.Name)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func (cert *SLoadbalancerCert) GetCommonName() string {
+	c, err := cert.parseCertificate()
+	if err != nil {
+		return ""
+	}
+	return c.Subject.CommonName
+}
+
+func (cert *SLoadbalancerCert) GetSubjectAlternativeNames() string {
+	c, err := cert.parseCertificate()
+	if err != nil {
+		return ""
+	}
+	return strings.Join(c.DNSNames, ",")
+}
+
+func (cert *SLoadbalancerCert) GetFingerprint() string {
+	publicKey := cert.GetPublickKey()
+	if len(publicKey) == 0 {
+		return ""
+	}
+	_fp := sha1.Sum([]byte(publicKey))
+	fp := fmt.Sprintf("sha1:% x", _fp)
+	return strings.Replace(fp, " ", ":", -1)
+}
+
+func (cert *SLoadbalancerCert) GetExpireTime() time.Time {
+	c, err := cert.parseCertificate()
+	if err != nil {
+		return time.Time{}
+	}
+	return c.NotAfter
+}
+
+// barbican secrets and containers are immutable
+func (cert *SLoadbalancerCert) Sync(name, privateKey, publickKey string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (cert *SLoadbalancerCert) Delete() error {
+	return cert.region.DeleteLoadbalancerCertificate(cert.GetId())
+}
+
+func (region *SRegion) GetLoadbalancerCertificates() ([]SLoadbalancerCert, error) {
+	ret := []SLoadbalancerCert{}
+	query := url.Values{}
+	query.Set("type", "certificate")
+	query.Set("limit", "100")
+	for {
+		query.Set("offset", fmt.Sprintf("%d", len(ret)))
+		resp, err := region.kmList("/v1/containers", query)
+		if err != nil {
+			return nil, errors.Wrap(err, "kmList")
+		}
+		part := struct {
+			Containers []SLoadbalancerCert
+			Total      int
+		}{}
+		err = resp.Unmarshal(&part)
+		if err != nil {
+			return nil, errors.Wrap(err, "resp.Unmarshal")
+		}
+		ret = append(ret, part.Containers...)
+		if len(part.Containers) == 0 || len(ret) >= part.Total {
+			break
+		}
+	}
+	return ret, nil
+}
+
+func (region *SRegion) GetLoadbalancerCertificate(id string) (*SLoadbalancerCert, error) {
+	resp, err := region.kmGet(fmt.Sprintf("/v1/containers/%s", id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "kmGet(%s)", id)
+	}
+	cert := &SLoadbalancerCert{region: region}
+	err = resp.Unmarshal(cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "resp.Unmarshal")
+	}
+	if cert.Type != "certificate" {
+		return nil, errors.Wrapf(cloudprovider.ErrNotFound, "container %s type %s", id, cert.Type)
+	}
+	return cert, nil
+}
+
+func (region *SRegion) GetSecretPayload(id string) (string, error) {
+	return region.kmPayload(fmt.Sprintf("/v1/secrets/%s/payload", id))
+}
+
+func (region *SRegion) createSecret(name, secretType, payload string) (string, error) {
+	params := map[string]interface{}{
+		"name":                 name,
+		"secret_type":          secretType,
+		"payload":              payload,
+		"payload_content_type": "text/plain",
+	}
+	resp, err := region.kmPost("/v1/secrets", params)
+	if err != nil {
+		return "", errors.Wrapf(err, "create secret %s", name)
+	}
+	return resp.GetString("secret_ref")
+}
+
+func (region *SRegion) CreateLoadbalancerCertificate(opts *cloudprovider.SLoadbalancerCertificate) (*SLoadbalancerCert, error) {
+	certRef, err := region.createSecret(opts.Name+"-cert", "certificate", opts.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	keyRef, err := region.createSecret(opts.Name+"-key", "private", opts.PrivateKey)
+	if err != nil {
+		region.kmDelete(fmt.Sprintf("/v1/secrets/%s", getRefId(certRef)))
+		return nil, err
+	}
+	params := map[string]interface{}{
+		"name": opts.Name,
+		"type": "certificate",
+		"secret_refs": []SSecretRef{
+			{Name: BARBICAN_SECRET_CERTIFICATE, SecretRef: certRef},
+			{Name: BARBICAN_SECRET_PRIVATE_KEY, SecretRef: keyRef},
+		},
+	}
+	resp, err := region.kmPost("/v1/containers", params)
+	if err != nil {
+		return nil, errors.Wrap(err, "create certificate container")
+	}
+	ref, err := resp.GetString("container_ref")
+	if err != nil {
+		return nil, errors.Wrap(err, "get container_ref")
+	}
+	return region.GetLoadbalancerCertificate(getRefId(ref))
+}
+
+func (region *SRegion) DeleteLoadbalancerCertificate(id string) error {
+	cert, err := region.GetLoadbalancerCertificate(id)
+	if err != nil {
+		return errors.Wrapf(err, "GetLoadbalancerCertificate(%s)", id)
+	}
+	_, err = region.kmDelete(fmt.Sprintf("/v1/containers/%s", id))
+	if err != nil {
+		return errors.Wrapf(err, "delete container %s", id)
+	}
+	for _, ref := range cert.SecretRefs {
+		_, err = region.kmDelete(fmt.Sprintf("/v1/secrets/%s", getRefId(ref.SecretRef)))
+		if err != nil && errors.Cause(err) != cloudprovider.ErrNotFound {
+			return errors.Wrapf(err, "delete secret %s", ref.SecretRef)
+		}
+	}
+	return nil
+}
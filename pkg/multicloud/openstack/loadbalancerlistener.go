@@ -256,6 +256,15 @@ func (region *SRegion) CreateLoadbalancerListener(loadbalancerId string, listene
 	if listenerParams.XForwardedFor {
 		params.Listener.InsertHeaders.XForwardedFor = "true"
 	}
+	// https listener with certificate terminates tls on octavia
+	if len(listenerParams.CertificateId) > 0 && (params.Listener.Protocol == "HTTPS" || params.Listener.Protocol == "TERMINATED_HTTPS") {
+		cert, err := region.GetLoadbalancerCertificate(listenerParams.CertificateId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetLoadbalancerCertificate(%s)", listenerParams.CertificateId)
+		}
+		params.Listener.Protocol = "TERMINATED_HTTPS"
+		params.Listener.DefaultTLSContainerRef = cert.ContainerRef
+	}
 	body, err := region.lbPost("/v2/lbaas/listeners", jsonutils.Marshal(params))
 	if err != nil {
 		return nil, errors.Wrap(err, "region.Post(/v2/lbaas/listeners)")
@@ -323,7 +332,27 @@ func (listener *SLoadbalancerListener) GetScheduler() string {
 }
 
 func (listerner *SLoadbalancerListener) ChangeCertificate(ctx context.Context, opts *cloudprovider.ListenerCertificateOptions) error {
-	return cloudprovider.ErrNotSupported
+	if listerner.Protocol != "TERMINATED_HTTPS" {
+		return errors.Wrapf(cloudprovider.ErrNotSupported, "listener protocol %s", listerner.Protocol)
+	}
+	cert, err := listerner.region.GetLoadbalancerCertificate(opts.CertificateId)
+	if err != nil {
+		return errors.Wrapf(err, "GetLoadbalancerCertificate(%s)", opts.CertificateId)
+	}
+	err = waitLbResStatus(listerner, 10*time.Second, 8*time.Minute)
+	if err != nil {
+		return errors.Wrap(err, "waitLbResStatus")
+	}
+	params := map[string]interface{}{
+		"listener": map[string]interface{}{
+			"default_tls_container_ref": cert.ContainerRef,
+		},
+	}
+	_, err = listerner.region.lbUpdate(fmt.Sprintf("/v2/lbaas/listeners/%s", listerner.ID), params)
+	if err != nil {
+		return errors.Wrapf(err, "update listener %s certificate", listerner.ID)
+	}
+	return waitLbResStatus(listerner, 10*time.Second, 8*time.Minute)
 }
 
 func (listerner *SLoadbalancerListener) SetAcl(ctx context.Context, opts *cloudprovider.ListenerAclOptions) error {
@@ -553,7 +582,10 @@ func (listener *SLoadbalancerListener) GzipEnabled() bool {
 }
 
 func (listener *SLoadbalancerListener) GetCertificateId() string {
-	return ""
+	if len(listener.DefaultTLSContainerRef) == 0 {
+		return ""
+	}
+	return getRefId(listener.DefaultTLSContainerRef)
 }
 
 func (listener *SLoadbalancerListener) GetTLSCipherPolicy() string {
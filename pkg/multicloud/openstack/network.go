@@ -120,6 +120,14 @@ type SNetwork struct {
 	UpdatedAt       time.Time
 }
 
+func (network *SNetwork) GetTags() (map[string]string, error) {
+	return neutronTagsToMap(network.Tags), nil
+}
+
+func (network *SNetwork) SetTags(tags map[string]string, replace bool) error {
+	return network.wire.vpc.region.SetNeutronTags("subnets", network.Id, network.Tags, tags, replace)
+}
+
 func (network *SNetwork) GetId() string {
 	return network.Id
 }
@@ -314,6 +322,7 @@ func (network *SNetwork) Refresh() error {
 	if err != nil {
 		return err
 	}
+	network.Tags = nil
 	return jsonutils.Update(network, _network)
 }
 
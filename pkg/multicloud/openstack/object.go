@@ -0,0 +1,69 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openstack
+
+import (
+	"context"
+	"net/http"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/httputils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+)
+
+type SObject struct {
+	bucket *SBucket
+
+	cloudprovider.SBaseCloudObject
+}
+
+func (o *SObject) GetIBucket() cloudprovider.ICloudBucket {
+	return o.bucket
+}
+
+// swift has no object level acl, objects inherit container acl
+func (o *SObject) GetAcl() cloudprovider.TBucketACLType {
+	return o.bucket.GetAcl()
+}
+
+func (o *SObject) SetAcl(aclStr cloudprovider.TBucketACLType) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (o *SObject) GetMeta() http.Header {
+	if o.Meta != nil {
+		return o.Meta
+	}
+	header, _, err := o.bucket.region.ossRequest(httputils.HEAD, swiftPath(o.bucket.Name, o.Key), nil, nil, nil)
+	if err != nil {
+		log.Errorf("head object %s/%s error: %v", o.bucket.Name, o.Key, err)
+		return nil
+	}
+	o.Meta = cloudprovider.FetchMetaFromHttpHeader(SWIFT_META_OBJECT_PREFIX, header)
+	return o.Meta
+}
+
+func (o *SObject) SetMeta(ctx context.Context, meta http.Header) error {
+	// POST replaces all the custom metadata of the object
+	header := cloudprovider.MetaToHttpHeader(SWIFT_META_OBJECT_PREFIX, meta)
+	_, _, err := o.bucket.region.ossRequest(httputils.POST, swiftPath(o.bucket.Name, o.Key), nil, header, nil)
+	if err != nil {
+		return errors.Wrapf(err, "set object %s meta", o.Key)
+	}
+	o.Meta = nil
+	return nil
+}
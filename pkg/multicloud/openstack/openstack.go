@@ -46,6 +46,8 @@ const (
 	OPENSTACK_SERVICE_VOLUME       = "volume"
 	OPENSTACK_SERVICE_IMAGE        = "image"
 	OPENSTACK_SERVICE_LOADBALANCER = "load-balancer"
+	OPENSTACK_SERVICE_OBJECT_STORE = "object-store"
+	OPENSTACK_SERVICE_KEY_MANAGER  = "key-manager"
 
 	ErrNoEndpoint = errors.Error("no valid endpoint")
 )
@@ -251,7 +253,7 @@ func (cli *SOpenStackClient) jsonReuest(token oscli.TokenCredential, service, re
 	header.Set("X-Auth-Token", token.GetTokenString())
 	apiVersion := ""
 	switch service {
-	case OPENSTACK_SERVICE_IMAGE, OPENSTACK_SERVICE_IDENTITY:
+	case OPENSTACK_SERVICE_IMAGE, OPENSTACK_SERVICE_IDENTITY, OPENSTACK_SERVICE_KEY_MANAGER:
 	case OPENSTACK_SERVICE_COMPUTE:
 		apiVersion = "2.1"
 	default:
@@ -283,6 +285,11 @@ func (cli *SOpenStackClient) jsonReuest(token oscli.TokenCredential, service, re
 		}
 	}
 
+	// barbican endpoint may or may not contain api version
+	if service == OPENSTACK_SERVICE_KEY_MANAGER {
+		serviceUrl = strings.TrimSuffix(strings.TrimSuffix(serviceUrl, "/"), "/v1")
+	}
+
 	requestUrl := resource
 	if !strings.HasPrefix(resource, serviceUrl) {
 		requestUrl = fmt.Sprintf("%s/%s", strings.TrimSuffix(serviceUrl, "/"), strings.TrimPrefix(resource, "/"))
@@ -389,10 +396,54 @@ func (cli *SOpenStackClient) imageUpload(region, url string, size int64, body io
 	return session.RawRequest(OPENSTACK_SERVICE_IMAGE, "", httputils.PUT, url, header, reader)
 }
 
+func (cli *SOpenStackClient) imageDownload(region, url string) (*http.Response, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/octet-stream")
+	session := cli.getDefaultSession(region)
+	return session.RawRequest(OPENSTACK_SERVICE_IMAGE, "", httputils.GET, url, header, nil)
+}
+
 func (cli *SOpenStackClient) lbRequest(region string, method httputils.THttpMethod, resource string, query url.Values, body interface{}) (jsonutils.JSONObject, error) {
 	return cli.jsonReuest(cli.tokenCredential, OPENSTACK_SERVICE_LOADBALANCER, region, cli.endpointType, method, resource, query, body, cli.debug)
 }
 
+func (cli *SOpenStackClient) kmRequest(region string, method httputils.THttpMethod, resource string, query url.Values, body interface{}) (jsonutils.JSONObject, error) {
+	return cli.jsonReuest(cli.tokenCredential, OPENSTACK_SERVICE_KEY_MANAGER, region, cli.endpointType, method, resource, query, body, cli.debug)
+}
+
+// barbican secret payload is not json, fetch it as raw text
+func (cli *SOpenStackClient) kmPayload(region string, resource string) (*http.Response, error) {
+	header := http.Header{}
+	header.Set("Accept", "text/plain")
+	session := cli.getDefaultSession(region)
+	return session.RawRequest(OPENSTACK_SERVICE_KEY_MANAGER, "", httputils.GET, resource, header, nil)
+}
+
+func (cli *SOpenStackClient) objectStoreRequest(region string, method httputils.THttpMethod, resource string, query url.Values, header http.Header, body io.Reader) (*http.Response, error) {
+	if len(query) > 0 {
+		resource = fmt.Sprintf("%s?%s", resource, query.Encode())
+	}
+	session := cli.getDefaultSession(region)
+	return session.RawRequest(OPENSTACK_SERVICE_OBJECT_STORE, "", method, resource, header, body)
+}
+
+func (cli *SOpenStackClient) getServiceUrl(region, service string) (string, error) {
+	if len(region) == 0 {
+		region = cli.getDefaultRegionName()
+	}
+	return cli.tokenCredential.GetServiceURL(service, region, "", cli.endpointType)
+}
+
+func (cli *SOpenStackClient) hasService(service string) bool {
+	for _, region := range cli.tokenCredential.GetRegions() {
+		_, err := cli.getServiceUrl(region, service)
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (cli *SOpenStackClient) fetchToken() error {
 	if cli.tokenCredential != nil {
 		return nil
@@ -577,10 +628,13 @@ func (self *SOpenStackClient) GetCapabilities() []string {
 		cloudprovider.CLOUD_CAPABILITY_EIP,
 		cloudprovider.CLOUD_CAPABILITY_LOADBALANCER,
 		cloudprovider.CLOUD_CAPABILITY_QUOTA + cloudprovider.READ_ONLY_SUFFIX,
-		// cloudprovider.CLOUD_CAPABILITY_OBJECTSTORE,
 		// cloudprovider.CLOUD_CAPABILITY_RDS,
 		// cloudprovider.CLOUD_CAPABILITY_CACHE,
 		// cloudprovider.CLOUD_CAPABILITY_EVENT,
 	}
+	// swift is optional, only report object storage if it exists in keystone catalog
+	if self.tokenCredential != nil && self.hasService(OPENSTACK_SERVICE_OBJECT_STORE) {
+		caps = append(caps, cloudprovider.CLOUD_CAPABILITY_OBJECTSTORE)
+	}
 	return caps
 }
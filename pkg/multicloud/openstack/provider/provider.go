@@ -211,7 +211,11 @@ func (self *SOpenStackProvider) GetStorageClasses(regionId string) []string {
 }
 
 func (self *SOpenStackProvider) GetBucketCannedAcls(regionId string) []string {
-	return nil
+	return []string{
+		string(cloudprovider.ACLPrivate),
+		string(cloudprovider.ACLPublicRead),
+		string(cloudprovider.ACLPublicReadWrite),
+	}
 }
 
 func (self *SOpenStackProvider) GetObjectCannedAcls(regionId string) []string {
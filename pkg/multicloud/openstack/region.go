@@ -17,6 +17,7 @@ package openstack
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -334,6 +335,18 @@ func (region *SRegion) imageUpload(url string, size int64, body io.Reader, callb
 	return err
 }
 
+func (region *SRegion) imageDownload(url string) (*http.Response, error) {
+	resp, err := region.client.imageDownload(region.Name, url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		_, _, err = httputils.ParseResponse("", resp, nil, region.client.debug)
+		return nil, err
+	}
+	return resp, nil
+}
+
 // Block Storage
 func (region *SRegion) bsList(resource string, query url.Values) (jsonutils.JSONObject, error) {
 	return region.client.bsRequest(region.Name, httputils.GET, resource, query, nil)
@@ -381,6 +394,61 @@ func (region *SRegion) lbDelete(resource string) (jsonutils.JSONObject, error) {
 	return region.client.lbRequest(region.Name, httputils.DELETE, resource, nil, nil)
 }
 
+// Key Manager
+func (region *SRegion) kmList(resource string, query url.Values) (jsonutils.JSONObject, error) {
+	return region.client.kmRequest(region.Name, httputils.GET, resource, query, nil)
+}
+
+func (region *SRegion) kmGet(resource string) (jsonutils.JSONObject, error) {
+	return region.client.kmRequest(region.Name, httputils.GET, resource, nil, nil)
+}
+
+func (region *SRegion) kmPost(resource string, params interface{}) (jsonutils.JSONObject, error) {
+	return region.client.kmRequest(region.Name, httputils.POST, resource, nil, params)
+}
+
+func (region *SRegion) kmDelete(resource string) (jsonutils.JSONObject, error) {
+	return region.client.kmRequest(region.Name, httputils.DELETE, resource, nil, nil)
+}
+
+func (region *SRegion) kmPayload(resource string) (string, error) {
+	resp, err := region.client.kmPayload(region.Name, resource)
+	_, body, err := httputils.ParseResponse("", resp, err, region.client.debug)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// Object Storage
+func (region *SRegion) ossRawRequest(method httputils.THttpMethod, resource string, query url.Values, header http.Header, body io.Reader) (*http.Response, error) {
+	resp, err := region.client.objectStoreRequest(region.Name, method, resource, query, header, body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		_, _, err = region.ossParseResponse(resp, nil)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (region *SRegion) ossRequest(method httputils.THttpMethod, resource string, query url.Values, header http.Header, body io.Reader) (http.Header, []byte, error) {
+	resp, err := region.client.objectStoreRequest(region.Name, method, resource, query, header, body)
+	return region.ossParseResponse(resp, err)
+}
+
+func (region *SRegion) ossParseResponse(resp *http.Response, err error) (http.Header, []byte, error) {
+	header, body, err := httputils.ParseResponse("", resp, err, region.client.debug)
+	if err != nil {
+		if e, ok := err.(*httputils.JSONClientError); ok && e.Code == 404 {
+			return nil, nil, errors.Wrap(cloudprovider.ErrNotFound, e.Error())
+		}
+		return nil, nil, err
+	}
+	return header, body, nil
+}
+
 func (region *SRegion) ProjectId() string {
 	return region.client.tokenCredential.GetProjectId()
 }
@@ -464,11 +532,11 @@ func (region *SRegion) GetILoadBalancerAclById(aclId string) (cloudprovider.IClo
 }
 
 func (region *SRegion) GetILoadBalancerCertificateById(certId string) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return region.GetLoadbalancerCertificate(certId)
 }
 
 func (region *SRegion) CreateILoadBalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return region.CreateLoadbalancerCertificate(cert)
 }
 
 func (region *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalancerAcl, error) {
@@ -484,7 +552,16 @@ func (region *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalance
 }
 
 func (region *SRegion) GetILoadBalancerCertificates() ([]cloudprovider.ICloudLoadbalancerCertificate, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	certs, err := region.GetLoadbalancerCertificates()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetLoadbalancerCertificates")
+	}
+	ret := []cloudprovider.ICloudLoadbalancerCertificate{}
+	for i := range certs {
+		certs[i].region = region
+		ret = append(ret, &certs[i])
+	}
+	return ret, nil
 }
 
 func (region *SRegion) CreateILoadBalancer(loadbalancer *cloudprovider.SLoadbalancerCreateOptions) (cloudprovider.ICloudLoadbalancer, error) {
@@ -524,27 +601,43 @@ func (region *SRegion) GetISkus() ([]cloudprovider.ICloudSku, error) {
 }
 
 func (region *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	buckets, err := region.GetBuckets()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetBuckets")
+	}
+	ret := []cloudprovider.ICloudBucket{}
+	for i := range buckets {
+		buckets[i].region = region
+		ret = append(ret, &buckets[i])
+	}
+	return ret, nil
 }
 
 func (region *SRegion) CreateIBucket(name string, storageClassStr string, acl string) error {
-	return cloudprovider.ErrNotImplemented
+	return region.CreateBucket(name, storageClassStr, cloudprovider.TBucketACLType(acl))
 }
 
 func (region *SRegion) DeleteIBucket(name string) error {
-	return cloudprovider.ErrNotImplemented
+	return region.DeleteBucket(name)
 }
 
 func (region *SRegion) IBucketExist(name string) (bool, error) {
-	return false, cloudprovider.ErrNotImplemented
+	_, err := region.GetBucket(name)
+	if err != nil {
+		if errors.Cause(err) == cloudprovider.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 func (region *SRegion) GetIBucketById(name string) (cloudprovider.ICloudBucket, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return region.GetBucket(name)
 }
 
 func (region *SRegion) GetIBucketByName(name string) (cloudprovider.ICloudBucket, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return region.GetBucket(name)
 }
 
 func (region *SRegion) GetISecurityGroupById(secgroupId string) (cloudprovider.ICloudSecurityGroup, error) {
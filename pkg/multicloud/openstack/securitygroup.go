@@ -121,6 +121,14 @@ func (secgroup *SSecurityGroup) GetVpcId() string {
 	return "normal"
 }
 
+func (secgroup *SSecurityGroup) GetTags() (map[string]string, error) {
+	return neutronTagsToMap(secgroup.Tags), nil
+}
+
+func (secgroup *SSecurityGroup) SetTags(tags map[string]string, replace bool) error {
+	return secgroup.region.SetNeutronTags("security-groups", secgroup.Id, secgroup.Tags, tags, replace)
+}
+
 func (secgroup *SSecurityGroup) GetId() string {
 	return secgroup.Id
 }
@@ -214,6 +222,7 @@ func (secgroup *SSecurityGroup) Refresh() error {
 	if err != nil {
 		return err
 	}
+	secgroup.Tags = nil
 	return jsonutils.Update(secgroup, new)
 }
 
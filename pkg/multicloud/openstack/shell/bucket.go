@@ -0,0 +1,38 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/multicloud/objectstore"
+	"yunion.io/x/cloudmux/pkg/multicloud/openstack"
+)
+
+func init() {
+	objectstore.S3Shell()
+
+	type BucketNameOptions struct {
+		NAME string
+	}
+	shellutils.R(&BucketNameOptions{}, "bucket-head", "Head swift container", func(cli *openstack.SRegion, args *BucketNameOptions) error {
+		bucket, err := cli.GetBucket(args.NAME)
+		if err != nil {
+			return err
+		}
+		printObject(bucket)
+		return nil
+	})
+}
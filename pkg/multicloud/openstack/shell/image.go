@@ -78,4 +78,23 @@ func init() {
 		return nil
 	})
 
+	type ImageDownloadOptions struct {
+		STORAGE_ID string
+		ID         string
+		PATH       string
+	}
+
+	shellutils.R(&ImageDownloadOptions{}, "image-download", "download image", func(cli *openstack.SRegion, args *ImageDownloadOptions) error {
+		cache, err := cli.GetIStoragecacheById(args.STORAGE_ID)
+		if err != nil {
+			return err
+		}
+		ret, err := cache.DownloadImage(args.ID, args.ID, args.PATH)
+		if err != nil {
+			return err
+		}
+		printObject(ret)
+		return nil
+	})
+
 }
@@ -15,8 +15,11 @@
 package shell
 
 import (
+	"io/ioutil"
+
 	"yunion.io/x/pkg/util/shellutils"
 
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud/openstack"
 )
 
@@ -74,6 +77,44 @@ func init() {
 		printObject(loadbalancers)
 		return nil
 	})
+	type LoadbalancerCertListOptions struct {
+	}
+	shellutils.R(&LoadbalancerCertListOptions{}, "lbcert-list", "List loadbalancer certificates", func(cli *openstack.SRegion, args *LoadbalancerCertListOptions) error {
+		certs, err := cli.GetLoadbalancerCertificates()
+		if err != nil {
+			return err
+		}
+		printObject(certs)
+		return nil
+	})
+	type LoadbalancerCertCreateOptions struct {
+		NAME        string
+		CERTIFICATE string `help:"certificate file path"`
+		PRIVATE_KEY string `help:"private key file path"`
+	}
+	shellutils.R(&LoadbalancerCertCreateOptions{}, "lbcert-create", "Create loadbalancer certificate", func(cli *openstack.SRegion, args *LoadbalancerCertCreateOptions) error {
+		certificate, err := ioutil.ReadFile(args.CERTIFICATE)
+		if err != nil {
+			return err
+		}
+		privateKey, err := ioutil.ReadFile(args.PRIVATE_KEY)
+		if err != nil {
+			return err
+		}
+		cert, err := cli.CreateLoadbalancerCertificate(&cloudprovider.SLoadbalancerCertificate{
+			Name:        args.NAME,
+			Certificate: string(certificate),
+			PrivateKey:  string(privateKey),
+		})
+		if err != nil {
+			return err
+		}
+		printObject(cert)
+		return nil
+	})
+	shellutils.R(&LoadbalancerOptions{}, "lbcert-delete", "Delete loadbalancer certificate", func(cli *openstack.SRegion, args *LoadbalancerOptions) error {
+		return cli.DeleteLoadbalancerCertificate(args.ID)
+	})
 	shellutils.R(&LoadbalancerOptions{}, "lblistener-delete", "Delete loadbalancer listener", func(cli *openstack.SRegion, args *LoadbalancerOptions) error {
 		err := cli.DeleteLoadbalancerListener(args.ID)
 		if err != nil {
@@ -15,6 +15,7 @@
 package openstack
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -139,6 +140,16 @@ func (storage *SStorage) CreateIDisk(conf *cloudprovider.DiskCreateConfig) (clou
 	return disk, cloudprovider.WaitStatus(disk, api.DISK_READY, time.Second*5, time.Minute*5)
 }
 
+// CreateIDiskFromImage 由镜像创建云硬盘
+func (storage *SStorage) CreateIDiskFromImage(ctx context.Context, imageId string, conf *cloudprovider.DiskCreateConfig) (cloudprovider.ICloudDisk, error) {
+	disk, err := storage.zone.region.CreateDisk(imageId, storage.Name, conf.Name, conf.SizeGb, conf.Desc, conf.ProjectId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateDisk")
+	}
+	disk.storage = storage
+	return disk, nil
+}
+
 func (storage *SStorage) GetIDiskById(idStr string) (cloudprovider.ICloudDisk, error) {
 	disk, err := storage.zone.region.GetDisk(idStr)
 	if err != nil {
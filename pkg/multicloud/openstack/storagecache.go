@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
 	"yunion.io/x/pkg/errors"
 	"yunion.io/x/pkg/util/qemuimgfmt"
 
@@ -93,10 +94,12 @@ func (cache *SStoragecache) UploadImage(ctx context.Context, image *cloudprovide
 }
 
 func (cache *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(progress float32)) (string, error) {
-	reader, size, err := image.GetReader(image.ImageId, string(qemuimgfmt.QCOW2))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.QCOW2, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	size, callback := reader.Size, reader.Callback
 
 	imageBaseName := image.ImageName
 	imageName := imageBaseName
@@ -148,5 +151,39 @@ func (cache *SStoragecache) CreateIImage(snapshoutId, imageName, osType, imageDe
 }
 
 func (cache *SStoragecache) DownloadImage(imageId string, extId string, path string) (jsonutils.JSONObject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return cache.ExportImage(context.Background(), extId, &multicloud.SImageDownloadOptions{
+		Path: path,
+		Callback: func(progress float32) {
+			log.Debugf("download image %s(%s) progress: %.2f%%", imageId, extId, progress)
+		},
+	})
+}
+
+// ExportImage downloads image data from glance, checksum is the md5 of image data
+func (cache *SStoragecache) ExportImage(ctx context.Context, imageId string, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	image, err := cache.region.GetImage(imageId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetImage(%s)", imageId)
+	}
+	if image.Status != ACTIVE {
+		return nil, errors.Wrapf(cloudprovider.ErrInvalidStatus, "image %s status %s", imageId, image.Status)
+	}
+	resp, err := cache.region.imageDownload(fmt.Sprintf("/v2/images/%s/file", image.Id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "imageDownload")
+	}
+	size := resp.ContentLength
+	if size <= 0 {
+		size = int64(image.Size)
+	}
+	stream := &multicloud.SImageStream{
+		Body:   resp.Body,
+		Size:   size,
+		Format: image.DiskFormat,
+		Md5:    image.Checksum,
+	}
+	if image.OsHashAlgo == "sha256" {
+		stream.Sha256 = image.OsHashValue
+	}
+	return multicloud.SaveImage(ctx, stream, opts)
 }
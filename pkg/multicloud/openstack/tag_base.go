@@ -15,6 +15,9 @@
 package openstack
 
 import (
+	"fmt"
+	"strings"
+
 	"yunion.io/x/pkg/errors"
 
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -35,3 +38,49 @@ func (self *OpenStackTags) GetSysTags() map[string]string {
 func (self *OpenStackTags) SetTags(tags map[string]string, replace bool) error {
 	return errors.Wrap(cloudprovider.ErrNotImplemented, "SetTags")
 }
+
+// neutron tags are plain strings, key and value are saved as key=value
+func neutronTagsToMap(tags []string) map[string]string {
+	ret := map[string]string{}
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, "=", 2)
+		if len(parts) == 2 {
+			ret[parts[0]] = parts[1]
+		} else {
+			ret[tag] = ""
+		}
+	}
+	return ret
+}
+
+func mapToNeutronTags(tags map[string]string) []string {
+	ret := []string{}
+	for k, v := range tags {
+		if len(v) > 0 {
+			ret = append(ret, fmt.Sprintf("%s=%s", k, v))
+		} else {
+			ret = append(ret, k)
+		}
+	}
+	return ret
+}
+
+// https://docs.openstack.org/api-ref/network/v2/#replace-all-tags
+func (region *SRegion) SetNeutronTags(resourceType, resourceId string, oldTags []string, tags map[string]string, replace bool) error {
+	newTags := map[string]string{}
+	if !replace {
+		newTags = neutronTagsToMap(oldTags)
+	}
+	for k, v := range tags {
+		newTags[k] = v
+	}
+	params := map[string]interface{}{
+		"tags": mapToNeutronTags(newTags),
+	}
+	resource := fmt.Sprintf("/v2.0/%s/%s/tags", resourceType, resourceId)
+	_, err := region.vpcUpdate(resource, params)
+	if err != nil {
+		return errors.Wrapf(err, "vpcUpdate(%s)", resource)
+	}
+	return nil
+}
@@ -47,6 +47,9 @@ type SDisk struct {
 	Name    string `json:"name"`
 	Parent  string `json:"parent"`
 	Content string `json:"content"`
+
+	// 虚拟机的启动盘
+	isSys bool
 }
 
 func (self *SDisk) GetName() string {
@@ -86,6 +89,9 @@ func (self *SDisk) GetDriver() string {
 }
 
 func (self *SDisk) GetDiskType() string {
+	if self.isSys {
+		return api.DISK_TYPE_SYS
+	}
 	return api.DISK_TYPE_DATA
 }
 
@@ -142,6 +148,33 @@ func (self *SDisk) GetISnapshots() ([]cloudprovider.ICloudSnapshot, error) {
 	return []cloudprovider.ICloudSnapshot{}, nil
 }
 
+// ExportDisk 通过存储的content接口下载磁盘卷数据, 存储不提供卷数据下载时返回ErrNotSupported
+func (self *SDisk) ExportDisk(ctx context.Context, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	res := fmt.Sprintf("/nodes/%s/storage/%s/content/%s", self.Node, self.Storage, url.PathEscape(self.VolId))
+	resp, err := self.region.client.download(ctx, res, url.Values{"download": []string{"1"}})
+	if err != nil {
+		return nil, errors.Wrapf(err, "download %s", self.VolId)
+	}
+	defer resp.Body.Close()
+	// 不支持下载时接口返回的是卷的属性
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "storage %s does not provide data of volume %s", self.Storage, self.VolId)
+	}
+	size := resp.ContentLength
+	if size < 0 {
+		size = 0
+	}
+	return multicloud.SaveImage(ctx, &multicloud.SImageStream{
+		Body:   resp.Body,
+		Size:   size,
+		Format: self.GetDiskFormat(),
+	}, opts)
+}
+
 func (self *SRegion) GetDisks(storageId string) ([]SDisk, error) {
 	vols := []SDisk{}
 	disks := []SDisk{}
@@ -0,0 +1,168 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxmox
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/tristate"
+	"yunion.io/x/pkg/utils"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+)
+
+type SHostNic struct {
+	host  *SHost
+	index int8
+
+	// bridge or bond the nic is enslaved to
+	bridge string
+	master *SHostNic
+
+	Iface       string `json:"iface"`
+	Type        string `json:"type"`
+	Active      int    `json:"active"`
+	Exists      int    `json:"exists"`
+	Mtu         int32  `json:"mtu"`
+	Address     string `json:"address"`
+	Netmask     string `json:"netmask"`
+	Gateway     string `json:"gateway"`
+	Cidr        string `json:"cidr"`
+	BridgePorts string `json:"bridge_ports"`
+	Slaves      string `json:"slaves"`
+	BondMode    string `json:"bond_mode"`
+	Hwaddress   string `json:"hwaddress"`
+}
+
+func (self *SHostNic) GetDevice() string {
+	return self.Iface
+}
+
+func (self *SHostNic) GetDriver() string {
+	return ""
+}
+
+// pve 节点网络接口仅返回配置的hwaddress, pve7以后网桥及bond的hwaddress固定为第一个端口的mac地址
+func (self *SHostNic) GetMac() string {
+	if len(self.Hwaddress) > 0 {
+		return strings.ToLower(self.Hwaddress)
+	}
+	for nic := self; nic.master != nil; nic = nic.master {
+		ports := nic.master.getPorts()
+		if len(ports) == 0 || ports[0] != nic.Iface {
+			break
+		}
+		if len(nic.master.Hwaddress) > 0 {
+			return strings.ToLower(nic.master.Hwaddress)
+		}
+	}
+	return ""
+}
+
+func (self *SHostNic) getPorts() []string {
+	switch self.Type {
+	case "bridge", "OVSBridge":
+		return strings.Fields(self.BridgePorts)
+	case "bond", "OVSBond":
+		return strings.Fields(self.Slaves)
+	}
+	return []string{}
+}
+
+func (self *SHostNic) GetIndex() int8 {
+	return self.index
+}
+
+func (self *SHostNic) IsLinkUp() tristate.TriState {
+	if self.Active == 1 {
+		return tristate.True
+	}
+	return tristate.False
+}
+
+func (self *SHostNic) GetIpAddr() string {
+	if len(self.Address) > 0 {
+		return self.Address
+	}
+	if self.master != nil {
+		return self.master.GetIpAddr()
+	}
+	return ""
+}
+
+func (self *SHostNic) GetMtu() int32 {
+	if self.Mtu == 0 && self.master != nil {
+		return self.master.GetMtu()
+	}
+	return self.Mtu
+}
+
+func (self *SHostNic) isAdmin() bool {
+	if len(self.Gateway) > 0 {
+		return true
+	}
+	if self.master != nil {
+		return self.master.isAdmin()
+	}
+	return false
+}
+
+func (self *SHostNic) GetNicType() string {
+	if self.isAdmin() {
+		return api.NIC_TYPE_ADMIN
+	}
+	return ""
+}
+
+func (self *SHostNic) GetBridge() string {
+	return self.bridge
+}
+
+// 返回节点上的物理网卡, 并关联其所属的bond及网桥
+func (self *SRegion) GetHostNics(node string) ([]SHostNic, error) {
+	ifaces := []SHostNic{}
+	res := fmt.Sprintf("/nodes/%s/network", node)
+	err := self.get(res, url.Values{}, &ifaces)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get %s", res)
+	}
+	masters := map[string]*SHostNic{}
+	for i := range ifaces {
+		for _, port := range ifaces[i].getPorts() {
+			masters[port] = &ifaces[i]
+		}
+	}
+	for i := range ifaces {
+		ifaces[i].master = masters[ifaces[i].Iface]
+	}
+	ret := []SHostNic{}
+	for i := range ifaces {
+		if ifaces[i].Type != "eth" {
+			continue
+		}
+		nic := ifaces[i]
+		for master := nic.master; master != nil; master = masters[master.Iface] {
+			if utils.IsInStringArray(master.Type, []string{"bridge", "OVSBridge"}) {
+				nic.bridge = master.Iface
+				break
+			}
+		}
+		ret = append(ret, nic)
+	}
+	return ret, nil
+}
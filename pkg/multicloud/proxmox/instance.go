@@ -27,7 +27,9 @@ import (
 	"yunion.io/x/jsonutils"
 	"yunion.io/x/log"
 	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/cloudinit"
 	"yunion.io/x/pkg/util/osprofile"
+	"yunion.io/x/pkg/utils"
 
 	api "yunion.io/x/cloudmux/pkg/apis/compute"
 	"yunion.io/x/cloudmux/pkg/cloudprovider"
@@ -184,7 +186,7 @@ func (self *SInstance) Refresh() error {
 }
 
 func (self *SInstance) AssignSecurityGroup(id string) error {
-	return cloudprovider.ErrNotSupported
+	return self.host.zone.region.AssignSecurityGroup(self.Node, self.VmID, id)
 }
 
 func (self *SInstance) AttachDisk(ctx context.Context, diskId string) error {
@@ -192,7 +194,8 @@ func (self *SInstance) AttachDisk(ctx context.Context, diskId string) error {
 }
 
 func (self *SInstance) CreateDisk(ctx context.Context, opts *cloudprovider.GuestDiskCreateOptions) (string, error) {
-	return "", cloudprovider.ErrNotSupported
+	sizeGb := (opts.SizeMb + 1023) / 1024
+	return self.host.zone.region.CreateDisk(self.VmID, opts.StorageId, opts.Driver, sizeGb)
 }
 
 func (self *SInstance) ChangeConfig(ctx context.Context, opts *cloudprovider.SManagedVMChangeConfig) error {
@@ -254,7 +257,24 @@ func (self *SInstance) GetIDisks() ([]cloudprovider.ICloudDisk, error) {
 		return nil, nil
 	}
 
+	sysDevice, _ := self.getSysDevice()
+	devices := []string{}
 	for k, v := range self.QemuDisks {
+		if isCdrom(v) || isCloudInitDrive(v) {
+			continue
+		}
+		device, _ := self.getDevice(k)
+		devices = append(devices, device)
+	}
+	// 系统盘在前, 数据盘按设备名排序
+	sort.Slice(devices, func(i, j int) bool {
+		if (devices[i] == sysDevice) != (devices[j] == sysDevice) {
+			return devices[i] == sysDevice
+		}
+		return devices[i] < devices[j]
+	})
+	for _, device := range devices {
+		k, v := self.getVolumeByDevice(device)
 		disk, err := self.host.zone.region.GetDisk(k)
 		if err != nil {
 			continue
@@ -265,6 +285,7 @@ func (self *SInstance) GetIDisks() ([]cloudprovider.ICloudDisk, error) {
 		if cache, ok := v["cache"].(string); ok {
 			disk.CacheMode = cache
 		}
+		disk.isSys = device == sysDevice
 		ret = append(ret, disk)
 	}
 
@@ -352,7 +373,7 @@ func (self *SInstance) GetProjectId() string {
 }
 
 func (self *SInstance) GetVNCInfo(input *cloudprovider.ServerVncInput) (*cloudprovider.ServerVncOutput, error) {
-	return nil, cloudprovider.ErrNotSupported
+	return self.host.zone.region.GetVNCInfo(self.Node, self.VmID, self.Name)
 }
 
 func (self *SInstance) GetVcpuCount() int {
@@ -372,15 +393,57 @@ func (self *SInstance) GetVdi() string {
 }
 
 func (self *SInstance) RebuildRoot(ctx context.Context, desc *cloudprovider.SManagedVMRebuildRootConfig) (string, error) {
-	return "", cloudprovider.ErrNotSupported
+	return self.host.zone.region.RebuildRoot(self.VmID, desc)
 }
 
 func (self *SInstance) GetSecurityGroupIds() ([]string, error) {
-	return []string{}, nil
+	rules, err := self.host.zone.region.GetInstanceFirewallRules(self.Node, self.VmID)
+	if err != nil {
+		return nil, err
+	}
+	ret := []string{}
+	for _, rule := range rules {
+		if rule.Type == "group" {
+			ret = append(ret, rule.Action)
+		}
+	}
+	return ret, nil
 }
 
 func (self *SInstance) SetSecurityGroups(secgroupIds []string) error {
-	return cloudprovider.ErrNotSupported
+	return self.host.zone.region.SetSecurityGroups(self.Node, self.VmID, secgroupIds)
+}
+
+func (self *SInstance) MigrateVM(hostId string) error {
+	return self.host.zone.region.MigrateVM(self.Node, self.VmID, hostId, false)
+}
+
+func (self *SInstance) LiveMigrateVM(hostId string) error {
+	return self.host.zone.region.MigrateVM(self.Node, self.VmID, hostId, true)
+}
+
+func (self *SInstance) CreateInstanceSnapshot(ctx context.Context, name string, desc string) (cloudprovider.ICloudInstanceSnapshot, error) {
+	return self.host.zone.region.CreateInstanceSnapshot(self.Node, self.VmID, name, desc)
+}
+
+func (self *SInstance) GetInstanceSnapshot(idStr string) (cloudprovider.ICloudInstanceSnapshot, error) {
+	return self.host.zone.region.GetInstanceSnapshot(self.Node, self.VmID, idStr)
+}
+
+func (self *SInstance) GetInstanceSnapshots() ([]cloudprovider.ICloudInstanceSnapshot, error) {
+	snapshots, err := self.host.zone.region.GetInstanceSnapshots(self.Node, self.VmID)
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudInstanceSnapshot{}
+	for i := range snapshots {
+		ret = append(ret, &snapshots[i])
+	}
+	return ret, nil
+}
+
+func (self *SInstance) ResetToInstanceSnapshot(ctx context.Context, idStr string) error {
+	return self.host.zone.region.ResetToInstanceSnapshot(self.Node, self.VmID, idStr)
 }
 
 func (self *SInstance) StartVM(ctx context.Context) error {
@@ -395,7 +458,7 @@ func (self *SInstance) StopVM(ctx context.Context, opts *cloudprovider.ServerSto
 }
 
 func (self *SInstance) UpdateUserData(userData string) error {
-	return cloudprovider.ErrNotSupported
+	return self.host.zone.region.UpdateUserData(self.VmID, userData)
 }
 
 func (self *SInstance) UpdateVM(ctx context.Context, name string) error {
@@ -1017,3 +1080,493 @@ func (self *SRegion) GenVM(name, node string, cores, memMB int) (*SInstance, err
 
 	return vm, nil
 }
+
+var diskDriverSlots = map[string]int{
+	"ide":    4,
+	"sata":   6,
+	"scsi":   31,
+	"virtio": 16,
+}
+
+// getDevice returns the device name and config of the disk whose volume is volId
+func (self *SInstance) getDevice(volId string) (string, QemuDevice) {
+	if conf, ok := self.QemuDisks[volId]; ok {
+		return fmt.Sprintf("%s%d", conf["type"], conf["slot"]), conf
+	}
+	return "", nil
+}
+
+func (self *SInstance) getVolumeByDevice(device string) (string, QemuDevice) {
+	for volId, conf := range self.QemuDisks {
+		if fmt.Sprintf("%s%d", conf["type"], conf["slot"]) == device {
+			return volId, conf
+		}
+	}
+	return "", nil
+}
+
+func isCdrom(conf QemuDevice) bool {
+	media, _ := conf["media"].(string)
+	return media == "cdrom"
+}
+
+func isCloudInitDrive(conf QemuDevice) bool {
+	file, _ := conf["file"].(string)
+	return strings.Contains(file, "cloudinit")
+}
+
+// getSysDevice returns the first bootable disk device, e.g. scsi0
+func (self *SInstance) getSysDevice() (string, error) {
+	devices := []string{}
+	if strings.HasPrefix(self.Boot, "order=") {
+		devices = strings.Split(strings.TrimPrefix(self.Boot, "order="), ";")
+	} else if len(self.BootDisk) > 0 {
+		devices = append(devices, self.BootDisk)
+	}
+	for _, device := range devices {
+		volId, conf := self.getVolumeByDevice(device)
+		if len(volId) > 0 && !isCdrom(conf) && !isCloudInitDrive(conf) {
+			return device, nil
+		}
+	}
+	disks := []string{}
+	for volId, conf := range self.QemuDisks {
+		if !isCdrom(conf) && !isCloudInitDrive(conf) {
+			device, _ := self.getDevice(volId)
+			disks = append(disks, device)
+		}
+	}
+	if len(disks) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "no system disk of vm %d", self.VmID)
+	}
+	sort.Strings(disks)
+	return disks[0], nil
+}
+
+func (self *SInstance) getFreeSlot(driver string) (int, error) {
+	used := map[int]bool{}
+	for _, conf := range self.QemuDisks {
+		if conf["type"] == driver {
+			used[conf["slot"].(int)] = true
+		}
+	}
+	for slot := 0; slot < diskDriverSlots[driver]; slot++ {
+		if !used[slot] {
+			return slot, nil
+		}
+	}
+	return -1, errors.Errorf("no free %s slot for vm %d", driver, self.VmID)
+}
+
+func (self *SRegion) updateConfig(node string, vmId int, body map[string]interface{}) error {
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/config", node, vmId)
+	_, err := self.post(res, jsonutils.Marshal(body))
+	return err
+}
+
+type SVncProxy struct {
+	Cert   string
+	Port   int64
+	Ticket string
+	Upid   string
+	User   string
+}
+
+func (self *SRegion) GetVNCInfo(node string, vmId int, name string) (*cloudprovider.ServerVncOutput, error) {
+	vnc := &SVncProxy{}
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/vncproxy", node, vmId)
+	err := self.postData(res, map[string]interface{}{"websocket": 1}, vnc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "vncproxy")
+	}
+	query := url.Values{}
+	query.Set("port", fmt.Sprintf("%d", vnc.Port))
+	query.Set("vncticket", vnc.Ticket)
+	ret := &cloudprovider.ServerVncOutput{
+		Protocol:     CLOUD_PROVIDER_PROXMOX,
+		InstanceId:   strconv.Itoa(vmId),
+		InstanceName: name,
+		Host:         self.client.host,
+		Port:         int64(self.client.port),
+		Url: fmt.Sprintf("wss://%s:%d/api2/json/nodes/%s/qemu/%d/vncwebsocket?%s",
+			self.client.host, self.client.port, node, vmId, query.Encode()),
+		VncPassword: vnc.Ticket,
+		// the websocket is authenticated by the api ticket
+		ConnectParams: fmt.Sprintf("PVEAuthCookie=%s", url.QueryEscape(self.client.authTicket)),
+		Hypervisor:    api.HYPERVISOR_PROXMOX,
+	}
+	return ret, nil
+}
+
+func (self *SRegion) CreateDisk(vmId int, storageId, driver string, sizeGb int) (string, error) {
+	vm, err := self.GetInstance(strconv.Itoa(vmId))
+	if err != nil {
+		return "", errors.Wrapf(err, "GetInstance(%d)", vmId)
+	}
+	if _, ok := diskDriverSlots[driver]; !ok {
+		driver = "scsi"
+	}
+	// storage/<node>/<storage>
+	storage := storageId
+	if splited := strings.Split(storageId, "/"); len(splited) == 3 {
+		storage = splited[2]
+	}
+	if len(storage) == 0 {
+		device, err := vm.getSysDevice()
+		if err != nil {
+			return "", err
+		}
+		volId, _ := vm.getVolumeByDevice(device)
+		storage, _ = ParseSubConf(volId, ":")
+	}
+	slot, err := vm.getFreeSlot(driver)
+	if err != nil {
+		return "", err
+	}
+	device := fmt.Sprintf("%s%d", driver, slot)
+	err = self.updateConfig(vm.Node, vmId, map[string]interface{}{
+		device: fmt.Sprintf("%s:%d", storage, sizeGb),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "add disk %s", device)
+	}
+	vm, err = self.GetInstance(strconv.Itoa(vmId))
+	if err != nil {
+		return "", errors.Wrapf(err, "GetInstance(%d)", vmId)
+	}
+	volId, _ := vm.getVolumeByDevice(device)
+	if len(volId) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "disk %s of vm %d", device, vmId)
+	}
+	return volId, nil
+}
+
+// ensureCloudInitDrive adds a cloud-init drive on the storage of the system disk if the vm has none
+func (self *SRegion) ensureCloudInitDrive(vm *SInstance) error {
+	for _, conf := range vm.QemuDisks {
+		if isCloudInitDrive(conf) {
+			return nil
+		}
+	}
+	device, err := vm.getSysDevice()
+	if err != nil {
+		return err
+	}
+	volId, _ := vm.getVolumeByDevice(device)
+	storage, _ := ParseSubConf(volId, ":")
+	slot := -1
+	// ide2 is usually taken by the cdrom
+	for _, i := range []int{3, 1, 0} {
+		if _, conf := vm.getVolumeByDevice(fmt.Sprintf("ide%d", i)); conf == nil {
+			slot = i
+			break
+		}
+	}
+	if slot < 0 {
+		return errors.Errorf("no free ide slot for cloud-init drive of vm %d", vm.VmID)
+	}
+	return self.updateConfig(vm.Node, vm.VmID, map[string]interface{}{
+		fmt.Sprintf("ide%d", slot): fmt.Sprintf("%s:cloudinit", storage),
+	})
+}
+
+// SetCloudInit configures the login user, password and ssh keys through the cloud-init drive,
+// which take effect on the next boot
+func (self *SRegion) SetCloudInit(vmId int, username, password string, publicKeys []string) error {
+	vm, err := self.GetInstance(strconv.Itoa(vmId))
+	if err != nil {
+		return errors.Wrapf(err, "GetInstance(%d)", vmId)
+	}
+	err = self.ensureCloudInitDrive(vm)
+	if err != nil {
+		return errors.Wrapf(err, "ensureCloudInitDrive")
+	}
+	body := map[string]interface{}{}
+	if len(username) > 0 {
+		body["ciuser"] = username
+	}
+	if len(password) > 0 {
+		body["cipassword"] = password
+	}
+	if len(publicKeys) > 0 {
+		// proxmox requires the ssh keys to be url encoded
+		body["sshkeys"] = strings.ReplaceAll(url.QueryEscape(strings.Join(publicKeys, "\n")), "+", "%20")
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return self.updateConfig(vm.Node, vmId, body)
+}
+
+// UpdateUserData applies the users of the cloud-config to the cloud-init drive,
+// custom snippets (cicustom) can not be uploaded through the api, so other sections are ignored
+func (self *SRegion) UpdateUserData(vmId int, userData string) error {
+	config, err := cloudinit.ParseUserData(userData)
+	if err != nil {
+		return errors.Wrapf(err, "ParseUserData")
+	}
+	for _, user := range config.Users {
+		if len(user.PlainTextPasswd) == 0 && len(user.SshAuthorizedKeys) == 0 {
+			continue
+		}
+		return self.SetCloudInit(vmId, user.Name, user.PlainTextPasswd, user.SshAuthorizedKeys)
+	}
+	return nil
+}
+
+func (self *SRegion) deleteVolume(node, volId string) error {
+	storage, _ := ParseSubConf(volId, ":")
+	res := fmt.Sprintf("/nodes/%s/storage/%s/content/%s", node, storage, url.PathEscape(volId))
+	return self.del(res, nil, nil)
+}
+
+// RebuildRoot replaces the system disk with a full copy of the template disk:
+// the template is cloned to a temporary vm, whose disk is then reassigned to the vm
+func (self *SRegion) RebuildRoot(vmId int, opts *cloudprovider.SManagedVMRebuildRootConfig) (string, error) {
+	vm, err := self.GetInstance(strconv.Itoa(vmId))
+	if err != nil {
+		return "", errors.Wrapf(err, "GetInstance(%d)", vmId)
+	}
+	image, err := self.GetImage(opts.ImageId)
+	if err != nil {
+		return "", errors.Wrapf(err, "GetImage(%s)", opts.ImageId)
+	}
+	sysDevice, err := vm.getSysDevice()
+	if err != nil {
+		return "", err
+	}
+	oldVolId, oldConf := vm.getVolumeByDevice(sysDevice)
+	storage, _ := ParseSubConf(oldVolId, ":")
+
+	tmpId, err := self.cloneRebuildVm(image, vm, storage)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err := self.DeleteVM(tmpId)
+		if err != nil {
+			log.Errorf("delete temporary vm %d error: %v", tmpId, err)
+		}
+	}()
+
+	tmp, err := self.GetInstance(strconv.Itoa(tmpId))
+	if err != nil {
+		return "", errors.Wrapf(err, "GetInstance(%d)", tmpId)
+	}
+	tmpDevice, err := tmp.getSysDevice()
+	if err != nil {
+		return "", err
+	}
+
+	// the old system disk becomes an unused disk and is removed after the new one is in place
+	err = self.updateConfig(vm.Node, vmId, map[string]interface{}{"delete": sysDevice})
+	if err != nil {
+		return "", errors.Wrapf(err, "detach %s", sysDevice)
+	}
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/move_disk", vm.Node, tmpId)
+	_, err = self.post(res, jsonutils.Marshal(map[string]interface{}{
+		"disk":        tmpDevice,
+		"target-vmid": vmId,
+		"target-disk": sysDevice,
+	}))
+	if err != nil {
+		rollbackErr := self.updateConfig(vm.Node, vmId, map[string]interface{}{sysDevice: oldVolId})
+		if rollbackErr != nil {
+			log.Errorf("reattach %s as %s of vm %d error: %v", oldVolId, sysDevice, vmId, rollbackErr)
+		}
+		return "", errors.Wrapf(err, "move %s to vm %d", tmpDevice, vmId)
+	}
+	err = self.deleteVolume(vm.Node, oldVolId)
+	if err != nil {
+		log.Errorf("delete old system disk %s error: %v", oldVolId, err)
+	}
+
+	vm, err = self.GetInstance(strconv.Itoa(vmId))
+	if err != nil {
+		return "", errors.Wrapf(err, "GetInstance(%d)", vmId)
+	}
+	volId, conf := vm.getVolumeByDevice(sysDevice)
+	if len(volId) == 0 {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "system disk %s of vm %d", sysDevice, vmId)
+	}
+	sizeGb := opts.SysSizeGB
+	if oldSize := int(DiskSizeGB(oldConf["size"])); oldSize > sizeGb {
+		sizeGb = oldSize
+	}
+	if sizeGb > int(DiskSizeGB(conf["size"])) {
+		res = fmt.Sprintf("/nodes/%s/qemu/%d/resize", vm.Node, vmId)
+		err = self.put(res, nil, jsonutils.Marshal(map[string]interface{}{
+			"disk": sysDevice,
+			"size": fmt.Sprintf("%dG", sizeGb),
+		}), nil)
+		if err != nil {
+			return "", errors.Wrapf(err, "resize %s", sysDevice)
+		}
+	}
+
+	publicKeys := []string{}
+	if len(opts.PublicKey) > 0 {
+		publicKeys = append(publicKeys, opts.PublicKey)
+	}
+	err = self.SetCloudInit(vmId, opts.Account, opts.Password, publicKeys)
+	if err != nil {
+		return "", errors.Wrapf(err, "SetCloudInit")
+	}
+	return volId, nil
+}
+
+// cloneRebuildVm clones the image to a temporary vm on the node of vm, the next vm id
+// may be taken by a vm created at the same time, then the clone is retried with a new id
+func (self *SRegion) cloneRebuildVm(image *SImage, vm *SInstance, storage string) (int, error) {
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/clone", image.Node, image.VmId)
+	for i := 0; i < 3; i++ {
+		tmpId := self.GetClusterVmMaxId()
+		if tmpId == -1 {
+			return 0, errors.Errorf("failed to get vm number by %d", tmpId)
+		}
+		tmpId++
+		_, err := self.post(res, jsonutils.Marshal(map[string]interface{}{
+			"newid":   tmpId,
+			"name":    fmt.Sprintf("%s-rebuild", vm.Name),
+			"full":    1,
+			"target":  vm.Node,
+			"storage": storage,
+		}))
+		if err == nil {
+			return tmpId, nil
+		}
+		if !strings.Contains(err.Error(), "already exists") {
+			return 0, errors.Wrapf(err, "clone template %d", image.VmId)
+		}
+		log.Warningf("vm id %d is taken, retry to clone template %d: %v", tmpId, image.VmId, err)
+	}
+	return 0, errors.Wrapf(cloudprovider.ErrDuplicateId, "clone template %d", image.VmId)
+}
+
+func (self *SRegion) getVmNode(vmId int) (string, error) {
+	resources, err := self.GetClusterVmResources()
+	if err != nil {
+		return "", err
+	}
+	resource, ok := resources[vmId]
+	if !ok {
+		return "", errors.Wrapf(cloudprovider.ErrNotFound, "vm %d", vmId)
+	}
+	return resource.Node, nil
+}
+
+// MigrateVM moves the vm to the node of hostId, local disks are moved along with the vm
+func (self *SRegion) MigrateVM(node string, vmId int, hostId string, online bool) error {
+	host, err := self.GetHost(hostId)
+	if err != nil {
+		return errors.Wrapf(err, "GetHost(%s)", hostId)
+	}
+	if host.Node == node {
+		return nil
+	}
+	body := map[string]interface{}{
+		"target":           host.Node,
+		"with-local-disks": 1,
+	}
+	if online {
+		body["online"] = 1
+	}
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/migrate", node, vmId)
+	_, err = self.post(res, jsonutils.Marshal(body))
+	if err != nil {
+		return errors.Wrapf(err, "migrate vm %d to %s", vmId, host.Node)
+	}
+	return nil
+}
+
+func (self *SRegion) GetInstanceFirewallRules(node string, vmId int) ([]SFirewallRule, error) {
+	ret := []SFirewallRule{}
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/firewall/rules", node, vmId)
+	err := self.get(res, url.Values{}, &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// enableFirewall turns on the vm firewall and the firewall flag of every nic,
+// the security group rules only take effect on nics with firewall=1
+func (self *SRegion) enableFirewall(node string, vmId int) error {
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/firewall/options", node, vmId)
+	err := self.put(res, nil, jsonutils.Marshal(map[string]interface{}{"enable": 1}), nil)
+	if err != nil {
+		return errors.Wrapf(err, "enable firewall")
+	}
+	config := map[string]interface{}{}
+	err = self.get(fmt.Sprintf("/nodes/%s/qemu/%d/config", node, vmId), url.Values{}, &config)
+	if err != nil {
+		return errors.Wrapf(err, "get config")
+	}
+	body := map[string]interface{}{}
+	for k, v := range config {
+		value, ok := v.(string)
+		if !ok || !strings.HasPrefix(k, "net") || strings.Contains(value, "firewall=1") {
+			continue
+		}
+		opts := []string{}
+		for _, opt := range strings.Split(value, ",") {
+			if !strings.HasPrefix(opt, "firewall=") {
+				opts = append(opts, opt)
+			}
+		}
+		body[k] = strings.Join(append(opts, "firewall=1"), ",")
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return self.updateConfig(node, vmId, body)
+}
+
+func (self *SRegion) AssignSecurityGroup(node string, vmId int, group string) error {
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/firewall/rules", node, vmId)
+	_, err := self.post(res, jsonutils.Marshal(map[string]interface{}{
+		"type":   "group",
+		"action": group,
+		"enable": 1,
+	}))
+	if err != nil {
+		return errors.Wrapf(err, "assign security group %s", group)
+	}
+	return self.enableFirewall(node, vmId)
+}
+
+func (self *SRegion) SetSecurityGroups(node string, vmId int, groups []string) error {
+	rules, err := self.GetInstanceFirewallRules(node, vmId)
+	if err != nil {
+		return err
+	}
+	current := []string{}
+	// delete from the bottom, so the positions of the remaining rules are not shifted
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Pos > rules[j].Pos
+	})
+	for _, rule := range rules {
+		if rule.Type != "group" {
+			continue
+		}
+		if utils.IsInStringArray(rule.Action, groups) {
+			current = append(current, rule.Action)
+			continue
+		}
+		res := fmt.Sprintf("/nodes/%s/qemu/%d/firewall/rules/%d", node, vmId, rule.Pos)
+		err = self.del(res, nil, nil)
+		if err != nil {
+			return errors.Wrapf(err, "remove security group %s", rule.Action)
+		}
+	}
+	for _, group := range groups {
+		if utils.IsInStringArray(group, current) {
+			continue
+		}
+		err = self.AssignSecurityGroup(node, vmId, group)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -18,6 +18,8 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -177,13 +179,34 @@ func (cli *SProxmoxClient) post(res string, params interface{}) (jsonutils.JSONO
 	if err != nil {
 		return resp, err
 	}
-	taskId, err := resp.GetString("data")
-	if err != nil {
-		return resp, err
+	return resp, cli.waitResponse(resp)
+}
+
+// waitResponse waits for the task when the api returns a task upid,
+// synchronous apis return null or the result object directly
+func (cli *SProxmoxClient) waitResponse(resp jsonutils.JSONObject) error {
+	if resp == nil || !resp.Contains("data") {
+		return nil
+	}
+	taskId, _ := resp.GetString("data")
+	if !strings.HasPrefix(taskId, "UPID:") {
+		return nil
 	}
-	_, err = cli.waitTask(taskId)
+	_, err := cli.waitTask(taskId)
+	return err
+}
 
-	return resp, err
+// postData sends a synchronous post request and unmarshals the returned data
+func (cli *SProxmoxClient) postData(res string, params interface{}, retVal interface{}) error {
+	resp, err := cli._jsonRequest(httputils.POST, res, params)
+	if err != nil {
+		return err
+	}
+	dat, err := resp.Get("data")
+	if err != nil {
+		return errors.Wrapf(err, "decode data")
+	}
+	return dat.Unmarshal(retVal)
 }
 
 func (cli *SProxmoxClient) put(res string, params url.Values, body jsonutils.JSONObject, retVal interface{}) error {
@@ -194,13 +217,7 @@ func (cli *SProxmoxClient) put(res string, params url.Values, body jsonutils.JSO
 	if err != nil {
 		return err
 	}
-	taskId, err := resp.GetString("data")
-	if err != nil {
-		return err
-	}
-	_, err = cli.waitTask(taskId)
-
-	return err
+	return cli.waitResponse(resp)
 }
 
 func (cli *SProxmoxClient) get(res string, params url.Values, retVal interface{}) error {
@@ -241,14 +258,45 @@ func (cli *SProxmoxClient) del(res string, params url.Values, retVal interface{}
 	if err != nil {
 		return err
 	}
-	taskId, err := resp.GetString("data")
+	return cli.waitResponse(resp)
+}
+
+// download streams the raw content of res, the caller must close the body of the response
+func (cli *SProxmoxClient) download(ctx context.Context, res string, params url.Values) (*http.Response, error) {
+	resp, err := cli._download(ctx, res, params)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		err = cli.auth()
+		if err != nil {
+			return nil, errors.Wrapf(err, "auth")
+		}
+		resp, err = cli._download(ctx, res, params)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = cli.waitTask(taskId)
-
-	return err
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, "%s: %s", res, msg)
+		}
+		return nil, errors.Errorf("download %s: %s %s", res, resp.Status, msg)
+	}
+	return resp, nil
+}
 
+func (cli *SProxmoxClient) _download(ctx context.Context, res string, params url.Values) (*http.Response, error) {
+	u := fmt.Sprintf("%s/%s", cli.authURL, strings.TrimPrefix(res, "/"))
+	if len(params) > 0 {
+		u = fmt.Sprintf("%s?%s", u, params.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "NewRequest")
+	}
+	req.Header.Set("Cookie", "PVEAuthCookie="+cli.authTicket)
+	return cli.getDefaultClient().Do(req)
 }
 
 func (cli *SProxmoxClient) _jsonRequest(method httputils.THttpMethod, res string, params interface{}) (jsonutils.JSONObject, error) {
@@ -268,7 +316,7 @@ func (cli *SProxmoxClient) __jsonRequest(method httputils.THttpMethod, res strin
 	url := fmt.Sprintf("%s/%s", cli.authURL, strings.TrimPrefix(res, "/"))
 	req := httputils.NewJsonRequest(method, url, params)
 	header := http.Header{}
-	if len(cli.csrfToken) > 0 && len(cli.csrfToken) > 0 && res != AUTH_ADDR {
+	if len(cli.authTicket) > 0 && len(cli.csrfToken) > 0 && res != AUTH_ADDR {
 		header.Set("Cookie", "PVEAuthCookie="+cli.authTicket)
 		header.Set("CSRFPreventionToken", cli.csrfToken)
 	}
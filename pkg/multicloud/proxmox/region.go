@@ -78,15 +78,15 @@ func (self *SRegion) Refresh() error {
 }
 
 func (self *SRegion) GetISecurityGroupById(secgroupId string) (cloudprovider.ICloudSecurityGroup, error) {
-	return nil, cloudprovider.ErrNotSupported
+	return self.GetSecurityGroup(secgroupId)
 }
 
 func (self *SRegion) GetISecurityGroupByName(opts *cloudprovider.SecurityGroupFilterOptions) (cloudprovider.ICloudSecurityGroup, error) {
-	return nil, cloudprovider.ErrNotSupported
+	return self.GetSecurityGroup(toGroupName(opts.Name))
 }
 
 func (self *SRegion) CreateISecurityGroup(conf *cloudprovider.SecurityGroupCreateInput) (cloudprovider.ICloudSecurityGroup, error) {
-	return nil, cloudprovider.ErrNotSupported
+	return self.CreateSecurityGroup(conf)
 }
 
 func (self *SRegion) CreateIVpc(conf *cloudprovider.VpcCreateOptions) (cloudprovider.ICloudVpc, error) {
@@ -195,6 +195,10 @@ func (self *SRegion) post(res string, params interface{}) (jsonutils.JSONObject,
 	return self.client.post(res, params)
 }
 
+func (self *SRegion) postData(res string, params interface{}, retVal interface{}) error {
+	return self.client.postData(res, params, retVal)
+}
+
 func (self *SRegion) put(res string, params url.Values, body jsonutils.JSONObject, retVal interface{}) error {
 	return self.client.put(res, params, body, retVal)
 }
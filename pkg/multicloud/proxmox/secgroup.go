@@ -0,0 +1,343 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxmox
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/secrules"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SFirewallRule struct {
+	Pos     int    `json:"pos"`
+	Type    string `json:"type"`
+	Action  string `json:"action"`
+	Enable  int    `json:"enable"`
+	Proto   string `json:"proto"`
+	Dport   string `json:"dport"`
+	Source  string `json:"source"`
+	Dest    string `json:"dest"`
+	Macro   string `json:"macro"`
+	Comment string `json:"comment"`
+}
+
+// SSecurityGroup maps a cluster firewall security group
+type SSecurityGroup struct {
+	multicloud.SSecurityGroup
+	ProxmoxTags
+
+	region *SRegion
+
+	Group   string `json:"group"`
+	Comment string `json:"comment"`
+	Digest  string `json:"digest"`
+}
+
+func (self *SSecurityGroup) GetId() string {
+	return self.Group
+}
+
+func (self *SSecurityGroup) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SSecurityGroup) GetName() string {
+	return self.Group
+}
+
+func (self *SSecurityGroup) GetDescription() string {
+	return self.Comment
+}
+
+func (self *SSecurityGroup) GetVpcId() string {
+	return self.region.getVpc().GetGlobalId()
+}
+
+func (self *SSecurityGroup) GetStatus() string {
+	return api.SECGROUP_STATUS_READY
+}
+
+func (self *SSecurityGroup) Refresh() error {
+	group, err := self.region.GetSecurityGroup(self.Group)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, group)
+}
+
+func parseFirewallPorts(dport string) (int, int, []int, error) {
+	if len(dport) == 0 {
+		return 0, 0, nil, nil
+	}
+	if strings.Contains(dport, ",") {
+		ports := []int{}
+		for _, p := range strings.Split(dport, ",") {
+			port, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil {
+				return 0, 0, nil, errors.Wrapf(err, "invalid port %s", p)
+			}
+			ports = append(ports, port)
+		}
+		return 0, 0, ports, nil
+	}
+	start, end := dport, dport
+	if idx := strings.Index(dport, ":"); idx > 0 {
+		start, end = dport[:idx], dport[idx+1:]
+	}
+	portStart, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, nil, errors.Wrapf(err, "invalid port %s", start)
+	}
+	portEnd, err := strconv.Atoi(end)
+	if err != nil {
+		return 0, 0, nil, errors.Wrapf(err, "invalid port %s", end)
+	}
+	return portStart, portEnd, nil, nil
+}
+
+func (rule *SFirewallRule) toRule(count int) (cloudprovider.SecurityRule, error) {
+	r := cloudprovider.SecurityRule{
+		ExternalId: strconv.Itoa(rule.Pos),
+		Name:       rule.Comment,
+		SecurityRule: secrules.SecurityRule{
+			Direction:   secrules.DIR_IN,
+			Action:      secrules.SecurityRuleAllow,
+			Protocol:    secrules.PROTO_ANY,
+			Description: rule.Comment,
+		},
+	}
+	if len(rule.Macro) > 0 {
+		return r, errors.Wrapf(cloudprovider.ErrNotSupported, "macro %s", rule.Macro)
+	}
+	// rules are evaluated from top to bottom, the first rule has the highest priority
+	r.Priority = count - rule.Pos
+	if r.Priority > 100 {
+		r.Priority = 100
+	}
+	if r.Priority < 1 {
+		r.Priority = 1
+	}
+	if strings.ToUpper(rule.Action) != "ACCEPT" {
+		r.Action = secrules.SecurityRuleDeny
+	}
+	cidr := rule.Source
+	if rule.Type == "out" {
+		r.Direction = secrules.DIR_OUT
+		cidr = rule.Dest
+	}
+	if len(cidr) == 0 {
+		cidr = "0.0.0.0/0"
+	}
+	r.ParseCIDR(cidr)
+	if len(rule.Proto) > 0 {
+		r.Protocol = strings.ToLower(rule.Proto)
+	}
+	var err error
+	r.PortStart, r.PortEnd, r.Ports, err = parseFirewallPorts(rule.Dport)
+	if err != nil {
+		return r, err
+	}
+	err = r.ValidateRule()
+	if err != nil {
+		return r, errors.Wrap(err, "invalid rule")
+	}
+	return r, nil
+}
+
+func (self *SSecurityGroup) GetRules() ([]cloudprovider.SecurityRule, error) {
+	rules, err := self.region.GetSecurityGroupRules(self.Group)
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.SecurityRule{}
+	for i := range rules {
+		if rules[i].Type != "in" && rules[i].Type != "out" {
+			continue
+		}
+		rule, err := rules[i].toRule(len(rules))
+		if err != nil {
+			log.Warningf("skip security group %s rule %d: %v", self.Group, rules[i].Pos, err)
+			continue
+		}
+		ret = append(ret, rule)
+	}
+	return ret, nil
+}
+
+func (self *SSecurityGroup) SyncRules(common, inAdds, outAdds, inDels, outDels []cloudprovider.SecurityRule) error {
+	positions := []int{}
+	for _, r := range append(inDels, outDels...) {
+		pos, err := strconv.Atoi(r.ExternalId)
+		if err != nil {
+			return errors.Wrapf(err, "invalid rule pos %s", r.ExternalId)
+		}
+		positions = append(positions, pos)
+	}
+	// delete from the bottom, so the positions of the remaining rules are not shifted
+	sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+	for _, pos := range positions {
+		err := self.region.DeleteSecurityGroupRule(self.Group, pos)
+		if err != nil {
+			return errors.Wrapf(err, "DeleteSecurityGroupRule(%d)", pos)
+		}
+	}
+	return self.region.AddSecurityGroupRules(self.Group, append(inAdds, outAdds...))
+}
+
+func (self *SSecurityGroup) Delete() error {
+	res := fmt.Sprintf("/cluster/firewall/groups/%s", url.PathEscape(self.Group))
+	return self.region.del(res, nil, nil)
+}
+
+func (self *SRegion) GetSecurityGroups() ([]SSecurityGroup, error) {
+	ret := []SSecurityGroup{}
+	err := self.get("/cluster/firewall/groups", url.Values{}, &ret)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ret {
+		ret[i].region = self
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetSecurityGroup(id string) (*SSecurityGroup, error) {
+	groups, err := self.GetSecurityGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].Group == id {
+			return &groups[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, id)
+}
+
+func (self *SRegion) GetSecurityGroupRules(group string) ([]SFirewallRule, error) {
+	ret := []SFirewallRule{}
+	res := fmt.Sprintf("/cluster/firewall/groups/%s", url.PathEscape(group))
+	err := self.get(res, url.Values{}, &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (self *SRegion) DeleteSecurityGroupRule(group string, pos int) error {
+	res := fmt.Sprintf("/cluster/firewall/groups/%s/%d", url.PathEscape(group), pos)
+	return self.del(res, nil, nil)
+}
+
+// AddSecurityGroupRules inserts the rules in ascending priority,
+// new rules are placed at the top of the group, so the highest priority ends up first
+func (self *SRegion) AddSecurityGroupRules(group string, rules []cloudprovider.SecurityRule) error {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority < rules[j].Priority
+	})
+	res := fmt.Sprintf("/cluster/firewall/groups/%s", url.PathEscape(group))
+	for _, rule := range rules {
+		body := map[string]interface{}{
+			"type":   "in",
+			"action": "ACCEPT",
+			"enable": 1,
+		}
+		if rule.Action == secrules.SecurityRuleDeny {
+			body["action"] = "DROP"
+		}
+		cidrKey := "source"
+		if rule.Direction == secrules.DIR_OUT {
+			body["type"] = "out"
+			cidrKey = "dest"
+		}
+		if rule.IPNet != nil && rule.IPNet.String() != "0.0.0.0/0" {
+			body[cidrKey] = rule.IPNet.String()
+		}
+		if rule.Protocol != secrules.PROTO_ANY {
+			body["proto"] = rule.Protocol
+		}
+		if rule.Protocol == secrules.PROTO_TCP || rule.Protocol == secrules.PROTO_UDP {
+			if len(rule.Ports) > 0 {
+				ports := []string{}
+				for _, port := range rule.Ports {
+					ports = append(ports, strconv.Itoa(port))
+				}
+				body["dport"] = strings.Join(ports, ",")
+			} else if rule.PortStart > 0 && rule.PortEnd > 0 {
+				body["dport"] = fmt.Sprintf("%d:%d", rule.PortStart, rule.PortEnd)
+				if rule.PortStart == rule.PortEnd {
+					body["dport"] = strconv.Itoa(rule.PortStart)
+				}
+			}
+		}
+		if len(rule.Description) > 0 {
+			body["comment"] = rule.Description
+		}
+		_, err := self.post(res, jsonutils.Marshal(body))
+		if err != nil {
+			return errors.Wrapf(err, "add rule %s", rule.String())
+		}
+	}
+	return nil
+}
+
+var secgroupNameReg = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// group names must start with a letter and only contain letters, digits, '-' and '_', max 18 characters
+func toGroupName(name string) string {
+	name = secgroupNameReg.ReplaceAllString(name, "-")
+	if len(name) == 0 || !((name[0] >= 'a' && name[0] <= 'z') || (name[0] >= 'A' && name[0] <= 'Z')) {
+		name = "sg" + name
+	}
+	if len(name) > 18 {
+		name = name[:18]
+	}
+	return name
+}
+
+func (self *SRegion) CreateSecurityGroup(opts *cloudprovider.SecurityGroupCreateInput) (*SSecurityGroup, error) {
+	group := toGroupName(opts.Name)
+	body := map[string]interface{}{
+		"group": group,
+	}
+	if len(opts.Desc) > 0 {
+		body["comment"] = opts.Desc
+	}
+	_, err := self.post("/cluster/firewall/groups", jsonutils.Marshal(body))
+	if err != nil {
+		return nil, errors.Wrapf(err, "create security group %s", group)
+	}
+	rules := []cloudprovider.SecurityRule{}
+	for i := range opts.Rules {
+		rules = append(rules, cloudprovider.SecurityRule{SecurityRule: opts.Rules[i]})
+	}
+	err = self.AddSecurityGroupRules(group, rules)
+	if err != nil {
+		return nil, err
+	}
+	return self.GetSecurityGroup(group)
+}
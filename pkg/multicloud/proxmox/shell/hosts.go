@@ -46,4 +46,17 @@ func init() {
 		return nil
 	})
 
+	type HostNicListOptions struct {
+		NODE string
+	}
+
+	shellutils.R(&HostNicListOptions{}, "host-nic-list", "list host physical nics", func(cli *proxmox.SRegion, args *HostNicListOptions) error {
+		nics, err := cli.GetHostNics(args.NODE)
+		if err != nil {
+			return err
+		}
+		printList(nics, 0, 0, 0, []string{})
+		return nil
+	})
+
 }
@@ -15,10 +15,12 @@
 package shell
 
 import (
+	"fmt"
 	"strconv"
 
 	"yunion.io/x/pkg/util/shellutils"
 
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
 	"yunion.io/x/cloudmux/pkg/multicloud/proxmox"
 )
 
@@ -95,6 +97,98 @@ func init() {
 		return cli.ChangeConfig(id, args.Cpu, args.MemMb)
 	})
 
+	shellutils.R(&InstanceIdOptions{}, "instance-vnc", "Show instance vnc info", func(cli *proxmox.SRegion, args *InstanceIdOptions) error {
+		vm, err := cli.GetInstance(args.ID)
+		if err != nil {
+			return err
+		}
+		ret, err := cli.GetVNCInfo(vm.Node, vm.VmID, vm.Name)
+		if err != nil {
+			return err
+		}
+		printObject(ret)
+		return nil
+	})
+
+	type InstanceCreateDiskOptions struct {
+		ID         string
+		STORAGE_ID string
+		SIZE_GB    int
+		Driver     string `choices:"scsi|virtio|sata|ide" default:"scsi"`
+	}
+
+	shellutils.R(&InstanceCreateDiskOptions{}, "instance-create-disk", "Create and attach a new disk to instance", func(cli *proxmox.SRegion, args *InstanceCreateDiskOptions) error {
+		id, _ := strconv.Atoi(args.ID)
+		diskId, err := cli.CreateDisk(id, args.STORAGE_ID, args.Driver, args.SIZE_GB)
+		if err != nil {
+			return err
+		}
+		fmt.Println(diskId)
+		return nil
+	})
+
+	type InstanceUserDataOptions struct {
+		ID        string
+		USER_DATA string
+	}
+
+	shellutils.R(&InstanceUserDataOptions{}, "instance-update-userdata", "Update instance cloud-init user data", func(cli *proxmox.SRegion, args *InstanceUserDataOptions) error {
+		id, _ := strconv.Atoi(args.ID)
+		return cli.UpdateUserData(id, args.USER_DATA)
+	})
+
+	type InstanceRebuildRootOptions struct {
+		ID        string
+		IMAGE_ID  string
+		Account   string
+		Password  string
+		PublicKey string
+		SysSizeGb int
+	}
+
+	shellutils.R(&InstanceRebuildRootOptions{}, "instance-rebuild-root", "Rebuild instance system disk", func(cli *proxmox.SRegion, args *InstanceRebuildRootOptions) error {
+		id, _ := strconv.Atoi(args.ID)
+		diskId, err := cli.RebuildRoot(id, &cloudprovider.SManagedVMRebuildRootConfig{
+			ImageId:   args.IMAGE_ID,
+			Account:   args.Account,
+			Password:  args.Password,
+			PublicKey: args.PublicKey,
+			SysSizeGB: args.SysSizeGb,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(diskId)
+		return nil
+	})
+
+	type InstanceMigrateOptions struct {
+		ID      string
+		HOST_ID string
+		Live    bool
+	}
+
+	shellutils.R(&InstanceMigrateOptions{}, "instance-migrate", "Migrate instance to host", func(cli *proxmox.SRegion, args *InstanceMigrateOptions) error {
+		vm, err := cli.GetInstance(args.ID)
+		if err != nil {
+			return err
+		}
+		return cli.MigrateVM(vm.Node, vm.VmID, args.HOST_ID, args.Live)
+	})
+
+	type InstanceSetSecgroupsOptions struct {
+		ID          string
+		SECGROUP_ID []string
+	}
+
+	shellutils.R(&InstanceSetSecgroupsOptions{}, "instance-set-secgroups", "Set instance security groups", func(cli *proxmox.SRegion, args *InstanceSetSecgroupsOptions) error {
+		vm, err := cli.GetInstance(args.ID)
+		if err != nil {
+			return err
+		}
+		return cli.SetSecurityGroups(vm.Node, vm.VmID, args.SECGROUP_ID)
+	})
+
 	type InstanceCreateOptions struct {
 		Name  string
 		Node  string
@@ -0,0 +1,77 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud/proxmox"
+)
+
+func init() {
+	type SecgroupListOptions struct {
+	}
+	shellutils.R(&SecgroupListOptions{}, "secgroup-list", "list security groups", func(cli *proxmox.SRegion, args *SecgroupListOptions) error {
+		groups, err := cli.GetSecurityGroups()
+		if err != nil {
+			return err
+		}
+		printList(groups, 0, 0, 0, []string{})
+		return nil
+	})
+
+	type SecgroupIdOptions struct {
+		ID string
+	}
+
+	shellutils.R(&SecgroupIdOptions{}, "secgroup-rule-list", "list security group rules", func(cli *proxmox.SRegion, args *SecgroupIdOptions) error {
+		group, err := cli.GetSecurityGroup(args.ID)
+		if err != nil {
+			return err
+		}
+		rules, err := group.GetRules()
+		if err != nil {
+			return err
+		}
+		printList(rules, 0, 0, 0, []string{})
+		return nil
+	})
+
+	shellutils.R(&SecgroupIdOptions{}, "secgroup-delete", "delete security group", func(cli *proxmox.SRegion, args *SecgroupIdOptions) error {
+		group, err := cli.GetSecurityGroup(args.ID)
+		if err != nil {
+			return err
+		}
+		return group.Delete()
+	})
+
+	type SecgroupCreateOptions struct {
+		NAME string
+		Desc string
+	}
+
+	shellutils.R(&SecgroupCreateOptions{}, "secgroup-create", "create security group", func(cli *proxmox.SRegion, args *SecgroupCreateOptions) error {
+		group, err := cli.CreateSecurityGroup(&cloudprovider.SecurityGroupCreateInput{
+			Name: args.NAME,
+			Desc: args.Desc,
+		})
+		if err != nil {
+			return err
+		}
+		printObject(group)
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/multicloud/proxmox"
+)
+
+func init() {
+	type InstanceSnapshotListOptions struct {
+		VM_ID string
+	}
+	shellutils.R(&InstanceSnapshotListOptions{}, "instance-snapshot-list", "list instance snapshots", func(cli *proxmox.SRegion, args *InstanceSnapshotListOptions) error {
+		vm, err := cli.GetInstance(args.VM_ID)
+		if err != nil {
+			return err
+		}
+		snapshots, err := cli.GetInstanceSnapshots(vm.Node, vm.VmID)
+		if err != nil {
+			return err
+		}
+		printList(snapshots, 0, 0, 0, []string{})
+		return nil
+	})
+
+	type InstanceSnapshotCreateOptions struct {
+		VM_ID string
+		NAME  string
+		Desc  string
+	}
+	shellutils.R(&InstanceSnapshotCreateOptions{}, "instance-snapshot-create", "create instance snapshot", func(cli *proxmox.SRegion, args *InstanceSnapshotCreateOptions) error {
+		vm, err := cli.GetInstance(args.VM_ID)
+		if err != nil {
+			return err
+		}
+		snapshot, err := cli.CreateInstanceSnapshot(vm.Node, vm.VmID, args.NAME, args.Desc)
+		if err != nil {
+			return err
+		}
+		printObject(snapshot)
+		return nil
+	})
+
+	type InstanceSnapshotOptions struct {
+		VM_ID string
+		NAME  string
+	}
+	shellutils.R(&InstanceSnapshotOptions{}, "instance-snapshot-delete", "delete instance snapshot", func(cli *proxmox.SRegion, args *InstanceSnapshotOptions) error {
+		vm, err := cli.GetInstance(args.VM_ID)
+		if err != nil {
+			return err
+		}
+		return cli.DeleteInstanceSnapshot(vm.Node, vm.VmID, args.NAME)
+	})
+
+	shellutils.R(&InstanceSnapshotOptions{}, "instance-snapshot-reset", "reset instance to snapshot", func(cli *proxmox.SRegion, args *InstanceSnapshotOptions) error {
+		vm, err := cli.GetInstance(args.VM_ID)
+		if err != nil {
+			return err
+		}
+		return cli.ResetToInstanceSnapshot(vm.Node, vm.VmID, args.NAME)
+	})
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxmox
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// the pseudo snapshot which stands for the running state of the vm
+const CURRENT_SNAPSHOT = "current"
+
+type SInstanceSnapshot struct {
+	multicloud.SResourceBase
+	ProxmoxTags
+
+	region *SRegion
+	Node   string
+	VmId   int
+
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Parent      string `json:"parent"`
+	Snaptime    int64  `json:"snaptime"`
+	Vmstate     int    `json:"vmstate"`
+}
+
+func (self *SInstanceSnapshot) GetId() string {
+	return self.Name
+}
+
+func (self *SInstanceSnapshot) GetName() string {
+	return self.Name
+}
+
+func (self *SInstanceSnapshot) GetGlobalId() string {
+	return self.GetId()
+}
+
+func (self *SInstanceSnapshot) GetDescription() string {
+	return self.Description
+}
+
+func (self *SInstanceSnapshot) GetStatus() string {
+	return api.INSTANCE_SNAPSHOT_READY
+}
+
+func (self *SInstanceSnapshot) GetCreatedAt() time.Time {
+	return time.Unix(self.Snaptime, 0)
+}
+
+func (self *SInstanceSnapshot) Refresh() error {
+	snapshot, err := self.region.GetInstanceSnapshot(self.Node, self.VmId, self.Name)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, snapshot)
+}
+
+func (self *SInstanceSnapshot) GetProjectId() string {
+	return ""
+}
+
+func (self *SInstanceSnapshot) Delete() error {
+	return self.region.DeleteInstanceSnapshot(self.Node, self.VmId, self.Name)
+}
+
+func (self *SRegion) GetInstanceSnapshots(node string, vmId int) ([]SInstanceSnapshot, error) {
+	snapshots := []SInstanceSnapshot{}
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/snapshot", node, vmId)
+	err := self.get(res, url.Values{}, &snapshots)
+	if err != nil {
+		return nil, err
+	}
+	ret := []SInstanceSnapshot{}
+	for i := range snapshots {
+		if snapshots[i].Name == CURRENT_SNAPSHOT {
+			continue
+		}
+		snapshots[i].region = self
+		snapshots[i].Node = node
+		snapshots[i].VmId = vmId
+		ret = append(ret, snapshots[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetInstanceSnapshot(node string, vmId int, name string) (*SInstanceSnapshot, error) {
+	snapshots, err := self.GetInstanceSnapshots(node, vmId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		if snapshots[i].Name == name {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, "snapshot %s of vm %d", name, vmId)
+}
+
+var snapshotNameReg = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// snapshot names must start with a letter and only contain letters, digits, '-' and '_', max 40 characters
+func toSnapshotName(name string) string {
+	name = snapshotNameReg.ReplaceAllString(name, "-")
+	if len(name) == 0 || !((name[0] >= 'a' && name[0] <= 'z') || (name[0] >= 'A' && name[0] <= 'Z')) || name == CURRENT_SNAPSHOT {
+		name = "snap" + name
+	}
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	return name
+}
+
+func (self *SRegion) CreateInstanceSnapshot(node string, vmId int, name, desc string) (*SInstanceSnapshot, error) {
+	name = toSnapshotName(name)
+	body := map[string]interface{}{
+		"snapname": name,
+	}
+	if len(desc) > 0 {
+		body["description"] = desc
+	}
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/snapshot", node, vmId)
+	_, err := self.post(res, jsonutils.Marshal(body))
+	if err != nil {
+		return nil, errors.Wrapf(err, "create snapshot %s of vm %d", name, vmId)
+	}
+	return self.GetInstanceSnapshot(node, vmId, name)
+}
+
+func (self *SRegion) DeleteInstanceSnapshot(node string, vmId int, name string) error {
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/snapshot/%s", node, vmId, url.PathEscape(name))
+	return self.del(res, nil, nil)
+}
+
+func (self *SRegion) ResetToInstanceSnapshot(node string, vmId int, name string) error {
+	res := fmt.Sprintf("/nodes/%s/qemu/%d/snapshot/%s/rollback", node, vmId, url.PathEscape(name))
+	_, err := self.post(res, url.Values{})
+	return err
+}
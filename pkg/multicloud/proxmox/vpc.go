@@ -60,7 +60,15 @@ func (self *SVpc) GetIRouteTableById(routeTableId string) (cloudprovider.ICloudR
 }
 
 func (self *SVpc) GetISecurityGroups() ([]cloudprovider.ICloudSecurityGroup, error) {
-	return []cloudprovider.ICloudSecurityGroup{}, nil
+	groups, err := self.region.GetSecurityGroups()
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudSecurityGroup{}
+	for i := range groups {
+		ret = append(ret, &groups[i])
+	}
+	return ret, nil
 }
 
 func (self *SVpc) GetIWires() ([]cloudprovider.ICloudWire, error) {
@@ -132,10 +132,12 @@ func (self *SRegion) getCosUrl(bucket, object string) string {
 }
 
 func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(progress float32)) (string, error) {
-	reader, sizeBytes, err := image.GetReader(image.ImageId, string(qemuimgfmt.QCOW2))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.QCOW2, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	sizeBytes, callback := reader.Size, reader.Callback
 
 	bucketName := strings.Replace(strings.ToLower(self.region.GetId()+image.ImageId), "-", "", -1)
 	if len(bucketName) > 40 {
@@ -168,6 +170,15 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 
 	defer bucket.DeleteObject(context.Background(), image.ImageId)
 
+	return self.importImage(bucketName, image.ImageId, image, callback)
+}
+
+// ImportImageFromBucket 从cos存储桶中的镜像文件导入镜像, 存储桶需允许公共读, 不删除镜像文件
+func (self *SStoragecache) ImportImageFromBucket(ctx context.Context, bucketName, key string, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
+	return self.importImage(bucketName, key, image, callback)
+}
+
+func (self *SStoragecache) importImage(bucketName, key string, image *cloudprovider.SImageCreateOption, callback func(float32)) (string, error) {
 	// 腾讯云镜像名称需要小于20个字符
 	imageBaseName := image.ImageId[:10]
 	if imageBaseName[0] >= '0' && imageBaseName[0] <= '9' {
@@ -178,7 +189,7 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 
 	// check image name, avoid name conflict
 	for {
-		_, err = self.region.GetImageByName(imageName)
+		_, err := self.region.GetImageByName(imageName)
 		if err != nil {
 			if errors.Cause(err) == cloudprovider.ErrNotFound {
 				break
@@ -191,7 +202,7 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 	}
 
 	log.Debugf("Import image %s", imageName)
-	img, err := self.region.ImportImage(imageName, image.OsArch, image.OsDistribution, image.OsVersion, self.region.getCosUrl(bucketName, image.ImageId))
+	img, err := self.region.ImportImage(imageName, image.OsArch, image.OsDistribution, image.OsVersion, self.region.getCosUrl(bucketName, key))
 	if err != nil {
 		return "", err
 	}
@@ -52,6 +52,11 @@ var UCLOUD_API_RESULT_KEYS = map[string]string{
 	"CreateVPC":              "VPCId",
 	"CreateUDiskSnapshot":    "SnapshotId",
 	"DescribeVIP":            "VIPSet",
+	"CreateULB":              "ULBId",
+	"CreateVServer":          "VServerId",
+	"AllocateBackend":        "BackendId",
+	"CreatePolicy":           "PolicyId",
+	"CreateSSL":              "SSLId",
 }
 
 type SParams struct {
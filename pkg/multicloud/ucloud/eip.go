@@ -261,10 +261,14 @@ func (self *SRegion) DeallocateEIP(eipId string) error {
 
 // https://docs.ucloud.cn/api/unet-api/bind_eip
 func (self *SRegion) AssociateEip(eipId string, instanceId string) error {
+	return self.BindEip(eipId, "uhost", instanceId)
+}
+
+func (self *SRegion) BindEip(eipId string, resourceType, resourceId string) error {
 	params := NewUcloudParams()
 	params.Set("EIPId", eipId)
-	params.Set("ResourceType", "uhost")
-	params.Set("ResourceId", instanceId)
+	params.Set("ResourceType", resourceType)
+	params.Set("ResourceId", resourceId)
 
 	return self.DoAction("BindEIP", params, nil)
 }
@@ -0,0 +1,328 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"context"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SULBIP struct {
+	OperatorName  string
+	EIP           string
+	EIPId         string
+	BandwidthType int
+	Bandwidth     int
+}
+
+type SLoadbalancer struct {
+	multicloud.SLoadbalancerBase
+	UcloudTags
+	region *SRegion
+
+	ULBId         string
+	Name          string
+	Tag           string
+	Remark        string
+	BandwidthType int
+	Bandwidth     int
+	CreateTime    int64
+	ExpireTime    int64
+	IPSet         []SULBIP
+	VServerSet    []SLBListener
+	// OuterMode: 外网; InnerMode: 内网
+	ULBType    string
+	ListenType string
+	VPCId      string
+	SubnetId   string
+	PrivateIP  string
+}
+
+func (self *SLoadbalancer) GetId() string {
+	return self.ULBId
+}
+
+func (self *SLoadbalancer) GetName() string {
+	return self.Name
+}
+
+func (self *SLoadbalancer) GetGlobalId() string {
+	return self.ULBId
+}
+
+func (self *SLoadbalancer) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLoadbalancer) Refresh() error {
+	lb, err := self.region.GetLoadbalancer(self.ULBId)
+	if err != nil {
+		return err
+	}
+	self.VServerSet = nil
+	self.IPSet = nil
+	return jsonutils.Update(self, lb)
+}
+
+func (self *SLoadbalancer) GetProjectId() string {
+	return self.region.client.projectId
+}
+
+func (self *SLoadbalancer) GetCreatedAt() time.Time {
+	return time.Unix(self.CreateTime, 0)
+}
+
+func (self *SLoadbalancer) GetAddress() string {
+	if self.ULBType == "OuterMode" {
+		for _, ip := range self.IPSet {
+			if len(ip.EIP) > 0 {
+				return ip.EIP
+			}
+		}
+	}
+	return self.PrivateIP
+}
+
+func (self *SLoadbalancer) GetAddressType() string {
+	if self.ULBType == "OuterMode" {
+		return api.LB_ADDR_TYPE_INTERNET
+	}
+	return api.LB_ADDR_TYPE_INTRANET
+}
+
+func (self *SLoadbalancer) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (self *SLoadbalancer) GetNetworkIds() []string {
+	if len(self.SubnetId) > 0 {
+		return []string{self.SubnetId}
+	}
+	return []string{}
+}
+
+func (self *SLoadbalancer) GetVpcId() string {
+	return self.VPCId
+}
+
+func (self *SLoadbalancer) GetZoneId() string {
+	return ""
+}
+
+func (self *SLoadbalancer) GetZone1Id() string {
+	return ""
+}
+
+func (self *SLoadbalancer) GetLoadbalancerSpec() string {
+	return self.ListenType
+}
+
+func (self *SLoadbalancer) GetChargeType() string {
+	return api.LB_CHARGE_TYPE_BY_BANDWIDTH
+}
+
+func (self *SLoadbalancer) GetEgressMbps() int {
+	return self.Bandwidth
+}
+
+func (self *SLoadbalancer) GetIEIP() (cloudprovider.ICloudEIP, error) {
+	for _, ip := range self.IPSet {
+		if len(ip.EIPId) > 0 {
+			eip, err := self.region.GetEipById(ip.EIPId)
+			if err != nil {
+				return nil, errors.Wrapf(err, "GetEipById(%s)", ip.EIPId)
+			}
+			return &eip, nil
+		}
+	}
+	return nil, nil
+}
+
+func (self *SLoadbalancer) Delete(ctx context.Context) error {
+	return self.region.DeleteLoadbalancer(self.ULBId)
+}
+
+func (self *SLoadbalancer) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancer) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListeners() ([]cloudprovider.ICloudLoadbalancerListener, error) {
+	ret := []cloudprovider.ICloudLoadbalancerListener{}
+	for i := range self.VServerSet {
+		self.VServerSet[i].lb = self
+		ret = append(ret, &self.VServerSet[i])
+	}
+	return ret, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerListenerById(listenerId string) (cloudprovider.ICloudLoadbalancerListener, error) {
+	for i := range self.VServerSet {
+		if self.VServerSet[i].VServerId == listenerId {
+			self.VServerSet[i].lb = self
+			return &self.VServerSet[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, listenerId)
+}
+
+// ULB 无独立的后端服务器组, 后端服务器挂载在 VServer 上
+func (self *SLoadbalancer) GetILoadBalancerBackendGroups() ([]cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	ret := []cloudprovider.ICloudLoadbalancerBackendGroup{}
+	for i := range self.VServerSet {
+		self.VServerSet[i].lb = self
+		ret = append(ret, self.VServerSet[i].GetBackendGroup())
+	}
+	return ret, nil
+}
+
+func (self *SLoadbalancer) GetILoadBalancerBackendGroupById(groupId string) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	for i := range self.VServerSet {
+		if self.VServerSet[i].VServerId == groupId {
+			self.VServerSet[i].lb = self
+			return self.VServerSet[i].GetBackendGroup(), nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, groupId)
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerBackendGroup(group *cloudprovider.SLoadbalancerBackendGroup) (cloudprovider.ICloudLoadbalancerBackendGroup, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancer) CreateILoadBalancerListener(ctx context.Context, opts *cloudprovider.SLoadbalancerListenerCreateOptions) (cloudprovider.ICloudLoadbalancerListener, error) {
+	listenerId, err := self.region.CreateLoadbalancerListener(self.ULBId, self.ListenType, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.CertificateId) > 0 {
+		err = self.region.BindLoadbalancerCertificate(self.ULBId, listenerId, opts.CertificateId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "BindLoadbalancerCertificate")
+		}
+	}
+	err = self.Refresh()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Refresh")
+	}
+	return self.GetILoadBalancerListenerById(listenerId)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/describe_ulb
+func (self *SRegion) GetLoadbalancers(ulbId string) ([]SLoadbalancer, error) {
+	params := NewUcloudParams()
+	if len(ulbId) > 0 {
+		params.Set("ULBId", ulbId)
+	}
+	lbs := make([]SLoadbalancer, 0)
+	err := self.DoListAll("DescribeULB", params, &lbs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "DescribeULB")
+	}
+	for i := range lbs {
+		lbs[i].region = self
+	}
+	return lbs, nil
+}
+
+func (self *SRegion) GetLoadbalancer(ulbId string) (*SLoadbalancer, error) {
+	lbs, err := self.GetLoadbalancers(ulbId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lbs {
+		if lbs[i].ULBId == ulbId {
+			return &lbs[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, ulbId)
+}
+
+func (self *SRegion) GetILoadBalancers() ([]cloudprovider.ICloudLoadbalancer, error) {
+	lbs, err := self.GetLoadbalancers("")
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudLoadbalancer{}
+	for i := range lbs {
+		ret = append(ret, &lbs[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetILoadBalancerById(id string) (cloudprovider.ICloudLoadbalancer, error) {
+	return self.GetLoadbalancer(id)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/create_ulb
+func (self *SRegion) CreateILoadBalancer(opts *cloudprovider.SLoadbalancerCreateOptions) (cloudprovider.ICloudLoadbalancer, error) {
+	params := NewUcloudParams()
+	params.Set("ULBName", opts.Name)
+	params.Set("Remark", opts.Desc)
+	params.Set("VPCId", opts.VpcId)
+	if len(opts.NetworkIds) > 0 {
+		params.Set("SubnetId", opts.NetworkIds[0])
+	}
+	if opts.AddressType == api.LB_ADDR_TYPE_INTERNET {
+		params.Set("OuterMode", "Yes")
+	} else {
+		params.Set("InnerMode", "Yes")
+	}
+	params.Set("ChargeType", "Dynamic")
+	if opts.BillingCycle != nil {
+		params.Set("ChargeType", "Month")
+	}
+	ulbId := ""
+	err := self.DoAction("CreateULB", params, &ulbId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateULB")
+	}
+	if len(opts.EipId) > 0 {
+		err = self.BindEip(opts.EipId, "ulb", ulbId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "BindEip")
+		}
+	}
+	return self.GetLoadbalancer(ulbId)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/delete_ulb
+func (self *SRegion) DeleteLoadbalancer(ulbId string) error {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	return self.DoAction("DeleteULB", params, nil)
+}
+
+func (self *SRegion) GetILoadBalancerAcls() ([]cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) GetILoadBalancerAclById(aclId string) (cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) CreateILoadBalancerAcl(acl *cloudprovider.SLoadbalancerAccessControlList) (cloudprovider.ICloudLoadbalancerAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
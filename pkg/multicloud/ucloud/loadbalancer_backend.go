@@ -0,0 +1,230 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"context"
+
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// ULB 的后端服务器直接挂载在 VServer 上, 每个 VServer 视为一个后端服务器组
+type SLBBackendGroup struct {
+	multicloud.SResourceBase
+	UcloudTags
+	listener *SLBListener
+}
+
+func (self *SLBBackendGroup) GetId() string {
+	return self.listener.VServerId
+}
+
+func (self *SLBBackendGroup) GetName() string {
+	return self.listener.VServerName
+}
+
+func (self *SLBBackendGroup) GetGlobalId() string {
+	return self.listener.VServerId
+}
+
+func (self *SLBBackendGroup) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLBBackendGroup) IsDefault() bool {
+	return false
+}
+
+func (self *SLBBackendGroup) GetType() string {
+	return api.LB_BACKENDGROUP_TYPE_NORMAL
+}
+
+func (self *SLBBackendGroup) GetILoadbalancerBackends() ([]cloudprovider.ICloudLoadbalancerBackend, error) {
+	ret := []cloudprovider.ICloudLoadbalancerBackend{}
+	for i := range self.listener.BackendSet {
+		self.listener.BackendSet[i].group = self
+		ret = append(ret, &self.listener.BackendSet[i])
+	}
+	return ret, nil
+}
+
+func (self *SLBBackendGroup) GetILoadbalancerBackendById(backendId string) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	for i := range self.listener.BackendSet {
+		if self.listener.BackendSet[i].BackendId == backendId {
+			self.listener.BackendSet[i].group = self
+			return &self.listener.BackendSet[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, backendId)
+}
+
+func (self *SLBBackendGroup) AddBackendServer(serverId string, weight int, port int) (cloudprovider.ICloudLoadbalancerBackend, error) {
+	lb := self.listener.lb
+	backendId, err := lb.region.AllocateLoadbalancerBackend(lb.ULBId, self.listener.VServerId, serverId, weight, port)
+	if err != nil {
+		return nil, err
+	}
+	err = self.listener.Refresh()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Refresh")
+	}
+	return self.GetILoadbalancerBackendById(backendId)
+}
+
+func (self *SLBBackendGroup) RemoveBackendServer(serverId string, weight int, port int) error {
+	lb := self.listener.lb
+	for _, backend := range self.listener.BackendSet {
+		if backend.ResourceId == serverId && backend.Port == port {
+			return lb.region.ReleaseLoadbalancerBackend(lb.ULBId, backend.BackendId)
+		}
+	}
+	return nil
+}
+
+func (self *SLBBackendGroup) Delete(ctx context.Context) error {
+	return nil
+}
+
+func (self *SLBBackendGroup) Sync(ctx context.Context, group *cloudprovider.SLoadbalancerBackendGroup) error {
+	return nil
+}
+
+type SLBBackend struct {
+	multicloud.SResourceBase
+	UcloudTags
+	group *SLBBackendGroup
+
+	BackendId string
+	// UHost, UNI, UPM, UDHost, UDocker, CUBE, IP
+	ResourceType string
+	ResourceId   string
+	ResourceName string
+	PrivateIP    string
+	Port         int
+	Enabled      int
+	Status       int
+	Weight       int
+	IsBackup     int
+}
+
+func (self *SLBBackend) GetId() string {
+	return self.BackendId
+}
+
+func (self *SLBBackend) GetName() string {
+	return self.ResourceName
+}
+
+func (self *SLBBackend) GetGlobalId() string {
+	return self.BackendId
+}
+
+func (self *SLBBackend) GetStatus() string {
+	if self.Enabled == 1 {
+		return api.LB_STATUS_ENABLED
+	}
+	return api.LB_STATUS_DISABLED
+}
+
+func (self *SLBBackend) Refresh() error {
+	err := self.group.listener.Refresh()
+	if err != nil {
+		return err
+	}
+	for _, backend := range self.group.listener.BackendSet {
+		if backend.BackendId == self.BackendId {
+			group := self.group
+			*self = backend
+			self.group = group
+			return nil
+		}
+	}
+	return errors.Wrapf(cloudprovider.ErrNotFound, self.BackendId)
+}
+
+func (self *SLBBackend) GetWeight() int {
+	return self.Weight
+}
+
+func (self *SLBBackend) GetPort() int {
+	return self.Port
+}
+
+func (self *SLBBackend) GetBackendType() string {
+	if self.ResourceType == "UHost" {
+		return api.LB_BACKEND_GUEST
+	}
+	return api.LB_BACKEND_IP
+}
+
+func (self *SLBBackend) GetBackendRole() string {
+	if self.IsBackup == 1 {
+		return api.LB_BACKEND_ROLE_SLAVE
+	}
+	return api.LB_BACKEND_ROLE_DEFAULT
+}
+
+func (self *SLBBackend) GetBackendId() string {
+	return self.ResourceId
+}
+
+func (self *SLBBackend) GetIpAddress() string {
+	return self.PrivateIP
+}
+
+func (self *SLBBackend) SyncConf(ctx context.Context, port, weight int) error {
+	lb := self.group.listener.lb
+	return lb.region.UpdateLoadbalancerBackend(lb.ULBId, self.BackendId, port, weight)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/allocate_backend
+func (self *SRegion) AllocateLoadbalancerBackend(ulbId, vserverId, serverId string, weight, port int) (string, error) {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("VServerId", vserverId)
+	params.Set("ResourceType", "UHost")
+	params.Set("ResourceId", serverId)
+	params.Set("Port", port)
+	params.Set("Weight", weight)
+	params.Set("Enabled", 1)
+	backendId := ""
+	err := self.DoAction("AllocateBackend", params, &backendId)
+	if err != nil {
+		return "", errors.Wrapf(err, "AllocateBackend")
+	}
+	return backendId, nil
+}
+
+// https://docs.ucloud.cn/api/ulb-api/release_backend
+func (self *SRegion) ReleaseLoadbalancerBackend(ulbId, backendId string) error {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("BackendId", backendId)
+	return self.DoAction("ReleaseBackend", params, nil)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/update_backend_attribute
+func (self *SRegion) UpdateLoadbalancerBackend(ulbId, backendId string, port, weight int) error {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("BackendId", backendId)
+	params.Set("Port", port)
+	params.Set("Weight", weight)
+	return self.DoAction("UpdateBackendAttribute", params, nil)
+}
@@ -0,0 +1,196 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SLoadbalancerCert struct {
+	multicloud.SResourceBase
+	UcloudTags
+	region *SRegion
+
+	SSLId      string
+	SSLName    string
+	SSLType    string
+	SSLContent string
+	Domains    string
+	CreateTime int64
+	NotBefore  int64
+	NotAfter   int64
+}
+
+func (self *SLoadbalancerCert) GetId() string {
+	return self.SSLId
+}
+
+func (self *SLoadbalancerCert) GetName() string {
+	return self.SSLName
+}
+
+func (self *SLoadbalancerCert) GetGlobalId() string {
+	return self.SSLId
+}
+
+func (self *SLoadbalancerCert) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLoadbalancerCert) GetCreatedAt() time.Time {
+	return time.Unix(self.CreateTime, 0)
+}
+
+func (self *SLoadbalancerCert) GetProjectId() string {
+	return self.region.client.projectId
+}
+
+func (self *SLoadbalancerCert) Refresh() error {
+	cert, err := self.region.GetLoadbalancerCertificate(self.SSLId)
+	if err != nil {
+		return err
+	}
+	*self = *cert
+	return nil
+}
+
+func (self *SLoadbalancerCert) Sync(name, privateKey, publickKey string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLoadbalancerCert) Delete() error {
+	return self.region.DeleteLoadbalancerCertificate(self.SSLId)
+}
+
+func (self *SLoadbalancerCert) GetCommonName() string {
+	return strings.Split(self.Domains, ",")[0]
+}
+
+func (self *SLoadbalancerCert) GetSubjectAlternativeNames() string {
+	return self.Domains
+}
+
+func (self *SLoadbalancerCert) GetFingerprint() string {
+	_fp := sha1.Sum([]byte(self.SSLContent))
+	fp := fmt.Sprintf("sha1:% x", _fp)
+	return strings.Replace(fp, " ", ":", -1)
+}
+
+func (self *SLoadbalancerCert) GetExpireTime() time.Time {
+	return time.Unix(self.NotAfter, 0)
+}
+
+func (self *SLoadbalancerCert) GetPublickKey() string {
+	return self.SSLContent
+}
+
+func (self *SLoadbalancerCert) GetPrivateKey() string {
+	return ""
+}
+
+// https://docs.ucloud.cn/api/ulb-api/describe_ssl
+func (self *SRegion) GetLoadbalancerCertificates(sslId string) ([]SLoadbalancerCert, error) {
+	params := NewUcloudParams()
+	if len(sslId) > 0 {
+		params.Set("SSLId", sslId)
+	}
+	certs := make([]SLoadbalancerCert, 0)
+	err := self.DoListAll("DescribeSSL", params, &certs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "DescribeSSL")
+	}
+	for i := range certs {
+		certs[i].region = self
+	}
+	return certs, nil
+}
+
+func (self *SRegion) GetLoadbalancerCertificate(sslId string) (*SLoadbalancerCert, error) {
+	certs, err := self.GetLoadbalancerCertificates(sslId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range certs {
+		if certs[i].SSLId == sslId {
+			return &certs[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, sslId)
+}
+
+func (self *SRegion) GetILoadBalancerCertificates() ([]cloudprovider.ICloudLoadbalancerCertificate, error) {
+	certs, err := self.GetLoadbalancerCertificates("")
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudLoadbalancerCertificate{}
+	for i := range certs {
+		ret = append(ret, &certs[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetILoadBalancerCertificateById(certId string) (cloudprovider.ICloudLoadbalancerCertificate, error) {
+	return self.GetLoadbalancerCertificate(certId)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/create_ssl
+func (self *SRegion) CreateILoadBalancerCertificate(cert *cloudprovider.SLoadbalancerCertificate) (cloudprovider.ICloudLoadbalancerCertificate, error) {
+	params := NewUcloudParams()
+	params.Set("SSLName", cert.Name)
+	params.Set("SSLType", "Pem")
+	params.Set("UserCert", cert.Certificate)
+	params.Set("PrivateKey", cert.PrivateKey)
+	sslId := ""
+	err := self.DoAction("CreateSSL", params, &sslId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CreateSSL")
+	}
+	return self.GetLoadbalancerCertificate(sslId)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/delete_ssl
+func (self *SRegion) DeleteLoadbalancerCertificate(sslId string) error {
+	params := NewUcloudParams()
+	params.Set("SSLId", sslId)
+	return self.DoAction("DeleteSSL", params, nil)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/bind_ssl
+func (self *SRegion) BindLoadbalancerCertificate(ulbId, vserverId, sslId string) error {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("VServerId", vserverId)
+	params.Set("SSLId", sslId)
+	return self.DoAction("BindSSL", params, nil)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/unbind_ssl
+func (self *SRegion) UnbindLoadbalancerCertificate(ulbId, vserverId, sslId string) error {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("VServerId", vserverId)
+	params.Set("SSLId", sslId)
+	return self.DoAction("UnbindSSL", params, nil)
+}
@@ -0,0 +1,428 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"context"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SLBSSL struct {
+	SSLId   string
+	SSLName string
+}
+
+type SLBListener struct {
+	multicloud.SResourceBase
+	multicloud.SLoadbalancerRedirectBase
+	UcloudTags
+	lb *SLoadbalancer
+
+	VServerId    string
+	VServerName  string
+	Protocol     string
+	FrontendPort int
+	// Roundrobin, Source, ConsistentHash, SourcePort, ConsistentHashPort, WeightRoundrobin, Leastconn
+	Method string
+	// None, ServerInsert, UserDefined
+	PersistenceType string
+	PersistenceInfo string
+	ClientTimeout   int
+	Status          int
+	SSLSet          []SLBSSL
+	BackendSet      []SLBBackend
+	ListenType      string
+	PolicySet       []SLBListenerRule
+	// Port, Path
+	MonitorType string
+	Domain      string
+	Path        string
+}
+
+func (self *SLBListener) GetId() string {
+	return self.VServerId
+}
+
+func (self *SLBListener) GetName() string {
+	return self.VServerName
+}
+
+func (self *SLBListener) GetGlobalId() string {
+	return self.VServerId
+}
+
+func (self *SLBListener) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLBListener) Refresh() error {
+	lb, err := self.lb.region.GetLoadbalancer(self.lb.ULBId)
+	if err != nil {
+		return err
+	}
+	for i := range lb.VServerSet {
+		if lb.VServerSet[i].VServerId == self.VServerId {
+			self.BackendSet = nil
+			self.PolicySet = nil
+			self.SSLSet = nil
+			return jsonutils.Update(self, lb.VServerSet[i])
+		}
+	}
+	return errors.Wrapf(cloudprovider.ErrNotFound, self.VServerId)
+}
+
+func (self *SLBListener) GetListenerType() string {
+	switch strings.ToUpper(self.Protocol) {
+	case "HTTP":
+		return api.LB_LISTENER_TYPE_HTTP
+	case "HTTPS":
+		return api.LB_LISTENER_TYPE_HTTPS
+	case "UDP":
+		return api.LB_LISTENER_TYPE_UDP
+	default:
+		return api.LB_LISTENER_TYPE_TCP
+	}
+}
+
+func (self *SLBListener) GetListenerPort() int {
+	return self.FrontendPort
+}
+
+func (self *SLBListener) GetScheduler() string {
+	switch self.Method {
+	case "WeightRoundrobin":
+		return api.LB_SCHEDULER_WRR
+	case "Leastconn":
+		return api.LB_SCHEDULER_WLC
+	case "Source", "ConsistentHash":
+		return api.LB_SCHEDULER_SCH
+	case "SourcePort", "ConsistentHashPort":
+		return api.LB_SCHEDULER_TCH
+	default:
+		return api.LB_SCHEDULER_RR
+	}
+}
+
+func (self *SLBListener) GetAclStatus() string {
+	return api.LB_BOOL_OFF
+}
+
+func (self *SLBListener) GetAclType() string {
+	return ""
+}
+
+func (self *SLBListener) GetAclId() string {
+	return ""
+}
+
+func (self *SLBListener) GetEgressMbps() int {
+	return 0
+}
+
+func (self *SLBListener) GetBackendGroup() *SLBBackendGroup {
+	return &SLBBackendGroup{listener: self}
+}
+
+func (self *SLBListener) GetBackendGroupId() string {
+	return self.VServerId
+}
+
+func (self *SLBListener) GetBackendServerPort() int {
+	return 0
+}
+
+func (self *SLBListener) GetClientIdleTimeout() int {
+	return self.ClientTimeout
+}
+
+func (self *SLBListener) GetBackendConnectTimeout() int {
+	return 0
+}
+
+func (self *SLBListener) GetHealthCheck() string {
+	return api.LB_BOOL_ON
+}
+
+func (self *SLBListener) GetHealthCheckType() string {
+	if self.MonitorType == "Path" {
+		return api.LB_HEALTH_CHECK_HTTP
+	}
+	if self.GetListenerType() == api.LB_LISTENER_TYPE_UDP {
+		return api.LB_HEALTH_CHECK_UDP
+	}
+	return api.LB_HEALTH_CHECK_TCP
+}
+
+func (self *SLBListener) GetHealthCheckTimeout() int {
+	return 0
+}
+
+func (self *SLBListener) GetHealthCheckInterval() int {
+	return 0
+}
+
+func (self *SLBListener) GetHealthCheckRise() int {
+	return 0
+}
+
+func (self *SLBListener) GetHealthCheckFail() int {
+	return 0
+}
+
+func (self *SLBListener) GetHealthCheckReq() string {
+	return ""
+}
+
+func (self *SLBListener) GetHealthCheckExp() string {
+	return ""
+}
+
+func (self *SLBListener) GetHealthCheckDomain() string {
+	return self.Domain
+}
+
+func (self *SLBListener) GetHealthCheckURI() string {
+	return self.Path
+}
+
+func (self *SLBListener) GetHealthCheckCode() string {
+	return ""
+}
+
+func (self *SLBListener) GetStickySession() string {
+	if self.PersistenceType == "ServerInsert" || self.PersistenceType == "UserDefined" {
+		return api.LB_BOOL_ON
+	}
+	return api.LB_BOOL_OFF
+}
+
+func (self *SLBListener) GetStickySessionType() string {
+	switch self.PersistenceType {
+	case "ServerInsert":
+		return api.LB_STICKY_SESSION_TYPE_INSERT
+	case "UserDefined":
+		return api.LB_STICKY_SESSION_TYPE_SERVER
+	}
+	return ""
+}
+
+func (self *SLBListener) GetStickySessionCookie() string {
+	if self.PersistenceType == "UserDefined" {
+		return self.PersistenceInfo
+	}
+	return ""
+}
+
+func (self *SLBListener) GetStickySessionCookieTimeout() int {
+	return 0
+}
+
+func (self *SLBListener) XForwardedForEnabled() bool {
+	return false
+}
+
+func (self *SLBListener) GzipEnabled() bool {
+	return false
+}
+
+func (self *SLBListener) GetCertificateId() string {
+	if len(self.SSLSet) > 0 {
+		return self.SSLSet[0].SSLId
+	}
+	return ""
+}
+
+func (self *SLBListener) GetTLSCipherPolicy() string {
+	return ""
+}
+
+func (self *SLBListener) HTTP2Enabled() bool {
+	return false
+}
+
+func (self *SLBListener) GetILoadbalancerListenerRules() ([]cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	ret := []cloudprovider.ICloudLoadbalancerListenerRule{}
+	for i := range self.PolicySet {
+		if self.PolicySet[i].PolicyType == "Default" {
+			continue
+		}
+		self.PolicySet[i].listener = self
+		ret = append(ret, &self.PolicySet[i])
+	}
+	return ret, nil
+}
+
+func (self *SLBListener) GetILoadBalancerListenerRuleById(ruleId string) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	for i := range self.PolicySet {
+		if self.PolicySet[i].PolicyId == ruleId {
+			self.PolicySet[i].listener = self
+			return &self.PolicySet[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, ruleId)
+}
+
+// 转发规则只能关联当前 VServer 的后端节点
+func (self *SLBListener) CreateILoadBalancerListenerRule(rule *cloudprovider.SLoadbalancerListenerRule) (cloudprovider.ICloudLoadbalancerListenerRule, error) {
+	if len(rule.BackendGroupId) > 0 && rule.BackendGroupId != self.VServerId {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "backend group %s not belong to listener %s", rule.BackendGroupId, self.VServerId)
+	}
+	backendIds := []string{}
+	for _, backend := range self.BackendSet {
+		backendIds = append(backendIds, backend.BackendId)
+	}
+	if len(backendIds) == 0 {
+		return nil, errors.Wrapf(cloudprovider.ErrNotSupported, "listener %s has no backends", self.VServerId)
+	}
+	policyType, match := "Domain", rule.Domain
+	if len(rule.Path) > 0 {
+		policyType, match = "Path", rule.Path
+	}
+	policyId, err := self.lb.region.CreateLoadbalancerPolicy(self.lb.ULBId, self.VServerId, policyType, match, backendIds)
+	if err != nil {
+		return nil, err
+	}
+	err = self.Refresh()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Refresh")
+	}
+	return self.GetILoadBalancerListenerRuleById(policyId)
+}
+
+func (self *SLBListener) Start() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLBListener) Stop() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLBListener) ChangeScheduler(ctx context.Context, opts *cloudprovider.ChangeListenerSchedulerOptions) error {
+	params := NewUcloudParams()
+	params.Set("Method", getULBMethod(opts.Scheduler))
+	setULBPersistence(&params, &opts.ListenerStickySessionOptions)
+	return self.lb.region.UpdateLoadbalancerListener(self.lb.ULBId, self.VServerId, params)
+}
+
+func (self *SLBListener) SetHealthCheck(ctx context.Context, opts *cloudprovider.ListenerHealthCheckOptions) error {
+	params := NewUcloudParams()
+	setULBMonitor(&params, opts)
+	return self.lb.region.UpdateLoadbalancerListener(self.lb.ULBId, self.VServerId, params)
+}
+
+func (self *SLBListener) ChangeCertificate(ctx context.Context, opts *cloudprovider.ListenerCertificateOptions) error {
+	for _, ssl := range self.SSLSet {
+		if ssl.SSLId == opts.CertificateId {
+			return nil
+		}
+		err := self.lb.region.UnbindLoadbalancerCertificate(self.lb.ULBId, self.VServerId, ssl.SSLId)
+		if err != nil {
+			return errors.Wrapf(err, "UnbindLoadbalancerCertificate %s", ssl.SSLId)
+		}
+	}
+	return self.lb.region.BindLoadbalancerCertificate(self.lb.ULBId, self.VServerId, opts.CertificateId)
+}
+
+func (self *SLBListener) SetAcl(ctx context.Context, opts *cloudprovider.ListenerAclOptions) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SLBListener) Delete(ctx context.Context) error {
+	return self.lb.region.DeleteLoadbalancerListener(self.lb.ULBId, self.VServerId)
+}
+
+func getULBMethod(scheduler string) string {
+	switch scheduler {
+	case api.LB_SCHEDULER_WRR:
+		return "WeightRoundrobin"
+	case api.LB_SCHEDULER_WLC:
+		return "Leastconn"
+	case api.LB_SCHEDULER_SCH:
+		return "Source"
+	case api.LB_SCHEDULER_TCH:
+		return "SourcePort"
+	default:
+		return "Roundrobin"
+	}
+}
+
+func setULBPersistence(params *SParams, opts *cloudprovider.ListenerStickySessionOptions) {
+	if opts.StickySession != api.LB_BOOL_ON {
+		params.Set("PersistenceType", "None")
+		return
+	}
+	if opts.StickySessionType == api.LB_STICKY_SESSION_TYPE_SERVER && len(opts.StickySessionCookie) > 0 {
+		params.Set("PersistenceType", "UserDefined")
+		params.Set("PersistenceInfo", opts.StickySessionCookie)
+		return
+	}
+	params.Set("PersistenceType", "ServerInsert")
+}
+
+func setULBMonitor(params *SParams, opts *cloudprovider.ListenerHealthCheckOptions) {
+	if opts.HealthCheckType == api.LB_HEALTH_CHECK_HTTP {
+		params.Set("MonitorType", "Path")
+		params.Set("Domain", opts.HealthCheckDomain)
+		params.Set("Path", opts.HealthCheckURI)
+		return
+	}
+	params.Set("MonitorType", "Port")
+}
+
+// https://docs.ucloud.cn/api/ulb-api/create_vserver
+func (self *SRegion) CreateLoadbalancerListener(ulbId, listenType string, opts *cloudprovider.SLoadbalancerListenerCreateOptions) (string, error) {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("VServerName", opts.Name)
+	if len(listenType) > 0 {
+		params.Set("ListenType", listenType)
+	}
+	params.Set("Protocol", strings.ToUpper(opts.ListenerType))
+	params.Set("FrontendPort", opts.ListenerPort)
+	params.Set("Method", getULBMethod(opts.Scheduler))
+	if opts.ClientIdleTimeout > 0 {
+		params.Set("ClientTimeout", opts.ClientIdleTimeout)
+	}
+	setULBPersistence(&params, &opts.ListenerStickySessionOptions)
+	setULBMonitor(&params, &opts.ListenerHealthCheckOptions)
+	vserverId := ""
+	err := self.DoAction("CreateVServer", params, &vserverId)
+	if err != nil {
+		return "", errors.Wrapf(err, "CreateVServer")
+	}
+	return vserverId, nil
+}
+
+// https://docs.ucloud.cn/api/ulb-api/update_vserver_attribute
+func (self *SRegion) UpdateLoadbalancerListener(ulbId, vserverId string, params SParams) error {
+	params.Set("ULBId", ulbId)
+	params.Set("VServerId", vserverId)
+	return self.DoAction("UpdateVServerAttribute", params, nil)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/delete_vserver
+func (self *SRegion) DeleteLoadbalancerListener(ulbId, vserverId string) error {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("VServerId", vserverId)
+	return self.DoAction("DeleteVServer", params, nil)
+}
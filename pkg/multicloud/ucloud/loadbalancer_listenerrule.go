@@ -0,0 +1,113 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"context"
+	"fmt"
+
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+type SLBListenerRule struct {
+	multicloud.SResourceBase
+	multicloud.SLoadbalancerRedirectBase
+	UcloudTags
+	listener *SLBListener
+
+	PolicyId string
+	// Custom, Default
+	PolicyType string
+	// Domain, Path
+	Type           string
+	Match          string
+	PolicyPriority int
+	VServerId      string
+}
+
+func (self *SLBListenerRule) GetId() string {
+	return self.PolicyId
+}
+
+func (self *SLBListenerRule) GetName() string {
+	return fmt.Sprintf("%s-%s", self.Type, self.Match)
+}
+
+func (self *SLBListenerRule) GetGlobalId() string {
+	return self.PolicyId
+}
+
+func (self *SLBListenerRule) GetStatus() string {
+	return api.LB_STATUS_ENABLED
+}
+
+func (self *SLBListenerRule) IsDefault() bool {
+	return self.PolicyType == "Default"
+}
+
+func (self *SLBListenerRule) GetDomain() string {
+	if self.Type == "Domain" {
+		return self.Match
+	}
+	return ""
+}
+
+func (self *SLBListenerRule) GetPath() string {
+	if self.Type == "Path" {
+		return self.Match
+	}
+	return ""
+}
+
+func (self *SLBListenerRule) GetCondition() string {
+	return ""
+}
+
+func (self *SLBListenerRule) GetBackendGroupId() string {
+	return self.listener.VServerId
+}
+
+func (self *SLBListenerRule) Delete(ctx context.Context) error {
+	return self.listener.lb.region.DeleteLoadbalancerPolicy(self.listener.VServerId, self.PolicyId)
+}
+
+// https://docs.ucloud.cn/api/ulb-api/create_policy
+func (self *SRegion) CreateLoadbalancerPolicy(ulbId, vserverId, policyType, match string, backendIds []string) (string, error) {
+	params := NewUcloudParams()
+	params.Set("ULBId", ulbId)
+	params.Set("VServerId", vserverId)
+	params.Set("Type", policyType)
+	params.Set("Match", match)
+	for i, id := range backendIds {
+		params.Set(fmt.Sprintf("BackendId.%d", i), id)
+	}
+	policyId := ""
+	err := self.DoAction("CreatePolicy", params, &policyId)
+	if err != nil {
+		return "", errors.Wrapf(err, "CreatePolicy")
+	}
+	return policyId, nil
+}
+
+// https://docs.ucloud.cn/api/ulb-api/delete_policy
+func (self *SRegion) DeleteLoadbalancerPolicy(vserverId, policyId string) error {
+	params := NewUcloudParams()
+	params.Set("VServerId", vserverId)
+	params.Set("PolicyId", policyId)
+	return self.DoAction("DeletePolicy", params, nil)
+}
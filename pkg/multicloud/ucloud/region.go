@@ -28,7 +28,6 @@ import (
 
 type SRegion struct {
 	multicloud.SRegion
-	multicloud.SNoLbRegion
 	client *SUcloudClient
 
 	RegionID string
@@ -617,7 +616,12 @@ func (region *SRegion) GetIBuckets() ([]cloudprovider.ICloudBucket, error) {
 }
 
 func (region *SRegion) CreateIBucket(name string, storageClassStr string, aclStr string) error {
-	if aclStr != "private" && aclStr != "public" {
+	switch cloudprovider.TBucketACLType(aclStr) {
+	case "", cloudprovider.ACLPrivate:
+		aclStr = "private"
+	case cloudprovider.ACLPublicRead, cloudprovider.ACLPublicReadWrite, "public":
+		aclStr = "public"
+	default:
 		return errors.Error("invalid acl")
 	}
 	return region.CreateBucket(name, aclStr)
@@ -650,7 +654,7 @@ func (region *SRegion) GetIBucketById(bucketId string) (cloudprovider.ICloudBuck
 }
 
 func (region *SRegion) GetIBucketByName(name string) (cloudprovider.ICloudBucket, error) {
-	return region.GetIBucketByName(name)
+	return cloudprovider.GetIBucketByName(region, name)
 }
 
 func (region *SRegion) GetCapabilities() []string {
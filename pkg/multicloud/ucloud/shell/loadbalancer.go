@@ -0,0 +1,70 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/multicloud/ucloud"
+)
+
+func init() {
+	type LoadbalancerListOptions struct {
+		Id string `help:"ULB id"`
+	}
+	shellutils.R(&LoadbalancerListOptions{}, "lb-list", "List loadbalancers", func(cli *ucloud.SRegion, args *LoadbalancerListOptions) error {
+		lbs, err := cli.GetLoadbalancers(args.Id)
+		if err != nil {
+			return err
+		}
+		printList(lbs, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerIdOptions struct {
+		ID string `help:"ULB id"`
+	}
+	shellutils.R(&LoadbalancerIdOptions{}, "lb-delete", "Delete loadbalancer", func(cli *ucloud.SRegion, args *LoadbalancerIdOptions) error {
+		return cli.DeleteLoadbalancer(args.ID)
+	})
+
+	shellutils.R(&LoadbalancerIdOptions{}, "lb-listener-list", "List loadbalancer listeners", func(cli *ucloud.SRegion, args *LoadbalancerIdOptions) error {
+		lb, err := cli.GetLoadbalancer(args.ID)
+		if err != nil {
+			return err
+		}
+		printList(lb.VServerSet, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerCertListOptions struct {
+		Id string `help:"SSL id"`
+	}
+	shellutils.R(&LoadbalancerCertListOptions{}, "lb-cert-list", "List loadbalancer certificates", func(cli *ucloud.SRegion, args *LoadbalancerCertListOptions) error {
+		certs, err := cli.GetLoadbalancerCertificates(args.Id)
+		if err != nil {
+			return err
+		}
+		printList(certs, 0, 0, 0, nil)
+		return nil
+	})
+
+	type LoadbalancerCertIdOptions struct {
+		ID string `help:"SSL id"`
+	}
+	shellutils.R(&LoadbalancerCertIdOptions{}, "lb-cert-delete", "Delete loadbalancer certificate", func(cli *ucloud.SRegion, args *LoadbalancerCertIdOptions) error {
+		return cli.DeleteLoadbalancerCertificate(args.ID)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"yunion.io/x/pkg/util/shellutils"
+
+	"yunion.io/x/cloudmux/pkg/multicloud/ucloud"
+)
+
+func init() {
+	type DBInstanceListOptions struct {
+		ClassType string `help:"UDB class type" choices:"SQL|Postgresql|NOSQL" default:"SQL"`
+		Id        string `help:"UDB id"`
+	}
+	shellutils.R(&DBInstanceListOptions{}, "dbinstance-list", "List udb instances", func(cli *ucloud.SRegion, args *DBInstanceListOptions) error {
+		instances, err := cli.GetDBInstances(args.ClassType, args.Id)
+		if err != nil {
+			return err
+		}
+		printList(instances, 0, 0, 0, nil)
+		return nil
+	})
+
+	type ElasticcacheListOptions struct {
+		Id string `help:"group id"`
+	}
+	shellutils.R(&ElasticcacheListOptions{}, "elasticcache-list", "List redis and memcache groups", func(cli *ucloud.SRegion, args *ElasticcacheListOptions) error {
+		caches, err := cli.GetElasticcaches(args.Id)
+		if err != nil {
+			return err
+		}
+		printList(caches, 0, 0, 0, nil)
+		return nil
+	})
+}
@@ -97,7 +97,81 @@ func (self *SStoragecache) CreateIImage(snapshotId, imageName, osType, imageDesc
 }
 
 func (self *SStoragecache) DownloadImage(imageId string, extId string, path string) (jsonutils.JSONObject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return self.ExportImage(context.Background(), extId, &multicloud.SImageDownloadOptions{
+		Path: path,
+		Callback: func(progress float32) {
+			log.Debugf("download image %s(%s) progress: %.2f%%", imageId, extId, progress)
+		},
+	})
+}
+
+// 镜像先导出到临时的ufile bucket, 再从bucket下载
+func (self *SStoragecache) ExportImage(ctx context.Context, imageId string, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	image, err := self.region.GetImage(imageId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetImage(%s)", imageId)
+	}
+
+	bucketName := GetBucketName(self.region.GetId(), imageId)
+	exist, err := self.region.IBucketExist(bucketName)
+	if err != nil {
+		return nil, errors.Wrap(err, "self.region.IBucketExist")
+	}
+	if !exist {
+		err = self.region.CreateBucket(bucketName, "private")
+		if err != nil {
+			return nil, errors.Wrap(err, "CreateBucket")
+		}
+	}
+	defer func() {
+		e := self.region.DeleteBucket(bucketName)
+		if e != nil {
+			log.Errorf("ExportImage delete bucket %s", e.Error())
+		}
+	}()
+
+	format := string(qemuimgfmt.QCOW2)
+	fileName := fmt.Sprintf("%s.%s", imageId, format)
+	err = self.region.ExportImage(imageId, bucketName, fileName, format)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ExportImage")
+	}
+
+	bucket, err := self.region.GetIBucketById(bucketName)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetIBucketById")
+	}
+	defer func() {
+		e := bucket.DeleteObject(context.Background(), fileName)
+		if e != nil {
+			log.Errorf("ExportImage delete object %s", e.Error())
+		}
+	}()
+
+	// timeout: 1hour = 3600 seconds
+	var obj cloudprovider.ICloudObject
+	err = cloudprovider.WaitCreated(30*time.Second, 3600*time.Second, func() bool {
+		obj, err = cloudprovider.GetIObject(bucket, fileName)
+		return err == nil && obj.GetSizeBytes() > 0
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "wait image %s exported", imageId)
+	}
+
+	body, err := bucket.GetObject(ctx, fileName, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetObject(%s)", fileName)
+	}
+	size := obj.GetSizeBytes()
+	if size <= 0 {
+		size = image.ImageSizeGB * 1024 * 1024 * 1024
+	}
+	stream := &multicloud.SImageStream{
+		Body:   body,
+		Size:   size,
+		Format: format,
+	}
+	return multicloud.SaveImage(ctx, stream, opts)
 }
 
 // https://docs.ucloud.cn/api/uhost-api/import_custom_image
@@ -130,10 +204,12 @@ func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider
 		}
 	}()
 
-	reader, size, err := image.GetReader(image.ImageId, string(qemuimgfmt.VMDK))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.VMDK, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	size, callback := reader.Size, reader.Callback
 
 	minDiskGB := int64(math.Ceil(float64(image.MinDiskMb) / 1024))
 
@@ -351,6 +427,16 @@ func normalizeDiskFormat(diskFormat string) (string, error) {
 }
 
 // https://docs.ucloud.cn/api/uhost-api/import_custom_image
+// https://docs.ucloud.cn/api/uhost-api/export_custom_image
+func (self *SRegion) ExportImage(imageId string, bucketName string, fileName string, format string) error {
+	params := NewUcloudParams()
+	params.Set("ImageId", imageId)
+	params.Set("TargetBucket", bucketName)
+	params.Set("TargetFileName", fileName)
+	params.Set("Format", strings.ToUpper(format))
+	return self.DoAction("ExportCustomImage", params, nil)
+}
+
 func (self *SRegion) ImportImage(name string, ufileUrl string, osType string, osVersion string, diskFormat string) (string, error) {
 	format, err := normalizeDiskFormat(diskFormat)
 	if err != nil {
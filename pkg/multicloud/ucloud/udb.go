@@ -0,0 +1,287 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"yunion.io/x/pkg/errors"
+
+	billing_api "yunion.io/x/cloudmux/pkg/apis/billing"
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// DescribeUDBInstance 需指定 ClassType, 只同步关系型数据库
+var UDB_CLASS_TYPES = []string{"SQL", "Postgresql"}
+
+type SDBInstance struct {
+	multicloud.SDBInstanceBase
+	UcloudTags
+	region *SRegion
+
+	DBId         string
+	Name         string
+	Zone         string
+	DBTypeId     string
+	ChargeType   string
+	ExpiredTime  int64
+	CreateTime   int64
+	Port         int
+	State        string
+	MemoryLimit  int
+	DiskSpace    int
+	DiskUsedSize float64
+	InstanceMode string
+	InstanceType string
+	MachineType  string
+	VPCId        string
+	SubnetId     string
+	VirtualIP    string
+	Role         string
+	SrcDBId      string
+	SlaveSet     []SDBInstance
+}
+
+func (self *SDBInstance) GetId() string {
+	return self.DBId
+}
+
+func (self *SDBInstance) GetName() string {
+	return self.Name
+}
+
+func (self *SDBInstance) GetGlobalId() string {
+	return self.DBId
+}
+
+func (self *SDBInstance) GetProjectId() string {
+	return self.region.client.projectId
+}
+
+// Init, Fail, Starting, Running, Shutdown, Shutoff, Delete, Upgrading, Promoting, Recovering, Recover fail
+func (self *SDBInstance) GetStatus() string {
+	switch self.State {
+	case "Init":
+		return api.DBINSTANCE_INIT
+	case "Fail":
+		return api.DBINSTANCE_CREATE_FAILED
+	case "Starting":
+		return api.DBINSTANCE_REBOOTING
+	case "Running":
+		return api.DBINSTANCE_RUNNING
+	case "Delete":
+		return api.DBINSTANCE_DELETING
+	case "Upgrading":
+		return api.DBINSTANCE_UPGRADING
+	case "Promoting", "Recovering":
+		return api.DBINSTANCE_RESTORING
+	default:
+		return api.DBINSTANCE_UNKNOWN
+	}
+}
+
+func (self *SDBInstance) Refresh() error {
+	instance, err := self.region.GetDBInstance(self.DBId)
+	if err != nil {
+		return err
+	}
+	region := self.region
+	*self = *instance
+	self.region = region
+	return nil
+}
+
+func (self *SDBInstance) GetCreatedAt() time.Time {
+	return time.Unix(self.CreateTime, 0)
+}
+
+func (self *SDBInstance) GetBillingType() string {
+	switch self.ChargeType {
+	case "Year", "Month":
+		return billing_api.BILLING_TYPE_PREPAID
+	default:
+		return billing_api.BILLING_TYPE_POSTPAID
+	}
+}
+
+func (self *SDBInstance) GetExpiredAt() time.Time {
+	if self.ExpiredTime > 0 && self.GetBillingType() == billing_api.BILLING_TYPE_PREPAID {
+		return time.Unix(self.ExpiredTime, 0)
+	}
+	return time.Time{}
+}
+
+func (self *SDBInstance) GetMasterInstanceId() string {
+	return self.SrcDBId
+}
+
+func (self *SDBInstance) GetPort() int {
+	return self.Port
+}
+
+// mysql-5.7, percona-5.6, postgresql-10.4
+func (self *SDBInstance) GetEngine() string {
+	engine := strings.Split(self.DBTypeId, "-")[0]
+	switch strings.ToLower(engine) {
+	case "mysql":
+		return api.DBINSTANCE_TYPE_MYSQL
+	case "percona":
+		return api.DBINSTANCE_TYPE_PERCONA
+	case "postgresql":
+		return api.DBINSTANCE_TYPE_POSTGRESQL
+	case "sqlserver":
+		return api.DBINSTANCE_TYPE_SQLSERVER
+	}
+	return engine
+}
+
+func (self *SDBInstance) GetEngineVersion() string {
+	if idx := strings.Index(self.DBTypeId, "-"); idx >= 0 {
+		return self.DBTypeId[idx+1:]
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetInstanceType() string {
+	if len(self.MachineType) > 0 {
+		return self.MachineType
+	}
+	return fmt.Sprintf("%dM", self.MemoryLimit)
+}
+
+func (self *SDBInstance) GetVcpuCount() int {
+	return 0
+}
+
+func (self *SDBInstance) GetVmemSizeMB() int {
+	return self.MemoryLimit
+}
+
+func (self *SDBInstance) GetDiskSizeGB() int {
+	return self.DiskSpace
+}
+
+func (self *SDBInstance) GetDiskSizeUsedMB() int {
+	return int(self.DiskUsedSize * 1024)
+}
+
+// Normal, HA
+func (self *SDBInstance) GetCategory() string {
+	return strings.ToLower(self.InstanceMode)
+}
+
+func (self *SDBInstance) GetStorageType() string {
+	return self.InstanceType
+}
+
+func (self *SDBInstance) GetMaintainTime() string {
+	return ""
+}
+
+func (self *SDBInstance) GetInternalConnectionStr() string {
+	if len(self.VirtualIP) > 0 {
+		return fmt.Sprintf("%s:%d", self.VirtualIP, self.Port)
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetZone1Id() string {
+	if len(self.Zone) > 0 {
+		return fmt.Sprintf("%s/%s", self.region.GetGlobalId(), self.Zone)
+	}
+	return ""
+}
+
+func (self *SDBInstance) GetZone2Id() string {
+	return ""
+}
+
+func (self *SDBInstance) GetZone3Id() string {
+	return ""
+}
+
+func (self *SDBInstance) GetIVpcId() string {
+	return self.VPCId
+}
+
+func (self *SDBInstance) GetDBNetworks() ([]cloudprovider.SDBInstanceNetwork, error) {
+	ret := []cloudprovider.SDBInstanceNetwork{}
+	if len(self.VirtualIP) > 0 && len(self.SubnetId) > 0 {
+		ret = append(ret, cloudprovider.SDBInstanceNetwork{IP: self.VirtualIP, NetworkId: self.SubnetId})
+	}
+	return ret, nil
+}
+
+// https://docs.ucloud.cn/api/udb-api/describe_udb_instance
+func (self *SRegion) GetDBInstances(classType, dbId string) ([]SDBInstance, error) {
+	params := NewUcloudParams()
+	params.Set("ClassType", classType)
+	params.Set("IncludeSlaves", true)
+	if len(dbId) > 0 {
+		params.Set("DBId", dbId)
+	}
+	instances := make([]SDBInstance, 0)
+	err := self.DoListAll("DescribeUDBInstance", params, &instances)
+	if err != nil {
+		return nil, errors.Wrapf(err, "DescribeUDBInstance")
+	}
+	ret := []SDBInstance{}
+	for i := range instances {
+		ret = append(ret, instances[i])
+		ret = append(ret, instances[i].SlaveSet...)
+	}
+	for i := range ret {
+		ret[i].region = self
+		ret[i].SlaveSet = nil
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetDBInstance(dbId string) (*SDBInstance, error) {
+	for _, classType := range UDB_CLASS_TYPES {
+		instances, err := self.GetDBInstances(classType, dbId)
+		if err != nil {
+			return nil, err
+		}
+		for i := range instances {
+			if instances[i].DBId == dbId {
+				return &instances[i], nil
+			}
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, dbId)
+}
+
+func (self *SRegion) GetIDBInstances() ([]cloudprovider.ICloudDBInstance, error) {
+	ret := []cloudprovider.ICloudDBInstance{}
+	for _, classType := range UDB_CLASS_TYPES {
+		instances, err := self.GetDBInstances(classType, "")
+		if err != nil {
+			return nil, err
+		}
+		for i := range instances {
+			ret = append(ret, &instances[i])
+		}
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetIDBInstanceById(id string) (cloudprovider.ICloudDBInstance, error) {
+	return self.GetDBInstance(id)
+}
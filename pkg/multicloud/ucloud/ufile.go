@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"yunion.io/x/jsonutils"
@@ -35,6 +36,12 @@ import (
 	"yunion.io/x/cloudmux/pkg/multicloud"
 )
 
+const (
+	UFILE_BLOCK_SIZE = int64(4 * 1024 * 1024)
+
+	UFILE_META_HEADER_PREFIX = "X-Ufile-Meta-"
+)
+
 type SBucket struct {
 	multicloud.SBaseBucket
 	UcloudTags
@@ -83,14 +90,22 @@ func (client *SUcloudClient) signHeader(httpMethod string, path string, md5 stri
 	if httpMethod == http.MethodPut {
 		contentType = "application/octet-stream"
 	}
+	return client.signRequest(httpMethod, md5, contentType, "", path)
+}
 
+// https://docs.ucloud.cn/ufile/api/authorization
+func (client *SUcloudClient) signRequest(httpMethod, md5, contentType, date, resource string) string {
 	data := httpMethod + "\n"
 	data += md5 + "\n"
 	data += contentType + "\n"
-	data += "\n"
-	data += path
+	data += date + "\n"
+	data += resource
 
 	log.Debugf("sign %s", data)
+	return client.sign(data)
+}
+
+func (client *SUcloudClient) sign(data string) string {
 	h := hmac.New(sha1.New, []byte(client.accessKeySecret))
 	h.Write([]byte(data))
 	return base64.StdEncoding.EncodeToString(h.Sum(nil))
@@ -112,22 +127,8 @@ func (self *SFile) GetUrl() string {
 	return fmt.Sprintf("http://%s/%s", self.GetHost(), self.FileName)
 }
 
-// https://github.com/ufilesdk-dev/ufile-gosdk/blob/master/auth.go
 func (self *SFile) FetchFileUrl() string {
-	expired := strconv.FormatInt(time.Now().Add(6*time.Hour).Unix(), 10)
-	// sign
-	data := "GET\n\n\n" + expired + "\n"
-	data += "/" + self.bucket.BucketName + "/" + self.FileName
-	h := hmac.New(sha1.New, []byte(self.bucket.region.client.accessKeySecret))
-	h.Write([]byte(data))
-	sign := base64.StdEncoding.EncodeToString(h.Sum(nil))
-
-	urlEncoder := url.Values{}
-	urlEncoder.Add("UCloudPublicKey", self.bucket.region.client.accessKeyId)
-	urlEncoder.Add("Signature", sign)
-	urlEncoder.Add("Expires", expired)
-	querys := urlEncoder.Encode()
-	return fmt.Sprintf("%s?%s", self.GetUrl(), querys)
+	return self.bucket.presignUrl(http.MethodGet, self.FileName, 6*time.Hour)
 }
 
 func (self *SFile) Upload() error {
@@ -184,11 +185,16 @@ func (self *SFile) SetAcl(cloudprovider.TBucketACLType) error {
 }
 
 func (self *SFile) GetMeta() http.Header {
-	return nil
+	if len(self.MimeType) == 0 {
+		return nil
+	}
+	meta := http.Header{}
+	meta.Set(cloudprovider.META_HEADER_CONTENT_TYPE, self.MimeType)
+	return meta
 }
 
 func (self *SFile) SetMeta(ctx context.Context, meta http.Header) error {
-	return cloudprovider.ErrNotSupported
+	return cloudprovider.ObjectSetMeta(ctx, self.bucket, self, meta)
 }
 
 func doRequest(req *http.Request) (jsonutils.JSONObject, error) {
@@ -212,28 +218,22 @@ type sPrefixFileListOutput struct {
 }
 
 func (b *SBucket) doPrefixFileList(prefix string, marker string, limit int) (*sPrefixFileListOutput, error) {
-	params := jsonutils.NewDict()
-	params.Add(jsonutils.NewString(""), "list")
+	query := url.Values{}
+	query.Set("list", "")
 	if len(prefix) > 0 {
-		params.Add(jsonutils.NewString(prefix), "prefix")
+		query.Set("prefix", prefix)
 	}
 	if len(marker) > 0 {
-		params.Add(jsonutils.NewString(marker), "marker")
+		query.Set("marker", marker)
 	}
 	if limit > 0 {
-		params.Add(jsonutils.NewInt(int64(limit)), "limit")
+		query.Set("limit", strconv.Itoa(limit))
 	}
-	host := fmt.Sprintf("https://%s.ufile.ucloud.cn", b.BucketName)
-	path := fmt.Sprintf("/?%s", params.QueryString())
-
-	log.Debugf("Request %s%s", host, path)
-
-	req, _ := http.NewRequest(http.MethodGet, host+path, nil)
 
-	sign := b.region.client.signHeader(http.MethodGet, path, "")
-	auth := "UCloud" + " " + b.region.client.accessKeyId + ":" + sign
-
-	req.Header.Add("Authorization", auth)
+	req, err := b.newRequest(context.Background(), http.MethodGet, "", query, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "newRequest")
+	}
 
 	output := sPrefixFileListOutput{}
 
@@ -301,6 +301,75 @@ func (b *SBucket) getSrcUrl() string {
 	return ""
 }
 
+func (b *SBucket) getHost() string {
+	if src := b.getSrcUrl(); len(src) > 0 {
+		return src
+	}
+	return fmt.Sprintf("%s.%s.ufileos.com", b.BucketName, b.Region)
+}
+
+func (b *SBucket) getObjectUrl(key string) string {
+	return fmt.Sprintf("https://%s/%s", b.getHost(), (&url.URL{Path: key}).EscapedPath())
+}
+
+// https://docs.ucloud.cn/ufile/api/authorization
+func (b *SBucket) newRequest(ctx context.Context, method string, key string, query url.Values, header http.Header, body io.Reader) (*http.Request, error) {
+	reqUrl := b.getObjectUrl(key)
+	if len(query) > 0 {
+		// ufile expects bare flags such as ?uploads and ?list without trailing '='
+		reqUrl = fmt.Sprintf("%s?%s", reqUrl, strings.ReplaceAll(query.Encode(), "=&", "&"))
+		reqUrl = strings.TrimSuffix(reqUrl, "=")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqUrl, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRequest")
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	client := b.region.client
+	sign := client.signRequest(method, req.Header.Get("Content-MD5"), req.Header.Get("Content-Type"), req.Header.Get("Date"), "/"+b.BucketName+"/"+key)
+	req.Header.Set("Authorization", "UCloud "+client.accessKeyId+":"+sign)
+	return req, nil
+}
+
+func (b *SBucket) doRawRequest(req *http.Request) (*http.Response, error) {
+	resp, err := httputils.GetAdaptiveTimeoutClient().Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "httpclient Do")
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.Wrapf(cloudprovider.ErrNotFound, "%s %s: %s", req.Method, req.URL.Path, msg)
+		}
+		return nil, errors.Errorf("%s %s: %d %s", req.Method, req.URL.Path, resp.StatusCode, msg)
+	}
+	return resp, nil
+}
+
+// https://github.com/ufilesdk-dev/ufile-gosdk/blob/master/auth.go
+func (b *SBucket) presignUrl(method string, key string, expire time.Duration) string {
+	expired := strconv.FormatInt(time.Now().Add(expire).Unix(), 10)
+	client := b.region.client
+	sign := client.sign(method + "\n\n\n" + expired + "\n/" + b.BucketName + "/" + key)
+
+	query := url.Values{}
+	query.Set("UCloudPublicKey", client.accessKeyId)
+	query.Set("Signature", sign)
+	query.Set("Expires", expired)
+	return fmt.Sprintf("%s?%s", b.getObjectUrl(key), query.Encode())
+}
+
+func ufileMetaHeader(meta http.Header) http.Header {
+	header := cloudprovider.MetaToHttpHeader(UFILE_META_HEADER_PREFIX, meta)
+	if len(header.Get(cloudprovider.META_HEADER_CONTENT_TYPE)) == 0 {
+		header.Set(cloudprovider.META_HEADER_CONTENT_TYPE, "application/octet-stream")
+	}
+	return header
+}
+
 func (b *SBucket) GetAccessUrls() []cloudprovider.SBucketAccessUrl {
 	ret := make([]cloudprovider.SBucketAccessUrl, 0)
 	for i, u := range b.Domain.Src {
@@ -349,24 +418,103 @@ func (b *SBucket) ListObjects(prefix string, marker string, delimiter string, ma
 	return result, nil
 }
 
+// https://docs.ucloud.cn/ufile/api/put_file
 func (b *SBucket) PutObject(ctx context.Context, key string, input io.Reader, sizeBytes int64, cannedAcl cloudprovider.TBucketACLType, storageClassStr string, meta http.Header) error {
-	return cloudprovider.ErrNotSupported
+	header := ufileMetaHeader(meta)
+	if len(storageClassStr) > 0 {
+		header.Set("X-Ufile-Storage-Class", storageClassStr)
+	}
+	req, err := b.newRequest(ctx, http.MethodPut, key, nil, header, input)
+	if err != nil {
+		return errors.Wrap(err, "newRequest")
+	}
+	req.ContentLength = sizeBytes
+	resp, err := b.doRawRequest(req)
+	if err != nil {
+		return errors.Wrapf(err, "PutObject %s", key)
+	}
+	resp.Body.Close()
+	return nil
 }
 
+// https://docs.ucloud.cn/ufile/api/initiate_multipart_upload
 func (b *SBucket) NewMultipartUpload(ctx context.Context, key string, cannedAcl cloudprovider.TBucketACLType, storageClassStr string, meta http.Header) (string, error) {
-	return "", cloudprovider.ErrNotSupported
+	header := ufileMetaHeader(meta)
+	if len(storageClassStr) > 0 {
+		header.Set("X-Ufile-Storage-Class", storageClassStr)
+	}
+	req, err := b.newRequest(ctx, http.MethodPost, key, url.Values{"uploads": []string{""}}, header, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "newRequest")
+	}
+	body, err := doRequest(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "NewMultipartUpload %s", key)
+	}
+	return body.GetString("UploadId")
 }
 
+// UFile requires every part except the last to be exactly UFILE_BLOCK_SIZE bytes,
+// so a part is split into blocks and the etags of the blocks are joined by comma
+// https://docs.ucloud.cn/ufile/api/upload_part
 func (b *SBucket) UploadPart(ctx context.Context, key string, uploadId string, partIndex int, input io.Reader, partSize int64, offset, totalSize int64) (string, error) {
-	return "", cloudprovider.ErrNotSupported
+	if offset%UFILE_BLOCK_SIZE != 0 || (offset+partSize < totalSize && partSize%UFILE_BLOCK_SIZE != 0) {
+		return "", errors.Wrapf(cloudprovider.ErrNotSupported, "part size %d must be a multiple of %d", partSize, UFILE_BLOCK_SIZE)
+	}
+	etags := []string{}
+	for written := int64(0); written < partSize; written += UFILE_BLOCK_SIZE {
+		blockSize := partSize - written
+		if blockSize > UFILE_BLOCK_SIZE {
+			blockSize = UFILE_BLOCK_SIZE
+		}
+		query := url.Values{}
+		query.Set("uploadId", uploadId)
+		query.Set("partNumber", strconv.FormatInt((offset+written)/UFILE_BLOCK_SIZE, 10))
+		header := http.Header{}
+		header.Set(cloudprovider.META_HEADER_CONTENT_TYPE, "application/octet-stream")
+		req, err := b.newRequest(ctx, http.MethodPut, key, query, header, io.LimitReader(input, blockSize))
+		if err != nil {
+			return "", errors.Wrap(err, "newRequest")
+		}
+		req.ContentLength = blockSize
+		resp, err := b.doRawRequest(req)
+		if err != nil {
+			return "", errors.Wrapf(err, "UploadPart %s %d", key, partIndex)
+		}
+		resp.Body.Close()
+		etags = append(etags, strings.Trim(resp.Header.Get("ETag"), "\""))
+	}
+	return strings.Join(etags, ","), nil
 }
 
+// https://docs.ucloud.cn/ufile/api/finish_multipart_upload
 func (b *SBucket) CompleteMultipartUpload(ctx context.Context, key string, uploadId string, partEtags []string) error {
-	return cloudprovider.ErrNotSupported
+	header := http.Header{}
+	header.Set(cloudprovider.META_HEADER_CONTENT_TYPE, "text/plain")
+	body := strings.Join(partEtags, ",")
+	req, err := b.newRequest(ctx, http.MethodPost, key, url.Values{"uploadId": []string{uploadId}}, header, strings.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "newRequest")
+	}
+	_, err = doRequest(req)
+	if err != nil {
+		return errors.Wrapf(err, "CompleteMultipartUpload %s", key)
+	}
+	return nil
 }
 
+// https://docs.ucloud.cn/ufile/api/abort_multipart_upload
 func (b *SBucket) AbortMultipartUpload(ctx context.Context, key string, uploadId string) error {
-	return cloudprovider.ErrNotSupported
+	req, err := b.newRequest(ctx, http.MethodDelete, key, url.Values{"uploadId": []string{uploadId}}, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "newRequest")
+	}
+	resp, err := b.doRawRequest(req)
+	if err != nil {
+		return errors.Wrapf(err, "AbortMultipartUpload %s", key)
+	}
+	resp.Body.Close()
+	return nil
 }
 
 func (b *SBucket) DeleteObject(ctx context.Context, key string) error {
@@ -378,15 +526,43 @@ func (b *SBucket) DeleteObject(ctx context.Context, key string) error {
 }
 
 func (b *SBucket) GetTempUrl(method string, key string, expire time.Duration) (string, error) {
-	return "", cloudprovider.ErrNotSupported
+	return b.presignUrl(method, key, expire), nil
 }
 
+// https://docs.ucloud.cn/ufile/api/copy_file
 func (b *SBucket) CopyObject(ctx context.Context, destKey string, srcBucket, srcKey string, cannedAcl cloudprovider.TBucketACLType, storageClassStr string, meta http.Header) error {
-	return cloudprovider.ErrNotSupported
+	header := ufileMetaHeader(meta)
+	header.Set("X-Ufile-Copy-Source", "/"+srcBucket+"/"+srcKey)
+	if len(storageClassStr) > 0 {
+		header.Set("X-Ufile-Storage-Class", storageClassStr)
+	}
+	req, err := b.newRequest(ctx, http.MethodPut, destKey, nil, header, nil)
+	if err != nil {
+		return errors.Wrap(err, "newRequest")
+	}
+	resp, err := b.doRawRequest(req)
+	if err != nil {
+		return errors.Wrapf(err, "CopyObject %s/%s => %s", srcBucket, srcKey, destKey)
+	}
+	resp.Body.Close()
+	return nil
 }
 
+// https://docs.ucloud.cn/ufile/api/get_file
 func (b *SBucket) GetObject(ctx context.Context, key string, rangeOpt *cloudprovider.SGetObjectRange) (io.ReadCloser, error) {
-	return nil, cloudprovider.ErrNotSupported
+	header := http.Header{}
+	if rangeOpt != nil && len(rangeOpt.String()) > 0 {
+		header.Set("Range", rangeOpt.String())
+	}
+	req, err := b.newRequest(ctx, http.MethodGet, key, nil, header, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "newRequest")
+	}
+	resp, err := b.doRawRequest(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetObject %s", key)
+	}
+	return resp.Body, nil
 }
 
 func (b *SBucket) CopyPart(ctx context.Context, key string, uploadId string, partIndex int, srcBucketName string, srcKey string, srcOffset int64, srcLength int64) (string, error) {
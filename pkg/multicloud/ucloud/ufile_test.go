@@ -0,0 +1,76 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestBucketNewRequest(t *testing.T) {
+	bucket := &SBucket{
+		region: &SRegion{
+			client: &SUcloudClient{
+				UcloudClientConfig: &UcloudClientConfig{
+					accessKeyId:     "ak",
+					accessKeySecret: "sk",
+				},
+			},
+		},
+		BucketName: "test",
+		Region:     "cn-bj",
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		query url.Values
+		want  string
+	}{
+		{
+			name: "object",
+			key:  "dir/a b.txt",
+			want: "https://test.cn-bj.ufileos.com/dir/a%20b.txt",
+		},
+		{
+			name:  "initiate multipart upload",
+			key:   "obj",
+			query: url.Values{"uploads": []string{""}},
+			want:  "https://test.cn-bj.ufileos.com/obj?uploads",
+		},
+		{
+			name:  "prefix file list",
+			query: url.Values{"list": []string{""}, "prefix": []string{"dir/"}},
+			want:  "https://test.cn-bj.ufileos.com/?list&prefix=dir%2F",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := bucket.newRequest(context.Background(), http.MethodGet, tt.key, tt.query, nil, nil)
+			if err != nil {
+				t.Fatalf("newRequest error: %v", err)
+			}
+			if got := req.URL.String(); got != tt.want {
+				t.Errorf("newRequest() url = %s, want %s", got, tt.want)
+			}
+			want := "UCloud ak:" + bucket.region.client.signRequest(http.MethodGet, "", "", "", "/test/"+tt.key)
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("newRequest() auth = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ucloud
+
+import (
+	"fmt"
+	"time"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+	"yunion.io/x/pkg/util/billing"
+
+	billing_api "yunion.io/x/cloudmux/pkg/apis/billing"
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// 主备版 Redis(URedisGroup) 与 Memcache(UMemcacheGroup)
+type SElasticcache struct {
+	multicloud.SElasticcacheBase
+	UcloudTags
+	region *SRegion
+	engine string
+
+	GroupId    string
+	Name       string
+	Zone       string
+	VirtualIP  string
+	Port       int
+	Size       int
+	UsedSize   int
+	Version    string
+	State      string
+	ChargeType string
+	CreateTime int64
+	ExpireTime int64
+	// single, double
+	Type             string
+	HighAvailability string
+	VPCId            string
+	SubnetId         string
+	BackupTime       int
+}
+
+func (self *SElasticcache) GetId() string {
+	return self.GroupId
+}
+
+func (self *SElasticcache) GetName() string {
+	return self.Name
+}
+
+func (self *SElasticcache) GetGlobalId() string {
+	return self.GroupId
+}
+
+func (self *SElasticcache) GetProjectId() string {
+	return self.region.client.projectId
+}
+
+func (self *SElasticcache) GetStatus() string {
+	switch self.State {
+	case "Creating":
+		return api.ELASTIC_CACHE_STATUS_DEPLOYING
+	case "CreateFail":
+		return api.ELASTIC_CACHE_STATUS_CREATE_FAILED
+	case "Deleting":
+		return api.ELASTIC_CACHE_STATUS_DELETING
+	case "DeleteFail":
+		return api.ELASTIC_CACHE_STATUS_ERROR
+	case "Running":
+		return api.ELASTIC_CACHE_STATUS_RUNNING
+	case "Resizing", "Configing":
+		return api.ELASTIC_CACHE_STATUS_CHANGING
+	case "ResizeFail", "ConfigFail", "SetPasswordFail":
+		return api.ELASTIC_CACHE_STATUS_CHANGE_FAILED
+	case "Restarting":
+		return api.ELASTIC_CACHE_STATUS_RESTARTING
+	default:
+		return api.ELASTIC_CACHE_STATUS_UNKNOWN
+	}
+}
+
+func (self *SElasticcache) Refresh() error {
+	cache, err := self.region.GetElasticcache(self.GroupId)
+	if err != nil {
+		return err
+	}
+	return jsonutils.Update(self, cache)
+}
+
+func (self *SElasticcache) GetCreatedAt() time.Time {
+	return time.Unix(self.CreateTime, 0)
+}
+
+func (self *SElasticcache) GetBillingType() string {
+	switch self.ChargeType {
+	case "Year", "Month":
+		return billing_api.BILLING_TYPE_PREPAID
+	default:
+		return billing_api.BILLING_TYPE_POSTPAID
+	}
+}
+
+func (self *SElasticcache) GetExpiredAt() time.Time {
+	if self.ExpireTime > 0 && self.GetBillingType() == billing_api.BILLING_TYPE_PREPAID {
+		return time.Unix(self.ExpireTime, 0)
+	}
+	return time.Time{}
+}
+
+func (self *SElasticcache) GetInstanceType() string {
+	return fmt.Sprintf("%s.%dG", self.engine, self.Size)
+}
+
+func (self *SElasticcache) GetCapacityMB() int {
+	return self.Size * 1024
+}
+
+func (self *SElasticcache) GetArchType() string {
+	if self.Type == "double" || self.HighAvailability == "enable" {
+		return api.ELASTIC_CACHE_ARCH_TYPE_MASTER
+	}
+	return api.ELASTIC_CACHE_ARCH_TYPE_SINGLE
+}
+
+func (self *SElasticcache) GetNodeType() string {
+	if self.GetArchType() == api.ELASTIC_CACHE_ARCH_TYPE_MASTER {
+		return api.ELASTIC_CACHE_NODE_TYPE_DOUBLE
+	}
+	return api.ELASTIC_CACHE_NODE_TYPE_SINGLE
+}
+
+func (self *SElasticcache) GetEngine() string {
+	return self.engine
+}
+
+func (self *SElasticcache) GetEngineVersion() string {
+	return self.Version
+}
+
+func (self *SElasticcache) GetVpcId() string {
+	return self.VPCId
+}
+
+func (self *SElasticcache) GetZoneId() string {
+	if len(self.Zone) > 0 {
+		return fmt.Sprintf("%s/%s", self.region.GetGlobalId(), self.Zone)
+	}
+	return ""
+}
+
+func (self *SElasticcache) GetNetworkType() string {
+	return api.LB_NETWORK_TYPE_VPC
+}
+
+func (self *SElasticcache) GetNetworkId() string {
+	return self.SubnetId
+}
+
+func (self *SElasticcache) GetPrivateDNS() string {
+	return ""
+}
+
+func (self *SElasticcache) GetPrivateIpAddr() string {
+	return self.VirtualIP
+}
+
+func (self *SElasticcache) GetPrivateConnectPort() int {
+	return self.Port
+}
+
+func (self *SElasticcache) GetPublicDNS() string {
+	return ""
+}
+
+func (self *SElasticcache) GetPublicIpAddr() string {
+	return ""
+}
+
+func (self *SElasticcache) GetPublicConnectPort() int {
+	return 0
+}
+
+func (self *SElasticcache) GetMaintainStartTime() string {
+	return ""
+}
+
+func (self *SElasticcache) GetMaintainEndTime() string {
+	return ""
+}
+
+func (self *SElasticcache) GetAuthMode() string {
+	return ""
+}
+
+func (self *SElasticcache) GetSecurityGroupIds() ([]string, error) {
+	return []string{}, nil
+}
+
+func (self *SElasticcache) GetICloudElasticcacheAccounts() ([]cloudprovider.ICloudElasticcacheAccount, error) {
+	return []cloudprovider.ICloudElasticcacheAccount{}, nil
+}
+
+func (self *SElasticcache) GetICloudElasticcacheAcls() ([]cloudprovider.ICloudElasticcacheAcl, error) {
+	return []cloudprovider.ICloudElasticcacheAcl{}, nil
+}
+
+func (self *SElasticcache) GetICloudElasticcacheBackups() ([]cloudprovider.ICloudElasticcacheBackup, error) {
+	return []cloudprovider.ICloudElasticcacheBackup{}, nil
+}
+
+func (self *SElasticcache) GetICloudElasticcacheParameters() ([]cloudprovider.ICloudElasticcacheParameter, error) {
+	return []cloudprovider.ICloudElasticcacheParameter{}, nil
+}
+
+func (self *SElasticcache) GetICloudElasticcacheAccount(accountId string) (cloudprovider.ICloudElasticcacheAccount, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) GetICloudElasticcacheAcl(aclId string) (cloudprovider.ICloudElasticcacheAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) GetICloudElasticcacheBackup(backupId string) (cloudprovider.ICloudElasticcacheBackup, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) Restart() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) Delete() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) ChangeInstanceSpec(spec string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) SetMaintainTime(maintainStartTime, maintainEndTime string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) AllocatePublicConnection(port int) (string, error) {
+	return "", cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) ReleasePublicConnection() error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) CreateAccount(account cloudprovider.SCloudElasticCacheAccountInput) (cloudprovider.ICloudElasticcacheAccount, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) CreateAcl(aclName, securityIps string) (cloudprovider.ICloudElasticcacheAcl, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) CreateBackup(desc string) (cloudprovider.ICloudElasticcacheBackup, error) {
+	return nil, cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) FlushInstance(input cloudprovider.SCloudElasticCacheFlushInstanceInput) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) UpdateAuthMode(noPasswordAccess bool, password string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) UpdateInstanceParameters(config jsonutils.JSONObject) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) UpdateBackupPolicy(config cloudprovider.SCloudElasticCacheBackupPolicyUpdateInput) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) UpdateSecurityGroups(secgroupIds []string) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SElasticcache) Renew(bc billing.SBillingCycle) error {
+	return cloudprovider.ErrNotSupported
+}
+
+func (self *SRegion) getElasticcaches(action, engine, groupId string) ([]SElasticcache, error) {
+	params := NewUcloudParams()
+	if len(groupId) > 0 {
+		params.Set("GroupId", groupId)
+	}
+	caches := make([]SElasticcache, 0)
+	err := self.DoListAll(action, params, &caches)
+	if err != nil {
+		return nil, errors.Wrapf(err, action)
+	}
+	for i := range caches {
+		caches[i].region = self
+		caches[i].engine = engine
+	}
+	return caches, nil
+}
+
+// https://docs.ucloud.cn/api/umem-api/describe_uredis_group
+// https://docs.ucloud.cn/api/umem-api/describe_umemcache_group
+func (self *SRegion) GetElasticcaches(groupId string) ([]SElasticcache, error) {
+	redis, err := self.getElasticcaches("DescribeURedisGroup", api.ELASTIC_CACHE_ENGINE_REDIS, groupId)
+	if err != nil {
+		return nil, err
+	}
+	memcache, err := self.getElasticcaches("DescribeUMemcacheGroup", api.ELASTIC_CACHE_ENGINE_MEMCACHED, groupId)
+	if err != nil {
+		return nil, err
+	}
+	return append(redis, memcache...), nil
+}
+
+func (self *SRegion) GetElasticcache(groupId string) (*SElasticcache, error) {
+	caches, err := self.GetElasticcaches(groupId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range caches {
+		if caches[i].GroupId == groupId {
+			return &caches[i], nil
+		}
+	}
+	return nil, errors.Wrapf(cloudprovider.ErrNotFound, groupId)
+}
+
+func (self *SRegion) GetIElasticcaches() ([]cloudprovider.ICloudElasticcache, error) {
+	caches, err := self.GetElasticcaches("")
+	if err != nil {
+		return nil, err
+	}
+	ret := []cloudprovider.ICloudElasticcache{}
+	for i := range caches {
+		ret = append(ret, &caches[i])
+	}
+	return ret, nil
+}
+
+func (self *SRegion) GetIElasticcacheById(id string) (cloudprovider.ICloudElasticcache, error) {
+	return self.GetElasticcache(id)
+}
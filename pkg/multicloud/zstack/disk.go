@@ -142,6 +142,14 @@ func (disk *SDisk) GetSysTags() map[string]string {
 	return data
 }
 
+func (disk *SDisk) GetTags() (map[string]string, error) {
+	return disk.region.GetResourceTags("VolumeVO", disk.UUID)
+}
+
+func (disk *SDisk) SetTags(tags map[string]string, replace bool) error {
+	return disk.region.SetResourceTags("VolumeVO", disk.UUID, tags, replace)
+}
+
 func (disk *SDisk) GetId() string {
 	return disk.UUID
 }
@@ -415,5 +423,5 @@ func (region *SRegion) RebuildDisk(diskId string) error {
 }
 
 func (disk *SDisk) GetProjectId() string {
-	return ""
+	return disk.region.getProjectId(disk.UUID)
 }
@@ -162,7 +162,7 @@ func (eip *SEipAddress) ChangeBandwidth(bw int) error {
 }
 
 func (eip *SEipAddress) GetProjectId() string {
-	return ""
+	return eip.region.getProjectId(eip.UUID)
 }
 
 func (region *SRegion) CreateEip(name string, vipId string, desc string) (*SEipAddress, error) {
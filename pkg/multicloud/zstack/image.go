@@ -204,6 +204,43 @@ func (image *SImage) GetCreatedAt() time.Time {
 	return image.CreateDate
 }
 
+type SExportedImage struct {
+	ImageURL     string `json:"imageUrl"`
+	ExportMd5Sum string `json:"exportMd5Sum"`
+}
+
+func (region *SRegion) ExportImage(backupStorageId, imageId, format string) (*SExportedImage, error) {
+	params := map[string]interface{}{
+		"exportImageFromBackupStorage": map[string]string{
+			"imageUuid":    imageId,
+			"exportFormat": format,
+		},
+	}
+	resp, err := region.client.put("backup-storage", backupStorageId, jsonutils.Marshal(params))
+	if err != nil {
+		return nil, err
+	}
+	ret := &SExportedImage{}
+	err = resp.Unmarshal(ret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unmarshal")
+	}
+	if len(ret.ImageURL) == 0 {
+		return nil, errors.Errorf("empty export url for image %s", imageId)
+	}
+	return ret, nil
+}
+
+func (region *SRegion) DeleteExportedImage(backupStorageId, imageId string) error {
+	params := map[string]interface{}{
+		"deleteExportedImageFromBackupStorage": map[string]string{
+			"imageUuid": imageId,
+		},
+	}
+	_, err := region.client.put("backup-storage", backupStorageId, jsonutils.Marshal(params))
+	return err
+}
+
 func (region *SRegion) GetImage(imageId string) (*SImage, error) {
 	image := &SImage{}
 	err := region.client.getResource("images", imageId, image)
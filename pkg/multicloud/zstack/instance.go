@@ -122,6 +122,14 @@ func (instance *SInstance) GetIHostId() string {
 	return instance.HostUUID
 }
 
+func (instance *SInstance) GetTags() (map[string]string, error) {
+	return instance.host.zone.region.GetResourceTags("VmInstanceVO", instance.UUID)
+}
+
+func (instance *SInstance) SetTags(tags map[string]string, replace bool) error {
+	return instance.host.zone.region.SetResourceTags("VmInstanceVO", instance.UUID, tags, replace)
+}
+
 func (instance *SInstance) GetId() string {
 	return instance.UUID
 }
@@ -645,7 +653,7 @@ func (instance *SInstance) Renew(bc billing.SBillingCycle) error {
 }
 
 func (instance *SInstance) GetProjectId() string {
-	return ""
+	return instance.host.zone.region.getProjectId(instance.UUID)
 }
 
 func (instance *SInstance) GetError() error {
@@ -120,6 +120,15 @@ func (region *SRegion) GetNetworks(zoneId string, wireId string, l3Id string, ne
 	return networks, nil
 }
 
+// tags are saved on the l3 network which the ip range belongs to
+func (network *SNetwork) GetTags() (map[string]string, error) {
+	return network.wire.vpc.region.GetResourceTags("L3NetworkVO", network.L3NetworkUUID)
+}
+
+func (network *SNetwork) SetTags(tags map[string]string, replace bool) error {
+	return network.wire.vpc.region.SetResourceTags("L3NetworkVO", network.L3NetworkUUID, tags, replace)
+}
+
 func (network *SNetwork) GetId() string {
 	return network.UUID
 }
@@ -218,7 +227,7 @@ func (network *SNetwork) Refresh() error {
 }
 
 func (network *SNetwork) GetProjectId() string {
-	return ""
+	return network.wire.vpc.region.getProjectId(network.L3NetworkUUID)
 }
 
 func (region *SRegion) CreateNetwork(name string, cidr string, wireId string, desc string) (*SNetwork, error) {
@@ -0,0 +1,144 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zstack
+
+import (
+	"net/url"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/errors"
+
+	api "yunion.io/x/cloudmux/pkg/apis/compute"
+	"yunion.io/x/cloudmux/pkg/cloudprovider"
+	"yunion.io/x/cloudmux/pkg/multicloud"
+)
+
+// IAM2 project, resources belong to the account linked with the project
+type SProject struct {
+	multicloud.SProjectBase
+	ZStackTags
+	ZStackTime
+
+	UUID              string `json:"uuid"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	State             string `json:"state"`
+	LinkedAccountUUID string `json:"linkedAccountUuid"`
+}
+
+type SAccountResourceRef struct {
+	ZStackTime
+	AccountUUID      string `json:"accountUuid"`
+	OwnerAccountUUID string `json:"ownerAccountUuid"`
+	ResourceUUID     string `json:"resourceUuid"`
+	ResourceType     string `json:"resourceType"`
+	Permission       int    `json:"permission"`
+	IsShared         bool   `json:"isShared"`
+}
+
+// project is identified by the linked account, which is the owner of resources
+func (project *SProject) GetId() string {
+	if len(project.LinkedAccountUUID) > 0 {
+		return project.LinkedAccountUUID
+	}
+	return project.UUID
+}
+
+func (project *SProject) GetGlobalId() string {
+	return project.GetId()
+}
+
+func (project *SProject) GetName() string {
+	return project.Name
+}
+
+func (project *SProject) GetStatus() string {
+	if project.State == "Enabled" {
+		return api.EXTERNAL_PROJECT_STATUS_AVAILABLE
+	}
+	return api.EXTERNAL_PROJECT_STATUS_UNAVAILABLE
+}
+
+func (cli *SZStackClient) GetProjects() ([]SProject, error) {
+	projects := []SProject{}
+	return projects, cli.listAll("iam2/projects", url.Values{}, &projects)
+}
+
+func (cli *SZStackClient) CreateProject(name, desc string) (*SProject, error) {
+	params := map[string]interface{}{
+		"params": map[string]string{
+			"name":        name,
+			"description": desc,
+		},
+	}
+	project := &SProject{}
+	return project, cli.create("iam2/projects", jsonutils.Marshal(params), project)
+}
+
+func (cli *SZStackClient) GetIProjects() ([]cloudprovider.ICloudProject, error) {
+	projects, err := cli.GetProjects()
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetProjects")
+	}
+	ret := []cloudprovider.ICloudProject{}
+	for i := range projects {
+		ret = append(ret, &projects[i])
+	}
+	return ret, nil
+}
+
+func (cli *SZStackClient) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	return cli.CreateProject(name, "")
+}
+
+func (cli *SZStackClient) GetResourceRefs() ([]SAccountResourceRef, error) {
+	refs := []SAccountResourceRef{}
+	return refs, cli.listAll("accounts/resources/refs", url.Values{}, &refs)
+}
+
+// 一次性加载资源与所属账号的对应关系, 避免每个资源单独查询
+func (cli *SZStackClient) getResourceOwners() map[string]string {
+	cli.ownerLock.Lock()
+	defer cli.ownerLock.Unlock()
+
+	if cli.resourceOwners != nil {
+		return cli.resourceOwners
+	}
+	owners := map[string]string{}
+	refs, err := cli.GetResourceRefs()
+	if err != nil {
+		log.Errorf("GetResourceRefs error: %v", err)
+		return owners
+	}
+	for i := range refs {
+		owner := refs[i].OwnerAccountUUID
+		if len(owner) == 0 {
+			owner = refs[i].AccountUUID
+		}
+		if len(owner) == 0 {
+			continue
+		}
+		if _, ok := owners[refs[i].ResourceUUID]; !ok {
+			owners[refs[i].ResourceUUID] = owner
+		}
+	}
+	cli.resourceOwners = owners
+	return owners
+}
+
+func (region *SRegion) getProjectId(resourceId string) string {
+	return region.client.getResourceOwners()[resourceId]
+}
@@ -144,6 +144,10 @@ func (self *SZStackProvider) GetIProjects() ([]cloudprovider.ICloudProject, erro
 	return self.client.GetIProjects()
 }
 
+func (self *SZStackProvider) CreateIProject(name string) (cloudprovider.ICloudProject, error) {
+	return self.client.CreateIProject(name)
+}
+
 func (self *SZStackProvider) GetStorageClasses(regionId string) []string {
 	return nil
 }
@@ -87,6 +87,14 @@ func (self *SSecurityGroup) GetVpcId() string {
 	return api.NORMAL_VPC_ID
 }
 
+func (self *SSecurityGroup) GetTags() (map[string]string, error) {
+	return self.region.GetResourceTags("SecurityGroupVO", self.UUID)
+}
+
+func (self *SSecurityGroup) SetTags(tags map[string]string, replace bool) error {
+	return self.region.SetResourceTags("SecurityGroupVO", self.UUID, tags, replace)
+}
+
 func (self *SSecurityGroup) GetId() string {
 	return self.UUID
 }
@@ -160,7 +168,7 @@ func (self *SSecurityGroup) Refresh() error {
 }
 
 func (self *SSecurityGroup) GetProjectId() string {
-	return ""
+	return self.region.getProjectId(self.UUID)
 }
 
 func (region *SRegion) AddSecurityGroupRule(secgroupId string, rules []cloudprovider.SecurityRule) error {
@@ -70,4 +70,23 @@ func init() {
 		return cli.DeleteImage(args.ID)
 	})
 
+	type ImageDownloadOptions struct {
+		STORAGE_ID string
+		ID         string
+		PATH       string
+	}
+
+	shellutils.R(&ImageDownloadOptions{}, "image-download", "download image", func(cli *zstack.SRegion, args *ImageDownloadOptions) error {
+		cache, err := cli.GetIStoragecacheById(args.STORAGE_ID)
+		if err != nil {
+			return err
+		}
+		ret, err := cache.DownloadImage(args.ID, args.ID, args.PATH)
+		if err != nil {
+			return err
+		}
+		printObject(ret)
+		return nil
+	})
+
 }
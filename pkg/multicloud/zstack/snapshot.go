@@ -124,7 +124,7 @@ func (region *SRegion) DeleteSnapshot(snapshotId string) error {
 }
 
 func (snapshot *SSnapshot) GetProjectId() string {
-	return ""
+	return snapshot.region.getProjectId(snapshot.UUID)
 }
 
 func (region *SRegion) CreateSnapshot(name, diskId, desc string) (*SSnapshot, error) {
@@ -102,10 +102,12 @@ func (scache *SStoragecache) UploadImage(ctx context.Context, image *cloudprovid
 }
 
 func (self *SStoragecache) uploadImage(ctx context.Context, image *cloudprovider.SImageCreateOption, callback func(progress float32)) (string, error) {
-	reader, size, err := image.GetReader(image.ImageId, string(qemuimgfmt.QCOW2))
+	reader, err := multicloud.GetImageReader(ctx, image, qemuimgfmt.QCOW2, callback)
 	if err != nil {
-		return "", errors.Wrapf(err, "GetReader")
+		return "", errors.Wrapf(err, "GetImageReader")
 	}
+	defer reader.Close()
+	size, callback := reader.Size, reader.Callback
 
 	// size, _ := meta.Int("size")
 	img, err := self.region.CreateImage(self.ZoneId, image.ImageName, string(qemuimgfmt.QCOW2), image.OsType, "", reader, size, callback)
@@ -128,5 +130,52 @@ func (scache *SStoragecache) CreateIImage(snapshoutId, imageName, osType, imageD
 }
 
 func (scache *SStoragecache) DownloadImage(imageId string, extId string, path string) (jsonutils.JSONObject, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return scache.ExportImage(context.Background(), extId, &multicloud.SImageDownloadOptions{
+		Path: path,
+		Callback: func(progress float32) {
+			log.Debugf("download image %s(%s) progress: %.2f%%", imageId, extId, progress)
+		},
+	})
+}
+
+// ExportImage exports the image on image server and downloads it from the exported url
+func (scache *SStoragecache) ExportImage(ctx context.Context, imageId string, opts *multicloud.SImageDownloadOptions) (jsonutils.JSONObject, error) {
+	image, err := scache.region.GetImage(imageId)
+	if err != nil {
+		return nil, err
+	}
+	if len(image.BackupStorageRefs) == 0 {
+		return nil, fmt.Errorf("image %s not in any backup storage", imageId)
+	}
+	bsId := image.BackupStorageRefs[0].BackupStorageUUID
+	export, err := scache.region.ExportImage(bsId, image.UUID, image.Format)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ExportImage")
+	}
+	defer func() {
+		err := scache.region.DeleteExportedImage(bsId, image.UUID)
+		if err != nil {
+			log.Warningf("delete exported image %s error: %v", image.UUID, err)
+		}
+	}()
+
+	resp, err := scache.region.client.request(ctx, "GET", export.ImageURL, nil, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "download %s", export.ImageURL)
+	}
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download %s status %d", export.ImageURL, resp.StatusCode)
+	}
+	size := resp.ContentLength
+	if size <= 0 {
+		size = int64(image.ActualSize)
+	}
+	stream := &multicloud.SImageStream{
+		Body:   resp.Body,
+		Size:   size,
+		Format: image.Format,
+		Md5:    export.ExportMd5Sum,
+	}
+	return multicloud.SaveImage(ctx, stream, opts)
 }
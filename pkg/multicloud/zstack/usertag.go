@@ -0,0 +1,122 @@
+// Copyright 2019 Yunion
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zstack
+
+import (
+	"net/url"
+	"strings"
+
+	"yunion.io/x/jsonutils"
+	"yunion.io/x/pkg/errors"
+)
+
+const (
+	ZSTACK_TAG_SEPARATOR = "::"
+)
+
+type SUserTag struct {
+	ZStackTime
+	ResourceType string `json:"resourceType"`
+	ResourceUUID string `json:"resourceUuid"`
+	Tag          string `json:"tag"`
+	Type         string `json:"type"`
+	UUID         string `json:"uuid"`
+}
+
+// user tag is a plain string, key and value are saved as key::value
+func (tag *SUserTag) split() (string, string) {
+	parts := strings.SplitN(tag.Tag, ZSTACK_TAG_SEPARATOR, 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return tag.Tag, ""
+}
+
+func (region *SRegion) GetResourceUserTags(resourceType string, resourceId string) ([]SUserTag, error) {
+	tags := []SUserTag{}
+	params := url.Values{}
+	if len(resourceType) > 0 {
+		params.Add("q", "resourceType="+resourceType)
+	}
+	if len(resourceId) > 0 {
+		params.Add("q", "resourceUuid="+resourceId)
+	}
+	return tags, region.client.listAll("user-tags", params, &tags)
+}
+
+func (region *SRegion) CreateUserTag(resourceType string, resourceId string, tag string) error {
+	params := map[string]interface{}{
+		"params": map[string]string{
+			"resourceType": resourceType,
+			"resourceUuid": resourceId,
+			"tag":          tag,
+		},
+	}
+	_, err := region.client.post("user-tags", jsonutils.Marshal(params))
+	return err
+}
+
+func (region *SRegion) DeleteUserTag(tagId string) error {
+	return region.client.delete("user-tags", tagId, "")
+}
+
+func (region *SRegion) GetResourceTags(resourceType string, resourceId string) (map[string]string, error) {
+	tags, err := region.GetResourceUserTags(resourceType, resourceId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetResourceUserTags(%s, %s)", resourceType, resourceId)
+	}
+	ret := map[string]string{}
+	for i := range tags {
+		k, v := tags[i].split()
+		ret[k] = v
+	}
+	return ret, nil
+}
+
+func (region *SRegion) SetResourceTags(resourceType string, resourceId string, tags map[string]string, replace bool) error {
+	oldTags, err := region.GetResourceUserTags(resourceType, resourceId)
+	if err != nil {
+		return errors.Wrapf(err, "GetResourceUserTags(%s, %s)", resourceType, resourceId)
+	}
+	exists := map[string]bool{}
+	for i := range oldTags {
+		k, v := oldTags[i].split()
+		newValue, ok := tags[k]
+		if ok && newValue == v {
+			exists[k] = true
+			continue
+		}
+		if ok || replace {
+			err = region.DeleteUserTag(oldTags[i].UUID)
+			if err != nil {
+				return errors.Wrapf(err, "DeleteUserTag(%s)", oldTags[i].Tag)
+			}
+		}
+	}
+	for k, v := range tags {
+		if exists[k] {
+			continue
+		}
+		tag := k
+		if len(v) > 0 {
+			tag = k + ZSTACK_TAG_SEPARATOR + v
+		}
+		err = region.CreateUserTag(resourceType, resourceId, tag)
+		if err != nil {
+			return errors.Wrapf(err, "CreateUserTag(%s)", tag)
+		}
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"yunion.io/x/jsonutils"
@@ -81,6 +82,10 @@ type SZStackClient struct {
 	httpClient *http.Client
 
 	iregions []cloudprovider.ICloudRegion
+
+	ownerLock sync.Mutex
+	// resource id => owner account id
+	resourceOwners map[string]string
 }
 
 func getTime() string {
@@ -512,13 +517,9 @@ func (cli *SZStackClient) GetRegions() []SRegion {
 	return regions
 }
 
-func (cli *SZStackClient) GetIProjects() ([]cloudprovider.ICloudProject, error) {
-	return nil, cloudprovider.ErrNotImplemented
-}
-
 func (self *SZStackClient) GetCapabilities() []string {
 	caps := []string{
-		// cloudprovider.CLOUD_CAPABILITY_PROJECT,
+		cloudprovider.CLOUD_CAPABILITY_PROJECT,
 		cloudprovider.CLOUD_CAPABILITY_COMPUTE,
 		cloudprovider.CLOUD_CAPABILITY_NETWORK,
 		cloudprovider.CLOUD_CAPABILITY_EIP,